@@ -4,12 +4,20 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
+
+	"live-stream-alerts/internal/clientip"
 )
 
 const (
 	defaultAddr = "127.0.0.1"
 	defaultPort = ":8880"
+
+	// StorageJSON keeps streamer records in data/streamers.json.
+	StorageJSON = "json"
+	// StorageSQLite keeps streamer records in a SQLite database.
+	StorageSQLite = "sqlite"
 )
 
 // YouTubeConfig captures the WebSub-specific defaults persisted in config files.
@@ -19,26 +27,189 @@ type YouTubeConfig struct {
 	LeaseSeconds int    `json:"lease_seconds"`
 	Mode         string `json:"mode"`
 	Verify       string `json:"verify"`
+	// SignatureMode controls how X-Hub-Signature mismatches are handled: "enforce" (default) or "log".
+	SignatureMode string `json:"signature_mode"`
+}
+
+// TwitchConfig captures the Helix credentials and EventSub webhook settings.
+type TwitchConfig struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	CallbackURL  string `json:"callback_url"`
+	// EventSubSecret signs every EventSub subscription and verifies incoming webhook payloads.
+	EventSubSecret string `json:"eventsub_secret"`
+	HelixURL       string `json:"helix_url"`
+	AuthURL        string `json:"auth_url"`
+}
+
+// FacebookConfig captures the Graph API webhook settings for Page live_videos changes.
+type FacebookConfig struct {
+	// AppSecret verifies the X-Hub-Signature-256 header on webhook deliveries.
+	AppSecret string `json:"app_secret"`
+	// VerifyToken must match hub.verify_token when Facebook verifies the endpoint.
+	VerifyToken string `json:"verify_token"`
+	GraphURL    string `json:"graph_url"`
+}
+
+// NotificationsConfig configures outbound go-live alerts.
+type NotificationsConfig struct {
+	OutboxPath  string                   `json:"outbox_path"`
+	MaxAttempts int                      `json:"max_attempts"`
+	Sinks       []NotificationSinkConfig `json:"sinks"`
+}
+
+// NotificationSinkConfig describes a Discord, Slack or generic webhook destination.
+type NotificationSinkConfig struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	URL      string `json:"url"`
+	Template string `json:"template"`
+	// Templates overrides Template for individual streamer IDs.
+	Templates map[string]string `json:"templates"`
+}
+
+// StorageConfig selects the streamer repository backend.
+type StorageConfig struct {
+	// Backend is "json" (default) or "sqlite".
+	Backend string `json:"backend"`
+	// Path overrides the backend's default file location.
+	Path string `json:"path"`
+	// JSONPath is the streamers.json the sqlite backend imports on its first start
+	// (default data/streamers.json). The json backend keeps its records at Path.
+	JSONPath string `json:"json_path"`
+	// BackupDir holds rolling backups of the JSON stores (default data/backups).
+	BackupDir string `json:"backup_dir"`
+	// BackupRetention is how many backups are kept per file; negative disables backups.
+	BackupRetention int `json:"backup_retention"`
+	// BackupIntervalSeconds is the least time between two backups of one file (default
+	// 60); saves within it are not backed up. Negative backs up every save.
+	BackupIntervalSeconds int `json:"backup_interval_seconds"`
+	// LockTimeoutSeconds bounds how long a write waits for another process holding a
+	// JSON store's file lock (default 5).
+	LockTimeoutSeconds int `json:"lock_timeout_seconds"`
+}
+
+// SessionsConfig controls the live-session history.
+type SessionsConfig struct {
+	// Path overrides where history is stored (default data/sessions.json).
+	Path string `json:"path"`
+	// RetentionDays drops sessions that ended longer ago (default 365); negative keeps
+	// them forever.
+	RetentionDays int `json:"retention_days"`
+	// MaxPerStreamer keeps only each streamer's newest ended sessions (default 500);
+	// negative is unlimited.
+	MaxPerStreamer int `json:"max_per_streamer"`
+}
+
+// RetryQueueConfig controls the queue that re-processes WebSub notifications whose
+// live lookup failed.
+type RetryQueueConfig struct {
+	// Path overrides where jobs are stored (default data/jobs.json).
+	Path    string `json:"path"`
+	Workers int    `json:"workers"`
+	// MaxAttempts is how many attempts a job gets before it is dead-lettered (default 8).
+	MaxAttempts        int `json:"max_attempts"`
+	BaseBackoffSeconds int `json:"base_backoff_seconds"`
+	MaxBackoffSeconds  int `json:"max_backoff_seconds"`
+	// DeadLetterLimit caps the dead-letter list (default 500); negative is unlimited.
+	DeadLetterLimit int `json:"dead_letter_limit"`
+}
+
+// FeedPollConfig controls the videos.xml poller that backs up WebSub deliveries.
+type FeedPollConfig struct {
+	// FeedURL overrides the YouTube feed endpoint (default https://www.youtube.com/feeds/videos.xml).
+	FeedURL string `json:"feed_url"`
+	// IntervalSeconds is the cadence for channels with a healthy lease (default 900);
+	// negative disables polling.
+	IntervalSeconds int `json:"interval_seconds"`
+	// FastIntervalSeconds is the cadence for channels whose lease is expired or pending
+	// (default 120).
+	FastIntervalSeconds int `json:"fast_interval_seconds"`
+	MaxBackoffSeconds   int `json:"max_backoff_seconds"`
+}
+
+// SubmissionsConfig guards the public submission endpoint against spam. Negative limits
+// disable the matching check.
+type SubmissionsConfig struct {
+	// MaxPerIP is how many submissions one client address may make per window (default 5).
+	MaxPerIP int `json:"max_per_ip"`
+	// WindowSeconds is the length of that window (default 3600).
+	WindowSeconds int `json:"window_seconds"`
+	// MaxPending caps how many submissions can await review at once (default 500).
+	MaxPending int `json:"max_pending"`
+	// ProofOfWorkBits requires a proof of work with this many leading zero bits (default 0,
+	// meaning none).
+	ProofOfWorkBits int `json:"proof_of_work_bits"`
+}
+
+// AuditConfig controls the append-only audit log of API changes.
+type AuditConfig struct {
+	// Path overrides where entries are appended (default data/audit.jsonl).
+	Path string `json:"path"`
+	// MaxFileBytes rotates the log once it would grow past this size (default 10 MiB).
+	MaxFileBytes int64 `json:"max_file_bytes"`
+	// MaxFiles is how many rotated files are kept (default 5).
+	MaxFiles int `json:"max_files"`
 }
 
 // ServerConfig configures the HTTP listener used by alert-server.
 type ServerConfig struct {
 	Addr string `json:"addr"`
 	Port string `json:"port"`
+	// TrustedProxies lists the reverse proxies (IP addresses or CIDR ranges) whose
+	// X-Forwarded-For header is believed when resolving client addresses.
+	TrustedProxies []string `json:"trusted_proxies,omitempty"`
+	// AllowedOrigins lists the browser origins, such as "https://overlay.example.com",
+	// that may open /api/streamers/ws besides the server's own. "*" allows any origin and
+	// "null" allows pages opened from file://.
+	AllowedOrigins []string `json:"allowed_origins,omitempty"`
 }
 
 // AdminConfig stores credentials for admin-authenticated APIs.
 type AdminConfig struct {
+	// Email and Password seed the first admin account when the user store is empty.
 	Email           string `json:"email"`
 	Password        string `json:"password"`
 	TokenTTLSeconds int    `json:"token_ttl_seconds"`
+	// UsersPath overrides where admin accounts are stored (default data/admin_users.json).
+	UsersPath string `json:"users_path"`
+	// RefreshTTLSeconds is how long a session survives without a refresh (default 14 days).
+	RefreshTTLSeconds int `json:"refresh_ttl_seconds"`
+	// SessionsPath overrides where admin sessions are stored (default data/admin_sessions.json).
+	SessionsPath string `json:"sessions_path"`
+	// LoginThrottle locks out addresses and accounts after repeated failed logins.
+	LoginThrottle LoginThrottleConfig `json:"login_throttle"`
+}
+
+// LoginThrottleConfig tunes brute-force protection for /api/admin/login.
+type LoginThrottleConfig struct {
+	// MaxAccountFailures failed logins for one email lock it (default 5).
+	MaxAccountFailures int `json:"max_account_failures"`
+	// MaxIPFailures failed logins from one client address lock it (default 20).
+	MaxIPFailures int `json:"max_ip_failures"`
+	// BaseLockoutSeconds is the first lockout, doubled by each further failure (default 30).
+	BaseLockoutSeconds int `json:"base_lockout_seconds"`
+	// MaxLockoutSeconds caps the lockout (default 3600).
+	MaxLockoutSeconds int `json:"max_lockout_seconds"`
+	// WindowSeconds forgets failures after this long without one (default 900).
+	WindowSeconds int `json:"window_seconds"`
 }
 
 // Config represents the combined runtime settings parsed from config.json.
 type Config struct {
-	Server  ServerConfig
-	YouTube YouTubeConfig
-	Admin   AdminConfig
+	Server   ServerConfig
+	YouTube  YouTubeConfig
+	Admin    AdminConfig
+	Twitch   TwitchConfig
+	Facebook FacebookConfig
+
+	Notifications NotificationsConfig
+	Storage       StorageConfig
+	Sessions      SessionsConfig
+	RetryQueue    RetryQueueConfig
+	FeedPoll      FeedPollConfig
+	Audit         AuditConfig
+	Submissions   SubmissionsConfig
 }
 
 type fileConfig struct {
@@ -49,6 +220,15 @@ type fileConfig struct {
 	YouTubeConfig
 	AdminBlock *AdminConfig `json:"admin"`
 	AdminConfig
+	TwitchBlock        *TwitchConfig        `json:"twitch"`
+	FacebookBlock      *FacebookConfig      `json:"facebook"`
+	NotificationsBlock *NotificationsConfig `json:"notifications"`
+	StorageBlock       *StorageConfig       `json:"storage"`
+	SessionsBlock      *SessionsConfig      `json:"sessions"`
+	RetryQueueBlock    *RetryQueueConfig    `json:"retry_queue"`
+	FeedPollBlock      *FeedPollConfig      `json:"feed_poll"`
+	AuditBlock         *AuditConfig         `json:"audit"`
+	SubmissionsBlock   *SubmissionsConfig   `json:"submissions"`
 }
 
 // Load reads the JSON config at the given path and returns the parsed structure.
@@ -86,6 +266,16 @@ func Load(path string) (Config, error) {
 	if server.Port == "" {
 		server.Port = defaultPort
 	}
+	for _, proxy := range server.TrustedProxies {
+		if _, err := clientip.ParseProxy(proxy); err != nil {
+			return Config{}, fmt.Errorf("server.trusted_proxies: %w", err)
+		}
+	}
+	for _, origin := range server.AllowedOrigins {
+		if !validOrigin(origin) {
+			return Config{}, fmt.Errorf("server.allowed_origins: %q is not an origin such as https://example.com", origin)
+		}
+	}
 
 	admin := raw.AdminConfig
 	if raw.AdminBlock != nil {
@@ -94,11 +284,154 @@ func Load(path string) (Config, error) {
 	if admin.TokenTTLSeconds <= 0 {
 		admin.TokenTTLSeconds = 86400
 	}
+	if admin.RefreshTTLSeconds <= 0 {
+		admin.RefreshTTLSeconds = 14 * 86400
+	}
+	throttle := &admin.LoginThrottle
+	if throttle.MaxAccountFailures <= 0 {
+		throttle.MaxAccountFailures = 5
+	}
+	if throttle.MaxIPFailures <= 0 {
+		throttle.MaxIPFailures = 20
+	}
+	if throttle.BaseLockoutSeconds <= 0 {
+		throttle.BaseLockoutSeconds = 30
+	}
+	if throttle.MaxLockoutSeconds <= 0 {
+		throttle.MaxLockoutSeconds = 3600
+	}
+	if throttle.WindowSeconds <= 0 {
+		throttle.WindowSeconds = 900
+	}
+
+	var twitch TwitchConfig
+	if raw.TwitchBlock != nil {
+		twitch = *raw.TwitchBlock
+	}
+
+	var facebook FacebookConfig
+	if raw.FacebookBlock != nil {
+		facebook = *raw.FacebookBlock
+	}
+
+	var notifications NotificationsConfig
+	if raw.NotificationsBlock != nil {
+		notifications = *raw.NotificationsBlock
+	}
+
+	var storage StorageConfig
+	if raw.StorageBlock != nil {
+		storage = *raw.StorageBlock
+	}
+	if storage.LockTimeoutSeconds <= 0 {
+		storage.LockTimeoutSeconds = 5
+	}
+	if storage.BackupIntervalSeconds == 0 {
+		storage.BackupIntervalSeconds = 60
+	}
+	if storage.JSONPath == "" {
+		storage.JSONPath = "data/streamers.json"
+	}
+	switch storage.Backend {
+	case "":
+		storage.Backend = StorageJSON
+	case StorageJSON, StorageSQLite:
+	default:
+		return Config{}, fmt.Errorf("unsupported storage backend %q", storage.Backend)
+	}
+
+	var sessions SessionsConfig
+	if raw.SessionsBlock != nil {
+		sessions = *raw.SessionsBlock
+	}
+	if sessions.RetentionDays == 0 {
+		sessions.RetentionDays = 365
+	}
+	if sessions.MaxPerStreamer == 0 {
+		sessions.MaxPerStreamer = 500
+	}
+
+	var retryQueue RetryQueueConfig
+	if raw.RetryQueueBlock != nil {
+		retryQueue = *raw.RetryQueueBlock
+	}
+	if retryQueue.Workers <= 0 {
+		retryQueue.Workers = 2
+	}
+	if retryQueue.MaxAttempts <= 0 {
+		retryQueue.MaxAttempts = 8
+	}
+	if retryQueue.BaseBackoffSeconds <= 0 {
+		retryQueue.BaseBackoffSeconds = 30
+	}
+	if retryQueue.MaxBackoffSeconds <= 0 {
+		retryQueue.MaxBackoffSeconds = 1800
+	}
+	if retryQueue.DeadLetterLimit == 0 {
+		retryQueue.DeadLetterLimit = 500
+	}
+
+	var feedPoll FeedPollConfig
+	if raw.FeedPollBlock != nil {
+		feedPoll = *raw.FeedPollBlock
+	}
+	if feedPoll.IntervalSeconds == 0 {
+		feedPoll.IntervalSeconds = 900
+	}
+	if feedPoll.FastIntervalSeconds <= 0 {
+		feedPoll.FastIntervalSeconds = 120
+	}
+	if feedPoll.MaxBackoffSeconds <= 0 {
+		feedPoll.MaxBackoffSeconds = 3600
+	}
+
+	var auditCfg AuditConfig
+	if raw.AuditBlock != nil {
+		auditCfg = *raw.AuditBlock
+	}
+	if auditCfg.Path == "" {
+		auditCfg.Path = "data/audit.jsonl"
+	}
+	if auditCfg.MaxFileBytes <= 0 {
+		auditCfg.MaxFileBytes = 10 << 20
+	}
+	if auditCfg.MaxFiles <= 0 {
+		auditCfg.MaxFiles = 5
+	}
+
+	var submissionsCfg SubmissionsConfig
+	if raw.SubmissionsBlock != nil {
+		submissionsCfg = *raw.SubmissionsBlock
+	}
+	if submissionsCfg.MaxPerIP == 0 {
+		submissionsCfg.MaxPerIP = 5
+	}
+	if submissionsCfg.WindowSeconds <= 0 {
+		submissionsCfg.WindowSeconds = 3600
+	}
+	if submissionsCfg.MaxPending == 0 {
+		submissionsCfg.MaxPending = 500
+	}
+	if submissionsCfg.ProofOfWorkBits < 0 {
+		submissionsCfg.ProofOfWorkBits = 0
+	}
+	if submissionsCfg.ProofOfWorkBits > 32 {
+		return Config{}, fmt.Errorf("submissions.proof_of_work_bits must be at most 32, got %d", submissionsCfg.ProofOfWorkBits)
+	}
 
 	cfg := Config{
-		Server:  server,
-		YouTube: yt,
-		Admin:   admin,
+		Server:        server,
+		YouTube:       yt,
+		Admin:         admin,
+		Twitch:        twitch,
+		Facebook:      facebook,
+		Notifications: notifications,
+		Storage:       storage,
+		Sessions:      sessions,
+		RetryQueue:    retryQueue,
+		FeedPoll:      feedPoll,
+		Audit:         auditCfg,
+		Submissions:   submissionsCfg,
 	}
 
 	return cfg, nil
@@ -112,3 +445,18 @@ func MustLoad(path string) Config {
 	}
 	return cfg
 }
+
+// validOrigin accepts "*", "null", or a bare http(s) scheme and host with an optional port.
+func validOrigin(origin string) bool {
+	if origin == "*" || origin == "null" {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Host == "" || parsed.User != nil || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return false
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return false
+	}
+	return parsed.Path == "" || parsed.Path == "/"
+}
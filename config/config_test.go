@@ -23,8 +23,32 @@ func TestLoadAppliesDefaults(t *testing.T) {
 	if cfg.Server.Port != defaultPort {
 		t.Fatalf("expected default port %s, got %s", defaultPort, cfg.Server.Port)
 	}
-	if cfg.Admin.TokenTTLSeconds != 86400 {
-		t.Fatalf("expected default admin ttl, got %d", cfg.Admin.TokenTTLSeconds)
+	if cfg.Admin.TokenTTLSeconds != 86400 || cfg.Admin.RefreshTTLSeconds != 1209600 {
+		t.Fatalf("expected default admin ttls, got %+v", cfg.Admin)
+	}
+	if lt := cfg.Admin.LoginThrottle; lt.MaxAccountFailures != 5 || lt.MaxIPFailures != 20 || lt.BaseLockoutSeconds != 30 || lt.MaxLockoutSeconds != 3600 || lt.WindowSeconds != 900 {
+		t.Fatalf("expected default login throttle, got %+v", lt)
+	}
+	if cfg.Storage.Backend != StorageJSON {
+		t.Fatalf("expected json storage by default, got %q", cfg.Storage.Backend)
+	}
+	if cfg.Storage.LockTimeoutSeconds != 5 || cfg.Storage.BackupIntervalSeconds != 60 || cfg.Storage.JSONPath != "data/streamers.json" {
+		t.Fatalf("expected default lock timeout and backup interval, got %+v", cfg.Storage)
+	}
+	if cfg.Sessions.RetentionDays != 365 || cfg.Sessions.MaxPerStreamer != 500 {
+		t.Fatalf("expected default session retention, got %+v", cfg.Sessions)
+	}
+	if rq := cfg.RetryQueue; rq.Workers != 2 || rq.MaxAttempts != 8 || rq.BaseBackoffSeconds != 30 || rq.MaxBackoffSeconds != 1800 || rq.DeadLetterLimit != 500 {
+		t.Fatalf("expected default retry queue settings, got %+v", rq)
+	}
+	if fp := cfg.FeedPoll; fp.FeedURL != "" || fp.IntervalSeconds != 900 || fp.FastIntervalSeconds != 120 || fp.MaxBackoffSeconds != 3600 {
+		t.Fatalf("expected default feed poll settings, got %+v", fp)
+	}
+	if cfg.Audit != (AuditConfig{Path: "data/audit.jsonl", MaxFileBytes: 10 << 20, MaxFiles: 5}) {
+		t.Fatalf("expected default audit settings, got %+v", cfg.Audit)
+	}
+	if cfg.Submissions != (SubmissionsConfig{MaxPerIP: 5, WindowSeconds: 3600, MaxPending: 500}) {
+		t.Fatalf("expected default submission limits, got %+v", cfg.Submissions)
 	}
 }
 
@@ -32,9 +56,18 @@ func TestLoadHonoursOverrides(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "config.json")
 	data := `{
-		"server": {"addr":"0.0.0.0","port":":9999"},
+		"server": {"addr":"0.0.0.0","port":":9999","trusted_proxies":["10.0.0.0/8","192.0.2.1"],"allowed_origins":["https://overlay.example.com","null"]},
 		"youtube": {"hub_url":"https://hub","callback_url":"https://callback","lease_seconds":123},
-		"admin": {"email":"admin@example.com","password":"secret","token_ttl_seconds":10}
+		"admin": {"email":"admin@example.com","password":"secret","token_ttl_seconds":10,"users_path":"data/accounts.json","refresh_ttl_seconds":3600,"sessions_path":"data/logins.json","login_throttle":{"max_account_failures":3,"max_ip_failures":10,"base_lockout_seconds":5,"max_lockout_seconds":60,"window_seconds":120}},
+		"twitch": {"client_id":"cid","client_secret":"csecret","callback_url":"https://callback/alerts/twitch","eventsub_secret":"s3cret"},
+		"facebook": {"app_secret":"fbsecret","verify_token":"fbverify","graph_url":"http://127.0.0.1:9000/v19.0"},
+		"notifications": {"max_attempts":3,"sinks":[{"name":"ops","type":"discord","url":"https://discord/webhook","templates":{"demo":"{{.Alias}} live"}}]},
+		"storage": {"backend":"sqlite","path":"data/test.db","json_path":"data/legacy.json","backup_dir":"/var/backups/alerts","backup_retention":3,"backup_interval_seconds":-1,"lock_timeout_seconds":30},
+		"sessions": {"path":"data/history.json","retention_days":-1,"max_per_streamer":50},
+		"retry_queue": {"path":"data/retry.json","workers":4,"max_attempts":3,"base_backoff_seconds":5,"max_backoff_seconds":60,"dead_letter_limit":-1},
+		"feed_poll": {"feed_url":"http://127.0.0.1:9001/feeds/videos.xml","interval_seconds":-1,"fast_interval_seconds":30,"max_backoff_seconds":600},
+		"audit": {"path":"data/trail.jsonl","max_file_bytes":4096,"max_files":2},
+		"submissions": {"max_per_ip":-1,"window_seconds":60,"max_pending":10,"proof_of_work_bits":16}
 	}`
 	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
 		t.Fatalf("write config: %v", err)
@@ -44,15 +77,76 @@ func TestLoadHonoursOverrides(t *testing.T) {
 	if err != nil {
 		t.Fatalf("load: %v", err)
 	}
-	if cfg.Server.Addr != "0.0.0.0" || cfg.Server.Port != ":9999" {
+	if cfg.Server.Addr != "0.0.0.0" || cfg.Server.Port != ":9999" || len(cfg.Server.TrustedProxies) != 2 || len(cfg.Server.AllowedOrigins) != 2 {
 		t.Fatalf("server overrides not applied: %+v", cfg.Server)
 	}
 	if cfg.YouTube.HubURL != "https://hub" || cfg.YouTube.LeaseSeconds != 123 {
 		t.Fatalf("youtube overrides not applied: %+v", cfg.YouTube)
 	}
-	if cfg.Admin.Email != "admin@example.com" || cfg.Admin.TokenTTLSeconds != 10 {
+	if cfg.Admin.Email != "admin@example.com" || cfg.Admin.TokenTTLSeconds != 10 || cfg.Admin.UsersPath != "data/accounts.json" ||
+		cfg.Admin.RefreshTTLSeconds != 3600 || cfg.Admin.SessionsPath != "data/logins.json" ||
+		cfg.Admin.LoginThrottle != (LoginThrottleConfig{MaxAccountFailures: 3, MaxIPFailures: 10, BaseLockoutSeconds: 5, MaxLockoutSeconds: 60, WindowSeconds: 120}) {
 		t.Fatalf("admin overrides not applied: %+v", cfg.Admin)
 	}
+	if cfg.Twitch.ClientID != "cid" || cfg.Twitch.EventSubSecret != "s3cret" || cfg.Twitch.CallbackURL != "https://callback/alerts/twitch" {
+		t.Fatalf("twitch overrides not applied: %+v", cfg.Twitch)
+	}
+	if cfg.Facebook.AppSecret != "fbsecret" || cfg.Facebook.VerifyToken != "fbverify" || cfg.Facebook.GraphURL != "http://127.0.0.1:9000/v19.0" {
+		t.Fatalf("facebook overrides not applied: %+v", cfg.Facebook)
+	}
+	if cfg.Notifications.MaxAttempts != 3 || len(cfg.Notifications.Sinks) != 1 || cfg.Notifications.Sinks[0].Templates["demo"] != "{{.Alias}} live" {
+		t.Fatalf("notification overrides not applied: %+v", cfg.Notifications)
+	}
+	if cfg.Storage.Backend != StorageSQLite || cfg.Storage.Path != "data/test.db" || cfg.Storage.JSONPath != "data/legacy.json" || cfg.Storage.BackupDir != "/var/backups/alerts" || cfg.Storage.BackupRetention != 3 || cfg.Storage.BackupIntervalSeconds != -1 || cfg.Storage.LockTimeoutSeconds != 30 {
+		t.Fatalf("storage overrides not applied: %+v", cfg.Storage)
+	}
+	if cfg.Sessions.Path != "data/history.json" || cfg.Sessions.RetentionDays != -1 || cfg.Sessions.MaxPerStreamer != 50 {
+		t.Fatalf("sessions overrides not applied: %+v", cfg.Sessions)
+	}
+	if rq := cfg.RetryQueue; rq.Path != "data/retry.json" || rq.Workers != 4 || rq.MaxAttempts != 3 || rq.BaseBackoffSeconds != 5 || rq.MaxBackoffSeconds != 60 || rq.DeadLetterLimit != -1 {
+		t.Fatalf("retry queue overrides not applied: %+v", rq)
+	}
+	if fp := cfg.FeedPoll; fp.FeedURL != "http://127.0.0.1:9001/feeds/videos.xml" || fp.IntervalSeconds != -1 || fp.FastIntervalSeconds != 30 || fp.MaxBackoffSeconds != 600 {
+		t.Fatalf("feed poll overrides not applied: %+v", fp)
+	}
+	if cfg.Audit != (AuditConfig{Path: "data/trail.jsonl", MaxFileBytes: 4096, MaxFiles: 2}) {
+		t.Fatalf("audit overrides not applied: %+v", cfg.Audit)
+	}
+	if cfg.Submissions != (SubmissionsConfig{MaxPerIP: -1, WindowSeconds: 60, MaxPending: 10, ProofOfWorkBits: 16}) {
+		t.Fatalf("submission limit overrides not applied: %+v", cfg.Submissions)
+	}
+}
+
+func TestLoadRejectsUnknownStorageBackend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"storage":{"backend":"postgres"}}`), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatalf("expected unsupported backend error")
+	}
+}
+
+func TestLoadRejectsInvalidTrustedProxy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"server":{"trusted_proxies":["10.0.0.0/33"]}}`), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatalf("expected invalid trusted proxy error")
+	}
+}
+
+func TestLoadRejectsInvalidAllowedOrigin(t *testing.T) {
+	for _, origin := range []string{"overlay.example.com", "https://example.com/path", "ftp://example.com"} {
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(`{"server":{"allowed_origins":["`+origin+`"]}}`), 0o644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Fatalf("expected %q to be rejected", origin)
+		}
+	}
 }
 
 func TestLoadErrorsForMissingFile(t *testing.T) {
@@ -2,38 +2,90 @@ package auth
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"sort"
 	"strings"
-	"sync"
 	"time"
+
+	"live-stream-alerts/internal/admin/users"
 )
 
-// Config captures the credentials and TTL required to issue admin tokens.
+// Config captures the credentials and TTLs required to issue admin tokens.
 type Config struct {
+	// Email and Password form a single legacy admin account, used only when Users is nil.
 	Email    string
 	Password string
 	TokenTTL time.Duration
+	// RefreshTTL is how long a session survives without being refreshed. Each refresh
+	// extends it again, so active sessions slide forward.
+	RefreshTTL time.Duration
+	// Users, when set, authenticates logins against stored accounts and their roles.
+	Users UserStore
+	// Sessions persists issued sessions. When nil they are kept in memory and lost on
+	// restart.
+	Sessions SessionStore
+	// Throttle, when set, locks out addresses and accounts after repeated failed logins.
+	Throttle *Throttle
+	Now      func() time.Time
+}
+
+// UserStore looks up admin accounts. users.Store satisfies it.
+type UserStore interface {
+	Authenticate(email, password string) (users.User, error)
+	Get(id string) (users.User, error)
 }
 
-// Token represents a bearer token issued after a successful login.
+// Token represents the bearer and refresh tokens issued after a successful login or
+// refresh.
 type Token struct {
-	Value     string
-	ExpiresAt time.Time
+	Value            string
+	ExpiresAt        time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+	SessionID        string
+	Role             users.Role
 }
 
-// Manager issues and validates admin bearer tokens.
-type Manager struct {
-	email    string
-	password string
-	tokenTTL time.Duration
+// Identity is the account and session behind a valid token.
+type Identity struct {
+	SessionID string
+	UserID    string
+	Email     string
+	Role      users.Role
+}
 
-	mu     sync.Mutex
-	tokens map[string]time.Time
+// Client describes where a login came from, recorded on the session for display.
+type Client struct {
+	UserAgent  string
+	RemoteAddr string
 }
 
-// ErrInvalidCredentials indicates that the provided email/password pair was rejected.
-var ErrInvalidCredentials = errors.New("invalid credentials")
+// Manager issues, refreshes and revokes admin sessions.
+type Manager struct {
+	email      string
+	password   string
+	tokenTTL   time.Duration
+	refreshTTL time.Duration
+	users      UserStore
+	sessions   SessionStore
+	throttle   *Throttle
+	now        func() time.Time
+}
+
+// touchInterval limits how often validating a token rewrites the session's LastUsedAt.
+const touchInterval = time.Minute
+
+var (
+	// ErrInvalidCredentials indicates that the provided email/password pair was rejected.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrInvalidRefreshToken indicates the refresh token is unknown, expired or belongs to
+	// an account that can no longer log in.
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+)
 
 // NewManager returns a Manager initialised with the supplied config.
 func NewManager(cfg Config) *Manager {
@@ -41,61 +93,282 @@ func NewManager(cfg Config) *Manager {
 	if ttl <= 0 {
 		ttl = 24 * time.Hour
 	}
+	refreshTTL := cfg.RefreshTTL
+	if refreshTTL <= 0 {
+		refreshTTL = 14 * 24 * time.Hour
+	}
+	if refreshTTL < ttl {
+		refreshTTL = ttl
+	}
+	sessions := cfg.Sessions
+	if sessions == nil {
+		sessions = newMemorySessions()
+	}
+	now := cfg.Now
+	if now == nil {
+		now = time.Now
+	}
 	return &Manager{
-		email:    strings.ToLower(strings.TrimSpace(cfg.Email)),
-		password: cfg.Password,
-		tokenTTL: ttl,
-		tokens:   make(map[string]time.Time),
+		email:      strings.ToLower(strings.TrimSpace(cfg.Email)),
+		password:   cfg.Password,
+		tokenTTL:   ttl,
+		refreshTTL: refreshTTL,
+		users:      cfg.Users,
+		sessions:   sessions,
+		throttle:   cfg.Throttle,
+		now:        now,
 	}
 }
 
-// Login validates the provided credentials and returns a short-lived token.
+// Login validates the provided credentials and starts a new session.
 func (m *Manager) Login(email, password string) (Token, error) {
+	return m.LoginFrom(email, password, Client{})
+}
+
+// LoginFrom is Login, recording the client on the session. While the client's address or
+// the account is locked out it returns a *LockedOutError without checking the password.
+func (m *Manager) LoginFrom(email, password string, client Client) (Token, error) {
 	if m == nil {
 		return Token{}, ErrInvalidCredentials
 	}
 	email = strings.ToLower(strings.TrimSpace(email))
+	if wait := m.throttle.Check(client.RemoteAddr, email); wait > 0 {
+		return Token{}, &LockedOutError{RetryAfter: wait}
+	}
 	if email == "" || password == "" {
-		return Token{}, ErrInvalidCredentials
+		return Token{}, m.failLogin(client, email)
 	}
-	if email != m.email || password != m.password {
-		return Token{}, ErrInvalidCredentials
+	now := m.now().UTC()
+	sess := Session{
+		ID:         generateSessionID(),
+		Email:      email,
+		Role:       users.RoleAdmin,
+		UserAgent:  truncate(client.UserAgent, 256),
+		RemoteAddr: client.RemoteAddr,
+		CreatedAt:  now,
+	}
+	if m.users != nil {
+		user, err := m.users.Authenticate(email, password)
+		if err != nil {
+			return Token{}, m.failLogin(client, email)
+		}
+		sess.UserID = user.ID
+		sess.Role = user.Role
+	} else if !m.matchesLegacy(email, password) {
+		return Token{}, m.failLogin(client, email)
 	}
+	m.throttle.Success(email)
+	return m.issue(sess, now)
+}
 
-	token := Token{
-		Value:     generateToken(),
-		ExpiresAt: time.Now().UTC().Add(m.tokenTTL),
+// failLogin counts a failed login against the throttle. The attempt that crosses a limit
+// already reports the lockout.
+func (m *Manager) failLogin(client Client, email string) error {
+	if wait := m.throttle.Failure(client.RemoteAddr, email); wait > 0 {
+		return &LockedOutError{RetryAfter: wait}
 	}
+	return ErrInvalidCredentials
+}
 
-	m.mu.Lock()
-	m.tokens[token.Value] = token.ExpiresAt
-	m.mu.Unlock()
+// Refresh exchanges a refresh token for a new token pair. Both tokens are rotated, so a
+// refresh token works once, and the session's refresh window restarts from now.
+func (m *Manager) Refresh(refreshToken string) (Token, error) {
+	if m == nil {
+		return Token{}, ErrInvalidRefreshToken
+	}
+	refreshToken = strings.TrimSpace(refreshToken)
+	if refreshToken == "" {
+		return Token{}, ErrInvalidRefreshToken
+	}
+	sess, err := m.sessions.ByRefreshHash(hashToken(refreshToken))
+	if errors.Is(err, ErrSessionNotFound) {
+		return Token{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return Token{}, err
+	}
+	now := m.now().UTC()
+	if now.After(sess.RefreshExpiresAt) {
+		_ = m.sessions.Delete(sess.ID)
+		return Token{}, ErrInvalidRefreshToken
+	}
+	sess, ok := m.current(sess)
+	if !ok {
+		return Token{}, ErrInvalidRefreshToken
+	}
+	return m.issue(sess, now)
+}
 
+// issue generates a fresh token pair for sess and stores it.
+func (m *Manager) issue(sess Session, now time.Time) (Token, error) {
+	token := Token{
+		Value:            generateToken(),
+		ExpiresAt:        now.Add(m.tokenTTL),
+		RefreshToken:     generateToken(),
+		RefreshExpiresAt: now.Add(m.refreshTTL),
+		SessionID:        sess.ID,
+		Role:             sess.Role,
+	}
+	sess.TokenHash = hashToken(token.Value)
+	sess.RefreshHash = hashToken(token.RefreshToken)
+	sess.ExpiresAt = token.ExpiresAt
+	sess.RefreshExpiresAt = token.RefreshExpiresAt
+	sess.LastUsedAt = now
+	if err := m.sessions.Put(sess); err != nil {
+		return Token{}, fmt.Errorf("store admin session: %w", err)
+	}
 	return token, nil
 }
 
+// matchesLegacy compares against the configured pair in constant time. Both sides are
+// hashed first, since ConstantTimeCompare returns early when the lengths differ.
+func (m *Manager) matchesLegacy(email, password string) bool {
+	if m.email == "" || m.password == "" {
+		return false
+	}
+	return constantTimeEqual(email, m.email)&constantTimeEqual(password, m.password) == 1
+}
+
+func constantTimeEqual(a, b string) int {
+	ha := sha256.Sum256([]byte(a))
+	hb := sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(ha[:], hb[:])
+}
+
 // Validate checks whether the provided token exists and has not expired.
 func (m *Manager) Validate(token string) bool {
+	_, ok := m.Identify(token)
+	return ok
+}
+
+// Identify returns the account behind token. Tokens stop validating once they expire,
+// their session is revoked, their account is disabled or removed, or its password is
+// reset; the role is read from the account on every call so role changes apply
+// immediately.
+func (m *Manager) Identify(token string) (Identity, bool) {
 	if m == nil {
-		return false
+		return Identity{}, false
 	}
 	token = strings.TrimSpace(token)
 	if token == "" {
-		return false
+		return Identity{}, false
+	}
+	sess, err := m.sessions.ByTokenHash(hashToken(token))
+	if err != nil {
+		return Identity{}, false
+	}
+	now := m.now().UTC()
+	if now.After(sess.ExpiresAt) {
+		return Identity{}, false
+	}
+	sess, ok := m.current(sess)
+	if !ok {
+		return Identity{}, false
 	}
+	if now.Sub(sess.LastUsedAt) >= touchInterval {
+		_ = m.sessions.Touch(sess.ID, now)
+	}
+	return Identity{SessionID: sess.ID, UserID: sess.UserID, Email: sess.Email, Role: sess.Role}, true
+}
 
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// current re-reads the account behind sess, deleting the session when the account can no
+// longer log in. The returned session carries the account's current email and role.
+func (m *Manager) current(sess Session) (Session, bool) {
+	if m.users == nil {
+		// A session from the legacy account ends once that account is reconfigured.
+		if sess.UserID != "" || constantTimeEqual(sess.Email, m.email) != 1 {
+			_ = m.sessions.Delete(sess.ID)
+			return Session{}, false
+		}
+		return sess, true
+	}
+	if sess.UserID == "" {
+		_ = m.sessions.Delete(sess.ID)
+		return Session{}, false
+	}
+	user, err := m.users.Get(sess.UserID)
+	if err != nil || user.Disabled || user.PasswordChangedAt.After(sess.CreatedAt) {
+		if err == nil || errors.Is(err, users.ErrNotFound) {
+			_ = m.sessions.Delete(sess.ID)
+		}
+		return Session{}, false
+	}
+	sess.Email = user.Email
+	sess.Role = user.Role
+	return sess, true
+}
 
-	expiry, ok := m.tokens[token]
-	if !ok {
-		return false
+// Sessions lists live sessions, newest first: every session when all is true, otherwise
+// only those belonging to identity's account.
+func (m *Manager) Sessions(identity Identity, all bool) ([]Session, error) {
+	if m == nil {
+		return nil, nil
 	}
-	if time.Now().UTC().After(expiry) {
-		delete(m.tokens, token)
-		return false
+	list, err := m.sessions.List()
+	if err != nil {
+		return nil, err
+	}
+	now := m.now().UTC()
+	out := make([]Session, 0, len(list))
+	for _, sess := range list {
+		if now.After(sess.RefreshExpiresAt) {
+			continue
+		}
+		if !all && !sess.OwnedBy(identity) {
+			continue
+		}
+		out = append(out, sess)
 	}
-	return true
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+// Session returns the session with the given ID.
+func (m *Manager) Session(id string) (Session, error) {
+	if m == nil {
+		return Session{}, ErrSessionNotFound
+	}
+	list, err := m.sessions.List()
+	if err != nil {
+		return Session{}, err
+	}
+	for _, sess := range list {
+		if sess.ID == id {
+			return sess, nil
+		}
+	}
+	return Session{}, fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+}
+
+// Revoke ends the session with the given ID; its access and refresh tokens stop working
+// immediately.
+func (m *Manager) Revoke(id string) error {
+	if m == nil {
+		return ErrSessionNotFound
+	}
+	return m.sessions.Delete(id)
+}
+
+// Prune deletes sessions whose refresh window has ended and reports how many it removed.
+func (m *Manager) Prune() (int, error) {
+	if m == nil {
+		return 0, nil
+	}
+	return m.sessions.Prune(m.now().UTC())
+}
+
+// OwnedBy reports whether the session belongs to identity's account. Legacy sessions
+// have no user ID and are matched by email.
+func (s Session) OwnedBy(identity Identity) bool {
+	if identity.UserID != "" {
+		return s.UserID == identity.UserID
+	}
+	return s.UserID == "" && s.Email == identity.Email
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 func generateToken() string {
@@ -105,3 +378,18 @@ func generateToken() string {
 	}
 	return hex.EncodeToString(buf)
 }
+
+func generateSessionID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("ses_%x", time.Now().UnixNano())
+	}
+	return "ses_" + hex.EncodeToString(buf)
+}
+
+func truncate(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	return value[:limit]
+}
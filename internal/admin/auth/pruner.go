@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"live-stream-alerts/internal/logging"
+)
+
+// PrunerConfig configures the background job that deletes expired admin sessions.
+type PrunerConfig struct {
+	Manager  *Manager
+	Interval time.Duration
+	Logger   logging.Logger
+}
+
+// Pruner periodically removes sessions whose refresh window has ended, so the sessions
+// file does not grow with every login.
+type Pruner struct {
+	cfg    PrunerConfig
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// StartPruner prunes once immediately and then every Interval (one hour by default)
+// until ctx is cancelled or Stop is called.
+func StartPruner(ctx context.Context, cfg PrunerConfig) *Pruner {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	p := &Pruner{cfg: cfg, cancel: cancel}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.run(ctx)
+	}()
+	return p
+}
+
+func (p *Pruner) run(ctx context.Context) {
+	p.prune()
+
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.prune()
+		}
+	}
+}
+
+func (p *Pruner) prune() {
+	pruned, err := p.cfg.Manager.Prune()
+	if p.cfg.Logger == nil {
+		return
+	}
+	switch {
+	case err != nil:
+		p.cfg.Logger.Printf("admin sessions: prune failed: %v", err)
+	case pruned > 0:
+		p.cfg.Logger.Printf("admin sessions: pruned %d expired sessions", pruned)
+	}
+}
+
+// Stop cancels the pruner and waits for it to finish.
+func (p *Pruner) Stop() {
+	if p == nil {
+		return
+	}
+	p.cancel()
+	p.wg.Wait()
+}
@@ -0,0 +1,366 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"live-stream-alerts/internal/admin/users"
+	"live-stream-alerts/internal/filestore"
+)
+
+// DefaultSessionsPath is where admin sessions are stored.
+const DefaultSessionsPath = "data/admin_sessions.json"
+
+// ErrSessionNotFound indicates no session matches the ID or token.
+var ErrSessionNotFound = errors.New("admin session not found")
+
+// Session is one login. Only SHA-256 hashes of its tokens are kept, so the sessions file
+// cannot be replayed if it leaks.
+type Session struct {
+	ID          string     `json:"id"`
+	TokenHash   string     `json:"tokenHash"`
+	RefreshHash string     `json:"refreshHash"`
+	UserID      string     `json:"userId,omitempty"`
+	Email       string     `json:"email"`
+	Role        users.Role `json:"role"`
+	UserAgent   string     `json:"userAgent,omitempty"`
+	RemoteAddr  string     `json:"remoteAddr,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	LastUsedAt  time.Time  `json:"lastUsedAt"`
+	// ExpiresAt ends the current access token; RefreshExpiresAt ends the session.
+	ExpiresAt        time.Time `json:"expiresAt"`
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt"`
+}
+
+// SessionStore persists sessions. Lookups by token use the hashed value.
+type SessionStore interface {
+	Put(session Session) error
+	ByTokenHash(hash string) (Session, error)
+	ByRefreshHash(hash string) (Session, error)
+	// Touch records activity without rewriting the token hashes, so it cannot undo a
+	// concurrent refresh.
+	Touch(id string, at time.Time) error
+	Delete(id string) error
+	List() ([]Session, error)
+	// Prune removes sessions whose refresh window ended before now.
+	Prune(now time.Time) (int, error)
+}
+
+// memorySessions keeps sessions for the life of the process only.
+type memorySessions struct {
+	mu       sync.Mutex
+	sessions map[string]Session
+}
+
+func newMemorySessions() *memorySessions {
+	return &memorySessions{sessions: make(map[string]Session)}
+}
+
+func (m *memorySessions) Put(session Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ID] = session
+	return nil
+}
+
+func (m *memorySessions) ByTokenHash(hash string) (Session, error) {
+	return m.find(func(s Session) bool { return s.TokenHash == hash })
+}
+
+func (m *memorySessions) ByRefreshHash(hash string) (Session, error) {
+	return m.find(func(s Session) bool { return s.RefreshHash == hash })
+}
+
+func (m *memorySessions) find(match func(Session) bool) (Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, session := range m.sessions {
+		if match(session) {
+			return session, nil
+		}
+	}
+	return Session{}, ErrSessionNotFound
+}
+
+func (m *memorySessions) Touch(id string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	session.LastUsedAt = at
+	m.sessions[id] = session
+	return nil
+}
+
+func (m *memorySessions) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sessions[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m *memorySessions) List() ([]Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := make([]Session, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		list = append(list, session)
+	}
+	return list, nil
+}
+
+func (m *memorySessions) Prune(now time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var pruned int
+	for id, session := range m.sessions {
+		if now.After(session.RefreshExpiresAt) {
+			delete(m.sessions, id)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+// FileSessionStore persists sessions to a JSON file behind a per-path mutex, so admins
+// stay logged in across restarts.
+type FileSessionStore struct {
+	path        string
+	mu          sync.Mutex
+	backups     filestore.Backups
+	lockTimeout time.Duration
+}
+
+// SessionStoreOption customises the file session store.
+type SessionStoreOption func(*FileSessionStore)
+
+// WithSessionBackups overrides where backups are written and how many are retained.
+func WithSessionBackups(backups filestore.Backups) SessionStoreOption {
+	return func(s *FileSessionStore) {
+		s.backups = backups
+	}
+}
+
+// WithSessionLockTimeout bounds how long writes wait for another process holding the
+// file lock.
+func WithSessionLockTimeout(timeout time.Duration) SessionStoreOption {
+	return func(s *FileSessionStore) {
+		s.lockTimeout = timeout
+	}
+}
+
+// NewFileSessionStore returns a file-backed session store for the provided path.
+func NewFileSessionStore(path string, opts ...SessionStoreOption) *FileSessionStore {
+	if path == "" {
+		path = DefaultSessionsPath
+	}
+	store := &FileSessionStore{path: filepath.Clean(path)}
+	for _, opt := range opts {
+		opt(store)
+	}
+	return store
+}
+
+// Path returns the path backing the store.
+func (s *FileSessionStore) Path() string {
+	if s == nil {
+		return ""
+	}
+	return s.path
+}
+
+// Put inserts or replaces the session with the same ID.
+func (s *FileSessionStore) Put(session Session) error {
+	return s.update(func(list []Session) ([]Session, error) {
+		for i := range list {
+			if list[i].ID == session.ID {
+				list[i] = session
+				return list, nil
+			}
+		}
+		return append(list, session), nil
+	})
+}
+
+// ByTokenHash returns the session whose access token hashes to hash.
+func (s *FileSessionStore) ByTokenHash(hash string) (Session, error) {
+	return s.find(func(session Session) bool { return session.TokenHash == hash })
+}
+
+// ByRefreshHash returns the session whose refresh token hashes to hash.
+func (s *FileSessionStore) ByRefreshHash(hash string) (Session, error) {
+	return s.find(func(session Session) bool { return session.RefreshHash == hash })
+}
+
+func (s *FileSessionStore) find(match func(Session) bool) (Session, error) {
+	list, err := s.List()
+	if err != nil {
+		return Session{}, err
+	}
+	for _, session := range list {
+		if match(session) {
+			return session, nil
+		}
+	}
+	return Session{}, ErrSessionNotFound
+}
+
+// Touch updates the session's LastUsedAt.
+func (s *FileSessionStore) Touch(id string, at time.Time) error {
+	return s.update(func(list []Session) ([]Session, error) {
+		for i := range list {
+			if list[i].ID == id {
+				list[i].LastUsedAt = at
+				return list, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	})
+}
+
+// Delete removes the session with the given ID.
+func (s *FileSessionStore) Delete(id string) error {
+	return s.update(func(list []Session) ([]Session, error) {
+		for i := range list {
+			if list[i].ID == id {
+				return append(list[:i], list[i+1:]...), nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	})
+}
+
+// List returns every stored session.
+func (s *FileSessionStore) List() ([]Session, error) {
+	if s == nil {
+		return nil, errors.New("admin sessions store is nil")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return readSessions(s.path)
+}
+
+// Prune removes sessions whose refresh window ended before now.
+func (s *FileSessionStore) Prune(now time.Time) (int, error) {
+	var pruned int
+	err := s.update(func(list []Session) ([]Session, error) {
+		kept := list[:0]
+		for _, session := range list {
+			if now.After(session.RefreshExpiresAt) {
+				pruned++
+				continue
+			}
+			kept = append(kept, session)
+		}
+		if pruned == 0 {
+			return nil, errNoChange
+		}
+		return kept, nil
+	})
+	if errors.Is(err, errNoChange) {
+		return 0, nil
+	}
+	return pruned, err
+}
+
+// errNoChange skips the write when an update leaves the file as it was.
+var errNoChange = errors.New("no change")
+
+// update runs a read-modify-write cycle under s.mu and the advisory file lock.
+func (s *FileSessionStore) update(fn func([]Session) ([]Session, error)) error {
+	if s == nil {
+		return errors.New("admin sessions store is nil")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, err := filestore.LockFile(s.path, s.lockTimeout)
+	if err != nil {
+		return fmt.Errorf("lock admin sessions file: %w", err)
+	}
+	defer lock.Unlock()
+
+	list, err := readSessions(s.path)
+	if err != nil {
+		return err
+	}
+	list, err = fn(list)
+	if err != nil {
+		return err
+	}
+	data, err := writeSessions(s.path, list)
+	if err != nil {
+		return err
+	}
+	// The data is already durable; a failed backup must not turn the write into an error.
+	_, _ = s.backups.Save(s.path, data)
+	return nil
+}
+
+// Recover checks that the sessions file decodes and, when it does not, restores the
+// newest backup that does. Read errors are returned without restoring anything. It
+// returns the restored backup path, or "" when the file was readable.
+func (s *FileSessionStore) Recover() (string, error) {
+	if s == nil {
+		return "", errors.New("admin sessions store is nil")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, err := filestore.LockFile(s.path, s.lockTimeout)
+	if err != nil {
+		return "", fmt.Errorf("lock admin sessions file: %w", err)
+	}
+	defer lock.Unlock()
+	return s.backups.RecoverCorrupt(s.path, func(data []byte) error {
+		var file sessionsFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("decode admin sessions file: %w", err)
+		}
+		return nil
+	})
+}
+
+type sessionsFile struct {
+	Sessions []Session `json:"sessions"`
+}
+
+func readSessions(path string) ([]Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []Session{}, nil
+		}
+		return nil, err
+	}
+	var file sessionsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("decode admin sessions file: %w", err)
+	}
+	if file.Sessions == nil {
+		file.Sessions = []Session{}
+	}
+	return file.Sessions, nil
+}
+
+func writeSessions(path string, list []Session) ([]byte, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create admin sessions dir: %w", err)
+	}
+	data, err := json.MarshalIndent(sessionsFile{Sessions: list}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode admin sessions file: %w", err)
+	}
+	if err := filestore.WriteAtomic(path, data, 0o600); err != nil {
+		return nil, fmt.Errorf("write admin sessions file: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManagerPersistsSessionsAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "admin_sessions.json")
+	cfg := Config{Email: "admin@example.com", Password: "secret", Sessions: NewFileSessionStore(path)}
+	token, err := NewManager(cfg).LoginFrom("admin@example.com", "secret", Client{UserAgent: "curl/8", RemoteAddr: "192.0.2.1"})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read sessions: %v", err)
+	}
+	if strings.Contains(string(data), token.Value) || strings.Contains(string(data), token.RefreshToken) {
+		t.Fatalf("expected only token hashes on disk: %s", data)
+	}
+
+	cfg.Sessions = NewFileSessionStore(path)
+	restarted := NewManager(cfg)
+	identity, ok := restarted.Identify(token.Value)
+	if !ok || identity.SessionID != token.SessionID || identity.Email != "admin@example.com" {
+		t.Fatalf("expected the session to survive a restart, got %+v, %v", identity, ok)
+	}
+	list, err := restarted.Sessions(identity, false)
+	if err != nil || len(list) != 1 || list[0].UserAgent != "curl/8" || list[0].RemoteAddr != "192.0.2.1" {
+		t.Fatalf("unexpected sessions %+v, %v", list, err)
+	}
+
+	if err := restarted.Revoke(token.SessionID); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if restarted.Validate(token.Value) {
+		t.Fatalf("expected a revoked token to be rejected")
+	}
+	if _, err := restarted.Refresh(token.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("expected a revoked session to refuse refresh, got %v", err)
+	}
+}
+
+func TestManagerRefreshRotatesAndSlides(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	mgr := NewManager(Config{
+		Email:      "admin@example.com",
+		Password:   "secret",
+		TokenTTL:   time.Hour,
+		RefreshTTL: 24 * time.Hour,
+		Now:        func() time.Time { return now },
+	})
+	first, err := mgr.Login("admin@example.com", "secret")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if mgr.Validate(first.Value) {
+		t.Fatalf("expected the access token to expire")
+	}
+	second, err := mgr.Refresh(first.RefreshToken)
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if second.SessionID != first.SessionID || second.Value == first.Value || second.RefreshToken == first.RefreshToken {
+		t.Fatalf("expected rotated tokens on the same session, got %+v", second)
+	}
+	if !second.RefreshExpiresAt.Equal(now.Add(24 * time.Hour)) {
+		t.Fatalf("expected the refresh window to slide, got %s", second.RefreshExpiresAt)
+	}
+	if !mgr.Validate(second.Value) {
+		t.Fatalf("expected the refreshed token to validate")
+	}
+	if _, err := mgr.Refresh(first.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("expected a used refresh token to be rejected, got %v", err)
+	}
+
+	now = now.Add(25 * time.Hour)
+	if _, err := mgr.Refresh(second.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("expected an expired refresh token to be rejected, got %v", err)
+	}
+}
+
+func TestManagerPrunesExpiredSessions(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := NewFileSessionStore(filepath.Join(t.TempDir(), "admin_sessions.json"))
+	mgr := NewManager(Config{
+		Email:      "admin@example.com",
+		Password:   "secret",
+		TokenTTL:   time.Hour,
+		RefreshTTL: 2 * time.Hour,
+		Sessions:   store,
+		Now:        func() time.Time { return now },
+	})
+	if _, err := mgr.Login("admin@example.com", "secret"); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	now = now.Add(90 * time.Minute)
+	kept, err := mgr.Login("admin@example.com", "secret")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	pruned, err := mgr.Prune()
+	if err != nil || pruned != 1 {
+		t.Fatalf("expected one pruned session, got %d, %v", pruned, err)
+	}
+	list, err := store.List()
+	if err != nil || len(list) != 1 || list[0].ID != kept.SessionID {
+		t.Fatalf("expected only the newer session to remain, got %+v, %v", list, err)
+	}
+}
@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrLockedOut indicates that logins are refused for a while after repeated failures.
+var ErrLockedOut = errors.New("too many failed login attempts")
+
+// LockedOutError reports how long until the next login attempt is accepted.
+type LockedOutError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedOutError) Error() string {
+	return fmt.Sprintf("%v; retry in %s", ErrLockedOut, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LockedOutError) Unwrap() error { return ErrLockedOut }
+
+// ThrottleConfig tunes login lockouts. Zero values fall back to the defaults noted on each
+// field.
+type ThrottleConfig struct {
+	// MaxAccountFailures failed logins for one email lock that account (default 5).
+	MaxAccountFailures int
+	// MaxIPFailures failed logins from one address lock that address (default 20). It is
+	// higher than the account limit because several admins may share an address.
+	MaxIPFailures int
+	// BaseLockout is the first lockout; each further failure doubles it (default 30s).
+	BaseLockout time.Duration
+	// MaxLockout caps the lockout (default 1h).
+	MaxLockout time.Duration
+	// Window forgets failures once this long has passed without one (default 15m).
+	Window time.Duration
+	Now    func() time.Time
+}
+
+// Throttle counts failed logins per client address and per account and locks either one
+// out with an exponentially growing delay once it crosses its limit.
+type Throttle struct {
+	cfg ThrottleConfig
+
+	mu        sync.Mutex
+	entries   map[string]*attempts
+	lastSweep time.Time
+}
+
+type attempts struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// NewThrottle returns a Throttle with cfg's limits.
+func NewThrottle(cfg ThrottleConfig) *Throttle {
+	if cfg.MaxAccountFailures <= 0 {
+		cfg.MaxAccountFailures = 5
+	}
+	if cfg.MaxIPFailures <= 0 {
+		cfg.MaxIPFailures = 20
+	}
+	if cfg.BaseLockout <= 0 {
+		cfg.BaseLockout = 30 * time.Second
+	}
+	if cfg.MaxLockout <= 0 {
+		cfg.MaxLockout = time.Hour
+	}
+	if cfg.MaxLockout < cfg.BaseLockout {
+		cfg.MaxLockout = cfg.BaseLockout
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 15 * time.Minute
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Throttle{cfg: cfg, entries: make(map[string]*attempts)}
+}
+
+// Check returns how long the address or account stays locked, or zero when a login may be
+// attempted. Empty values are not tracked.
+func (t *Throttle) Check(addr, account string) time.Duration {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.cfg.Now()
+	var wait time.Duration
+	for _, key := range throttleKeys(addr, account) {
+		if entry := t.entry(key, now, false); entry != nil && entry.lockedUntil.After(now) {
+			wait = max(wait, entry.lockedUntil.Sub(now))
+		}
+	}
+	return wait
+}
+
+// Failure records a failed login and returns the resulting lockout, or zero when neither
+// limit has been reached yet.
+func (t *Throttle) Failure(addr, account string) time.Duration {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.cfg.Now()
+	t.sweep(now)
+	var wait time.Duration
+	for _, key := range throttleKeys(addr, account) {
+		entry := t.entry(key, now, true)
+		entry.failures++
+		entry.lastFailure = now
+		limit := t.cfg.MaxAccountFailures
+		if strings.HasPrefix(key, "ip:") {
+			limit = t.cfg.MaxIPFailures
+		}
+		if entry.failures < limit {
+			continue
+		}
+		lockout := t.cfg.MaxLockout
+		if shift := entry.failures - limit; shift < 32 {
+			lockout = min(t.cfg.BaseLockout<<shift, t.cfg.MaxLockout)
+		}
+		entry.lockedUntil = now.Add(lockout)
+		wait = max(wait, lockout)
+	}
+	return wait
+}
+
+// Success clears the account's failures. The address keeps its count, so one valid
+// account cannot be used to reset the limit while guessing others.
+func (t *Throttle) Success(account string) {
+	if t == nil || account == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.entries, "account:"+account)
+}
+
+// entry returns the live entry for key, dropping it when its window has passed.
+func (t *Throttle) entry(key string, now time.Time, create bool) *attempts {
+	entry, ok := t.entries[key]
+	if ok && t.expired(entry, now) {
+		delete(t.entries, key)
+		ok = false
+	}
+	if !ok && create {
+		entry = &attempts{}
+		t.entries[key] = entry
+		ok = true
+	}
+	if !ok {
+		return nil
+	}
+	return entry
+}
+
+func (t *Throttle) expired(entry *attempts, now time.Time) bool {
+	last := entry.lastFailure
+	if entry.lockedUntil.After(last) {
+		last = entry.lockedUntil
+	}
+	return now.Sub(last) > t.cfg.Window
+}
+
+// sweep drops expired entries at most once per window, so addresses that stopped trying
+// do not stay in memory.
+func (t *Throttle) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < t.cfg.Window {
+		return
+	}
+	t.lastSweep = now
+	for key, entry := range t.entries {
+		if t.expired(entry, now) {
+			delete(t.entries, key)
+		}
+	}
+}
+
+func throttleKeys(addr, account string) []string {
+	keys := make([]string, 0, 2)
+	if addr != "" {
+		keys = append(keys, "ip:"+addr)
+	}
+	if account != "" {
+		keys = append(keys, "account:"+account)
+	}
+	return keys
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestThrottleLocksOutWithExponentialBackoff(t *testing.T) {
+	now := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	throttle := NewThrottle(ThrottleConfig{
+		MaxAccountFailures: 3,
+		MaxIPFailures:      100,
+		BaseLockout:        10 * time.Second,
+		MaxLockout:         30 * time.Second,
+		Window:             time.Minute,
+		Now:                func() time.Time { return now },
+	})
+
+	for i := 0; i < 2; i++ {
+		if wait := throttle.Failure("192.0.2.1", "admin@example.com"); wait != 0 {
+			t.Fatalf("expected no lockout after %d failures, got %s", i+1, wait)
+		}
+	}
+	if wait := throttle.Failure("192.0.2.1", "admin@example.com"); wait != 10*time.Second {
+		t.Fatalf("expected the first lockout, got %s", wait)
+	}
+	if wait := throttle.Check("198.51.100.7", "admin@example.com"); wait != 10*time.Second {
+		t.Fatalf("expected the account to be locked from any address, got %s", wait)
+	}
+	if wait := throttle.Check("192.0.2.1", "other@example.com"); wait != 0 {
+		t.Fatalf("expected other accounts to stay open, got %s", wait)
+	}
+
+	now = now.Add(10 * time.Second)
+	if wait := throttle.Failure("192.0.2.1", "admin@example.com"); wait != 20*time.Second {
+		t.Fatalf("expected the lockout to double, got %s", wait)
+	}
+	now = now.Add(20 * time.Second)
+	if wait := throttle.Failure("192.0.2.1", "admin@example.com"); wait != 30*time.Second {
+		t.Fatalf("expected the lockout to be capped, got %s", wait)
+	}
+
+	now = now.Add(30*time.Second + 2*time.Minute)
+	if wait := throttle.Failure("192.0.2.1", "admin@example.com"); wait != 0 {
+		t.Fatalf("expected failures to be forgotten after the window, got %s", wait)
+	}
+	throttle.Success("admin@example.com")
+	if len(throttle.entries) != 1 {
+		t.Fatalf("expected only the address entry to remain, got %d", len(throttle.entries))
+	}
+}
+
+func TestManagerRefusesLockedOutLogins(t *testing.T) {
+	mgr := NewManager(Config{
+		Email:    "admin@example.com",
+		Password: "secret",
+		Throttle: NewThrottle(ThrottleConfig{MaxIPFailures: 2}),
+	})
+	client := Client{RemoteAddr: "192.0.2.1"}
+	if _, err := mgr.LoginFrom("a@example.com", "guess", client); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected invalid credentials, got %v", err)
+	}
+	var locked *LockedOutError
+	if _, err := mgr.LoginFrom("b@example.com", "guess", client); !errors.As(err, &locked) || locked.RetryAfter != 30*time.Second {
+		t.Fatalf("expected a lockout, got %v", err)
+	}
+	if _, err := mgr.LoginFrom("admin@example.com", "secret", client); !errors.Is(err, ErrLockedOut) {
+		t.Fatalf("expected the address to stay locked, got %v", err)
+	}
+	if _, err := mgr.LoginFrom("admin@example.com", "secret", Client{RemoteAddr: "192.0.2.2"}); err != nil {
+		t.Fatalf("expected other addresses to log in, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package adminhttp
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	adminauth "live-stream-alerts/internal/admin/auth"
+	adminservice "live-stream-alerts/internal/admin/service"
+	"live-stream-alerts/internal/admin/users"
+	"live-stream-alerts/internal/audit"
+	"live-stream-alerts/internal/logging"
+)
+
+// AuditHandlerOptions configures the admin audit log handler.
+type AuditHandlerOptions struct {
+	Authorizer authorizer
+	Manager    *adminauth.Manager
+	Log        auditLog
+	Logger     logging.Logger
+}
+
+type auditLog interface {
+	Query(filter audit.Filter) ([]audit.Entry, error)
+}
+
+type auditHandler struct {
+	authorizer authorizer
+	log        auditLog
+	logger     logging.Logger
+}
+
+// NewAuditHandler constructs the admin handler that queries the audit log. Every request
+// requires the admin role.
+func NewAuditHandler(opts AuditHandlerOptions) http.Handler {
+	auth := opts.Authorizer
+	if auth == nil && opts.Manager != nil {
+		auth = adminservice.AuthService{Manager: opts.Manager}
+	}
+	return auditHandler{authorizer: auth, log: opts.Log, logger: opts.Logger}
+}
+
+func (h auditHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.authorizer == nil || h.log == nil {
+		http.Error(w, "admin audit log disabled", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var err error
+	if roles, ok := h.authorizer.(roleAuthorizer); ok {
+		err = roles.AuthorizeRole(r, users.RoleAdmin)
+	} else {
+		err = h.authorizer.AuthorizeRequest(r)
+	}
+	switch {
+	case errors.Is(err, adminservice.ErrForbidden):
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	case err != nil:
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	entries, err := h.log.Query(filter)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Printf("query audit log: %v", err)
+		}
+		http.Error(w, "failed to read audit log", http.StatusInternalServerError)
+		return
+	}
+	if entries == nil {
+		entries = []audit.Entry{}
+	}
+	respondJSON(w, map[string][]audit.Entry{"entries": entries})
+}
+
+func parseAuditFilter(r *http.Request) (audit.Filter, error) {
+	query := r.URL.Query()
+	filter := audit.Filter{
+		Actor:      strings.TrimSpace(query.Get("actor")),
+		Action:     strings.TrimSpace(query.Get("action")),
+		TargetType: strings.TrimSpace(query.Get("target_type")),
+		TargetID:   strings.TrimSpace(query.Get("target_id")),
+	}
+	for _, bound := range []struct {
+		name string
+		dst  *time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		value := strings.TrimSpace(query.Get(bound.name))
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return audit.Filter{}, errors.New(bound.name + " must be an RFC 3339 timestamp")
+		}
+		*bound.dst = parsed
+	}
+	if value := strings.TrimSpace(query.Get("limit")); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > audit.MaxQueryLimit {
+			return audit.Filter{}, errors.New("limit must be between 1 and " + strconv.Itoa(audit.MaxQueryLimit))
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
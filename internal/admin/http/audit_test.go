@@ -0,0 +1,79 @@
+package adminhttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	adminhttp "live-stream-alerts/internal/admin/http"
+	"live-stream-alerts/internal/admin/users"
+	"live-stream-alerts/internal/audit"
+)
+
+func TestAuditHandlerQueriesRecordedChanges(t *testing.T) {
+	log := audit.NewLog(audit.Options{Path: filepath.Join(t.TempDir(), "audit.jsonl")})
+	usersHandler := adminhttp.NewUsersHandler(adminhttp.UsersHandlerOptions{
+		Authorizer: stubRoleAuthorizer{role: users.RoleAdmin},
+		Store:      users.NewStore(filepath.Join(t.TempDir(), "admin_users.json"), users.WithHashCost(bcrypt.MinCost)),
+		Audit:      log,
+	})
+	ctx := audit.WithActor(context.Background(), audit.Actor{Email: "root@example.com", Role: "admin"})
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/users", strings.NewReader(`{"action":"create","email":"reviewer@example.com","password":"reviewer password","role":"reviewer"}`)).WithContext(ctx)
+	rr := httptest.NewRecorder()
+	usersHandler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	log.Record(ctx, audit.Entry{Action: "streamer.delete", TargetType: "streamer", TargetID: "s1"})
+
+	handler := adminhttp.NewAuditHandler(adminhttp.AuditHandlerOptions{
+		Authorizer: stubRoleAuthorizer{role: users.RoleAdmin},
+		Log:        log,
+	})
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/admin/audit?action=user.&actor=root@example.com", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Entries []audit.Entry `json:"entries"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Entries) != 1 {
+		t.Fatalf("expected one user entry, got %+v", resp.Entries)
+	}
+	entry := resp.Entries[0]
+	if entry.Action != "user.create" || entry.TargetType != "user" || entry.Before != nil || entry.After == nil {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+	if strings.Contains(string(entry.After), "passwordHash") {
+		t.Fatalf("expected the snapshot to omit the hash: %s", entry.After)
+	}
+
+	cases := []struct {
+		name  string
+		role  users.Role
+		query string
+		code  int
+	}{
+		{name: "reviewer", role: users.RoleReviewer, code: http.StatusForbidden},
+		{name: "bad limit", role: users.RoleAdmin, query: "?limit=0", code: http.StatusBadRequest},
+		{name: "bad since", role: users.RoleAdmin, query: "?since=yesterday", code: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		handler := adminhttp.NewAuditHandler(adminhttp.AuditHandlerOptions{Authorizer: stubRoleAuthorizer{role: tc.role}, Log: log})
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/admin/audit"+tc.query, nil))
+		if rr.Code != tc.code {
+			t.Fatalf("%s: expected %d, got %d", tc.name, tc.code, rr.Code)
+		}
+	}
+}
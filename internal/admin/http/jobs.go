@@ -0,0 +1,131 @@
+package adminhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	adminauth "live-stream-alerts/internal/admin/auth"
+	adminservice "live-stream-alerts/internal/admin/service"
+	"live-stream-alerts/internal/audit"
+	"live-stream-alerts/internal/jobs"
+	"live-stream-alerts/internal/logging"
+)
+
+// JobsHandlerOptions configures the admin retry-queue handler.
+type JobsHandlerOptions struct {
+	Authorizer authorizer
+	Manager    *adminauth.Manager
+	Queue      jobsQueue
+	Logger     logging.Logger
+	Audit      audit.Recorder
+}
+
+type jobsQueue interface {
+	List() (jobs.File, error)
+	Requeue(id string) (jobs.Job, error)
+}
+
+type jobsHandler struct {
+	authorizer authorizer
+	queue      jobsQueue
+	logger     logging.Logger
+	audit      audit.Recorder
+}
+
+// JobsActionRequest is the POST body accepted by the jobs handler.
+type JobsActionRequest struct {
+	Action string `json:"action"`
+	ID     string `json:"id"`
+}
+
+// JobsActionResponse reports the job after the action was applied.
+type JobsActionResponse struct {
+	Action string   `json:"action"`
+	Job    jobs.Job `json:"job"`
+}
+
+// NewJobsHandler constructs the admin handler that lists queued and dead-lettered jobs
+// and requeues dead ones.
+func NewJobsHandler(opts JobsHandlerOptions) http.Handler {
+	auth := opts.Authorizer
+	if auth == nil && opts.Manager != nil {
+		auth = adminservice.AuthService{Manager: opts.Manager}
+	}
+	return jobsHandler{
+		authorizer: auth,
+		queue:      opts.Queue,
+		logger:     opts.Logger,
+		audit:      opts.Audit,
+	}
+}
+
+func (h jobsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.authorizer == nil || h.queue == nil {
+		http.Error(w, "admin jobs disabled", http.StatusServiceUnavailable)
+		return
+	}
+	if err := h.authorizer.AuthorizeRequest(r); err != nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		h.list(w)
+	case http.MethodPost:
+		h.update(w, r)
+	default:
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h jobsHandler) list(w http.ResponseWriter) {
+	file, err := h.queue.List()
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Printf("list jobs: %v", err)
+		}
+		http.Error(w, "failed to load jobs", http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, file)
+}
+
+func (h jobsHandler) update(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	var req JobsActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if !strings.EqualFold(strings.TrimSpace(req.Action), "requeue") {
+		http.Error(w, "action must be requeue", http.StatusBadRequest)
+		return
+	}
+	id := strings.TrimSpace(req.ID)
+	if id == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return
+	}
+	job, err := h.queue.Requeue(id)
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		http.Error(w, "dead-lettered job not found", http.StatusNotFound)
+		return
+	case err != nil:
+		if h.logger != nil {
+			h.logger.Printf("requeue job %s: %v", id, err)
+		}
+		http.Error(w, "failed to requeue job", http.StatusInternalServerError)
+		return
+	}
+	if h.audit != nil {
+		// The payload can be a whole feed document; the job's metadata is enough here.
+		snapshot := job
+		snapshot.Payload = nil
+		h.audit.Record(r.Context(), audit.Entry{Action: "job.requeue", TargetType: "job", TargetID: job.ID, After: audit.Snapshot(snapshot)})
+	}
+	respondJSON(w, JobsActionResponse{Action: "requeue", Job: job})
+}
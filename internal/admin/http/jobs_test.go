@@ -0,0 +1,81 @@
+package adminhttp_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	adminhttp "live-stream-alerts/internal/admin/http"
+	"live-stream-alerts/internal/jobs"
+)
+
+type stubJobsQueue struct {
+	file     jobs.File
+	requeued []string
+}
+
+func (s *stubJobsQueue) List() (jobs.File, error) { return s.file, nil }
+
+func (s *stubJobsQueue) Requeue(id string) (jobs.Job, error) {
+	for _, job := range s.file.Dead {
+		if job.ID == id {
+			s.requeued = append(s.requeued, id)
+			return job, nil
+		}
+	}
+	return jobs.Job{}, fmt.Errorf("%w: %s", jobs.ErrNotFound, id)
+}
+
+func TestJobsHandlerListsAndRequeues(t *testing.T) {
+	queue := &stubJobsQueue{file: jobs.File{
+		Pending: []jobs.Job{{ID: "job_1", Kind: "youtube.notification"}},
+		Dead:    []jobs.Job{{ID: "job_2", Kind: "youtube.notification", LastError: "lookup failed"}},
+	}}
+	handler := adminhttp.NewJobsHandler(adminhttp.JobsHandlerOptions{Authorizer: &stubAuthorizer{}, Queue: queue})
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/admin/jobs", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	var file jobs.File
+	if err := json.Unmarshal(rr.Body.Bytes(), &file); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(file.Pending) != 1 || len(file.Dead) != 1 || file.Dead[0].LastError != "lookup failed" {
+		t.Fatalf("unexpected listing %+v", file)
+	}
+
+	cases := []struct {
+		body string
+		code int
+	}{
+		{body: `{"action":"requeue","id":"job_2"}`, code: http.StatusOK},
+		{body: `{"action":"requeue","id":"job_1"}`, code: http.StatusNotFound},
+		{body: `{"action":"delete","id":"job_2"}`, code: http.StatusBadRequest},
+		{body: `{"action":"requeue"}`, code: http.StatusBadRequest},
+		{body: `not json`, code: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/admin/jobs", strings.NewReader(tc.body)))
+		if rr.Code != tc.code {
+			t.Fatalf("%s: expected %d, got %d", tc.body, tc.code, rr.Code)
+		}
+	}
+	if len(queue.requeued) != 1 || queue.requeued[0] != "job_2" {
+		t.Fatalf("expected job_2 to be requeued, got %v", queue.requeued)
+	}
+}
+
+func TestJobsHandlerDisabledWithoutQueue(t *testing.T) {
+	handler := adminhttp.NewJobsHandler(adminhttp.JobsHandlerOptions{Authorizer: &stubAuthorizer{}})
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/admin/jobs", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rr.Code)
+	}
+}
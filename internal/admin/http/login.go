@@ -3,26 +3,41 @@ package adminhttp
 import (
 	"encoding/json"
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	adminauth "live-stream-alerts/internal/admin/auth"
 	adminservice "live-stream-alerts/internal/admin/service"
+	"live-stream-alerts/internal/clientip"
+	"live-stream-alerts/internal/logging"
 )
 
 // LoginHandlerOptions configures the admin login handler.
 type LoginHandlerOptions struct {
 	Service loginService
 	Manager *adminauth.Manager
+	// Proxies resolves the client address recorded on sessions and in failed-login logs.
+	// When nil, X-Forwarded-For is ignored.
+	Proxies *clientip.Resolver
+	Logger  logging.Logger
 }
 
 type loginService interface {
 	Login(email, password string) (adminauth.Token, error)
 }
 
+// clientLoginService is implemented by services that record the client on the session.
+type clientLoginService interface {
+	LoginFrom(email, password string, client adminauth.Client) (adminauth.Token, error)
+}
+
 // LoginHandler exposes the admin login endpoint.
 type LoginHandler struct {
 	service loginService
+	proxies *clientip.Resolver
+	logger  logging.Logger
 }
 
 type loginRequest struct {
@@ -31,8 +46,12 @@ type loginRequest struct {
 }
 
 type loginResponse struct {
-	Token     string `json:"token"`
-	ExpiresAt string `json:"expiresAt"`
+	Token            string `json:"token"`
+	ExpiresAt        string `json:"expiresAt"`
+	RefreshToken     string `json:"refreshToken,omitempty"`
+	RefreshExpiresAt string `json:"refreshExpiresAt,omitempty"`
+	SessionID        string `json:"sessionId,omitempty"`
+	Role             string `json:"role,omitempty"`
 }
 
 // NewLoginHandler constructs the admin login handler.
@@ -41,7 +60,7 @@ func NewLoginHandler(opts LoginHandlerOptions) http.Handler {
 	if svc == nil && opts.Manager != nil {
 		svc = adminservice.AuthService{Manager: opts.Manager}
 	}
-	return LoginHandler{service: svc}
+	return LoginHandler{service: svc, proxies: opts.Proxies, logger: opts.Logger}
 }
 
 func (h LoginHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -61,10 +80,25 @@ func (h LoginHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.service.Login(req.Email, req.Password)
+	var (
+		token adminauth.Token
+		err   error
+	)
+	client := h.client(r)
+	if svc, ok := h.service.(clientLoginService); ok {
+		token, err = svc.LoginFrom(req.Email, req.Password, client)
+	} else {
+		token, err = h.service.Login(req.Email, req.Password)
+	}
 	if err != nil {
+		var locked *adminauth.LockedOutError
 		switch {
+		case errors.As(err, &locked):
+			h.logf("admin login for %q from %s refused: %v", req.Email, client.RemoteAddr, err)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+			http.Error(w, "too many failed login attempts", http.StatusTooManyRequests)
 		case errors.Is(err, adminservice.ErrInvalidCredentials):
+			h.logf("admin login for %q from %s failed: invalid credentials", req.Email, client.RemoteAddr)
 			http.Error(w, "invalid credentials", http.StatusUnauthorized)
 		case errors.Is(err, adminservice.ErrUnauthorized):
 			http.Error(w, "admin auth disabled", http.StatusServiceUnavailable)
@@ -74,8 +108,32 @@ func (h LoginHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp := loginResponse{Token: token.Value, ExpiresAt: token.ExpiresAt.Format(time.RFC3339)}
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(resp)
+	_ = json.NewEncoder(w).Encode(newLoginResponse(token))
+}
+
+func newLoginResponse(token adminauth.Token) loginResponse {
+	resp := loginResponse{
+		Token:     token.Value,
+		ExpiresAt: token.ExpiresAt.Format(time.RFC3339),
+		SessionID: token.SessionID,
+		Role:      string(token.Role),
+	}
+	if token.RefreshToken != "" {
+		resp.RefreshToken = token.RefreshToken
+		resp.RefreshExpiresAt = token.RefreshExpiresAt.Format(time.RFC3339)
+	}
+	return resp
+}
+
+// client describes the caller for the throttle, the logs, and the sessions list.
+func (h LoginHandler) client(r *http.Request) adminauth.Client {
+	return adminauth.Client{UserAgent: r.UserAgent(), RemoteAddr: h.proxies.IP(r)}
+}
+
+func (h LoginHandler) logf(format string, args ...any) {
+	if h.logger != nil {
+		h.logger.Printf(format, args...)
+	}
 }
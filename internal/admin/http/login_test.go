@@ -12,6 +12,7 @@ import (
 	adminauth "live-stream-alerts/internal/admin/auth"
 	adminhttp "live-stream-alerts/internal/admin/http"
 	adminservice "live-stream-alerts/internal/admin/service"
+	"live-stream-alerts/internal/clientip"
 )
 
 func TestLoginHandlerSuccess(t *testing.T) {
@@ -67,6 +68,39 @@ func TestLoginHandlerHandlesErrors(t *testing.T) {
 	}
 }
 
+func TestLoginHandlerLocksOutRepeatedFailures(t *testing.T) {
+	manager := adminauth.NewManager(adminauth.Config{
+		Email:    "admin@example.com",
+		Password: "secret",
+		Throttle: adminauth.NewThrottle(adminauth.ThrottleConfig{MaxAccountFailures: 2, BaseLockout: 90 * time.Second}),
+	})
+	proxies, err := clientip.NewResolver([]string{"10.0.0.1"})
+	if err != nil {
+		t.Fatalf("resolver: %v", err)
+	}
+	handler := adminhttp.NewLoginHandler(adminhttp.LoginHandlerOptions{Manager: manager, Proxies: proxies})
+	login := func(password string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"email": "admin@example.com", "password": password})
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/login", bytes.NewReader(body))
+		req.RemoteAddr = "10.0.0.1:5000"
+		req.Header.Set("X-Forwarded-For", "203.0.113.9")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := login("wrong"); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+	rr := login("wrong")
+	if rr.Code != http.StatusTooManyRequests || rr.Header().Get("Retry-After") != "90" {
+		t.Fatalf("expected 429 with Retry-After 90, got %d %q", rr.Code, rr.Header().Get("Retry-After"))
+	}
+	if rr := login("secret"); rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected the correct password to be refused while locked, got %d", rr.Code)
+	}
+}
+
 type stubLoginService struct {
 	email    string
 	password string
@@ -19,7 +19,7 @@ type MonitorHandlerOptions struct {
 	Service        monitorService
 	Manager        *adminauth.Manager
 	Logger         logging.Logger
-	StreamersStore *streamers.Store
+	StreamersStore streamers.Repository
 	YouTube        config.YouTubeConfig
 }
 
@@ -0,0 +1,236 @@
+package adminhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	adminauth "live-stream-alerts/internal/admin/auth"
+	adminservice "live-stream-alerts/internal/admin/service"
+	"live-stream-alerts/internal/admin/users"
+	"live-stream-alerts/internal/audit"
+	"live-stream-alerts/internal/logging"
+)
+
+// SessionsHandlerOptions configures the logout, refresh and session management handlers.
+type SessionsHandlerOptions struct {
+	Manager *adminauth.Manager
+	Logger  logging.Logger
+	Audit   audit.Recorder
+}
+
+// SessionView is a session as returned by the API, without its token hashes.
+type SessionView struct {
+	ID               string     `json:"id"`
+	UserID           string     `json:"userId,omitempty"`
+	Email            string     `json:"email"`
+	Role             users.Role `json:"role"`
+	UserAgent        string     `json:"userAgent,omitempty"`
+	RemoteAddr       string     `json:"remoteAddr,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	LastUsedAt       time.Time  `json:"lastUsedAt"`
+	ExpiresAt        time.Time  `json:"expiresAt"`
+	RefreshExpiresAt time.Time  `json:"refreshExpiresAt"`
+	Current          bool       `json:"current"`
+}
+
+// SessionsActionRequest is the POST body accepted by the sessions handler. The only
+// action is revoke.
+type SessionsActionRequest struct {
+	Action string `json:"action"`
+	ID     string `json:"id"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// NewLogoutHandler constructs the handler that revokes the caller's own session.
+func NewLogoutHandler(opts SessionsHandlerOptions) http.Handler {
+	return logoutHandler{manager: opts.Manager, logger: opts.Logger}
+}
+
+type logoutHandler struct {
+	manager *adminauth.Manager
+	logger  logging.Logger
+}
+
+func (h logoutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.manager == nil {
+		http.Error(w, "admin auth disabled", http.StatusServiceUnavailable)
+		return
+	}
+	identity, err := adminservice.AuthService{Manager: h.manager}.Identify(r)
+	if err != nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := h.manager.Revoke(identity.SessionID); err != nil && !errors.Is(err, adminauth.ErrSessionNotFound) {
+		if h.logger != nil {
+			h.logger.Printf("admin logout: %v", err)
+		}
+		http.Error(w, "failed to log out", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// NewRefreshHandler constructs the handler that exchanges a refresh token for a new token
+// pair. It needs no bearer token, since the access token may already have expired.
+func NewRefreshHandler(opts SessionsHandlerOptions) http.Handler {
+	return refreshHandler{manager: opts.Manager, logger: opts.Logger}
+}
+
+type refreshHandler struct {
+	manager *adminauth.Manager
+	logger  logging.Logger
+}
+
+func (h refreshHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.manager == nil {
+		http.Error(w, "admin auth disabled", http.StatusServiceUnavailable)
+		return
+	}
+	var req refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	token, err := adminservice.AuthService{Manager: h.manager}.Refresh(req.RefreshToken)
+	switch {
+	case errors.Is(err, adminservice.ErrUnauthorized):
+		http.Error(w, "invalid refresh token", http.StatusUnauthorized)
+		return
+	case err != nil:
+		if h.logger != nil {
+			h.logger.Printf("admin refresh: %v", err)
+		}
+		http.Error(w, "failed to refresh session", http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, newLoginResponse(token))
+}
+
+// NewSessionsHandler constructs the handler that lists the caller's sessions and revokes
+// them. Admins may pass ?all=true to list every account's sessions and may revoke any
+// session; other roles only see and revoke their own.
+func NewSessionsHandler(opts SessionsHandlerOptions) http.Handler {
+	return sessionsHandler{manager: opts.Manager, logger: opts.Logger, audit: opts.Audit}
+}
+
+type sessionsHandler struct {
+	manager *adminauth.Manager
+	logger  logging.Logger
+	audit   audit.Recorder
+}
+
+func (h sessionsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.manager == nil {
+		http.Error(w, "admin auth disabled", http.StatusServiceUnavailable)
+		return
+	}
+	identity, err := adminservice.AuthService{Manager: h.manager}.Identify(r)
+	if err != nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		h.list(w, r, identity)
+	case http.MethodPost:
+		h.revoke(w, r, identity)
+	default:
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h sessionsHandler) list(w http.ResponseWriter, r *http.Request, identity adminauth.Identity) {
+	all := strings.EqualFold(r.URL.Query().Get("all"), "true")
+	if all && !identity.Role.Allows(users.RoleAdmin) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	list, err := h.manager.Sessions(identity, all)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Printf("list admin sessions: %v", err)
+		}
+		http.Error(w, "failed to load admin sessions", http.StatusInternalServerError)
+		return
+	}
+	views := make([]SessionView, 0, len(list))
+	for _, sess := range list {
+		views = append(views, viewSession(sess, identity))
+	}
+	respondJSON(w, map[string][]SessionView{"sessions": views})
+}
+
+func (h sessionsHandler) revoke(w http.ResponseWriter, r *http.Request, identity adminauth.Identity) {
+	defer r.Body.Close()
+	var req SessionsActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if action := strings.ToLower(strings.TrimSpace(req.Action)); action != "revoke" {
+		http.Error(w, "action must be revoke", http.StatusBadRequest)
+		return
+	}
+	id := strings.TrimSpace(req.ID)
+	if id == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return
+	}
+	sess, err := h.manager.Session(id)
+	if err == nil && !sess.OwnedBy(identity) && !identity.Role.Allows(users.RoleAdmin) {
+		// Report someone else's session as missing rather than confirming it exists.
+		err = adminauth.ErrSessionNotFound
+	}
+	if err == nil {
+		err = h.manager.Revoke(id)
+	}
+	switch {
+	case errors.Is(err, adminauth.ErrSessionNotFound):
+		http.Error(w, "admin session not found", http.StatusNotFound)
+		return
+	case err != nil:
+		if h.logger != nil {
+			h.logger.Printf("revoke admin session: %v", err)
+		}
+		http.Error(w, "failed to revoke admin session", http.StatusInternalServerError)
+		return
+	}
+	if h.audit != nil {
+		h.audit.Record(r.Context(), audit.Entry{Action: "session.revoke", TargetType: "session", TargetID: id, Before: audit.Snapshot(viewSession(sess, identity))})
+	}
+	respondJSON(w, map[string]any{"action": "revoke", "session": viewSession(sess, identity)})
+}
+
+func viewSession(sess adminauth.Session, identity adminauth.Identity) SessionView {
+	return SessionView{
+		ID:               sess.ID,
+		UserID:           sess.UserID,
+		Email:            sess.Email,
+		Role:             sess.Role,
+		UserAgent:        sess.UserAgent,
+		RemoteAddr:       sess.RemoteAddr,
+		CreatedAt:        sess.CreatedAt,
+		LastUsedAt:       sess.LastUsedAt,
+		ExpiresAt:        sess.ExpiresAt,
+		RefreshExpiresAt: sess.RefreshExpiresAt,
+		Current:          sess.ID == identity.SessionID,
+	}
+}
@@ -0,0 +1,110 @@
+package adminhttp_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	adminauth "live-stream-alerts/internal/admin/auth"
+	adminhttp "live-stream-alerts/internal/admin/http"
+)
+
+func authedRequest(method, target, token, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestSessionsHandlersListRevokeAndLogout(t *testing.T) {
+	manager := adminauth.NewManager(adminauth.Config{Email: "admin@example.com", Password: "secret"})
+	opts := adminhttp.SessionsHandlerOptions{Manager: manager}
+	sessions := adminhttp.NewSessionsHandler(opts)
+
+	current, err := manager.Login("admin@example.com", "secret")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	other, err := manager.LoginFrom("admin@example.com", "secret", adminauth.Client{UserAgent: "laptop"})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	sessions.ServeHTTP(rr, authedRequest(http.MethodGet, "/api/admin/sessions", current.Value, ""))
+	var listed struct {
+		Sessions []adminhttp.SessionView `json:"sessions"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(listed.Sessions) != 2 || strings.Contains(rr.Body.String(), "Hash") {
+		t.Fatalf("unexpected listing: %s", rr.Body.String())
+	}
+	for _, sess := range listed.Sessions {
+		if sess.Current != (sess.ID == current.SessionID) {
+			t.Fatalf("expected only the caller's session to be current: %+v", sess)
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	sessions.ServeHTTP(rr, authedRequest(http.MethodPost, "/api/admin/sessions", current.Value, `{"action":"revoke","id":"`+other.SessionID+`"}`))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if manager.Validate(other.Value) {
+		t.Fatalf("expected the revoked session to stop working")
+	}
+	rr = httptest.NewRecorder()
+	sessions.ServeHTTP(rr, authedRequest(http.MethodPost, "/api/admin/sessions", current.Value, `{"action":"revoke","id":"ses_missing"}`))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	adminhttp.NewLogoutHandler(opts).ServeHTTP(rr, authedRequest(http.MethodPost, "/api/admin/logout", current.Value, ""))
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rr.Code)
+	}
+	if manager.Validate(current.Value) {
+		t.Fatalf("expected logout to revoke the token")
+	}
+	rr = httptest.NewRecorder()
+	sessions.ServeHTTP(rr, authedRequest(http.MethodGet, "/api/admin/sessions", current.Value, ""))
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 after logout, got %d", rr.Code)
+	}
+}
+
+func TestRefreshHandlerRotatesTokens(t *testing.T) {
+	manager := adminauth.NewManager(adminauth.Config{Email: "admin@example.com", Password: "secret"})
+	handler := adminhttp.NewRefreshHandler(adminhttp.SessionsHandlerOptions{Manager: manager})
+	token, err := manager.Login("admin@example.com", "secret")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/admin/refresh", strings.NewReader(`{"refreshToken":"`+token.RefreshToken+`"}`)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refreshToken"`
+		SessionID    string `json:"sessionId"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.SessionID != token.SessionID || resp.RefreshToken == "" || resp.RefreshToken == token.RefreshToken || !manager.Validate(resp.Token) {
+		t.Fatalf("unexpected refresh response %+v", resp)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/admin/refresh", strings.NewReader(`{"refreshToken":"`+token.RefreshToken+`"}`)))
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected a reused refresh token to get 401, got %d", rr.Code)
+	}
+}
@@ -5,12 +5,17 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"live-stream-alerts/config"
 	adminauth "live-stream-alerts/internal/admin/auth"
 	adminservice "live-stream-alerts/internal/admin/service"
+	"live-stream-alerts/internal/audit"
 	"live-stream-alerts/internal/logging"
+	"live-stream-alerts/internal/platforms"
 	"live-stream-alerts/internal/streamers"
+	streamersvc "live-stream-alerts/internal/streamers/service"
 	"live-stream-alerts/internal/submissions"
 )
 
@@ -20,10 +25,12 @@ type SubmissionsHandlerOptions struct {
 	Service          submissionsService
 	Manager          *adminauth.Manager
 	SubmissionsStore *submissions.Store
-	StreamersStore   *streamers.Store
+	StreamersStore   streamers.Repository
 	YouTubeClient    *http.Client
 	Logger           logging.Logger
 	YouTube          config.YouTubeConfig
+	Platforms        *platforms.Registry
+	Audit            audit.Recorder
 }
 
 type authorizer interface {
@@ -32,7 +39,15 @@ type authorizer interface {
 
 type submissionsService interface {
 	List(ctx context.Context) ([]submissions.Submission, error)
+	History(ctx context.Context, filter adminservice.HistoryFilter) ([]submissions.Submission, error)
 	Process(ctx context.Context, req adminservice.ActionRequest) (adminservice.ActionResult, error)
+	Edit(ctx context.Context, req adminservice.EditRequest) (submissions.Submission, error)
+}
+
+// identifier is implemented by authorizers that can name the account behind a token, so
+// decisions and edits record their reviewer.
+type identifier interface {
+	Identify(r *http.Request) (adminauth.Identity, error)
 }
 
 type submissionsHandler struct {
@@ -41,8 +56,18 @@ type submissionsHandler struct {
 	logger     logging.Logger
 }
 
-// NewSubmissionsHandler constructs the admin submissions HTTP handler.
+// NewSubmissionsHandler constructs the admin submissions HTTP handler: GET lists pending
+// submissions, POST approves, rejects or withdraws one and PATCH edits one.
 func NewSubmissionsHandler(opts SubmissionsHandlerOptions) http.Handler {
+	return newSubmissionsHandler(opts)
+}
+
+// NewSubmissionsHistoryHandler constructs the handler that lists decided submissions.
+func NewSubmissionsHistoryHandler(opts SubmissionsHandlerOptions) http.Handler {
+	return submissionsHistoryHandler{newSubmissionsHandler(opts)}
+}
+
+func newSubmissionsHandler(opts SubmissionsHandlerOptions) submissionsHandler {
 	auth := opts.Authorizer
 	if auth == nil && opts.Manager != nil {
 		auth = adminservice.AuthService{Manager: opts.Manager}
@@ -55,6 +80,8 @@ func NewSubmissionsHandler(opts SubmissionsHandlerOptions) http.Handler {
 			YouTubeClient:    opts.YouTubeClient,
 			YouTube:          opts.YouTube,
 			Logger:           opts.Logger,
+			Platforms:        opts.Platforms,
+			Audit:            opts.Audit,
 		})
 	}
 	return submissionsHandler{
@@ -78,12 +105,31 @@ func (h submissionsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.list(w, r)
 	case http.MethodPost:
 		h.update(w, r)
+	case http.MethodPatch:
+		h.edit(w, r)
 	default:
-		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPost)
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPost+", "+http.MethodPatch)
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 	}
 }
 
+// reviewer names the caller for the submission's reviewedBy/updatedBy fields, preferring
+// the account email. It is empty when the authorizer cannot identify callers.
+func (h submissionsHandler) reviewer(r *http.Request) string {
+	ident, ok := h.authorizer.(identifier)
+	if !ok {
+		return ""
+	}
+	identity, err := ident.Identify(r)
+	if err != nil {
+		return ""
+	}
+	if identity.Email != "" {
+		return identity.Email
+	}
+	return identity.UserID
+}
+
 func (h submissionsHandler) list(w http.ResponseWriter, r *http.Request) {
 	pending, err := h.service.List(r.Context())
 	if err != nil {
@@ -103,6 +149,7 @@ func (h submissionsHandler) update(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid JSON body", http.StatusBadRequest)
 		return
 	}
+	req.Reviewer = h.reviewer(r)
 	result, err := h.service.Process(r.Context(), req)
 	if err != nil {
 		h.handleProcessError(w, err)
@@ -114,16 +161,38 @@ func (h submissionsHandler) update(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (h submissionsHandler) edit(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	var req adminservice.EditRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	req.Editor = h.reviewer(r)
+	updated, err := h.service.Edit(r.Context(), req)
+	if err != nil {
+		h.handleProcessError(w, err)
+		return
+	}
+	respondJSON(w, map[string]any{"submission": updated})
+}
+
 func (h submissionsHandler) handleProcessError(w http.ResponseWriter, err error) {
 	switch {
 	case errors.Is(err, adminservice.ErrInvalidAction):
-		http.Error(w, "action must be approve or reject", http.StatusBadRequest)
+		http.Error(w, "action must be approve, reject or withdraw", http.StatusBadRequest)
 	case errors.Is(err, adminservice.ErrMissingIdentifier):
 		http.Error(w, "id is required", http.StatusBadRequest)
+	case errors.Is(err, adminservice.ErrReasonTooLong), errors.Is(err, adminservice.ErrInvalidEdit):
+		http.Error(w, err.Error(), http.StatusBadRequest)
 	case errors.Is(err, submissions.ErrNotFound):
 		http.Error(w, "submission not found", http.StatusNotFound)
+	case errors.Is(err, submissions.ErrNotPending):
+		http.Error(w, "submission has already been reviewed", http.StatusConflict)
 	case errors.Is(err, streamers.ErrDuplicateAlias):
 		http.Error(w, "a streamer with that alias already exists", http.StatusConflict)
+	case errors.Is(err, streamersvc.ErrDuplicateSubmission):
+		http.Error(w, err.Error(), http.StatusConflict)
 	default:
 		if h.logger != nil {
 			h.logger.Printf("update submission: %v", err)
@@ -132,6 +201,50 @@ func (h submissionsHandler) handleProcessError(w http.ResponseWriter, err error)
 	}
 }
 
+type submissionsHistoryHandler struct {
+	submissionsHandler
+}
+
+func (h submissionsHistoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.authorizer == nil || h.service == nil {
+		http.Error(w, "admin submissions disabled", http.StatusServiceUnavailable)
+		return
+	}
+	if err := h.authorizer.AuthorizeRequest(r); err != nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	filter := adminservice.HistoryFilter{
+		Status: submissions.Status(strings.ToLower(strings.TrimSpace(r.URL.Query().Get("status")))),
+	}
+	if value := strings.TrimSpace(r.URL.Query().Get("limit")); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > adminservice.MaxHistoryLimit {
+			http.Error(w, "limit must be between 1 and "+strconv.Itoa(adminservice.MaxHistoryLimit), http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+	decided, err := h.service.History(r.Context(), filter)
+	switch {
+	case errors.Is(err, adminservice.ErrInvalidStatus):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		if h.logger != nil {
+			h.logger.Printf("list submission history: %v", err)
+		}
+		http.Error(w, "failed to load submission history", http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, map[string]any{"submissions": decided})
+}
+
 func respondJSON(w http.ResponseWriter, payload any) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	_ = json.NewEncoder(w).Encode(payload)
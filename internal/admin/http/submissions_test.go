@@ -7,9 +7,11 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	adminauth "live-stream-alerts/internal/admin/auth"
 	adminhttp "live-stream-alerts/internal/admin/http"
 	adminservice "live-stream-alerts/internal/admin/service"
 	"live-stream-alerts/internal/streamers"
@@ -85,6 +87,55 @@ func TestSubmissionsHandlerApprove(t *testing.T) {
 	}
 }
 
+func TestSubmissionsHandlerRecordsReviewer(t *testing.T) {
+	manager := adminauth.NewManager(adminauth.Config{Email: "admin@example.com", Password: "secret"})
+	token, err := manager.Login("admin@example.com", "secret")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	svc := &stubSubmissionsService{result: adminservice.ActionResult{Status: adminservice.ActionReject}}
+	handler := adminhttp.NewSubmissionsHandler(adminhttp.SubmissionsHandlerOptions{Manager: manager, Service: svc})
+	send := func(method, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/admin/submissions", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token.Value)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := send(http.MethodPost, `{"action":"reject","id":"1","reason":"not a streamer"}`); rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if svc.received.Reviewer != "admin@example.com" || svc.received.Reason != "not a streamer" {
+		t.Fatalf("expected reviewer and reason to reach the service, got %+v", svc.received)
+	}
+	if rr := send(http.MethodPatch, `{"id":"1","alias":"Fixed"}`); rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if svc.edited.Editor != "admin@example.com" || svc.edited.Alias == nil || *svc.edited.Alias != "Fixed" || svc.edited.Description != nil {
+		t.Fatalf("unexpected edit request %+v", svc.edited)
+	}
+}
+
+func TestSubmissionsHistoryHandler(t *testing.T) {
+	svc := &stubSubmissionsService{list: []submissions.Submission{{ID: "1", Status: submissions.StatusRejected}}}
+	handler := adminhttp.NewSubmissionsHistoryHandler(adminhttp.SubmissionsHandlerOptions{Authorizer: &stubAuthorizer{}, Service: svc})
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/admin/submissions/history?status=Rejected&limit=5", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if svc.history.Status != submissions.StatusRejected || svc.history.Limit != 5 {
+		t.Fatalf("unexpected filter %+v", svc.history)
+	}
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/admin/submissions/history?limit=0", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad limit, got %d", rr.Code)
+	}
+}
+
 func TestSubmissionsHandlerProcessErrors(t *testing.T) {
 	cases := []struct {
 		err            error
@@ -93,6 +144,8 @@ func TestSubmissionsHandlerProcessErrors(t *testing.T) {
 		{adminservice.ErrInvalidAction, http.StatusBadRequest},
 		{adminservice.ErrMissingIdentifier, http.StatusBadRequest},
 		{submissions.ErrNotFound, http.StatusNotFound},
+		{submissions.ErrNotPending, http.StatusConflict},
+		{adminservice.ErrReasonTooLong, http.StatusBadRequest},
 		{streamers.ErrDuplicateAlias, http.StatusConflict},
 		{errors.New("boom"), http.StatusInternalServerError},
 	}
@@ -130,6 +183,8 @@ type stubSubmissionsService struct {
 	result     adminservice.ActionResult
 	processErr error
 	received   adminservice.ActionRequest
+	edited     adminservice.EditRequest
+	history    adminservice.HistoryFilter
 }
 
 func (s *stubSubmissionsService) List(context.Context) ([]submissions.Submission, error) {
@@ -140,3 +195,13 @@ func (s *stubSubmissionsService) Process(ctx context.Context, req adminservice.A
 	s.received = req
 	return s.result, s.processErr
 }
+
+func (s *stubSubmissionsService) History(ctx context.Context, filter adminservice.HistoryFilter) ([]submissions.Submission, error) {
+	s.history = filter
+	return s.list, s.listErr
+}
+
+func (s *stubSubmissionsService) Edit(ctx context.Context, req adminservice.EditRequest) (submissions.Submission, error) {
+	s.edited = req
+	return s.result.Submission, s.processErr
+}
@@ -0,0 +1,230 @@
+package adminhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	adminauth "live-stream-alerts/internal/admin/auth"
+	adminservice "live-stream-alerts/internal/admin/service"
+	"live-stream-alerts/internal/admin/users"
+	"live-stream-alerts/internal/audit"
+	"live-stream-alerts/internal/logging"
+)
+
+// UsersHandlerOptions configures the admin account management handler.
+type UsersHandlerOptions struct {
+	Authorizer authorizer
+	Manager    *adminauth.Manager
+	Store      usersStore
+	Logger     logging.Logger
+	Audit      audit.Recorder
+}
+
+type usersStore interface {
+	List() ([]users.User, error)
+	Get(id string) (users.User, error)
+	Create(email, password string, role users.Role) (users.User, error)
+	SetRole(id string, role users.Role) (users.User, error)
+	SetDisabled(id string, disabled bool) (users.User, error)
+	ResetPassword(id, password string) (users.User, error)
+}
+
+// roleAuthorizer is implemented by authorizers that distinguish admin roles.
+type roleAuthorizer interface {
+	AuthorizeRole(r *http.Request, role users.Role) error
+}
+
+type usersHandler struct {
+	authorizer authorizer
+	store      usersStore
+	logger     logging.Logger
+	audit      audit.Recorder
+}
+
+// UserView is an account as returned by the API, without its password hash.
+type UserView struct {
+	ID                string     `json:"id"`
+	Email             string     `json:"email"`
+	Role              users.Role `json:"role"`
+	Disabled          bool       `json:"disabled"`
+	PasswordChangedAt time.Time  `json:"passwordChangedAt"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
+}
+
+// UsersActionRequest is the POST body accepted by the users handler. Action is one of
+// create, disable, enable, reset_password or set_role.
+type UsersActionRequest struct {
+	Action   string `json:"action"`
+	ID       string `json:"id"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+// UsersActionResponse reports the account after the action was applied.
+type UsersActionResponse struct {
+	Action string   `json:"action"`
+	User   UserView `json:"user"`
+}
+
+// NewUsersHandler constructs the admin handler that lists and manages admin accounts.
+// Every request requires the admin role.
+func NewUsersHandler(opts UsersHandlerOptions) http.Handler {
+	auth := opts.Authorizer
+	if auth == nil && opts.Manager != nil {
+		auth = adminservice.AuthService{Manager: opts.Manager}
+	}
+	return usersHandler{
+		authorizer: auth,
+		store:      opts.Store,
+		logger:     opts.Logger,
+		audit:      opts.Audit,
+	}
+}
+
+func (h usersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.authorizer == nil || h.store == nil {
+		http.Error(w, "admin users disabled", http.StatusServiceUnavailable)
+		return
+	}
+	if !h.authorize(w, r) {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		h.list(w)
+	case http.MethodPost:
+		h.update(w, r)
+	default:
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h usersHandler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	var err error
+	if roles, ok := h.authorizer.(roleAuthorizer); ok {
+		err = roles.AuthorizeRole(r, users.RoleAdmin)
+	} else {
+		err = h.authorizer.AuthorizeRequest(r)
+	}
+	switch {
+	case errors.Is(err, adminservice.ErrForbidden):
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return false
+	case err != nil:
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+func (h usersHandler) list(w http.ResponseWriter) {
+	accounts, err := h.store.List()
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Printf("list admin users: %v", err)
+		}
+		http.Error(w, "failed to load admin users", http.StatusInternalServerError)
+		return
+	}
+	views := make([]UserView, 0, len(accounts))
+	for _, user := range accounts {
+		views = append(views, viewUser(user))
+	}
+	respondJSON(w, map[string][]UserView{"users": views})
+}
+
+func (h usersHandler) update(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	var req UsersActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	action := strings.ToLower(strings.TrimSpace(req.Action))
+	id := strings.TrimSpace(req.ID)
+	if action != "create" && id == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		user   users.User
+		before any
+		err    error
+	)
+	if h.audit != nil && action != "create" {
+		if previous, err := h.store.Get(id); err == nil {
+			before = viewUser(previous)
+		}
+	}
+	switch action {
+	case "create":
+		user, err = h.store.Create(req.Email, req.Password, users.Role(strings.ToLower(strings.TrimSpace(req.Role))))
+	case "disable":
+		user, err = h.store.SetDisabled(id, true)
+	case "enable":
+		user, err = h.store.SetDisabled(id, false)
+	case "reset_password":
+		user, err = h.store.ResetPassword(id, req.Password)
+	case "set_role":
+		var role users.Role
+		if role, err = users.ParseRole(req.Role); err == nil {
+			user, err = h.store.SetRole(id, role)
+		}
+	default:
+		http.Error(w, "action must be create, disable, enable, reset_password or set_role", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case errors.Is(err, users.ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, users.ErrNotFound):
+		http.Error(w, "admin user not found", http.StatusNotFound)
+		return
+	case errors.Is(err, users.ErrDuplicateEmail), errors.Is(err, users.ErrLastAdmin):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		if h.logger != nil {
+			h.logger.Printf("admin users %s: %v", action, err)
+		}
+		http.Error(w, "failed to update admin user", http.StatusInternalServerError)
+		return
+	}
+	if h.audit != nil {
+		h.audit.Record(r.Context(), audit.Entry{
+			Action:     "user." + action,
+			TargetType: "user",
+			TargetID:   user.ID,
+			Before:     audit.Snapshot(before),
+			After:      audit.Snapshot(viewUser(user)),
+		})
+	}
+	if action == "create" {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(UsersActionResponse{Action: action, User: viewUser(user)})
+		return
+	}
+	respondJSON(w, UsersActionResponse{Action: action, User: viewUser(user)})
+}
+
+func viewUser(user users.User) UserView {
+	return UserView{
+		ID:                user.ID,
+		Email:             user.Email,
+		Role:              user.Role,
+		Disabled:          user.Disabled,
+		PasswordChangedAt: user.PasswordChangedAt,
+		CreatedAt:         user.CreatedAt,
+		UpdatedAt:         user.UpdatedAt,
+	}
+}
@@ -0,0 +1,105 @@
+package adminhttp_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	adminhttp "live-stream-alerts/internal/admin/http"
+	adminservice "live-stream-alerts/internal/admin/service"
+	"live-stream-alerts/internal/admin/users"
+)
+
+type stubRoleAuthorizer struct {
+	role users.Role
+}
+
+func (s stubRoleAuthorizer) AuthorizeRequest(*http.Request) error { return nil }
+
+func (s stubRoleAuthorizer) AuthorizeRole(r *http.Request, role users.Role) error {
+	if !s.role.Allows(role) {
+		return adminservice.ErrForbidden
+	}
+	return nil
+}
+
+func postUsers(t *testing.T, handler http.Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/admin/users", strings.NewReader(body)))
+	return rr
+}
+
+func TestUsersHandlerManagesAccounts(t *testing.T) {
+	store := users.NewStore(filepath.Join(t.TempDir(), "admin_users.json"), users.WithHashCost(bcrypt.MinCost))
+	handler := adminhttp.NewUsersHandler(adminhttp.UsersHandlerOptions{
+		Authorizer: stubRoleAuthorizer{role: users.RoleAdmin},
+		Store:      store,
+	})
+
+	rr := postUsers(t, handler, `{"action":"create","email":"admin@example.com","password":"admin password","role":"admin"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created adminhttp.UsersActionResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if strings.Contains(rr.Body.String(), "passwordHash") {
+		t.Fatalf("expected the hash to stay private: %s", rr.Body.String())
+	}
+	adminID := created.User.ID
+
+	cases := []struct {
+		body string
+		code int
+	}{
+		{body: `{"action":"create","email":"admin@example.com","password":"another one","role":"viewer"}`, code: http.StatusConflict},
+		{body: `{"action":"create","email":"viewer@example.com","password":"short","role":"viewer"}`, code: http.StatusBadRequest},
+		{body: `{"action":"create","email":"viewer@example.com","password":"viewer password","role":"owner"}`, code: http.StatusBadRequest},
+		{body: `{"action":"disable","id":"` + adminID + `"}`, code: http.StatusConflict},
+		{body: `{"action":"set_role","id":"` + adminID + `","role":"viewer"}`, code: http.StatusConflict},
+		{body: `{"action":"reset_password","id":"` + adminID + `","password":"rotated password"}`, code: http.StatusOK},
+		{body: `{"action":"disable","id":"usr_missing"}`, code: http.StatusNotFound},
+		{body: `{"action":"disable"}`, code: http.StatusBadRequest},
+		{body: `{"action":"delete","id":"` + adminID + `"}`, code: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		if rr := postUsers(t, handler, tc.body); rr.Code != tc.code {
+			t.Fatalf("%s: expected %d, got %d: %s", tc.body, tc.code, rr.Code, rr.Body.String())
+		}
+	}
+	if _, err := store.Authenticate("admin@example.com", "rotated password"); err != nil {
+		t.Fatalf("expected the reset password to work: %v", err)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/admin/users", nil))
+	var listed struct {
+		Users []adminhttp.UserView `json:"users"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(listed.Users) != 1 || listed.Users[0].Email != "admin@example.com" || listed.Users[0].Role != users.RoleAdmin {
+		t.Fatalf("unexpected listing %+v", listed.Users)
+	}
+}
+
+func TestUsersHandlerRequiresAdminRole(t *testing.T) {
+	store := users.NewStore(filepath.Join(t.TempDir(), "admin_users.json"), users.WithHashCost(bcrypt.MinCost))
+	handler := adminhttp.NewUsersHandler(adminhttp.UsersHandlerOptions{
+		Authorizer: stubRoleAuthorizer{role: users.RoleReviewer},
+		Store:      store,
+	})
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/admin/users", nil))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rr.Code)
+	}
+}
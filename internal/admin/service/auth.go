@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	adminauth "live-stream-alerts/internal/admin/auth"
+	"live-stream-alerts/internal/admin/users"
 )
 
 // AuthService provides helpers for validating admin credentials and tokens.
@@ -16,38 +17,77 @@ type AuthService struct {
 // AuthorizeRequest validates the Authorization header on the provided request.
 // It returns ErrUnauthorized when the token is missing or invalid.
 func (s AuthService) AuthorizeRequest(r *http.Request) error {
+	_, err := s.Identify(r)
+	return err
+}
+
+// AuthorizeRole validates the request's token like AuthorizeRequest and returns
+// ErrForbidden when the account's role does not include role.
+func (s AuthService) AuthorizeRole(r *http.Request, role users.Role) error {
+	identity, err := s.Identify(r)
+	if err != nil {
+		return err
+	}
+	if !identity.Role.Allows(role) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// Identify returns the account behind the request's bearer token.
+func (s AuthService) Identify(r *http.Request) (adminauth.Identity, error) {
 	if s.Manager == nil {
-		return ErrUnauthorized
+		return adminauth.Identity{}, ErrUnauthorized
 	}
 	header := strings.TrimSpace(r.Header.Get("Authorization"))
 	if header == "" {
-		return ErrUnauthorized
+		return adminauth.Identity{}, ErrUnauthorized
 	}
 	parts := strings.SplitN(header, " ", 2)
 	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
-		return ErrUnauthorized
+		return adminauth.Identity{}, ErrUnauthorized
 	}
-	if token := strings.TrimSpace(parts[1]); token != "" && s.Manager.Validate(token) {
-		return nil
+	if identity, ok := s.Manager.Identify(strings.TrimSpace(parts[1])); ok {
+		return identity, nil
 	}
-	return ErrUnauthorized
+	return adminauth.Identity{}, ErrUnauthorized
 }
 
 // Login verifies the provided credentials and returns a scoped token.
 func (s AuthService) Login(email, password string) (adminauth.Token, error) {
+	return s.LoginFrom(email, password, adminauth.Client{})
+}
+
+// LoginFrom is Login, recording the client on the new session.
+func (s AuthService) LoginFrom(email, password string, client adminauth.Client) (adminauth.Token, error) {
 	if s.Manager == nil {
 		return adminauth.Token{}, ErrUnauthorized
 	}
-	token, err := s.Manager.Login(email, password)
-	if err != nil {
+	token, err := s.Manager.LoginFrom(email, password, client)
+	if errors.Is(err, adminauth.ErrInvalidCredentials) {
 		return adminauth.Token{}, ErrInvalidCredentials
 	}
-	return token, nil
+	return token, err
+}
+
+// Refresh exchanges a refresh token for a new token pair. It returns ErrUnauthorized
+// when the refresh token is unknown, expired or already used.
+func (s AuthService) Refresh(refreshToken string) (adminauth.Token, error) {
+	if s.Manager == nil {
+		return adminauth.Token{}, ErrUnauthorized
+	}
+	token, err := s.Manager.Refresh(refreshToken)
+	if errors.Is(err, adminauth.ErrInvalidRefreshToken) {
+		return adminauth.Token{}, ErrUnauthorized
+	}
+	return token, err
 }
 
 var (
 	// ErrUnauthorized indicates the caller lacks a valid admin token.
 	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden indicates the caller's role does not grant the requested action.
+	ErrForbidden = errors.New("forbidden")
 	// ErrInvalidCredentials signals bad login credentials.
 	ErrInvalidCredentials = errors.New("invalid credentials")
 )
@@ -1,10 +1,15 @@
 package service
 
 import (
+	"errors"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 
+	"golang.org/x/crypto/bcrypt"
+
 	adminauth "live-stream-alerts/internal/admin/auth"
+	"live-stream-alerts/internal/admin/users"
 )
 
 func TestAuthServiceAuthorizeRequest(t *testing.T) {
@@ -38,3 +43,56 @@ func TestAuthServiceLogin(t *testing.T) {
 		t.Fatalf("expected invalid credentials")
 	}
 }
+
+func TestAuthServiceAuthorizeRoleWithUserStore(t *testing.T) {
+	store := users.NewStore(filepath.Join(t.TempDir(), "admin_users.json"), users.WithHashCost(bcrypt.MinCost))
+	if _, err := store.Create("admin@example.com", "admin password", users.RoleAdmin); err != nil {
+		t.Fatalf("create admin: %v", err)
+	}
+	reviewer, err := store.Create("reviewer@example.com", "reviewer password", users.RoleReviewer)
+	if err != nil {
+		t.Fatalf("create reviewer: %v", err)
+	}
+	mgr := adminauth.NewManager(adminauth.Config{Email: "legacy@example.com", Password: "secret", Users: store})
+	service := AuthService{Manager: mgr}
+
+	if _, err := service.Login("legacy@example.com", "secret"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected the legacy pair to be ignored once a user store is set, got %v", err)
+	}
+	token, err := service.Login("reviewer@example.com", "reviewer password")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if token.Role != users.RoleReviewer {
+		t.Fatalf("expected reviewer token, got %q", token.Role)
+	}
+	req := httptest.NewRequest("POST", "/api/admin/submissions", nil)
+	req.Header.Set("Authorization", "Bearer "+token.Value)
+	if err := service.AuthorizeRole(req, users.RoleReviewer); err != nil {
+		t.Fatalf("expected reviewer access, got %v", err)
+	}
+	if err := service.AuthorizeRole(req, users.RoleAdmin); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected admin routes to be forbidden, got %v", err)
+	}
+
+	if _, err := store.SetDisabled(reviewer.ID, true); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	if err := service.AuthorizeRequest(req); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected disabling the account to revoke its token, got %v", err)
+	}
+	if _, err := store.SetDisabled(reviewer.ID, false); err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+	token, err = service.Login("reviewer@example.com", "reviewer password")
+	if err != nil {
+		t.Fatalf("login again: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token.Value)
+	if _, err := store.ResetPassword(reviewer.ID, "rotated password"); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if err := service.AuthorizeRequest(req); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected a password reset to revoke existing tokens, got %v", err)
+	}
+}
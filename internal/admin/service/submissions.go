@@ -3,14 +3,20 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
 	"time"
 
 	"live-stream-alerts/config"
+	"live-stream-alerts/internal/audit"
 	"live-stream-alerts/internal/logging"
-	"live-stream-alerts/internal/platforms/youtube/onboarding"
+	"live-stream-alerts/internal/platforms"
+	youtubeprovider "live-stream-alerts/internal/platforms/youtube/provider"
 	"live-stream-alerts/internal/streamers"
+	streamersvc "live-stream-alerts/internal/streamers/service"
 	"live-stream-alerts/internal/submissions"
 )
 
@@ -22,12 +28,21 @@ const (
 	ActionApprove Action = "approve"
 	// ActionReject represents rejecting a pending submission.
 	ActionReject Action = "reject"
+	// ActionWithdraw represents withdrawing a pending submission on the submitter's behalf.
+	ActionWithdraw Action = "withdraw"
 )
 
+// MaxReasonLength bounds the reason a reviewer can attach to a decision.
+const MaxReasonLength = 500
+
 // ActionRequest captures the payload required to mutate a submission.
 type ActionRequest struct {
 	Action Action `json:"action"`
 	ID     string `json:"id"`
+	Reason string `json:"reason,omitempty"`
+	// Reviewer names who made the decision. The HTTP handler fills it from the caller's
+	// token rather than the request body.
+	Reviewer string `json:"-"`
 }
 
 // ActionResult contains the final status for the processed submission.
@@ -36,7 +51,33 @@ type ActionResult struct {
 	Submission submissions.Submission `json:"submission"`
 }
 
-// Onboarder abstracts the YouTube onboarding workflow for dependency injection.
+// EditRequest captures the submission fields a reviewer may correct before deciding.
+// Nil fields are left unchanged.
+type EditRequest struct {
+	ID          string    `json:"id"`
+	Alias       *string   `json:"alias,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	Languages   *[]string `json:"languages,omitempty"`
+	PlatformURL *string   `json:"platformUrl,omitempty"`
+	// Editor names who made the change; like ActionRequest.Reviewer it comes from the token.
+	Editor string `json:"-"`
+}
+
+// HistoryFilter narrows the list of decided submissions.
+type HistoryFilter struct {
+	// Status keeps only one outcome; empty returns approved, rejected and withdrawn.
+	Status submissions.Status
+	// Limit caps the result (DefaultHistoryLimit when zero, at most MaxHistoryLimit).
+	Limit int
+}
+
+const (
+	// DefaultHistoryLimit and MaxHistoryLimit bound how many decisions History returns.
+	DefaultHistoryLimit = 100
+	MaxHistoryLimit     = 1000
+)
+
+// Onboarder abstracts the platform onboarding workflow for dependency injection.
 type Onboarder interface {
 	FromURL(ctx context.Context, record streamers.Record, url string) error
 }
@@ -55,21 +96,28 @@ func (f OnboarderFunc) FromURL(ctx context.Context, record streamers.Record, url
 // SubmissionsOptions configures the SubmissionsService.
 type SubmissionsOptions struct {
 	SubmissionsStore *submissions.Store
-	StreamersStore   *streamers.Store
+	StreamersStore   streamers.Repository
 	YouTubeClient    *http.Client
 	YouTube          config.YouTubeConfig
 	Logger           logging.Logger
 	Onboarder        Onboarder
+	// Platforms picks the provider that onboards a submission's platform URL. When nil,
+	// only YouTube (configured from YouTube and YouTubeClient) is supported.
+	Platforms *platforms.Registry
+	// Audit records edits and decisions. When nil nothing is recorded.
+	Audit audit.Recorder
 }
 
 // SubmissionsService encapsulates streamer submission review logic.
 type SubmissionsService struct {
 	submissionsStore *submissions.Store
-	streamersStore   *streamers.Store
+	streamersStore   streamers.Repository
 	youtubeClient    *http.Client
 	youtube          config.YouTubeConfig
 	logger           logging.Logger
 	onboarder        Onboarder
+	platforms        *platforms.Registry
+	audit            audit.Recorder
 }
 
 // NewSubmissionsService constructs a SubmissionsService with the provided options.
@@ -93,19 +141,27 @@ func NewSubmissionsService(opts SubmissionsOptions) *SubmissionsService {
 		youtube:          opts.YouTube,
 		logger:           opts.Logger,
 		onboarder:        opts.Onboarder,
+		platforms:        opts.Platforms,
+		audit:            opts.Audit,
+	}
+	if svc.platforms == nil {
+		svc.platforms = platforms.NewRegistry(youtubeprovider.New(youtubeprovider.Options{
+			Store:        svc.streamersStore,
+			Client:       svc.youtubeClient,
+			HubURL:       svc.youtube.HubURL,
+			CallbackURL:  svc.youtube.CallbackURL,
+			VerifyMode:   svc.youtube.Verify,
+			LeaseSeconds: svc.youtube.LeaseSeconds,
+			Logger:       svc.logger,
+		}))
 	}
 	if svc.onboarder == nil {
 		svc.onboarder = OnboarderFunc(func(ctx context.Context, record streamers.Record, url string) error {
-			onboardOpts := onboarding.Options{
-				Client:       svc.youtubeClient,
-				HubURL:       strings.TrimSpace(svc.youtube.HubURL),
-				CallbackURL:  strings.TrimSpace(svc.youtube.CallbackURL),
-				VerifyMode:   strings.TrimSpace(svc.youtube.Verify),
-				LeaseSeconds: svc.youtube.LeaseSeconds,
-				Logger:       svc.logger,
-				Store:        svc.streamersStore,
+			provider, _, err := svc.platforms.ForURL(url)
+			if err != nil {
+				return err
 			}
-			return onboarding.FromURL(ctx, record, url, onboardOpts)
+			return provider.Onboard(ctx, record, url)
 		})
 	}
 	return svc
@@ -116,10 +172,54 @@ func (s *SubmissionsService) List(ctx context.Context) ([]submissions.Submission
 	if err := s.ensureStores(); err != nil {
 		return nil, err
 	}
-	return s.submissionsStore.List()
+	all, err := s.submissionsStore.List()
+	if err != nil {
+		return nil, err
+	}
+	pending := make([]submissions.Submission, 0, len(all))
+	for _, sub := range all {
+		if sub.Pending() {
+			pending = append(pending, sub)
+		}
+	}
+	return pending, nil
+}
+
+// History returns decided submissions, most recently reviewed first.
+func (s *SubmissionsService) History(ctx context.Context, filter HistoryFilter) ([]submissions.Submission, error) {
+	if err := s.ensureStores(); err != nil {
+		return nil, err
+	}
+	if filter.Status != "" && (!filter.Status.Valid() || filter.Status == submissions.StatusPending) {
+		return nil, ErrInvalidStatus
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	limit = min(limit, MaxHistoryLimit)
+	all, err := s.submissionsStore.List()
+	if err != nil {
+		return nil, err
+	}
+	decided := make([]submissions.Submission, 0, len(all))
+	for _, sub := range all {
+		if sub.Pending() || (filter.Status != "" && sub.Status != filter.Status) {
+			continue
+		}
+		decided = append(decided, sub)
+	}
+	sort.SliceStable(decided, func(i, j int) bool {
+		return decided[i].ReviewedAt.After(decided[j].ReviewedAt)
+	})
+	if len(decided) > limit {
+		decided = decided[:limit]
+	}
+	return decided, nil
 }
 
-// Process mutates a submission according to the provided action.
+// Process records a decision on a pending submission. Decided submissions are kept with
+// their status, reviewer and reason; an approval also creates the streamer record.
 func (s *SubmissionsService) Process(ctx context.Context, req ActionRequest) (ActionResult, error) {
 	if err := s.ensureStores(); err != nil {
 		return ActionResult{}, err
@@ -132,17 +232,179 @@ func (s *SubmissionsService) Process(ctx context.Context, req ActionRequest) (Ac
 	if id == "" {
 		return ActionResult{}, ErrMissingIdentifier
 	}
-	removed, err := s.submissionsStore.Remove(id)
+	reason := strings.TrimSpace(req.Reason)
+	if len([]rune(reason)) > MaxReasonLength {
+		return ActionResult{}, ErrReasonTooLong
+	}
+
+	var (
+		before     submissions.Submission
+		streamerID string
+	)
+	if action == ActionApprove {
+		streamerID = streamers.GenerateID()
+	}
+	// Deciding first, under the store lock, means two reviewers approving at once cannot
+	// both create a streamer.
+	decided, err := s.submissionsStore.Update(id, func(sub *submissions.Submission) error {
+		if !sub.Pending() {
+			return submissions.ErrNotPending
+		}
+		before = *sub
+		sub.Status = statusFor(action)
+		sub.ReviewedAt = time.Now().UTC()
+		sub.ReviewedBy = strings.TrimSpace(req.Reviewer)
+		sub.Reason = reason
+		sub.StreamerID = streamerID
+		return nil
+	})
 	if err != nil {
 		return ActionResult{}, err
 	}
 	if action == ActionApprove {
-		if err := s.approve(ctx, removed); err != nil {
+		record, err := s.approve(ctx, decided)
+		if err != nil {
+			s.reopen(before)
 			return ActionResult{}, err
 		}
-		return ActionResult{Status: ActionApprove, Submission: removed}, nil
+		s.record(ctx, "submission.approve", before, record.Public())
+		return ActionResult{Status: ActionApprove, Submission: decided}, nil
+	}
+	s.record(ctx, "submission."+string(action), before, decided)
+	return ActionResult{Status: action, Submission: decided}, nil
+}
+
+// Edit corrects a pending submission's alias, description, languages or platform URL.
+func (s *SubmissionsService) Edit(ctx context.Context, req EditRequest) (submissions.Submission, error) {
+	if err := s.ensureStores(); err != nil {
+		return submissions.Submission{}, err
+	}
+	id := strings.TrimSpace(req.ID)
+	if id == "" {
+		return submissions.Submission{}, ErrMissingIdentifier
+	}
+	// Check up front so a reviewed submission reports as such rather than failing
+	// validation; Update checks again under the lock.
+	current, err := s.submissionsStore.Get(id)
+	if err != nil {
+		return submissions.Submission{}, err
+	}
+	if !current.Pending() {
+		return submissions.Submission{}, submissions.ErrNotPending
+	}
+	var (
+		alias, description, platformURL *string
+		languages                       *[]string
+	)
+	if req.Alias != nil {
+		value := strings.TrimSpace(*req.Alias)
+		if streamers.NormaliseAlias(value) == "" {
+			return submissions.Submission{}, fmt.Errorf("%w: alias must contain a letter or digit", ErrInvalidEdit)
+		}
+		if err := s.ensureUniqueAlias(id, value); err != nil {
+			return submissions.Submission{}, err
+		}
+		alias = &value
+	}
+	if req.Description != nil {
+		value := strings.TrimSpace(*req.Description)
+		description = &value
+	}
+	if req.Languages != nil {
+		value, err := streamersvc.SanitiseLanguages(*req.Languages)
+		if err != nil {
+			return submissions.Submission{}, fmt.Errorf("%w: %v", ErrInvalidEdit, err)
+		}
+		languages = &value
+	}
+	if req.PlatformURL != nil {
+		value := strings.TrimSpace(*req.PlatformURL)
+		if value != "" {
+			parsed, err := url.Parse(value)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return submissions.Submission{}, fmt.Errorf("%w: platformUrl must be an http or https URL", ErrInvalidEdit)
+			}
+		}
+		platformURL = &value
+	}
+	if alias == nil && description == nil && languages == nil && platformURL == nil {
+		return submissions.Submission{}, fmt.Errorf("%w: nothing to update", ErrInvalidEdit)
+	}
+
+	var before submissions.Submission
+	updated, err := s.submissionsStore.UpdateChecked(id, func(sub *submissions.Submission) error {
+		if !sub.Pending() {
+			return submissions.ErrNotPending
+		}
+		before = *sub
+		if alias != nil {
+			sub.Alias = *alias
+		}
+		if description != nil {
+			sub.Description = *description
+		}
+		if languages != nil {
+			sub.Languages = *languages
+		}
+		if platformURL != nil {
+			sub.PlatformURL = *platformURL
+		}
+		sub.UpdatedAt = time.Now().UTC()
+		sub.UpdatedBy = strings.TrimSpace(req.Editor)
+		return nil
+	}, func(updated submissions.Submission, others []submissions.Submission) error {
+		// The same duplicate rule Create applies, so an edit cannot point two pending
+		// submissions at one URL or channel.
+		if platformURL == nil {
+			return nil
+		}
+		return streamersvc.CheckDuplicatePending(s.platforms, others, updated.PlatformURL)
+	})
+	if err != nil {
+		return submissions.Submission{}, err
+	}
+	s.record(ctx, "submission.update", before, updated)
+	return updated, nil
+}
+
+// ensureUniqueAlias rejects an edited alias that clashes with a streamer or with another
+// pending submission, matching the check made when the submission was created.
+func (s *SubmissionsService) ensureUniqueAlias(id, alias string) error {
+	key := streamers.NormaliseAlias(alias)
+	records, err := s.streamersStore.List()
+	if err != nil {
+		return err
+	}
+	for _, rec := range records {
+		if key == streamers.NormaliseAlias(rec.Streamer.Alias) {
+			return streamers.ErrDuplicateAlias
+		}
+	}
+	pending, err := s.submissionsStore.List()
+	if err != nil {
+		return err
+	}
+	for _, sub := range pending {
+		if sub.ID != id && sub.Pending() && key == streamers.NormaliseAlias(sub.Alias) {
+			return streamers.ErrDuplicateAlias
+		}
+	}
+	return nil
+}
+
+// record audits a change to a submission. For approvals, after is the created streamer,
+// redacted with Record.Public.
+func (s *SubmissionsService) record(ctx context.Context, action string, before submissions.Submission, after any) {
+	if s.audit == nil {
+		return
 	}
-	return ActionResult{Status: ActionReject, Submission: removed}, nil
+	s.audit.Record(ctx, audit.Entry{
+		Action:     action,
+		TargetType: "submission",
+		TargetID:   before.ID,
+		Before:     audit.Snapshot(before),
+		After:      audit.Snapshot(after),
+	})
 }
 
 func (s *SubmissionsService) ensureStores() error {
@@ -158,10 +420,10 @@ func (s *SubmissionsService) ensureStores() error {
 	return nil
 }
 
-func (s *SubmissionsService) approve(ctx context.Context, submission submissions.Submission) error {
+func (s *SubmissionsService) approve(ctx context.Context, submission submissions.Submission) (*streamers.Record, error) {
 	record := streamers.Record{
 		Streamer: streamers.Streamer{
-			ID:          streamers.GenerateID(),
+			ID:          submission.StreamerID,
 			Alias:       submission.Alias,
 			Description: submission.Description,
 			Languages:   submission.Languages,
@@ -169,31 +431,47 @@ func (s *SubmissionsService) approve(ctx context.Context, submission submissions
 	}
 	persisted, err := s.streamersStore.Append(record)
 	if err != nil {
-		s.requeue(submission)
-		return err
+		return nil, err
 	}
-	url := strings.TrimSpace(submission.PlatformURL)
-	if url == "" {
-		return nil
+	platformURL := strings.TrimSpace(submission.PlatformURL)
+	if platformURL == "" {
+		return &persisted, nil
 	}
 	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
-	if err := s.onboarder.FromURL(ctx, persisted, url); err != nil && s.logger != nil {
+	if err := s.onboarder.FromURL(ctx, persisted, platformURL); err != nil && s.logger != nil {
 		s.logger.Printf("failed to process platform url for %s: %v", persisted.Streamer.Alias, err)
 	}
-	return nil
+	return &persisted, nil
 }
 
-func (s *SubmissionsService) requeue(submission submissions.Submission) {
-	if _, err := s.submissionsStore.Append(submission); err != nil && s.logger != nil {
-		s.logger.Printf("failed to requeue submission %s: %v", submission.ID, err)
+// reopen puts a submission back to how it was before a decision whose follow-up failed,
+// so it can be reviewed again.
+func (s *SubmissionsService) reopen(previous submissions.Submission) {
+	_, err := s.submissionsStore.Update(previous.ID, func(sub *submissions.Submission) error {
+		*sub = previous
+		return nil
+	})
+	if err != nil && s.logger != nil {
+		s.logger.Printf("failed to reopen submission %s: %v", previous.ID, err)
+	}
+}
+
+func statusFor(action Action) submissions.Status {
+	switch action {
+	case ActionApprove:
+		return submissions.StatusApproved
+	case ActionWithdraw:
+		return submissions.StatusWithdrawn
+	default:
+		return submissions.StatusRejected
 	}
 }
 
 func normaliseAction(value Action) Action {
 	normalized := Action(strings.ToLower(strings.TrimSpace(string(value))))
 	switch normalized {
-	case ActionApprove, ActionReject:
+	case ActionApprove, ActionReject, ActionWithdraw:
 		return normalized
 	default:
 		return ""
@@ -202,7 +480,13 @@ func normaliseAction(value Action) Action {
 
 var (
 	// ErrInvalidAction indicates the request payload contained an unsupported action.
-	ErrInvalidAction = errors.New("action must be approve or reject")
+	ErrInvalidAction = errors.New("action must be approve, reject or withdraw")
 	// ErrMissingIdentifier signals that the submission ID was omitted.
 	ErrMissingIdentifier = errors.New("submission id is required")
+	// ErrReasonTooLong signals a decision reason over MaxReasonLength characters.
+	ErrReasonTooLong = fmt.Errorf("reason must be at most %d characters", MaxReasonLength)
+	// ErrInvalidEdit wraps the validation failures of Edit.
+	ErrInvalidEdit = errors.New("invalid submission edit")
+	// ErrInvalidStatus signals a history filter other than approved, rejected or withdrawn.
+	ErrInvalidStatus = errors.New("status must be approved, rejected or withdrawn")
 )
@@ -10,6 +10,7 @@ import (
 
 	"live-stream-alerts/config"
 	"live-stream-alerts/internal/streamers"
+	streamersvc "live-stream-alerts/internal/streamers/service"
 	"live-stream-alerts/internal/submissions"
 )
 
@@ -24,19 +25,122 @@ func TestSubmissionsServiceReject(t *testing.T) {
 		SubmissionsStore: subStore,
 		StreamersStore:   streamStore,
 	})
-	result, err := svc.Process(context.Background(), ActionRequest{Action: ActionReject, ID: "sub_1"})
+	result, err := svc.Process(context.Background(), ActionRequest{Action: ActionReject, ID: "sub_1", Reason: " duplicate channel ", Reviewer: "reviewer@example.com"})
 	if err != nil {
 		t.Fatalf("process reject: %v", err)
 	}
 	if result.Status != ActionReject {
 		t.Fatalf("expected reject status, got %s", result.Status)
 	}
-	remaining, err := subStore.List()
+	pending, err := svc.List(context.Background())
 	if err != nil {
 		t.Fatalf("list submissions: %v", err)
 	}
-	if len(remaining) != 0 {
-		t.Fatalf("expected submissions cleared, got %d", len(remaining))
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending submissions, got %d", len(pending))
+	}
+	stored, err := subStore.Get("sub_1")
+	if err != nil {
+		t.Fatalf("get submission: %v", err)
+	}
+	if stored.Status != submissions.StatusRejected || stored.Reason != "duplicate channel" || stored.ReviewedBy != "reviewer@example.com" || stored.ReviewedAt.IsZero() {
+		t.Fatalf("expected the rejection to be kept with its reason, got %+v", stored)
+	}
+	if _, err := svc.Process(context.Background(), ActionRequest{Action: ActionApprove, ID: "sub_1"}); !errors.Is(err, submissions.ErrNotPending) {
+		t.Fatalf("expected a decided submission to stay decided, got %v", err)
+	}
+}
+
+func TestSubmissionsServiceEditRefusesDuplicateURL(t *testing.T) {
+	dir := t.TempDir()
+	subStore := submissions.NewStore(filepath.Join(dir, "subs.json"))
+	for id, url := range map[string]string{"one": "https://example.com/one", "two": "https://example.com/two", "done": "https://example.com/done"} {
+		if _, err := subStore.Append(submissions.Submission{ID: id, Alias: id, PlatformURL: url}); err != nil {
+			t.Fatalf("append submission: %v", err)
+		}
+	}
+	svc := NewSubmissionsService(SubmissionsOptions{SubmissionsStore: subStore, StreamersStore: streamers.NewStore(filepath.Join(dir, "streamers.json"))})
+	ctx := context.Background()
+	if _, err := svc.Process(ctx, ActionRequest{Action: ActionReject, ID: "done"}); err != nil {
+		t.Fatalf("reject: %v", err)
+	}
+
+	duplicate := "https://www.example.com/one/"
+	if _, err := svc.Edit(ctx, EditRequest{ID: "two", PlatformURL: &duplicate}); !errors.Is(err, streamersvc.ErrDuplicateSubmission) {
+		t.Fatalf("expected duplicate submission error, got %v", err)
+	}
+	if got, err := subStore.Get("two"); err != nil || got.PlatformURL != "https://example.com/two" {
+		t.Fatalf("expected the refused edit to leave the submission alone, got %+v, %v", got, err)
+	}
+	decided := "https://example.com/done"
+	if _, err := svc.Edit(ctx, EditRequest{ID: "two", PlatformURL: &decided}); err != nil {
+		t.Fatalf("expected a decided submission's URL to be reusable: %v", err)
+	}
+	alias := "Renamed"
+	if _, err := svc.Edit(ctx, EditRequest{ID: "one", Alias: &alias}); err != nil {
+		t.Fatalf("expected alias edits to skip the URL check: %v", err)
+	}
+}
+
+func TestSubmissionsServiceEditAndHistory(t *testing.T) {
+	dir := t.TempDir()
+	subStore := submissions.NewStore(filepath.Join(dir, "subs.json"))
+	for _, alias := range []string{"Typo", "Other", "Third"} {
+		if _, err := subStore.Append(submissions.Submission{ID: alias, Alias: alias}); err != nil {
+			t.Fatalf("append submission: %v", err)
+		}
+	}
+	streamStore := streamers.NewStore(filepath.Join(dir, "streamers.json"))
+	svc := NewSubmissionsService(SubmissionsOptions{SubmissionsStore: subStore, StreamersStore: streamStore})
+	ctx := context.Background()
+
+	alias, languages := "Fixed", []string{"English", "English"}
+	edited, err := svc.Edit(ctx, EditRequest{ID: "Typo", Alias: &alias, Languages: &languages, Editor: "reviewer@example.com"})
+	if err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	if edited.Alias != "Fixed" || len(edited.Languages) != 1 || edited.UpdatedBy != "reviewer@example.com" || !edited.Pending() {
+		t.Fatalf("unexpected edited submission %+v", edited)
+	}
+	taken := "other"
+	if _, err := svc.Edit(ctx, EditRequest{ID: "Typo", Alias: &taken}); !errors.Is(err, streamers.ErrDuplicateAlias) {
+		t.Fatalf("expected duplicate alias error, got %v", err)
+	}
+	badURL := "ftp://example.com"
+	if _, err := svc.Edit(ctx, EditRequest{ID: "Typo", PlatformURL: &badURL}); !errors.Is(err, ErrInvalidEdit) {
+		t.Fatalf("expected invalid edit error, got %v", err)
+	}
+
+	result, err := svc.Process(ctx, ActionRequest{Action: ActionApprove, ID: "Typo"})
+	if err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	records, err := streamStore.List()
+	if err != nil {
+		t.Fatalf("list streamers: %v", err)
+	}
+	if len(records) != 1 || records[0].Streamer.Alias != "Fixed" || records[0].Streamer.ID != result.Submission.StreamerID {
+		t.Fatalf("expected the edited submission to become the streamer, got %+v and %+v", records, result.Submission)
+	}
+	if _, err := svc.Edit(ctx, EditRequest{ID: "Typo", Alias: &alias}); !errors.Is(err, submissions.ErrNotPending) {
+		t.Fatalf("expected an approved submission to be read-only, got %v", err)
+	}
+	if _, err := svc.Process(ctx, ActionRequest{Action: ActionWithdraw, ID: "Other"}); err != nil {
+		t.Fatalf("withdraw: %v", err)
+	}
+
+	history, err := svc.History(ctx, HistoryFilter{})
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(history) != 2 || history[0].ID != "Other" || history[1].Status != submissions.StatusApproved {
+		t.Fatalf("expected both decisions newest first, got %+v", history)
+	}
+	if approved, _ := svc.History(ctx, HistoryFilter{Status: submissions.StatusApproved}); len(approved) != 1 {
+		t.Fatalf("expected one approval, got %+v", approved)
+	}
+	if _, err := svc.History(ctx, HistoryFilter{Status: submissions.StatusPending}); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("expected invalid status error, got %v", err)
 	}
 }
 
@@ -106,8 +210,8 @@ func TestSubmissionsServiceDuplicateAlias(t *testing.T) {
 	if err != nil {
 		t.Fatalf("list submissions: %v", err)
 	}
-	if len(list) != 1 {
-		t.Fatalf("expected submission requeued, got %d", len(list))
+	if len(list) != 1 || !list[0].Pending() || list[0].StreamerID != "" {
+		t.Fatalf("expected submission reopened, got %+v", list)
 	}
 }
 
@@ -0,0 +1,434 @@
+// Package users stores admin accounts with bcrypt password hashes and roles.
+package users
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"live-stream-alerts/internal/filestore"
+)
+
+// DefaultFilePath is where admin accounts are stored.
+const DefaultFilePath = "data/admin_users.json"
+
+// MinPasswordLength is the shortest password accepted for an account.
+const MinPasswordLength = 8
+
+// Role grants access to a set of admin routes. Each role includes the ones below it.
+type Role string
+
+const (
+	// RoleViewer can read submissions, the lease monitor and the retry queue.
+	RoleViewer Role = "viewer"
+	// RoleReviewer can also approve and reject submissions and edit streamers.
+	RoleReviewer Role = "reviewer"
+	// RoleAdmin can also delete streamers, requeue jobs and manage accounts.
+	RoleAdmin Role = "admin"
+)
+
+var roleRank = map[Role]int{RoleViewer: 1, RoleReviewer: 2, RoleAdmin: 3}
+
+// ParseRole validates a role name.
+func ParseRole(value string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(value)))
+	if _, ok := roleRank[role]; !ok {
+		return "", fmt.Errorf("%w: unknown role %q", ErrInvalid, value)
+	}
+	return role, nil
+}
+
+// Allows reports whether r grants everything required grants.
+func (r Role) Allows(required Role) bool {
+	rank, ok := roleRank[r]
+	return ok && rank >= roleRank[required]
+}
+
+var (
+	// ErrNotFound indicates no account has the given ID.
+	ErrNotFound = errors.New("admin user not found")
+	// ErrDuplicateEmail indicates another account already uses the email address.
+	ErrDuplicateEmail = errors.New("admin user email already exists")
+	// ErrInvalid wraps validation failures for emails, passwords and roles.
+	ErrInvalid = errors.New("invalid admin user")
+	// ErrLastAdmin prevents disabling or demoting the only enabled admin.
+	ErrLastAdmin = errors.New("at least one enabled admin is required")
+	// ErrInvalidCredentials is returned for unknown emails, wrong passwords and
+	// disabled accounts alike.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// User is a stored admin account.
+type User struct {
+	ID           string `json:"id"`
+	Email        string `json:"email"`
+	PasswordHash string `json:"passwordHash"`
+	Role         Role   `json:"role"`
+	Disabled     bool   `json:"disabled"`
+	// PasswordChangedAt invalidates tokens issued before a password reset.
+	PasswordChangedAt time.Time `json:"passwordChangedAt"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+// File is the on-disk account format.
+type File struct {
+	Users []User `json:"users"`
+}
+
+// Store persists admin accounts to disk behind a per-path mutex.
+type Store struct {
+	path        string
+	mu          sync.Mutex
+	now         func() time.Time
+	cost        int
+	backups     filestore.Backups
+	lockTimeout time.Duration
+	// dummyHash is compared against for unknown emails so that lookups take as long as
+	// a wrong password.
+	dummyHash []byte
+}
+
+// StoreOption customises the store behaviour.
+type StoreOption func(*Store)
+
+// WithNow overrides the clock used for account timestamps.
+func WithNow(fn func() time.Time) StoreOption {
+	return func(s *Store) {
+		if fn != nil {
+			s.now = fn
+		}
+	}
+}
+
+// WithHashCost overrides the bcrypt cost (default bcrypt.DefaultCost).
+func WithHashCost(cost int) StoreOption {
+	return func(s *Store) {
+		if cost >= bcrypt.MinCost && cost <= bcrypt.MaxCost {
+			s.cost = cost
+		}
+	}
+}
+
+// WithBackups overrides where backups are written and how many are retained.
+func WithBackups(backups filestore.Backups) StoreOption {
+	return func(s *Store) {
+		s.backups = backups
+	}
+}
+
+// WithLockTimeout bounds how long writes wait for another process holding the file lock.
+func WithLockTimeout(timeout time.Duration) StoreOption {
+	return func(s *Store) {
+		s.lockTimeout = timeout
+	}
+}
+
+// NewStore returns a file-backed account store for the provided path.
+func NewStore(path string, opts ...StoreOption) *Store {
+	if path == "" {
+		path = DefaultFilePath
+	}
+	store := &Store{
+		path: filepath.Clean(path),
+		now:  time.Now,
+		cost: bcrypt.DefaultCost,
+	}
+	for _, opt := range opts {
+		opt(store)
+	}
+	store.dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not-a-real-password"), store.cost)
+	return store
+}
+
+// Path returns the path backing the store.
+func (s *Store) Path() string {
+	if s == nil {
+		return ""
+	}
+	return s.path
+}
+
+// List returns every account, including disabled ones.
+func (s *Store) List() ([]User, error) {
+	if s == nil {
+		return nil, errors.New("admin users store is nil")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := readFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	return file.Users, nil
+}
+
+// Get returns the account with the given ID.
+func (s *Store) Get(id string) (User, error) {
+	users, err := s.List()
+	if err != nil {
+		return User{}, err
+	}
+	for _, user := range users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return User{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+// Authenticate returns the enabled account matching email and password.
+func (s *Store) Authenticate(email, password string) (User, error) {
+	users, err := s.List()
+	if err != nil {
+		return User{}, err
+	}
+	email = normalizeEmail(email)
+	for _, user := range users {
+		if user.Email != email {
+			continue
+		}
+		if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil || user.Disabled {
+			return User{}, ErrInvalidCredentials
+		}
+		return user, nil
+	}
+	_ = bcrypt.CompareHashAndPassword(s.dummyHash, []byte(password))
+	return User{}, ErrInvalidCredentials
+}
+
+// Create adds an enabled account.
+func (s *Store) Create(email, password string, role Role) (User, error) {
+	if s == nil {
+		return User{}, errors.New("admin users store is nil")
+	}
+	email = normalizeEmail(email)
+	if _, err := mail.ParseAddress(email); err != nil || email == "" {
+		return User{}, fmt.Errorf("%w: invalid email %q", ErrInvalid, email)
+	}
+	if _, err := ParseRole(string(role)); err != nil {
+		return User{}, err
+	}
+	hash, err := s.hash(password)
+	if err != nil {
+		return User{}, err
+	}
+	now := s.now().UTC()
+	user := User{
+		ID:                generateID(),
+		Email:             email,
+		PasswordHash:      hash,
+		Role:              role,
+		PasswordChangedAt: now,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err = s.updateFileLocked(func(file *File) error {
+		for _, existing := range file.Users {
+			if existing.Email == email {
+				return fmt.Errorf("%w: %s", ErrDuplicateEmail, email)
+			}
+		}
+		file.Users = append(file.Users, user)
+		return nil
+	})
+	if err != nil {
+		return User{}, err
+	}
+	return user, nil
+}
+
+// SetRole changes an account's role.
+func (s *Store) SetRole(id string, role Role) (User, error) {
+	if _, err := ParseRole(string(role)); err != nil {
+		return User{}, err
+	}
+	return s.update(id, func(user *User) {
+		user.Role = role
+	})
+}
+
+// SetDisabled disables or re-enables an account. Disabled accounts cannot log in and
+// their tokens stop validating.
+func (s *Store) SetDisabled(id string, disabled bool) (User, error) {
+	return s.update(id, func(user *User) {
+		user.Disabled = disabled
+	})
+}
+
+// ResetPassword replaces an account's password and invalidates its existing tokens.
+func (s *Store) ResetPassword(id, password string) (User, error) {
+	hash, err := s.hash(password)
+	if err != nil {
+		return User{}, err
+	}
+	now := s.now().UTC()
+	return s.update(id, func(user *User) {
+		user.PasswordHash = hash
+		user.PasswordChangedAt = now
+	})
+}
+
+// update applies fn to the account and rejects the change when it would leave no
+// enabled admin.
+func (s *Store) update(id string, fn func(*User)) (User, error) {
+	if s == nil {
+		return User{}, errors.New("admin users store is nil")
+	}
+	now := s.now().UTC()
+	var updated User
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.updateFileLocked(func(file *File) error {
+		idx := -1
+		for i := range file.Users {
+			if file.Users[i].ID == id {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		user := file.Users[idx]
+		wasAdmin := user.Role == RoleAdmin && !user.Disabled
+		fn(&user)
+		if wasAdmin && (user.Role != RoleAdmin || user.Disabled) && countAdmins(file.Users) == 1 {
+			return ErrLastAdmin
+		}
+		user.UpdatedAt = now
+		file.Users[idx] = user
+		updated = user
+		return nil
+	})
+	if err != nil {
+		return User{}, err
+	}
+	return updated, nil
+}
+
+func countAdmins(users []User) int {
+	var count int
+	for _, user := range users {
+		if user.Role == RoleAdmin && !user.Disabled {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *Store) hash(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", fmt.Errorf("%w: password must be at least %d characters", ErrInvalid, MinPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), s.cost)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return string(hash), nil
+}
+
+// updateFileLocked runs a read-modify-write cycle. The caller holds s.mu, which serialises
+// goroutines; the advisory file lock extends that to other processes sharing the file.
+func (s *Store) updateFileLocked(updateFn func(*File) error) error {
+	lock, err := filestore.LockFile(s.path, s.lockTimeout)
+	if err != nil {
+		return fmt.Errorf("lock admin users file: %w", err)
+	}
+	defer lock.Unlock()
+
+	file, err := readFile(s.path)
+	if err != nil {
+		return err
+	}
+	if err := updateFn(&file); err != nil {
+		return err
+	}
+	data, err := writeFile(s.path, file)
+	if err != nil {
+		return err
+	}
+	// The data is already durable; a failed backup must not turn the write into an error.
+	_, _ = s.backups.Save(s.path, data)
+	return nil
+}
+
+// Recover checks that the accounts file decodes and, when it does not, restores the
+// newest backup that does. Read errors are returned without restoring anything. It
+// returns the restored backup path, or "" when the file was readable.
+func (s *Store) Recover() (string, error) {
+	if s == nil {
+		return "", errors.New("admin users store is nil")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, err := filestore.LockFile(s.path, s.lockTimeout)
+	if err != nil {
+		return "", fmt.Errorf("lock admin users file: %w", err)
+	}
+	defer lock.Unlock()
+	return s.backups.RecoverCorrupt(s.path, func(data []byte) error {
+		var file File
+		if err := json.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("decode admin users file: %w", err)
+		}
+		return nil
+	})
+}
+
+func readFile(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return File{Users: []User{}}, nil
+		}
+		return File{}, err
+	}
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return File{}, fmt.Errorf("decode admin users file: %w", err)
+	}
+	if file.Users == nil {
+		file.Users = []User{}
+	}
+	return file, nil
+}
+
+func writeFile(path string, file File) ([]byte, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create admin users dir: %w", err)
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode admin users file: %w", err)
+	}
+	// The file holds password hashes, so keep it private to the service account.
+	if err := filestore.WriteAtomic(path, data, 0o600); err != nil {
+		return nil, fmt.Errorf("write admin users file: %w", err)
+	}
+	return data, nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func generateID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("usr_%d", time.Now().UnixNano())
+	}
+	return "usr_" + hex.EncodeToString(buf)
+}
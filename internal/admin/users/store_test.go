@@ -0,0 +1,114 @@
+package users
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	return NewStore(filepath.Join(t.TempDir(), "admin_users.json"), WithHashCost(bcrypt.MinCost))
+}
+
+func TestStoreCreatesAndAuthenticatesUsers(t *testing.T) {
+	store := newTestStore(t)
+	admin, err := store.Create(" Admin@Example.com ", "correct horse", RoleAdmin)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if admin.Email != "admin@example.com" || !strings.HasPrefix(admin.ID, "usr_") || admin.PasswordHash == "correct horse" {
+		t.Fatalf("unexpected user %+v", admin)
+	}
+	if _, err := store.Create("admin@example.com", "another password", RoleViewer); !errors.Is(err, ErrDuplicateEmail) {
+		t.Fatalf("expected duplicate email error, got %v", err)
+	}
+	if _, err := store.Create("viewer@example.com", "short", RoleViewer); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected short password to be rejected, got %v", err)
+	}
+	if _, err := store.Create("viewer@example.com", "long enough", Role("owner")); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected unknown role to be rejected, got %v", err)
+	}
+
+	user, err := store.Authenticate("ADMIN@example.com", "correct horse")
+	if err != nil || user.ID != admin.ID {
+		t.Fatalf("expected login to succeed, got %+v, %v", user, err)
+	}
+	if _, err := store.Authenticate("admin@example.com", "wrong password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected wrong password to fail, got %v", err)
+	}
+	if _, err := store.Authenticate("nobody@example.com", "correct horse"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected unknown email to fail, got %v", err)
+	}
+
+	info, err := os.Stat(store.Path())
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("expected the accounts file to be private, got %v", perm)
+	}
+}
+
+func TestStoreDisablesResetsAndProtectsLastAdmin(t *testing.T) {
+	now := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+	store := NewStore(filepath.Join(t.TempDir(), "admin_users.json"), WithHashCost(bcrypt.MinCost), WithNow(func() time.Time { return now }))
+	admin, err := store.Create("admin@example.com", "correct horse", RoleAdmin)
+	if err != nil {
+		t.Fatalf("create admin: %v", err)
+	}
+	reviewer, err := store.Create("reviewer@example.com", "reviewer pass", RoleReviewer)
+	if err != nil {
+		t.Fatalf("create reviewer: %v", err)
+	}
+
+	if _, err := store.SetDisabled(admin.ID, true); !errors.Is(err, ErrLastAdmin) {
+		t.Fatalf("expected the last admin to stay enabled, got %v", err)
+	}
+	if _, err := store.SetRole(admin.ID, RoleViewer); !errors.Is(err, ErrLastAdmin) {
+		t.Fatalf("expected the last admin to keep its role, got %v", err)
+	}
+
+	if _, err := store.SetDisabled(reviewer.ID, true); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	if _, err := store.Authenticate("reviewer@example.com", "reviewer pass"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected disabled account to be rejected, got %v", err)
+	}
+	if _, err := store.SetDisabled(reviewer.ID, false); err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	updated, err := store.ResetPassword(reviewer.ID, "new reviewer pass")
+	if err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if !updated.PasswordChangedAt.Equal(now) {
+		t.Fatalf("expected password change time to be recorded, got %s", updated.PasswordChangedAt)
+	}
+	if _, err := store.Authenticate("reviewer@example.com", "reviewer pass"); err == nil {
+		t.Fatalf("expected the old password to stop working")
+	}
+	if _, err := store.Authenticate("reviewer@example.com", "new reviewer pass"); err != nil {
+		t.Fatalf("expected the new password to work: %v", err)
+	}
+
+	if _, err := store.SetRole("usr_missing", RoleAdmin); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestRoleAllows(t *testing.T) {
+	if !RoleAdmin.Allows(RoleReviewer) || !RoleReviewer.Allows(RoleViewer) || !RoleViewer.Allows(RoleViewer) {
+		t.Fatalf("expected higher roles to include lower ones")
+	}
+	if RoleViewer.Allows(RoleReviewer) || RoleReviewer.Allows(RoleAdmin) || Role("").Allows(RoleViewer) {
+		t.Fatalf("expected lower roles to be refused")
+	}
+}
@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"live-stream-alerts/internal/calendar"
+	"live-stream-alerts/internal/logging"
+	"live-stream-alerts/internal/sessions"
+	"live-stream-alerts/internal/streamers"
+)
+
+// calendarHistory is how far back past broadcasts are included in the feeds.
+const calendarHistory = 90 * 24 * time.Hour
+
+type calendarOptions struct {
+	Streamers streamers.Repository
+	Sessions  *sessions.Store
+	Logger    logging.Logger
+	Now       func() time.Time
+}
+
+// calendarHandler serves an iCalendar feed of scheduled, live, and past broadcasts. When the
+// route carries an {id} path value the feed is limited to that streamer.
+func calendarHandler(opts calendarOptions) http.Handler {
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		records, err := opts.Streamers.List()
+		if err != nil {
+			if opts.Logger != nil {
+				opts.Logger.Printf("calendar: failed to list streamers: %v", err)
+			}
+			http.Error(w, "failed to load streamers", http.StatusInternalServerError)
+			return
+		}
+
+		name := "Sharpen Live streams"
+		filename := "calendar.ics"
+		if id := r.PathValue("id"); id != "" {
+			record, ok := findStreamer(records, id)
+			if !ok {
+				http.Error(w, "streamer not found", http.StatusNotFound)
+				return
+			}
+			records = []streamers.Record{record}
+			name = record.Streamer.Alias + " streams"
+			filename = record.Streamer.ID + ".ics"
+		}
+
+		current := now().UTC()
+		var history []sessions.Session
+		if opts.Sessions != nil {
+			page, err := opts.Sessions.List(r.PathValue("id"), sessions.Query{From: current.Add(-calendarHistory)})
+			if err != nil && opts.Logger != nil {
+				opts.Logger.Printf("calendar: failed to read session history: %v", err)
+			}
+			history = page.Sessions
+		}
+		var buf bytes.Buffer
+		cal := calendar.Calendar{Name: name, Events: calendar.Events(records, history, current)}
+		if err := calendar.Encode(&buf, cal, current); err != nil {
+			http.Error(w, "failed to render calendar", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("Content-Disposition", `inline; filename="`+filename+`"`)
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		if r.Method == http.MethodHead {
+			return
+		}
+		_, _ = w.Write(buf.Bytes())
+	})
+}
+
+func findStreamer(records []streamers.Record, id string) (streamers.Record, bool) {
+	for _, record := range records {
+		if record.Streamer.ID == id {
+			return record, true
+		}
+	}
+	return streamers.Record{}, false
+}
@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"live-stream-alerts/internal/streamers"
+)
+
+func TestCalendarRoutesServeFeeds(t *testing.T) {
+	dir := t.TempDir()
+	store := streamers.NewStore(filepath.Join(dir, "streamers.json"))
+	start := time.Date(2025, 11, 21, 18, 0, 0, 0, time.UTC)
+	for _, alias := range []string{"Alpha", "Beta"} {
+		_, err := store.Append(streamers.Record{
+			Streamer:  streamers.Streamer{Alias: alias},
+			Platforms: streamers.Platforms{YouTube: &streamers.YouTubePlatform{ChannelID: "UC" + alias}},
+		})
+		if err != nil {
+			t.Fatalf("append: %v", err)
+		}
+		if _, err := store.SetYouTubeUpcoming("UC"+alias, streamers.ScheduledBroadcast{VideoID: "vid" + alias, Title: alias + " show", ScheduledStart: start}); err != nil {
+			t.Fatalf("set upcoming: %v", err)
+		}
+	}
+	records, _ := store.List()
+	router := NewRouter(Options{StreamersStore: store, YouTube: testYouTubeConfig()})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/calendar.ics", nil))
+	if rr.Code != http.StatusOK || !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/calendar") {
+		t.Fatalf("expected an iCalendar response, got %d %q", rr.Code, rr.Header().Get("Content-Type"))
+	}
+	if body := rr.Body.String(); strings.Count(body, "BEGIN:VEVENT") != 2 || !strings.Contains(body, "UID:youtube-vidAlpha@live-stream-alerts") {
+		t.Fatalf("expected both broadcasts in the global feed:\n%s", body)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/streamers/"+records[1].Streamer.ID+"/calendar.ics", nil))
+	if body := rr.Body.String(); rr.Code != http.StatusOK || strings.Count(body, "BEGIN:VEVENT") != 1 || !strings.Contains(body, "X-WR-CALNAME:Beta streams") {
+		t.Fatalf("expected only Beta's broadcast, got %d:\n%s", rr.Code, body)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/streamers/missing/calendar.ics", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown streamer, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,49 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"live-stream-alerts/internal/health"
+)
+
+// livenessHandler answers as long as the process can serve HTTP. It deliberately checks
+// nothing else so a broken dependency never gets the server restarted in a loop.
+func livenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeHealthJSON(w, r, http.StatusOK, map[string]health.Status{"status": health.StatusOK})
+	})
+}
+
+// readinessHandler runs every registered check and answers 503 when any of them failed.
+// Warnings are reported but keep the server in rotation.
+func readinessHandler(checker *health.Checker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		report := checker.Run(r.Context())
+		status := http.StatusOK
+		if report.Status == health.StatusFail {
+			status = http.StatusServiceUnavailable
+		}
+		writeHealthJSON(w, r, status, report)
+	})
+}
+
+func writeHealthJSON(w http.ResponseWriter, r *http.Request, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if r.Method == http.MethodHead {
+		return
+	}
+	_ = json.NewEncoder(w).Encode(body)
+}
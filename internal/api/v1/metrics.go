@@ -0,0 +1,20 @@
+package v1
+
+import (
+	"net/http"
+
+	"live-stream-alerts/internal/metrics"
+)
+
+// metricsHandler serves the Prometheus scrape endpoint.
+func metricsHandler(m *metrics.Metrics) http.Handler {
+	scrape := m.Handler()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		scrape.ServeHTTP(w, r)
+	})
+}
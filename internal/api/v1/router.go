@@ -1,26 +1,153 @@
 package v1
 
 import (
+	"context"
+	"errors"
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"live-stream-alerts/config"
+	adminauth "live-stream-alerts/internal/admin/auth"
+	adminhttp "live-stream-alerts/internal/admin/http"
+	adminservice "live-stream-alerts/internal/admin/service"
+	"live-stream-alerts/internal/admin/users"
+	"live-stream-alerts/internal/audit"
+	"live-stream-alerts/internal/clientip"
+	"live-stream-alerts/internal/health"
+	"live-stream-alerts/internal/jobs"
 	"live-stream-alerts/internal/logging"
+	"live-stream-alerts/internal/metrics"
+	"live-stream-alerts/internal/platforms"
+	fbapi "live-stream-alerts/internal/platforms/facebook/api"
+	fbhandlers "live-stream-alerts/internal/platforms/facebook/handlers"
+	fbservice "live-stream-alerts/internal/platforms/facebook/service"
+	twitchhandlers "live-stream-alerts/internal/platforms/twitch/handlers"
+	twitchservice "live-stream-alerts/internal/platforms/twitch/service"
 	youtubehandlers "live-stream-alerts/internal/platforms/youtube/handlers"
 	"live-stream-alerts/internal/platforms/youtube/liveinfo"
+	"live-stream-alerts/internal/platforms/youtube/monitoring"
+	youtubeprovider "live-stream-alerts/internal/platforms/youtube/provider"
+	youtubeservice "live-stream-alerts/internal/platforms/youtube/service"
+	"live-stream-alerts/internal/platforms/youtube/subscriptions"
+	"live-stream-alerts/internal/sessions"
 	"live-stream-alerts/internal/streamers"
+	streamershandlers "live-stream-alerts/internal/streamers/handlers"
+	streamersvc "live-stream-alerts/internal/streamers/service"
+	"live-stream-alerts/internal/submissions"
 )
 
 const rootPlaceholder = "Sharpen Live alerts service (API disabled).\n"
 
 // Options configures the HTTP router.
 type Options struct {
-	Logger             logging.Logger
-	StreamersPath      string
-	StreamersStore     *streamers.Store
-	YouTube            config.YouTubeConfig
+	Logger           logging.Logger
+	StreamersPath    string
+	StreamersStore   streamers.Repository
+	SubmissionsStore *submissions.Store
+	// SessionsStore backs /api/streamers/{id}/sessions and the calendar's past broadcasts.
+	SessionsStore *sessions.Store
+	YouTube       config.YouTubeConfig
+	YouTubeClient *http.Client
+	Server        config.ServerConfig
+	ReadTimeout   time.Duration
+	// Submissions limits public POST /api/streamers submissions. The zero value applies
+	// no limits.
+	Submissions        config.SubmissionsConfig
 	AlertNotifications youtubehandlers.AlertNotificationOptions
+	Twitch             config.TwitchConfig
+	TwitchEventSub     twitchhandlers.EventSubOptions
+	Facebook           config.FacebookConfig
+	FacebookWebhook    fbhandlers.WebhookOptions
+	// Notifier receives go-live alerts from the YouTube, Twitch and Facebook processors.
+	Notifier youtubeservice.LiveNotifier
+	// Platforms dispatches /alerts callbacks and submission onboarding. When nil, the
+	// router registers a YouTube provider built from YouTube and AlertNotifications.
+	Platforms *platforms.Registry
+
+	// AdminManager issues and validates admin bearer tokens. When nil, admin routes respond with 503.
+	AdminManager *adminauth.Manager
+	// AdminAuthorizer overrides the Manager-backed authorizer guarding /api/admin/*.
+	// Authorizers that also implement AdminRoleAuthorizer get per-route roles; others
+	// grant every route to any valid token.
+	AdminAuthorizer AdminAuthorizer
+	// AdminUsers backs /api/admin/users. When nil, the route answers 503.
+	AdminUsers *users.Store
+
+	// Optional service overrides; defaults are built from the stores and YouTube config above.
+	StreamersService streamershandlers.StreamerService
+	SubscribeProxy   SubscriptionProxy
+	UnsubscribeProxy SubscriptionProxy
+	ChannelResolver  ChannelResolver
+	MetadataFetcher  MetadataFetcher
+	AdminLogin       AdminLoginService
+	AdminSubmissions AdminSubmissionsService
+	AdminMonitor     AdminMonitorService
+	// StreamerEvents feeds /api/streamers/watch. It must be the bus attached to
+	// StreamersStore; when StreamersStore is nil the router creates both.
+	StreamerEvents *streamers.EventBus
+	WatchKeepAlive time.Duration
+	// WebSocketPingInterval controls /api/streamers/ws heartbeats (default 30s).
+	WebSocketPingInterval time.Duration
+	// Metrics backs /metrics and instruments /alerts. When nil, the router builds one
+	// from StreamersStore and the YouTube lease settings.
+	Metrics *metrics.Metrics
+	// RetryQueue receives YouTube notifications whose live lookup failed and backs
+	// /api/admin/jobs. The caller owns its workers; when nil, such notifications are
+	// acknowledged and dropped and the admin route answers 503.
+	RetryQueue *jobs.Queue
+	// Readiness backs /readyz. When nil, the router checks StreamersStore and the most
+	// recent YouTube hub verification.
+	Readiness *health.Checker
+	// Audit records mutating API calls and backs /api/admin/audit. When nil nothing is
+	// recorded and the admin route answers 503.
+	Audit *audit.Log
+}
+
+// AdminAuthorizer validates admin credentials attached to a request.
+type AdminAuthorizer interface {
+	AuthorizeRequest(*http.Request) error
+}
+
+// AdminRoleAuthorizer checks that the request's account has at least the given role,
+// returning adminservice.ErrForbidden when it does not.
+type AdminRoleAuthorizer interface {
+	AuthorizeRole(r *http.Request, role users.Role) error
+}
+
+// SubscriptionProxy forwards subscribe/unsubscribe requests to the YouTube hub.
+type SubscriptionProxy interface {
+	Process(ctx context.Context, req subscriptions.YouTubeRequest) (youtubeservice.SubscriptionResult, error)
+}
+
+// ChannelResolver converts YouTube handles into channel IDs.
+type ChannelResolver interface {
+	ResolveHandle(ctx context.Context, handle string) (string, error)
+}
+
+// MetadataFetcher scrapes metadata for public URLs.
+type MetadataFetcher interface {
+	Fetch(ctx context.Context, rawURL string) (youtubeservice.Metadata, error)
+}
+
+// AdminLoginService issues admin tokens.
+type AdminLoginService interface {
+	Login(email, password string) (adminauth.Token, error)
+}
+
+// AdminSubmissionsService lists, edits and decides pending submissions and lists past
+// decisions.
+type AdminSubmissionsService interface {
+	List(ctx context.Context) ([]submissions.Submission, error)
+	History(ctx context.Context, filter adminservice.HistoryFilter) ([]submissions.Submission, error)
+	Process(ctx context.Context, req adminservice.ActionRequest) (adminservice.ActionResult, error)
+	Edit(ctx context.Context, req adminservice.EditRequest) (submissions.Submission, error)
+}
+
+// AdminMonitorService summarises YouTube lease health.
+type AdminMonitorService interface {
+	Overview(ctx context.Context) (monitoring.Overview, error)
 }
 
 // NewRouter constructs the HTTP router for the public API.
@@ -31,9 +158,47 @@ func NewRouter(opts Options) http.Handler {
 	if streamersPath == "" {
 		streamersPath = streamers.DefaultFilePath
 	}
+	streamerEvents := opts.StreamerEvents
+	if streamerEvents == nil {
+		streamerEvents = streamers.NewEventBus(streamers.DefaultEventReplay)
+	}
 	streamersStore := opts.StreamersStore
 	if streamersStore == nil {
-		streamersStore = streamers.NewStore(streamersPath)
+		streamersStore = streamers.NewStore(streamersPath, streamers.WithEvents(streamerEvents))
+	}
+	submissionsStore := opts.SubmissionsStore
+	if submissionsStore == nil {
+		submissionsStore = submissions.NewStore(submissions.DefaultFilePath)
+	}
+	sessionsStore := opts.SessionsStore
+	if sessionsStore == nil {
+		sessionsStore = sessions.NewStore(sessions.DefaultFilePath)
+	}
+	leaseOverview := monitoring.NewService(monitoring.ServiceOptions{
+		StreamersStore:      streamersStore,
+		DefaultLeaseSeconds: opts.YouTube.LeaseSeconds,
+	})
+	appMetrics := opts.Metrics
+	if appMetrics == nil {
+		appMetrics = metrics.New(metrics.Options{
+			Overview:  leaseOverview,
+			Streamers: streamersStore,
+			Logger:    logger,
+		})
+	}
+	readiness := opts.Readiness
+	if readiness == nil {
+		readiness = health.NewChecker([]health.Check{
+			health.StoreCheck("streamers_store", streamersStore),
+			health.HubVerificationCheck("hub_verification", leaseOverview),
+		})
+	}
+	youtubeClient := opts.YouTubeClient
+	if youtubeClient == nil {
+		youtubeClient = &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: appMetrics.HubTransport(nil, opts.YouTube.HubURL),
+		}
 	}
 
 	alertsOpts := opts.AlertNotifications
@@ -44,13 +209,154 @@ func NewRouter(opts Options) http.Handler {
 		alertsOpts.StreamersStore = streamersStore
 	}
 	if alertsOpts.VideoLookup == nil {
-		alertsOpts.VideoLookup = &liveinfo.Client{Logger: logger}
+		alertsOpts.VideoLookup = &liveinfo.Client{Logger: logger, Observer: appMetrics}
+	}
+	if alertsOpts.Notifier == nil {
+		alertsOpts.Notifier = opts.Notifier
+	}
+	if alertsOpts.SignatureMode == "" {
+		alertsOpts.SignatureMode = opts.YouTube.SignatureMode
+	}
+	if alertsOpts.Retry == nil && opts.RetryQueue != nil {
+		alertsOpts.Retry = opts.RetryQueue
+	}
+
+	registry := opts.Platforms
+	if registry == nil {
+		registry = platforms.NewRegistry(youtubeprovider.New(youtubeprovider.Options{
+			Store:         streamersStore,
+			Client:        youtubeClient,
+			HubURL:        opts.YouTube.HubURL,
+			CallbackURL:   opts.YouTube.CallbackURL,
+			VerifyMode:    opts.YouTube.Verify,
+			LeaseSeconds:  opts.YouTube.LeaseSeconds,
+			Logger:        logger,
+			Notifications: alertsOpts,
+			Lookup:        alertsOpts.VideoLookup,
+		}))
 	}
 
-	alertsHandler := handleAlerts(alertsOpts)
+	alertsHandler := handleAlerts(registry, logger, appMetrics)
 	mux.Handle("/alerts", alertsHandler)
 	mux.Handle("/alert", alertsHandler)
 
+	twitchOpts := opts.TwitchEventSub
+	if twitchOpts.Logger == nil {
+		twitchOpts.Logger = logger
+	}
+	if twitchOpts.Secret == "" {
+		twitchOpts.Secret = opts.Twitch.EventSubSecret
+	}
+	if twitchOpts.Processor == nil {
+		twitchOpts.Processor = twitchservice.EventProcessor{Streamers: streamersStore, Logger: logger, Notifier: opts.Notifier}
+	}
+	mux.Handle("/alerts/twitch", twitchhandlers.NewEventSubHandler(twitchOpts))
+
+	facebookOpts := opts.FacebookWebhook
+	if facebookOpts.Logger == nil {
+		facebookOpts.Logger = logger
+	}
+	if facebookOpts.AppSecret == "" {
+		facebookOpts.AppSecret = opts.Facebook.AppSecret
+	}
+	if facebookOpts.VerifyToken == "" {
+		facebookOpts.VerifyToken = opts.Facebook.VerifyToken
+	}
+	if facebookOpts.Processor == nil {
+		facebookOpts.Processor = fbservice.WebhookProcessor{
+			Streamers: streamersStore,
+			Graph:     fbapi.NewGraphClient(fbapi.GraphClientOptions{BaseURL: opts.Facebook.GraphURL}),
+			Logger:    logger,
+			Notifier:  opts.Notifier,
+		}
+	}
+	mux.Handle("/alerts/facebook", fbhandlers.NewWebhookHandler(facebookOpts))
+
+	streamersService := opts.StreamersService
+	if streamersService == nil {
+		streamersService = streamersvc.New(streamersvc.Options{
+			Streamers:     streamersStore,
+			Submissions:   submissionsStore,
+			YouTubeClient: youtubeClient,
+			YouTubeHubURL: opts.YouTube.HubURL,
+			Audit:         auditRecorder(opts),
+			Limits:        submissionLimits(opts.Submissions),
+			Platforms:     registry,
+		})
+	}
+	mux.Handle("/api/streamers", guardStreamerWrites(adminAuthorizer(opts), streamershandlers.StreamersHandler(streamershandlers.StreamOptions{
+		Service: streamersService,
+		Logger:  logger,
+		Proxies: clientResolver(opts),
+	})))
+	mux.Handle("/api/admin/streamers", requireRole(adminAuthorizer(opts), users.RoleViewer, users.RoleViewer, streamershandlers.ListHandler(streamershandlers.StreamOptions{
+		Service:     streamersService,
+		Logger:      logger,
+		FullRecords: true,
+	})))
+	mux.Handle("/api/streamers/watch", streamersWatchHandler(streamersWatchOptions{
+		Events:    streamerEvents,
+		Logger:    logger,
+		KeepAlive: opts.WatchKeepAlive,
+	}))
+	mux.Handle("/api/streamers/ws", streamersWSHandler(streamersWSOptions{
+		Events:         streamerEvents,
+		Logger:         logger,
+		PingInterval:   opts.WebSocketPingInterval,
+		AllowedOrigins: opts.Server.AllowedOrigins,
+	}))
+
+	subscriptionOpts := youtubehandlers.SubscriptionHandlerOptions{
+		Client:       youtubeClient,
+		Logger:       logger,
+		HubURL:       opts.YouTube.HubURL,
+		CallbackURL:  opts.YouTube.CallbackURL,
+		VerifyMode:   opts.YouTube.Verify,
+		LeaseSeconds: opts.YouTube.LeaseSeconds,
+		Audit:        auditRecorder(opts),
+	}
+	subscribeOpts := subscriptionOpts
+	if opts.SubscribeProxy != nil {
+		subscribeOpts.Proxy = opts.SubscribeProxy
+	}
+	unsubscribeOpts := subscriptionOpts
+	if opts.UnsubscribeProxy != nil {
+		unsubscribeOpts.Proxy = opts.UnsubscribeProxy
+	}
+	// Subscriptions point the hub at arbitrary topics and secrets, so only admins may
+	// manage them by hand.
+	mux.Handle("/api/youtube/subscribe", requireRole(adminAuthorizer(opts), users.RoleAdmin, users.RoleAdmin, youtubehandlers.NewSubscribeHandler(subscribeOpts)))
+	mux.Handle("/api/youtube/unsubscribe", requireRole(adminAuthorizer(opts), users.RoleAdmin, users.RoleAdmin, youtubehandlers.NewUnsubscribeHandler(unsubscribeOpts)))
+
+	channelOpts := youtubehandlers.ChannelLookupHandlerOptions{Client: youtubeClient, Logger: logger}
+	if opts.ChannelResolver != nil {
+		channelOpts.Resolver = opts.ChannelResolver
+	}
+	mux.Handle("/api/youtube/channel", youtubehandlers.NewChannelLookupHandler(channelOpts))
+
+	metadataOpts := youtubehandlers.MetadataHandlerOptions{Client: youtubeClient, Logger: logger}
+	if opts.MetadataFetcher != nil {
+		metadataOpts.Fetcher = opts.MetadataFetcher
+	}
+	mux.Handle("/api/youtube/metadata", youtubehandlers.NewMetadataHandler(metadataOpts))
+
+	mux.Handle("/api/schedule", scheduleHandler(scheduleOptions{Streamers: streamersStore, Logger: logger}))
+	calendarFeed := calendarHandler(calendarOptions{Streamers: streamersStore, Sessions: sessionsStore, Logger: logger})
+	mux.Handle("/api/calendar.ics", calendarFeed)
+	mux.Handle("/api/streamers/{id}/calendar.ics", calendarFeed)
+	mux.Handle("/api/streamers/{id}/sessions", sessionsHandler(sessionsOptions{
+		Sessions:  sessionsStore,
+		Streamers: streamersStore,
+		Logger:    logger,
+	}))
+
+	mux.Handle("/metrics", metricsHandler(appMetrics))
+	mux.Handle("/healthz", livenessHandler())
+	mux.Handle("/readyz", readinessHandler(readiness))
+	mux.Handle("/api/server/config", serverConfigHandler(opts.Server, opts.ReadTimeout, opts.Submissions))
+
+	registerAdminRoutes(mux, opts, streamersStore, submissionsStore, youtubeClient, registry)
+
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/" {
 			http.NotFound(w, r)
@@ -61,52 +367,260 @@ func NewRouter(opts Options) http.Handler {
 		_, _ = io.WriteString(w, rootPlaceholder)
 	})
 
-	return logging.WithHTTPLogging(mux, logger)
+	// Probes and scrapes arrive every few seconds; dumping each of them would drown the
+	// request log.
+	audited := withAuditActor(opts, mux)
+	logged := logging.WithHTTPLogging(audited, logger)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/healthz", "/readyz", "/metrics":
+			mux.ServeHTTP(w, r)
+		default:
+			logged.ServeHTTP(w, r)
+		}
+	})
+}
+
+func adminAuthorizer(opts Options) AdminAuthorizer {
+	if opts.AdminAuthorizer != nil {
+		return opts.AdminAuthorizer
+	}
+	if opts.AdminManager != nil {
+		return adminservice.AuthService{Manager: opts.AdminManager}
+	}
+	return nil
 }
 
-// handleAlerts returns an HTTP handler that only treats likely Google/YouTube
-// requests as WebSub subscription confirmations/notifications.
-func handleAlerts(notificationOpts youtubehandlers.AlertNotificationOptions) http.Handler {
+// auditRecorder returns opts.Audit as a Recorder, or nil so that services skip building
+// snapshots when auditing is off.
+func auditRecorder(opts Options) audit.Recorder {
+	if opts.Audit == nil {
+		return nil
+	}
+	return opts.Audit
+}
+
+// adminIdentifier is implemented by authorizers that can name the account behind a token.
+type adminIdentifier interface {
+	Identify(r *http.Request) (adminauth.Identity, error)
+}
+
+// withAuditActor stores who is making each mutating request in its context for the audit
+// log: the client address and, when the request carries a valid admin token, the account.
+func withAuditActor(opts Options, next http.Handler) http.Handler {
+	if opts.Audit == nil {
+		return next
+	}
+	proxies := clientResolver(opts)
+	identifier, _ := adminAuthorizer(opts).(adminIdentifier)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		actor := audit.Actor{RemoteAddr: proxies.IP(r)}
+		if identifier != nil {
+			if identity, err := identifier.Identify(r); err == nil {
+				actor.UserID = identity.UserID
+				actor.Email = identity.Email
+				actor.Role = string(identity.Role)
+			}
+		}
+		next.ServeHTTP(w, r.WithContext(audit.WithActor(r.Context(), actor)))
+	})
+}
+
+// submissionLimits converts the submissions config block, where negative values disable a
+// limit, into the service's limits, where zero does.
+func submissionLimits(cfg config.SubmissionsConfig) streamersvc.SubmissionLimits {
+	return streamersvc.SubmissionLimits{
+		MaxPerIP:        max(cfg.MaxPerIP, 0),
+		Window:          time.Duration(cfg.WindowSeconds) * time.Second,
+		MaxPending:      max(cfg.MaxPending, 0),
+		ProofOfWorkBits: max(cfg.ProofOfWorkBits, 0),
+	}
+}
+
+// clientResolver builds the client-address resolver from server.trusted_proxies. config.Load
+// already rejects invalid entries, so an error here only comes from hand-built Options.
+func clientResolver(opts Options) *clientip.Resolver {
+	resolver, err := clientip.NewResolver(opts.Server.TrustedProxies)
+	if err != nil {
+		if opts.Logger != nil {
+			opts.Logger.Printf("ignoring server.trusted_proxies: %v", err)
+		}
+		return nil
+	}
+	return resolver
+}
+
+func registerAdminRoutes(mux *http.ServeMux, opts Options, streamersStore streamers.Repository, submissionsStore *submissions.Store, youtubeClient *http.Client, registry *platforms.Registry) {
+	authz := adminAuthorizer(opts)
+
+	loginOpts := adminhttp.LoginHandlerOptions{Manager: opts.AdminManager, Proxies: clientResolver(opts), Logger: opts.Logger}
+	if opts.AdminLogin != nil {
+		loginOpts.Service = opts.AdminLogin
+	}
+	mux.Handle("/api/admin/login", adminhttp.NewLoginHandler(loginOpts))
+
+	// Session routes resolve the caller's own session, so they always use the Manager.
+	sessionsOpts := adminhttp.SessionsHandlerOptions{Manager: opts.AdminManager, Logger: opts.Logger, Audit: auditRecorder(opts)}
+	mux.Handle("/api/admin/logout", adminhttp.NewLogoutHandler(sessionsOpts))
+	mux.Handle("/api/admin/refresh", adminhttp.NewRefreshHandler(sessionsOpts))
+	mux.Handle("/api/admin/sessions", adminhttp.NewSessionsHandler(sessionsOpts))
+
+	submissionsOpts := adminhttp.SubmissionsHandlerOptions{
+		Authorizer:       authz,
+		SubmissionsStore: submissionsStore,
+		StreamersStore:   streamersStore,
+		YouTubeClient:    youtubeClient,
+		Logger:           opts.Logger,
+		YouTube:          opts.YouTube,
+		Platforms:        registry,
+		Audit:            auditRecorder(opts),
+	}
+	if opts.AdminSubmissions != nil {
+		submissionsOpts.Service = opts.AdminSubmissions
+	}
+	mux.Handle("/api/admin/submissions", requireRole(authz, users.RoleViewer, users.RoleReviewer, adminhttp.NewSubmissionsHandler(submissionsOpts)))
+	mux.Handle("/api/admin/submissions/history", requireRole(authz, users.RoleViewer, users.RoleViewer, adminhttp.NewSubmissionsHistoryHandler(submissionsOpts)))
+
+	monitorOpts := adminhttp.MonitorHandlerOptions{
+		Authorizer:     authz,
+		Logger:         opts.Logger,
+		StreamersStore: streamersStore,
+		YouTube:        opts.YouTube,
+	}
+	if opts.AdminMonitor != nil {
+		monitorOpts.Service = opts.AdminMonitor
+	}
+	mux.Handle("/api/admin/monitor/youtube", requireRole(authz, users.RoleViewer, users.RoleViewer, adminhttp.NewMonitorHandler(monitorOpts)))
+
+	jobsOpts := adminhttp.JobsHandlerOptions{Authorizer: authz, Logger: opts.Logger, Audit: auditRecorder(opts)}
+	if opts.RetryQueue != nil {
+		jobsOpts.Queue = opts.RetryQueue
+	}
+	mux.Handle("/api/admin/jobs", requireRole(authz, users.RoleViewer, users.RoleAdmin, adminhttp.NewJobsHandler(jobsOpts)))
+
+	usersOpts := adminhttp.UsersHandlerOptions{Authorizer: authz, Logger: opts.Logger, Audit: auditRecorder(opts)}
+	if opts.AdminUsers != nil {
+		usersOpts.Store = opts.AdminUsers
+	}
+	mux.Handle("/api/admin/users", requireRole(authz, users.RoleAdmin, users.RoleAdmin, adminhttp.NewUsersHandler(usersOpts)))
+
+	auditOpts := adminhttp.AuditHandlerOptions{Authorizer: authz, Logger: opts.Logger}
+	if opts.Audit != nil {
+		auditOpts.Log = opts.Audit
+	}
+	mux.Handle("/api/admin/audit", requireRole(authz, users.RoleAdmin, users.RoleAdmin, adminhttp.NewAuditHandler(auditOpts)))
+}
+
+// requireRole rejects requests that do not carry a valid admin bearer token before
+// they reach the wrapped handler. GET and HEAD need the read role, every other method
+// the write role.
+func requireRole(authz AdminAuthorizer, read, write users.Role, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		role := write
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			role = read
+		}
+		if authorizeRole(w, r, authz, role) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// guardStreamerWrites leaves listing and public submissions on /api/streamers open but
+// requires a reviewer to edit streamers and an admin to delete them.
+func guardStreamerWrites(authz AdminAuthorizer, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPatch:
+			if !authorizeRole(w, r, authz, users.RoleReviewer) {
+				return
+			}
+		case http.MethodDelete:
+			if !authorizeRole(w, r, authz, users.RoleAdmin) {
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authorizeRole answers 503 when admin auth is not configured, 401 for a missing or
+// invalid token and 403 when the account's role is too low. It reports whether the
+// request may proceed.
+func authorizeRole(w http.ResponseWriter, r *http.Request, authz AdminAuthorizer, role users.Role) bool {
+	if authz == nil {
+		http.Error(w, "admin auth disabled", http.StatusServiceUnavailable)
+		return false
+	}
+	var err error
+	if roles, ok := authz.(AdminRoleAuthorizer); ok {
+		err = roles.AuthorizeRole(r, role)
+	} else {
+		err = authz.AuthorizeRequest(r)
+	}
+	switch {
+	case errors.Is(err, adminservice.ErrForbidden):
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return false
+	case err != nil:
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// notificationObserver counts push notifications by platform and response status.
+type notificationObserver interface {
+	ObserveNotification(platform string, status int)
+}
+
+// handleAlerts returns an HTTP handler that hands /alerts requests to the registered
+// provider that claims them and rejects everything else. POSTs are reported to observer.
+func handleAlerts(registry *platforms.Registry, logger logging.Logger, observer notificationObserver) http.Handler {
 	allowedMethods := strings.Join([]string{http.MethodGet, http.MethodPost}, ", ")
-	logger := notificationOpts.Logger
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/alerts" && r.URL.Path != "/alert" {
 			http.NotFound(w, r)
 			return
 		}
 
-		userAgent := r.Header.Get("User-Agent")
-		from := r.Header.Get("From")
-		forwardedFor := r.Header.Get("X-Forwarded-For")
-		platform := alertPlatform(userAgent, from)
+		provider := registry.ForCallback(r)
+		var platform string
+		if provider != nil {
+			platform = provider.Name()
+		}
 
 		switch r.Method {
 		case http.MethodGet:
-			if platform == "youtube" {
-				if youtubehandlers.HandleSubscriptionConfirmation(w, r, youtubehandlers.SubscriptionConfirmationOptions{
-					Logger:         logger,
-					StreamersStore: notificationOpts.StreamersStore,
-				}) {
+			if provider != nil {
+				if provider.VerifyCallback(w, r) {
 					return
 				}
 				http.Error(w, "invalid subscription confirmation", http.StatusBadRequest)
 				return
 			}
-			if logger != nil {
-				logger.Printf("suspicious /alerts GET request: platform=%q ua=%q from=%q xff=%q", platform, userAgent, from, forwardedFor)
-			}
+			logSuspiciousAlert(logger, r, platform)
 			w.Header().Set("Allow", allowedMethods)
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		case http.MethodPost:
-			if platform != "youtube" {
-				if logger != nil {
-					logger.Printf("suspicious /alerts POST request: platform=%q ua=%q from=%q xff=%q", platform, userAgent, from, forwardedFor)
-				}
+			if observer != nil {
+				sw := &statusWriter{ResponseWriter: w}
+				defer func() { observer.ObserveNotification(platform, sw.Status()) }()
+				w = sw
+			}
+			if provider == nil {
+				logSuspiciousAlert(logger, r, platform)
 				w.Header().Set("Allow", allowedMethods)
 				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 				return
 			}
-			if youtubehandlers.HandleAlertNotification(w, r, notificationOpts) {
+			if provider.HandleNotification(w, r) {
 				return
 			}
 			http.Error(w, "failed to process notification", http.StatusInternalServerError)
@@ -117,9 +631,37 @@ func handleAlerts(notificationOpts youtubehandlers.AlertNotificationOptions) htt
 	})
 }
 
-func alertPlatform(userAgent, from string) string {
-	if strings.HasPrefix(userAgent, "FeedFetcher-Google") && from == "googlebot(at)googlebot.com" {
-		return "youtube"
+// statusWriter remembers the status code written by the wrapped handler.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(code int) {
+	if sw.status == 0 {
+		sw.status = code
+	}
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	if sw.status == 0 {
+		sw.status = http.StatusOK
+	}
+	return sw.ResponseWriter.Write(b)
+}
+
+// Status returns the response status, treating an untouched response as 200.
+func (sw *statusWriter) Status() int {
+	if sw.status == 0 {
+		return http.StatusOK
+	}
+	return sw.status
+}
+
+func logSuspiciousAlert(logger logging.Logger, r *http.Request, platform string) {
+	if logger == nil {
+		return
 	}
-	return ""
+	logger.Printf("suspicious /alerts %s request: platform=%q ua=%q from=%q xff=%q", r.Method, platform, r.Header.Get("User-Agent"), r.Header.Get("From"), r.Header.Get("X-Forwarded-For"))
 }
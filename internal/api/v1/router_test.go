@@ -2,6 +2,7 @@ package v1
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"live-stream-alerts/config"
+	"live-stream-alerts/internal/health"
 	youtubehandlers "live-stream-alerts/internal/platforms/youtube/handlers"
 	"live-stream-alerts/internal/platforms/youtube/liveinfo"
 	"live-stream-alerts/internal/platforms/youtube/websub"
@@ -224,3 +226,80 @@ type noopVideoLookup struct{}
 func (noopVideoLookup) Fetch(ctx context.Context, videoIDs []string) (map[string]liveinfo.VideoInfo, error) {
 	return map[string]liveinfo.VideoInfo{}, nil
 }
+
+func TestMetricsRouteCountsAlerts(t *testing.T) {
+	tmp := t.TempDir()
+	streamersPath := filepath.Join(tmp, "streamers.json")
+	if err := os.WriteFile(streamersPath, []byte(`{"$schema":"","streamers":[]}`), 0o644); err != nil {
+		t.Fatalf("write streamers file: %v", err)
+	}
+	router := NewRouter(Options{
+		StreamersPath: streamersPath,
+		AlertNotifications: youtubehandlers.AlertNotificationOptions{
+			VideoLookup: noopVideoLookup{},
+		},
+		YouTube: testYouTubeConfig(),
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/alerts", strings.NewReader("not xml")))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	body := rr.Body.String()
+	for _, want := range []string{
+		`live_stream_alerts_websub_notifications_received_total{platform="unknown"} 1`,
+		`live_stream_alerts_websub_notifications_rejected_total{platform="unknown",reason="unknown_sender"} 1`,
+		`live_stream_alerts_live_streamers 0`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in metrics output:\n%s", want, body)
+		}
+	}
+}
+
+func TestHealthRoutes(t *testing.T) {
+	failing := false
+	checker := health.NewChecker([]health.Check{{Name: "store", Run: func(context.Context) health.Result {
+		if failing {
+			return health.Result{Status: health.StatusFail, Detail: "unreadable"}
+		}
+		return health.Result{Status: health.StatusOK}
+	}}})
+	router := NewRouter(Options{
+		StreamersPath: filepath.Join(t.TempDir(), "streamers.json"),
+		YouTube:       testYouTubeConfig(),
+		Readiness:     checker,
+	})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected /healthz 200, got %d", rr.Code)
+	}
+
+	for _, tc := range []struct {
+		failing bool
+		code    int
+		status  health.Status
+	}{
+		{failing: false, code: http.StatusOK, status: health.StatusOK},
+		{failing: true, code: http.StatusServiceUnavailable, status: health.StatusFail},
+	} {
+		failing = tc.failing
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if rr.Code != tc.code {
+			t.Fatalf("expected /readyz %d, got %d", tc.code, rr.Code)
+		}
+		var report health.Report
+		if err := json.Unmarshal(rr.Body.Bytes(), &report); err != nil {
+			t.Fatalf("decode report: %v", err)
+		}
+		if report.Status != tc.status || len(report.Checks) != 1 || report.Checks[0].Name != "store" {
+			t.Fatalf("unexpected report %+v", report)
+		}
+	}
+}
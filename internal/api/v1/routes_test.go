@@ -0,0 +1,212 @@
+package v1
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	adminauth "live-stream-alerts/internal/admin/auth"
+	"live-stream-alerts/internal/admin/users"
+	youtubehandlers "live-stream-alerts/internal/platforms/youtube/handlers"
+	"live-stream-alerts/internal/streamers"
+	"live-stream-alerts/internal/submissions"
+)
+
+type documentedRoute struct {
+	Method string
+	Path   string
+}
+
+var readmeRoutePattern = regexp.MustCompile("^\\|\\s*([A-Z]+)\\s*\\|\\s*`([^`]+)`")
+
+// readmeRoutes parses the API reference table in the repository README.
+func readmeRoutes(t *testing.T) []documentedRoute {
+	t.Helper()
+	file, err := os.Open(filepath.Join("..", "..", "..", "README.md"))
+	if err != nil {
+		t.Fatalf("open README: %v", err)
+	}
+	defer file.Close()
+
+	var routes []documentedRoute
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		match := readmeRoutePattern.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if match == nil {
+			continue
+		}
+		routes = append(routes, documentedRoute{Method: match[1], Path: match[2]})
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("scan README: %v", err)
+	}
+	if len(routes) == 0 {
+		t.Fatalf("expected README to document at least one route")
+	}
+	return routes
+}
+
+// documentedStreamerID fills the {id} placeholder of documented per-streamer routes.
+const documentedStreamerID = "demo"
+
+func TestNewRouterServesEveryDocumentedRoute(t *testing.T) {
+	dir := t.TempDir()
+	streamersPath := filepath.Join(dir, "streamers.json")
+	if _, err := streamers.NewStore(streamersPath).Append(streamers.Record{Streamer: streamers.Streamer{ID: documentedStreamerID, Alias: "Demo"}}); err != nil {
+		t.Fatalf("seed streamer: %v", err)
+	}
+	router := NewRouter(Options{
+		StreamersPath:    streamersPath,
+		SubmissionsStore: submissions.NewStore(filepath.Join(dir, "submissions.json")),
+		YouTube:          testYouTubeConfig(),
+		AdminManager:     adminauth.NewManager(adminauth.Config{Email: "admin@example.com", Password: "secret"}),
+		AlertNotifications: youtubehandlers.AlertNotificationOptions{
+			VideoLookup: noopVideoLookup{},
+		},
+	})
+
+	for _, route := range readmeRoutes(t) {
+		t.Run(route.Method+" "+route.Path, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			if strings.HasSuffix(route.Path, "/watch") {
+				// Streaming endpoints return once the client disconnects.
+				cancel()
+			} else {
+				defer cancel()
+			}
+			path := strings.ReplaceAll(route.Path, "{id}", documentedStreamerID)
+			req := httptest.NewRequest(route.Method, path, strings.NewReader("{}")).WithContext(ctx)
+			if strings.HasPrefix(route.Path, "/alert") {
+				req.Header.Set("User-Agent", "FeedFetcher-Google")
+				req.Header.Set("From", "googlebot(at)googlebot.com")
+			}
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			if rr.Code == http.StatusNotFound || rr.Code == http.StatusMethodNotAllowed {
+				t.Fatalf("documented route responded with %d: %s", rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestAdminRoutesRequireToken(t *testing.T) {
+	dir := t.TempDir()
+	manager := adminauth.NewManager(adminauth.Config{Email: "admin@example.com", Password: "secret"})
+	router := NewRouter(Options{
+		StreamersPath:    filepath.Join(dir, "streamers.json"),
+		SubmissionsStore: submissions.NewStore(filepath.Join(dir, "submissions.json")),
+		YouTube:          testYouTubeConfig(),
+		AdminManager:     manager,
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/submissions", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", rr.Code)
+	}
+
+	token, err := manager.Login("admin@example.com", "secret")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/submissions", nil)
+	req.Header.Set("Authorization", "Bearer "+token.Value)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 with token, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestAdminRoutesEnforceRoles(t *testing.T) {
+	dir := t.TempDir()
+	accounts := users.NewStore(filepath.Join(dir, "admin_users.json"), users.WithHashCost(bcrypt.MinCost))
+	manager := adminauth.NewManager(adminauth.Config{Users: accounts})
+	tokens := make(map[users.Role]string)
+	for _, role := range []users.Role{users.RoleViewer, users.RoleReviewer, users.RoleAdmin} {
+		email := string(role) + "@example.com"
+		if _, err := accounts.Create(email, "password-"+string(role), role); err != nil {
+			t.Fatalf("create %s: %v", role, err)
+		}
+		token, err := manager.Login(email, "password-"+string(role))
+		if err != nil {
+			t.Fatalf("login %s: %v", role, err)
+		}
+		tokens[role] = token.Value
+	}
+	router := NewRouter(Options{
+		StreamersPath:    filepath.Join(dir, "streamers.json"),
+		SubmissionsStore: submissions.NewStore(filepath.Join(dir, "submissions.json")),
+		YouTube:          testYouTubeConfig(),
+		AdminManager:     manager,
+		AdminUsers:       accounts,
+	})
+
+	cases := []struct {
+		role      users.Role
+		method    string
+		path      string
+		forbidden bool
+	}{
+		{role: users.RoleViewer, method: http.MethodGet, path: "/api/admin/submissions"},
+		{role: users.RoleViewer, method: http.MethodGet, path: "/api/admin/streamers"},
+		{role: users.RoleViewer, method: http.MethodPost, path: "/api/admin/submissions", forbidden: true},
+		{role: users.RoleReviewer, method: http.MethodPost, path: "/api/admin/submissions"},
+		{role: users.RoleViewer, method: http.MethodPatch, path: "/api/streamers", forbidden: true},
+		{role: users.RoleReviewer, method: http.MethodPatch, path: "/api/streamers"},
+		{role: users.RoleReviewer, method: http.MethodDelete, path: "/api/streamers", forbidden: true},
+		{role: users.RoleAdmin, method: http.MethodDelete, path: "/api/streamers"},
+		{role: users.RoleReviewer, method: http.MethodPost, path: "/api/admin/jobs", forbidden: true},
+		{role: users.RoleReviewer, method: http.MethodGet, path: "/api/admin/users", forbidden: true},
+		{role: users.RoleAdmin, method: http.MethodGet, path: "/api/admin/users"},
+		{role: users.RoleReviewer, method: http.MethodPost, path: "/api/youtube/subscribe", forbidden: true},
+		{role: users.RoleReviewer, method: http.MethodPost, path: "/api/youtube/unsubscribe", forbidden: true},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader("{}"))
+		req.Header.Set("Authorization", "Bearer "+tokens[tc.role])
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if forbidden := rr.Code == http.StatusForbidden; forbidden != tc.forbidden || rr.Code == http.StatusUnauthorized {
+			t.Fatalf("%s %s as %s: got %d: %s", tc.method, tc.path, tc.role, rr.Code, rr.Body.String())
+		}
+	}
+
+	for _, route := range []documentedRoute{
+		{Method: http.MethodPatch, Path: "/api/streamers"},
+		{Method: http.MethodDelete, Path: "/api/streamers"},
+		{Method: http.MethodGet, Path: "/api/admin/streamers"},
+		{Method: http.MethodPost, Path: "/api/youtube/subscribe"},
+		{Method: http.MethodPost, Path: "/api/youtube/unsubscribe"},
+	} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(route.Method, route.Path, strings.NewReader("{}")))
+		if rr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected %s %s without a token to be rejected, got %d", route.Method, route.Path, rr.Code)
+		}
+	}
+}
+
+func TestAdminRoutesDisabledWithoutManager(t *testing.T) {
+	router := NewRouter(Options{
+		StreamersPath: filepath.Join(t.TempDir(), "streamers.json"),
+		YouTube:       testYouTubeConfig(),
+	})
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/monitor/youtube", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without admin manager, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,80 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"live-stream-alerts/internal/logging"
+	"live-stream-alerts/internal/streamers"
+)
+
+type scheduleOptions struct {
+	Streamers streamers.Repository
+	Logger    logging.Logger
+}
+
+type scheduleResponse struct {
+	Broadcasts []scheduledBroadcast `json:"broadcasts"`
+}
+
+type scheduledBroadcast struct {
+	StreamerID     string    `json:"streamerId"`
+	Alias          string    `json:"alias"`
+	Platform       string    `json:"platform"`
+	VideoID        string    `json:"videoId"`
+	Title          string    `json:"title,omitempty"`
+	URL            string    `json:"url,omitempty"`
+	ScheduledStart time.Time `json:"scheduledStart"`
+}
+
+// scheduleHandler lists upcoming broadcasts across all streamers, soonest first. The
+// optional streamer (ID or alias) and platform query parameters narrow the list.
+func scheduleHandler(opts scheduleOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		records, err := opts.Streamers.List()
+		if err != nil {
+			if opts.Logger != nil {
+				opts.Logger.Printf("schedule: failed to list streamers: %v", err)
+			}
+			http.Error(w, "failed to load schedule", http.StatusInternalServerError)
+			return
+		}
+
+		streamerFilter := strings.TrimSpace(r.URL.Query().Get("streamer"))
+		platformFilter := strings.TrimSpace(r.URL.Query().Get("platform"))
+		resp := scheduleResponse{Broadcasts: []scheduledBroadcast{}}
+		for _, record := range records {
+			if streamerFilter != "" && record.Streamer.ID != streamerFilter && !strings.EqualFold(record.Streamer.Alias, streamerFilter) {
+				continue
+			}
+			for _, broadcast := range record.Schedule {
+				if platformFilter != "" && !strings.EqualFold(broadcast.Platform, platformFilter) {
+					continue
+				}
+				resp.Broadcasts = append(resp.Broadcasts, scheduledBroadcast{
+					StreamerID:     record.Streamer.ID,
+					Alias:          record.Streamer.Alias,
+					Platform:       broadcast.Platform,
+					VideoID:        broadcast.VideoID,
+					Title:          broadcast.Title,
+					URL:            broadcast.URL,
+					ScheduledStart: broadcast.ScheduledStart,
+				})
+			}
+		}
+		sort.SliceStable(resp.Broadcasts, func(i, j int) bool {
+			return resp.Broadcasts[i].ScheduledStart.Before(resp.Broadcasts[j].ScheduledStart)
+		})
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
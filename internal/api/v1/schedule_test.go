@@ -0,0 +1,61 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"live-stream-alerts/internal/streamers"
+)
+
+func TestScheduleHandlerListsUpcomingBroadcasts(t *testing.T) {
+	store := streamers.NewStore(filepath.Join(t.TempDir(), "streamers.json"))
+	start := time.Date(2025, 11, 21, 18, 0, 0, 0, time.UTC)
+	for _, alias := range []string{"Later", "Sooner"} {
+		if _, err := store.Append(streamers.Record{
+			Streamer:  streamers.Streamer{Alias: alias},
+			Platforms: streamers.Platforms{YouTube: &streamers.YouTubePlatform{ChannelID: "UC" + alias}},
+		}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	if _, err := store.SetYouTubeUpcoming("UCLater", streamers.ScheduledBroadcast{VideoID: "v2", Title: "Late show", ScheduledStart: start.Add(time.Hour)}); err != nil {
+		t.Fatalf("set upcoming: %v", err)
+	}
+	if _, err := store.SetYouTubeUpcoming("UCSooner", streamers.ScheduledBroadcast{VideoID: "v1", Title: "Early show", ScheduledStart: start}); err != nil {
+		t.Fatalf("set upcoming: %v", err)
+	}
+	handler := scheduleHandler(scheduleOptions{Streamers: store})
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/schedule", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	var resp scheduleResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Broadcasts) != 2 || resp.Broadcasts[0].VideoID != "v1" || resp.Broadcasts[0].Alias != "Sooner" || resp.Broadcasts[1].VideoID != "v2" {
+		t.Fatalf("expected broadcasts soonest first, got %+v", resp.Broadcasts)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/schedule?streamer=later", nil))
+	resp = scheduleResponse{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Broadcasts) != 1 || resp.Broadcasts[0].VideoID != "v2" {
+		t.Fatalf("expected the streamer filter to apply, got %+v", resp.Broadcasts)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/schedule", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,40 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"live-stream-alerts/config"
+)
+
+const serviceName = "live-stream-alerts"
+
+type serverConfigResponse struct {
+	Name        string `json:"name"`
+	Addr        string `json:"addr"`
+	Port        string `json:"port"`
+	ReadTimeout string `json:"readTimeout"`
+	// SubmissionProofOfWorkBits tells the UI how hard a proof of work POST /api/streamers
+	// needs; it is omitted when none is required.
+	SubmissionProofOfWorkBits int `json:"submissionProofOfWorkBits,omitempty"`
+}
+
+func serverConfigHandler(server config.ServerConfig, readTimeout time.Duration, submissions config.SubmissionsConfig) http.Handler {
+	payload := serverConfigResponse{
+		Name:                      serviceName,
+		Addr:                      server.Addr,
+		Port:                      server.Port,
+		ReadTimeout:               readTimeout.String(),
+		SubmissionProofOfWorkBits: max(submissions.ProofOfWorkBits, 0),
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_ = json.NewEncoder(w).Encode(payload)
+	})
+}
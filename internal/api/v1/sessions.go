@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"live-stream-alerts/internal/logging"
+	"live-stream-alerts/internal/sessions"
+	"live-stream-alerts/internal/streamers"
+)
+
+const (
+	defaultSessionsLimit = 50
+	maxSessionsLimit     = 200
+)
+
+type sessionsOptions struct {
+	Sessions  *sessions.Store
+	Streamers streamers.Repository
+	Logger    logging.Logger
+}
+
+type sessionsResponse struct {
+	Sessions []sessions.Session `json:"sessions"`
+	Total    int                `json:"total"`
+	Limit    int                `json:"limit"`
+	Offset   int                `json:"offset"`
+}
+
+// sessionsHandler pages through a streamer's broadcast history, newest first. from/to
+// (RFC 3339) keep sessions overlapping the range; limit and offset select the page.
+func sessionsHandler(opts sessionsOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		query, err := parseSessionsQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		records, err := opts.Streamers.List()
+		if err != nil {
+			opts.logf("sessions: failed to list streamers: %v", err)
+			http.Error(w, "failed to load streamers", http.StatusInternalServerError)
+			return
+		}
+		id := r.PathValue("id")
+		if _, ok := findStreamer(records, id); !ok {
+			http.Error(w, "streamer not found", http.StatusNotFound)
+			return
+		}
+		page, err := opts.Sessions.List(id, query)
+		if err != nil {
+			opts.logf("sessions: failed to list sessions for %s: %v", id, err)
+			http.Error(w, "failed to load sessions", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_ = json.NewEncoder(w).Encode(sessionsResponse{
+			Sessions: page.Sessions,
+			Total:    page.Total,
+			Limit:    query.Limit,
+			Offset:   query.Offset,
+		})
+	})
+}
+
+func parseSessionsQuery(r *http.Request) (sessions.Query, error) {
+	values := r.URL.Query()
+	query := sessions.Query{Limit: defaultSessionsLimit}
+	var err error
+	if raw := values.Get("from"); raw != "" {
+		if query.From, err = time.Parse(time.RFC3339, raw); err != nil {
+			return query, errBadParam("from must be an RFC 3339 timestamp")
+		}
+	}
+	if raw := values.Get("to"); raw != "" {
+		if query.To, err = time.Parse(time.RFC3339, raw); err != nil {
+			return query, errBadParam("to must be an RFC 3339 timestamp")
+		}
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		return query, errBadParam("to must not be before from")
+	}
+	if raw := values.Get("limit"); raw != "" {
+		if query.Limit, err = strconv.Atoi(raw); err != nil || query.Limit < 1 || query.Limit > maxSessionsLimit {
+			return query, errBadParam("limit must be between 1 and " + strconv.Itoa(maxSessionsLimit))
+		}
+	}
+	if raw := values.Get("offset"); raw != "" {
+		if query.Offset, err = strconv.Atoi(raw); err != nil || query.Offset < 0 {
+			return query, errBadParam("offset must be a non-negative integer")
+		}
+	}
+	return query, nil
+}
+
+type errBadParam string
+
+func (e errBadParam) Error() string { return string(e) }
+
+func (o sessionsOptions) logf(format string, args ...any) {
+	if o.Logger != nil {
+		o.Logger.Printf(format, args...)
+	}
+}
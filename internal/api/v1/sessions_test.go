@@ -0,0 +1,59 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"live-stream-alerts/internal/sessions"
+	"live-stream-alerts/internal/streamers"
+)
+
+func TestSessionsRoutePagesHistory(t *testing.T) {
+	dir := t.TempDir()
+	store := streamers.NewStore(filepath.Join(dir, "streamers.json"))
+	record, err := store.Append(streamers.Record{Streamer: streamers.Streamer{Alias: "Demo"}})
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	history := sessions.NewStore(filepath.Join(dir, "sessions.json"), sessions.WithRetention(sessions.Retention{}))
+	base := time.Date(2025, 1, 1, 18, 0, 0, 0, time.UTC)
+	for day := 0; day < 3; day++ {
+		start := base.AddDate(0, 0, day)
+		if err := history.Apply(sessions.Snapshot{StreamerID: record.Streamer.ID, At: start, Live: []sessions.Session{{Platform: "youtube", VideoID: "v" + string(rune('0'+day)), StartedAt: start}}}); err != nil {
+			t.Fatalf("apply: %v", err)
+		}
+		if err := history.Apply(sessions.Snapshot{StreamerID: record.Streamer.ID, At: start.Add(time.Hour)}); err != nil {
+			t.Fatalf("apply offline: %v", err)
+		}
+	}
+	router := NewRouter(Options{StreamersStore: store, SessionsStore: history, YouTube: testYouTubeConfig()})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/streamers/"+record.Streamer.ID+"/sessions?limit=1&offset=1&from=2025-01-01T20:00:00Z", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp sessionsResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Total != 2 || resp.Limit != 1 || resp.Offset != 1 || len(resp.Sessions) != 1 || resp.Sessions[0].VideoID != "v1" || resp.Sessions[0].DurationSeconds != 3600 {
+		t.Fatalf("unexpected page %+v", resp)
+	}
+
+	for path, want := range map[string]int{
+		"/api/streamers/" + record.Streamer.ID + "/sessions?limit=0":     http.StatusBadRequest,
+		"/api/streamers/" + record.Streamer.ID + "/sessions?from=monday": http.StatusBadRequest,
+		"/api/streamers/missing/sessions":                                http.StatusNotFound,
+	} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		if rr.Code != want {
+			t.Fatalf("%s: expected %d, got %d", path, want, rr.Code)
+		}
+	}
+}
@@ -1,27 +1,32 @@
 package v1
 
 import (
-	"errors"
+	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"live-stream-alerts/internal/logging"
+	"live-stream-alerts/internal/streamers"
 )
 
-const defaultWatchPollInterval = 2 * time.Second
+const defaultWatchKeepAlive = 15 * time.Second
 
 type streamersWatchOptions struct {
-	FilePath     string
-	Logger       logging.Logger
-	PollInterval time.Duration
+	Events    *streamers.EventBus
+	Logger    logging.Logger
+	KeepAlive time.Duration
 }
 
+// streamersWatchHandler streams record changes from the event bus as typed server-sent
+// events. Clients that reconnect with Last-Event-ID receive the events they missed, or a
+// resync event when those have already left the replay buffer.
 func streamersWatchHandler(opts streamersWatchOptions) http.Handler {
-	interval := opts.PollInterval
-	if interval <= 0 {
-		interval = defaultWatchPollInterval
+	keepAlive := opts.KeepAlive
+	if keepAlive <= 0 {
+		keepAlive = defaultWatchKeepAlive
 	}
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -30,8 +35,8 @@ func streamersWatchHandler(opts streamersWatchOptions) http.Handler {
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
-		if opts.FilePath == "" {
-			http.Error(w, "streamers path not configured", http.StatusInternalServerError)
+		if opts.Events == nil {
+			http.Error(w, "streamer events not configured", http.StatusInternalServerError)
 			return
 		}
 
@@ -41,44 +46,79 @@ func streamersWatchHandler(opts streamersWatchOptions) http.Handler {
 			return
 		}
 
+		lastID, resume := parseLastEventID(r.Header.Get("Last-Event-ID"))
+		sub, replay, complete := opts.Events.Subscribe(lastID, resume)
+		defer sub.Close()
+
 		w.Header().Set("Content-Type", "text/event-stream")
 		w.Header().Set("Cache-Control", "no-cache")
 		w.Header().Set("Connection", "keep-alive")
 		w.WriteHeader(http.StatusOK)
 
-		lastMod, _ := fileModTime(opts.FilePath)
-		fmt.Fprintf(w, "event: ready\ndata: %d\n\n", lastMod.UnixMilli())
+		if !complete {
+			fmt.Fprintf(w, "event: resync\ndata: {}\n\n")
+		}
+		fmt.Fprintf(w, "event: ready\ndata: {\"lastEventId\":%d}\n\n", opts.Events.LastID())
+		for _, event := range replay {
+			if err := writeStreamerEvent(w, event); err != nil {
+				return
+			}
+		}
 		flusher.Flush()
 
-		ticker := time.NewTicker(interval)
+		ticker := time.NewTicker(keepAlive)
 		defer ticker.Stop()
 
 		for {
 			select {
 			case <-r.Context().Done():
 				return
-			case <-ticker.C:
-				mod, err := fileModTime(opts.FilePath)
-				if err != nil {
-					if !errors.Is(err, os.ErrNotExist) && opts.Logger != nil {
-						opts.Logger.Printf("streamers watch: stat failed: %v", err)
+			case event, ok := <-sub.C:
+				if !ok {
+					// Dropped for falling behind; the client reconnects with Last-Event-ID.
+					if opts.Logger != nil {
+						opts.Logger.Printf("streamers watch: dropped slow subscriber")
 					}
-					continue
+					return
+				}
+				if err := writeStreamerEvent(w, event); err != nil {
+					return
 				}
-				if mod.After(lastMod) {
-					lastMod = mod
-					fmt.Fprintf(w, "event: change\ndata: %d\n\n", mod.UnixMilli())
-					flusher.Flush()
+				flusher.Flush()
+			case <-ticker.C:
+				if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+					return
 				}
+				flusher.Flush()
 			}
 		}
 	})
 }
 
-func fileModTime(path string) (time.Time, error) {
-	info, err := os.Stat(path)
+func parseLastEventID(value string) (uint64, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		// An unparseable ID cannot be resumed; force the client to resynchronise.
+		return ^uint64(0), true
+	}
+	return id, true
+}
+
+// writeStreamerEvent sends the record's public view; the stream is open to anyone. A
+// resync carries no record, so it is sent with an empty body.
+func writeStreamerEvent(w http.ResponseWriter, event streamers.Event) error {
+	if event.Type == streamers.EventResync {
+		_, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: {}\n\n", event.ID, event.Type)
+		return err
+	}
+	data, err := json.Marshal(event.Record.Public())
 	if err != nil {
-		return time.Time{}, err
+		return err
 	}
-	return info.ModTime(), nil
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
 }
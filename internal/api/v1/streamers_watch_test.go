@@ -0,0 +1,116 @@
+package v1
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"live-stream-alerts/internal/streamers"
+)
+
+type sseStream struct {
+	lines chan string
+}
+
+// readSSE collects lines from the event stream until want lines containing marker are seen.
+func readSSE(t *testing.T, stream *sseStream, marker string, want int) []string {
+	t.Helper()
+	var out []string
+	seen := 0
+	timeout := time.After(2 * time.Second)
+	for seen < want {
+		select {
+		case line, ok := <-stream.lines:
+			if !ok {
+				t.Fatalf("stream closed early: %v", out)
+			}
+			out = append(out, line)
+			if strings.Contains(line, marker) {
+				seen++
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %q, got %v", marker, out)
+		}
+	}
+	return out
+}
+
+func openWatch(t *testing.T, srv *httptest.Server, lastEventID string) *sseStream {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected response %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	stream := &sseStream{lines: make(chan string, 64)}
+	go func() {
+		defer close(stream.lines)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			stream.lines <- scanner.Text()
+		}
+	}()
+	return stream
+}
+
+func TestStreamersWatchStreamsTypedEvents(t *testing.T) {
+	bus := streamers.NewEventBus(0)
+	srv := httptest.NewServer(streamersWatchHandler(streamersWatchOptions{Events: bus, KeepAlive: 20 * time.Millisecond}))
+	t.Cleanup(srv.Close)
+
+	stream := openWatch(t, srv, "")
+	readSSE(t, stream, "event: ready", 1)
+	bus.Publish(streamers.EventStatusLive, streamers.Record{
+		Streamer:  streamers.Streamer{ID: "demo", Email: "demo@example.com"},
+		Platforms: streamers.Platforms{YouTube: &streamers.YouTubePlatform{ChannelID: "UCdemo", HubSecret: "hub-secret"}},
+	})
+
+	lines := readSSE(t, stream, `data: {"streamer"`, 1)
+	joined := strings.Join(lines, "\n")
+	if !strings.Contains(joined, "id: 1\nevent: status.live\ndata: {\"streamer\":{\"id\":\"demo\"") {
+		t.Fatalf("unexpected event framing:\n%s", joined)
+	}
+	if strings.Contains(joined, "hub-secret") || strings.Contains(joined, "demo@example.com") {
+		t.Fatalf("expected the event to carry the public record:\n%s", joined)
+	}
+	readSSE(t, stream, ": keep-alive", 1)
+
+	bus.Publish(streamers.EventResync, streamers.Record{})
+	lines = readSSE(t, stream, "data: {}", 1)
+	if joined := strings.Join(lines, "\n"); !strings.Contains(joined, "id: 2\nevent: resync\ndata: {}") {
+		t.Fatalf("expected an empty resync event for external changes:\n%s", joined)
+	}
+}
+
+func TestStreamersWatchResumesFromLastEventID(t *testing.T) {
+	bus := streamers.NewEventBus(2)
+	for _, id := range []string{"a", "b", "c"} {
+		bus.Publish(streamers.EventStreamerCreated, streamers.Record{Streamer: streamers.Streamer{ID: id}})
+	}
+	srv := httptest.NewServer(streamersWatchHandler(streamersWatchOptions{Events: bus}))
+	t.Cleanup(srv.Close)
+
+	lines := readSSE(t, openWatch(t, srv, "2"), "event: streamer.created", 1)
+	joined := strings.Join(lines, "\n")
+	if strings.Contains(joined, "resync") || !strings.Contains(joined, "id: 3\n") {
+		t.Fatalf("expected replay of event 3 only:\n%s", joined)
+	}
+
+	lines = readSSE(t, openWatch(t, srv, "0"), "event: ready", 1)
+	if lines[0] != "event: resync" {
+		t.Fatalf("expected resync when the replay buffer no longer covers the gap, got %v", lines)
+	}
+}
@@ -0,0 +1,342 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"live-stream-alerts/internal/logging"
+	"live-stream-alerts/internal/streamers"
+)
+
+const (
+	defaultWSPingInterval = 30 * time.Second
+	wsWriteWait           = 10 * time.Second
+	wsMaxMessageBytes     = 4096
+	wsReplyBuffer         = 8
+	// wsMaxFilterEntries caps how many streamers, and how many platforms, one connection
+	// can subscribe to.
+	wsMaxFilterEntries = 100
+)
+
+type streamersWSOptions struct {
+	Events       *streamers.EventBus
+	Logger       logging.Logger
+	PingInterval time.Duration
+	// AllowedOrigins are the browser origins accepted besides the server's own
+	// (server.allowed_origins).
+	AllowedOrigins []string
+}
+
+// wsClientMessage is a control message sent by a WebSocket client.
+type wsClientMessage struct {
+	Type      string   `json:"type"`
+	Streamers []string `json:"streamers,omitempty"`
+	Platforms []string `json:"platforms,omitempty"`
+}
+
+// wsServerMessage is anything other than a streamer event sent to the client.
+type wsServerMessage struct {
+	Type        string   `json:"type"`
+	LastEventID uint64   `json:"lastEventId,omitempty"`
+	Streamers   []string `json:"streamers,omitempty"`
+	Platforms   []string `json:"platforms,omitempty"`
+	Message     string   `json:"message,omitempty"`
+}
+
+// wsFilter narrows the events a connection receives. Empty sets match everything.
+type wsFilter struct {
+	mu        sync.Mutex
+	streamers map[string]struct{}
+	platforms map[string]struct{}
+}
+
+func newWSFilter() *wsFilter {
+	return &wsFilter{streamers: map[string]struct{}{}, platforms: map[string]struct{}{}}
+}
+
+// apply adds or removes the message's entries. A subscribe that would grow either set
+// past wsMaxFilterEntries is refused as a whole.
+func (f *wsFilter) apply(msg wsClientMessage) wsServerMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if msg.Type != "unsubscribe" && (overflows(f.streamers, msg.Streamers) || overflows(f.platforms, msg.Platforms)) {
+		return wsServerMessage{Type: "error", Message: "subscriptions are limited to " + strconv.Itoa(wsMaxFilterEntries) + " streamers and " + strconv.Itoa(wsMaxFilterEntries) + " platforms"}
+	}
+	for _, id := range msg.Streamers {
+		key := strings.ToLower(strings.TrimSpace(id))
+		if key == "" {
+			continue
+		}
+		if msg.Type == "unsubscribe" {
+			delete(f.streamers, key)
+		} else {
+			f.streamers[key] = struct{}{}
+		}
+	}
+	for _, platform := range msg.Platforms {
+		key := strings.ToLower(strings.TrimSpace(platform))
+		if key == "" {
+			continue
+		}
+		if msg.Type == "unsubscribe" {
+			delete(f.platforms, key)
+		} else {
+			f.platforms[key] = struct{}{}
+		}
+	}
+	return wsServerMessage{Type: "subscribed", Streamers: sortedKeys(f.streamers), Platforms: sortedKeys(f.platforms)}
+}
+
+func (f *wsFilter) matches(record streamers.Record) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.streamers) > 0 {
+		if _, ok := f.streamers[strings.ToLower(record.Streamer.ID)]; !ok {
+			return false
+		}
+	}
+	if len(f.platforms) == 0 {
+		return true
+	}
+	for _, platform := range recordPlatforms(record) {
+		if _, ok := f.platforms[platform]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// overflows reports whether adding values to set would take it past wsMaxFilterEntries.
+func overflows(set map[string]struct{}, values []string) bool {
+	added := make(map[string]struct{})
+	for _, value := range values {
+		key := strings.ToLower(strings.TrimSpace(value))
+		if key == "" {
+			continue
+		}
+		if _, ok := set[key]; !ok {
+			added[key] = struct{}{}
+		}
+	}
+	return len(set)+len(added) > wsMaxFilterEntries
+}
+
+func recordPlatforms(record streamers.Record) []string {
+	var out []string
+	if record.Platforms.YouTube != nil {
+		out = append(out, "youtube")
+	}
+	if record.Platforms.Twitch != nil {
+		out = append(out, "twitch")
+	}
+	if record.Platforms.Facebook != nil {
+		out = append(out, "facebook")
+	}
+	return out
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	out := make([]string, 0, len(set))
+	for key := range set {
+		out = append(out, key)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// streamersWSHandler mirrors the /api/streamers/watch event stream over a WebSocket.
+// Clients narrow the stream with subscribe/unsubscribe messages (or the streamer and
+// platform query parameters), resume with ?lastEventId=, and must answer pings.
+func streamersWSHandler(opts streamersWSOptions) http.Handler {
+	pingInterval := opts.PingInterval
+	if pingInterval <= 0 {
+		pingInterval = defaultWSPingInterval
+	}
+	pongWait := pingInterval * 2
+	upgrader := websocket.Upgrader{CheckOrigin: wsOriginChecker(opts.AllowedOrigins)}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if opts.Events == nil {
+			http.Error(w, "streamer events not configured", http.StatusInternalServerError)
+			return
+		}
+
+		query := r.URL.Query()
+		filter := newWSFilter()
+		if reply := filter.apply(wsClientMessage{Type: "subscribe", Streamers: query["streamer"], Platforms: query["platform"]}); reply.Type == "error" {
+			http.Error(w, reply.Message, http.StatusBadRequest)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// Upgrade has already written an HTTP error response.
+			return
+		}
+		defer conn.Close()
+
+		lastID, resume := parseLastEventID(query.Get("lastEventId"))
+		sub, replay, complete := opts.Events.Subscribe(lastID, resume)
+		defer sub.Close()
+
+		replies := make(chan wsServerMessage, wsReplyBuffer)
+		done := make(chan struct{})
+		go readWSClient(conn, filter, replies, done, pongWait)
+
+		write := func(v any) error {
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			return conn.WriteJSON(v)
+		}
+		// A resync concerns every record, so it bypasses the filter.
+		sendEvent := func(event streamers.Event) bool {
+			return event.Type == streamers.EventResync || filter.matches(event.Record)
+		}
+		closeWith := func(code int, reason string) {
+			_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(wsWriteWait))
+		}
+
+		if !complete {
+			if err := write(wsServerMessage{Type: "resync"}); err != nil {
+				return
+			}
+		}
+		if err := write(wsServerMessage{Type: "ready", LastEventID: opts.Events.LastID()}); err != nil {
+			return
+		}
+		for _, event := range replay {
+			if !sendEvent(event) {
+				continue
+			}
+			if err := write(wsEventMessage(event)); err != nil {
+				return
+			}
+		}
+
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case reply, ok := <-replies:
+				if !ok {
+					closeWith(websocket.ClosePolicyViolation, "too many unanswered control messages")
+					return
+				}
+				if err := write(reply); err != nil {
+					return
+				}
+			case event, ok := <-sub.C:
+				if !ok {
+					// The bus dropped us for falling behind; the client should reconnect
+					// with ?lastEventId= to replay what it missed.
+					if opts.Logger != nil {
+						opts.Logger.Printf("streamers ws: dropped slow subscriber")
+					}
+					closeWith(websocket.CloseTryAgainLater, "subscriber fell behind")
+					return
+				}
+				if !sendEvent(event) {
+					continue
+				}
+				if err := write(wsEventMessage(event)); err != nil {
+					return
+				}
+			case <-ticker.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+					return
+				}
+			}
+		}
+	})
+}
+
+// wsEventMessage encodes an event for the socket: a resync as the same message sent on
+// connect, anything else as the event with its record's public view.
+func wsEventMessage(event streamers.Event) any {
+	if event.Type == streamers.EventResync {
+		return wsServerMessage{Type: "resync"}
+	}
+	return publicEvent(event)
+}
+
+// publicEvent swaps the event's record for its public view; the stream is open to anyone.
+func publicEvent(event streamers.Event) streamers.Event {
+	event.Record = event.Record.Public()
+	return event
+}
+
+// wsOriginChecker accepts requests without an Origin header (non-browser clients), from
+// the server's own host, and from the allowed origins. Anything else is refused so other
+// sites cannot open the stream from a visitor's browser.
+func wsOriginChecker(allowed []string) func(*http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		if parsed, err := url.Parse(origin); err == nil && strings.EqualFold(parsed.Host, r.Host) {
+			return true
+		}
+		for _, candidate := range allowed {
+			if candidate == "*" || strings.EqualFold(strings.TrimSuffix(candidate, "/"), origin) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// readWSClient applies subscription messages and keeps the read deadline alive on pongs.
+// It closes done when the connection fails or the client goes quiet for longer than
+// pongWait, and closes replies if the client floods it faster than replies are written.
+func readWSClient(conn *websocket.Conn, filter *wsFilter, replies chan<- wsServerMessage, done chan<- struct{}, pongWait time.Duration) {
+	defer close(done)
+	conn.SetReadLimit(wsMaxMessageBytes)
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msg wsClientMessage
+		var reply wsServerMessage
+		switch {
+		case json.Unmarshal(data, &msg) != nil:
+			reply = wsServerMessage{Type: "error", Message: "invalid message"}
+		case msg.Type == "subscribe" || msg.Type == "unsubscribe":
+			reply = filter.apply(msg)
+		default:
+			reply = wsServerMessage{Type: "error", Message: "unknown message type " + strconv.Quote(msg.Type)}
+		}
+		if !queueReply(replies, reply) {
+			return
+		}
+	}
+}
+
+func queueReply(replies chan<- wsServerMessage, reply wsServerMessage) bool {
+	select {
+	case replies <- reply:
+		return true
+	default:
+		close(replies)
+		return false
+	}
+}
@@ -0,0 +1,168 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"live-stream-alerts/internal/streamers"
+)
+
+func dialStreamersWS(t *testing.T, bus *streamers.EventBus, ping time.Duration, query string) *websocket.Conn {
+	t.Helper()
+	srv := httptest.NewServer(streamersWSHandler(streamersWSOptions{Events: bus, PingInterval: ping}))
+	t.Cleanup(srv.Close)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+query, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readWSMessage(t *testing.T, conn *websocket.Conn) map[string]any {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg map[string]any
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return msg
+}
+
+func recordWith(id string, platforms streamers.Platforms) streamers.Record {
+	return streamers.Record{Streamer: streamers.Streamer{ID: id}, Platforms: platforms}
+}
+
+func TestStreamersWSFiltersBySubscription(t *testing.T) {
+	bus := streamers.NewEventBus(0)
+	conn := dialStreamersWS(t, bus, time.Minute, "")
+	if msg := readWSMessage(t, conn); msg["type"] != "ready" {
+		t.Fatalf("expected ready, got %v", msg)
+	}
+
+	if err := conn.WriteJSON(map[string]any{"type": "subscribe", "streamers": []string{"Wanted"}}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	ack := readWSMessage(t, conn)
+	if ack["type"] != "subscribed" || ack["streamers"].([]any)[0] != "wanted" {
+		t.Fatalf("unexpected ack: %v", ack)
+	}
+
+	bus.Publish(streamers.EventStatusLive, recordWith("other", streamers.Platforms{}))
+	bus.Publish(streamers.EventStatusLive, recordWith("wanted", streamers.Platforms{YouTube: &streamers.YouTubePlatform{HubSecret: "hub-secret"}}))
+	msg := readWSMessage(t, conn)
+	if raw, _ := json.Marshal(msg); strings.Contains(string(raw), "hub-secret") {
+		t.Fatalf("expected the event to carry the public record: %s", raw)
+	}
+	record, _ := msg["record"].(map[string]any)
+	streamer, _ := record["streamer"].(map[string]any)
+	if msg["type"] != streamers.EventStatusLive || streamer["id"] != "wanted" {
+		t.Fatalf("expected only the subscribed streamer's event, got %v", msg)
+	}
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("not json")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if msg := readWSMessage(t, conn); msg["type"] != "error" {
+		t.Fatalf("expected error reply, got %v", msg)
+	}
+}
+
+func TestStreamersWSPlatformQueryAndReplay(t *testing.T) {
+	bus := streamers.NewEventBus(0)
+	bus.Publish(streamers.EventStreamerCreated, recordWith("yt", streamers.Platforms{YouTube: &streamers.YouTubePlatform{}}))
+	bus.Publish(streamers.EventStreamerCreated, recordWith("tw", streamers.Platforms{Twitch: &streamers.TwitchPlatform{}}))
+
+	conn := dialStreamersWS(t, bus, time.Minute, "?platform=twitch&lastEventId=0")
+	if msg := readWSMessage(t, conn); msg["type"] != "ready" {
+		t.Fatalf("expected ready, got %v", msg)
+	}
+	msg := readWSMessage(t, conn)
+	raw, _ := json.Marshal(msg)
+	if msg["type"] != streamers.EventStreamerCreated || !strings.Contains(string(raw), `"id":"tw"`) {
+		t.Fatalf("expected replayed twitch event only, got %s", raw)
+	}
+}
+
+func TestStreamersWSChecksOrigin(t *testing.T) {
+	srv := httptest.NewServer(streamersWSHandler(streamersWSOptions{
+		Events:         streamers.NewEventBus(0),
+		AllowedOrigins: []string{"https://overlay.example.com"},
+	}))
+	t.Cleanup(srv.Close)
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+	cases := map[string]bool{
+		"":                            true,
+		srv.URL:                       true,
+		"https://overlay.example.com": true,
+		"https://evil.example.com":    false,
+	}
+	for origin, allowed := range cases {
+		header := http.Header{}
+		if origin != "" {
+			header.Set("Origin", origin)
+		}
+		conn, resp, err := websocket.DefaultDialer.Dial(wsURL, header)
+		if conn != nil {
+			conn.Close()
+		}
+		if allowed != (err == nil) {
+			t.Fatalf("origin %q: expected allowed=%v, got err=%v", origin, allowed, err)
+		}
+		if !allowed && resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("origin %q: expected 403, got %d", origin, resp.StatusCode)
+		}
+	}
+}
+
+func TestStreamersWSCapsSubscriptions(t *testing.T) {
+	conn := dialStreamersWS(t, streamers.NewEventBus(0), time.Minute, "")
+	readWSMessage(t, conn)
+	ids := make([]string, wsMaxFilterEntries+1)
+	for i := range ids {
+		ids[i] = "streamer-" + strconv.Itoa(i)
+	}
+	if err := conn.WriteJSON(map[string]any{"type": "subscribe", "streamers": ids}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if msg := readWSMessage(t, conn); msg["type"] != "error" {
+		t.Fatalf("expected an oversized subscription to be refused, got %v", msg)
+	}
+	if err := conn.WriteJSON(map[string]any{"type": "subscribe", "streamers": ids[:wsMaxFilterEntries]}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if msg := readWSMessage(t, conn); msg["type"] != "subscribed" || len(msg["streamers"].([]any)) != wsMaxFilterEntries {
+		t.Fatalf("expected the subscription to be accepted, got type %v", msg["type"])
+	}
+}
+
+func TestStreamersWSSendsPings(t *testing.T) {
+	conn := dialStreamersWS(t, streamers.NewEventBus(0), 20*time.Millisecond, "")
+	pinged := make(chan struct{}, 1)
+	conn.SetPingHandler(func(string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return conn.WriteControl(websocket.PongMessage, nil, time.Now().Add(time.Second))
+	})
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	select {
+	case <-pinged:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected a ping from the server")
+	}
+}
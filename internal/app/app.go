@@ -6,14 +6,35 @@ import (
 	"fmt"
 	"net/http"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"live-stream-alerts/config"
+	adminauth "live-stream-alerts/internal/admin/auth"
+	"live-stream-alerts/internal/admin/users"
 	apiv1 "live-stream-alerts/internal/api/v1"
+	"live-stream-alerts/internal/audit"
+	"live-stream-alerts/internal/filestore"
+	"live-stream-alerts/internal/health"
 	"live-stream-alerts/internal/httpserver"
+	"live-stream-alerts/internal/jobs"
 	"live-stream-alerts/internal/logging"
-	"live-stream-alerts/internal/platforms/youtube/subscriptions"
+	"live-stream-alerts/internal/metrics"
+	"live-stream-alerts/internal/notifications"
+	"live-stream-alerts/internal/platforms"
+	fbapi "live-stream-alerts/internal/platforms/facebook/api"
+	fbservice "live-stream-alerts/internal/platforms/facebook/service"
+	twitchapi "live-stream-alerts/internal/platforms/twitch/api"
+	twitchservice "live-stream-alerts/internal/platforms/twitch/service"
+	"live-stream-alerts/internal/platforms/youtube/feedpoll"
+	youtubehandlers "live-stream-alerts/internal/platforms/youtube/handlers"
+	"live-stream-alerts/internal/platforms/youtube/liveinfo"
+	"live-stream-alerts/internal/platforms/youtube/monitoring"
+	youtubeprovider "live-stream-alerts/internal/platforms/youtube/provider"
+	youtubeservice "live-stream-alerts/internal/platforms/youtube/service"
+	"live-stream-alerts/internal/sessions"
 	"live-stream-alerts/internal/streamers"
+	"live-stream-alerts/internal/submissions"
 )
 
 const (
@@ -51,15 +72,179 @@ func Run(ctx context.Context, opts Options) error {
 	if err != nil {
 		return err
 	}
+	configLoadedAt := time.Now()
 	logger := logging.New()
 
-	streamerStore := streamers.NewStore(streamers.DefaultFilePath)
+	backups := filestore.Backups{
+		Dir:       appCfg.Storage.BackupDir,
+		Retention: appCfg.Storage.BackupRetention,
+		Interval:  time.Duration(max(appCfg.Storage.BackupIntervalSeconds, 0)) * time.Second,
+	}
+	lockTimeout := time.Duration(appCfg.Storage.LockTimeoutSeconds) * time.Second
+	streamerEvents := streamers.NewEventBus(streamers.DefaultEventReplay)
+	streamerStore, closeStore, err := openStreamersRepository(appCfg.Storage, backups, lockTimeout, streamerEvents, logger)
+	if err != nil {
+		return fmt.Errorf("open streamers storage: %w", err)
+	}
+	defer closeStore()
+	if detector, ok := streamerStore.(streamers.ChangeDetector); ok {
+		changeWatcher := streamers.StartChangeWatcher(ctx, streamers.ChangeWatcherConfig{
+			Source: detector,
+			Events: streamerEvents,
+			Logger: logger,
+		})
+		defer changeWatcher.Stop()
+	}
+	submissionsStore := submissions.NewStore(
+		submissions.DefaultFilePath,
+		submissions.WithBackups(backups),
+		submissions.WithLockTimeout(lockTimeout),
+	)
+	if err := recoverStore("submissions", submissionsStore, logger); err != nil {
+		return err
+	}
+	sessionsStore := openSessionsStore(appCfg.Sessions, backups, lockTimeout)
+	if err := recoverStore("sessions", sessionsStore, logger); err != nil {
+		return err
+	}
+	recorder, err := sessions.StartRecorder(ctx, sessions.RecorderConfig{
+		Store:     sessionsStore,
+		Streamers: streamerStore,
+		Events:    streamerEvents,
+		Logger:    logger,
+	})
+	if err != nil {
+		return fmt.Errorf("start session recorder: %w", err)
+	}
+	defer recorder.Stop()
+	adminUsers := users.NewStore(appCfg.Admin.UsersPath, users.WithBackups(backups), users.WithLockTimeout(lockTimeout))
+	if err := recoverStore("admin users", adminUsers, logger); err != nil {
+		return err
+	}
+	if err := seedAdminUser(adminUsers, appCfg.Admin, logger); err != nil {
+		return err
+	}
+	adminSessions := adminauth.NewFileSessionStore(
+		appCfg.Admin.SessionsPath,
+		adminauth.WithSessionBackups(backups),
+		adminauth.WithSessionLockTimeout(lockTimeout),
+	)
+	if err := recoverStore("admin sessions", adminSessions, logger); err != nil {
+		return err
+	}
+	adminManager := adminauth.NewManager(adminauth.Config{
+		TokenTTL:   time.Duration(appCfg.Admin.TokenTTLSeconds) * time.Second,
+		RefreshTTL: time.Duration(appCfg.Admin.RefreshTTLSeconds) * time.Second,
+		Users:      adminUsers,
+		Sessions:   adminSessions,
+		Throttle:   newLoginThrottle(appCfg.Admin.LoginThrottle),
+	})
+	sessionPruner := adminauth.StartPruner(ctx, adminauth.PrunerConfig{Manager: adminManager, Logger: logger})
+	defer sessionPruner.Stop()
+
+	dispatcher, err := buildDispatcher(appCfg.Notifications, lockTimeout, logger)
+	if err != nil {
+		return fmt.Errorf("configure notifications: %w", err)
+	}
+	dispatcher.Start(ctx)
+	defer dispatcher.Stop()
+
+	leaseOverview := monitoring.NewService(monitoring.ServiceOptions{
+		StreamersStore:      streamerStore,
+		DefaultLeaseSeconds: appCfg.YouTube.LeaseSeconds,
+	})
+	appMetrics := metrics.New(metrics.Options{
+		Overview:  leaseOverview,
+		Streamers: streamerStore,
+		Logger:    logger,
+	})
+	readiness := health.NewChecker([]health.Check{
+		health.ConfigCheck("config", opts.ConfigPath, configLoadedAt),
+		health.StoreCheck("streamers_store", streamerStore),
+		health.FileWritableCheck("log_file", logFilePath),
+		health.HubVerificationCheck("hub_verification", leaseOverview),
+	})
+	// One instrumented client so hub responses from renewals, onboarding and the
+	// subscription proxy all land in the same counters.
+	youtubeClient := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: appMetrics.HubTransport(nil, appCfg.YouTube.HubURL),
+	}
+
+	lookup := &liveinfo.Client{Logger: logger, Observer: appMetrics}
+
+	auditLog := audit.NewLog(audit.Options{
+		Path:         appCfg.Audit.Path,
+		MaxFileBytes: appCfg.Audit.MaxFileBytes,
+		MaxFiles:     appCfg.Audit.MaxFiles,
+		LockTimeout:  lockTimeout,
+		Logger:       logger,
+	})
+
+	retryStore := openRetryStore(appCfg.RetryQueue, backups, lockTimeout)
+	if err := recoverStore("jobs", retryStore, logger); err != nil {
+		return err
+	}
+	retryQueue := jobs.NewQueue(jobs.QueueConfig{
+		Store: retryStore,
+		Handlers: map[string]jobs.Handler{
+			youtubeservice.NotificationJobKind: youtubeservice.NotificationRetryHandler{
+				Processor: youtubeservice.AlertProcessor{
+					Streamers:   streamerStore,
+					VideoLookup: lookup,
+					Notifier:    dispatcher,
+				},
+			},
+		},
+		Workers:     appCfg.RetryQueue.Workers,
+		MaxAttempts: appCfg.RetryQueue.MaxAttempts,
+		BaseBackoff: time.Duration(appCfg.RetryQueue.BaseBackoffSeconds) * time.Second,
+		MaxBackoff:  time.Duration(appCfg.RetryQueue.MaxBackoffSeconds) * time.Second,
+		Logger:      logger,
+	})
+	retryQueue.Start(ctx)
+	defer retryQueue.Stop()
+
+	registry := platforms.NewRegistry(youtubeprovider.New(youtubeprovider.Options{
+		Store:        streamerStore,
+		Client:       youtubeClient,
+		HubURL:       appCfg.YouTube.HubURL,
+		CallbackURL:  appCfg.YouTube.CallbackURL,
+		VerifyMode:   appCfg.YouTube.Verify,
+		LeaseSeconds: appCfg.YouTube.LeaseSeconds,
+		Logger:       logger,
+		Notifications: youtubehandlers.AlertNotificationOptions{
+			VideoLookup:   lookup,
+			Notifier:      dispatcher,
+			SignatureMode: appCfg.YouTube.SignatureMode,
+			Retry:         retryQueue,
+		},
+		Lookup:   lookup,
+		Notifier: dispatcher,
+	}))
 
 	router := apiv1.NewRouter(apiv1.Options{
-		Logger:         logger,
-		StreamersPath:  streamerStore.Path(),
-		StreamersStore: streamerStore,
-		YouTube:        appCfg.YouTube,
+		Logger:           logger,
+		StreamersPath:    streamerStore.Path(),
+		StreamersStore:   streamerStore,
+		StreamerEvents:   streamerEvents,
+		SubmissionsStore: submissionsStore,
+		SessionsStore:    sessionsStore,
+		YouTube:          appCfg.YouTube,
+		YouTubeClient:    youtubeClient,
+		Twitch:           appCfg.Twitch,
+		Facebook:         appCfg.Facebook,
+		Server:           appCfg.Server,
+		ReadTimeout:      opts.ReadTimeout,
+		Submissions:      appCfg.Submissions,
+		AdminManager:     adminManager,
+		AdminUsers:       adminUsers,
+		Notifier:         dispatcher,
+		Platforms:        registry,
+		Metrics:          appMetrics,
+		Readiness:        readiness,
+		RetryQueue:       retryQueue,
+		Audit:            auditLog,
 	})
 
 	serverCfg := httpserver.Config{
@@ -79,20 +264,42 @@ func Run(ctx context.Context, opts Options) error {
 		errCh <- srv.ListenAndServe()
 	}()
 
-	monitorOpts := subscriptions.Options{
-		Client:       &http.Client{Timeout: 10 * time.Second},
-		HubURL:       appCfg.YouTube.HubURL,
-		Logger:       logger,
-		Mode:         "subscribe",
-		Verify:       appCfg.YouTube.Verify,
-		LeaseSeconds: appCfg.YouTube.LeaseSeconds,
-	}
-	monitor := subscriptions.StartLeaseMonitor(ctx, subscriptions.LeaseMonitorConfig{
-		StreamersPath: streamerStore.Path(),
-		Interval:      time.Minute,
-		Options:       monitorOpts,
+	monitor := platforms.StartMonitor(ctx, platforms.MonitorConfig{
+		Registry:      registry,
+		Store:         streamerStore,
+		RenewInterval: time.Minute,
+		LiveInterval:  2 * time.Minute,
+		Observer:      appMetrics,
+		Logger:        logger,
 	})
 	defer monitor.Stop()
+	// The renewal loop ticks every RenewInterval; three missed passes mean it is stuck.
+	readiness.Add(health.HeartbeatCheck("lease_monitor", monitor.Heartbeat, 3*monitor.RenewInterval(), nil))
+
+	if appCfg.FeedPoll.IntervalSeconds > 0 {
+		poller := feedpoll.StartPoller(ctx, feedpoll.PollerConfig{
+			Store: streamerStore,
+			Processor: youtubeservice.AlertProcessor{
+				Streamers:   streamerStore,
+				VideoLookup: lookup,
+				Notifier:    dispatcher,
+			},
+			Leases:       leaseOverview,
+			FeedURL:      appCfg.FeedPoll.FeedURL,
+			Interval:     time.Duration(appCfg.FeedPoll.IntervalSeconds) * time.Second,
+			FastInterval: time.Duration(appCfg.FeedPoll.FastIntervalSeconds) * time.Second,
+			MaxBackoff:   time.Duration(appCfg.FeedPoll.MaxBackoffSeconds) * time.Second,
+			Logger:       logger,
+		})
+		defer poller.Stop()
+	}
+
+	if err := subscribeTwitch(ctx, appCfg.Twitch, streamerStore, logger); err != nil {
+		logger.Printf("Twitch EventSub subscriptions disabled: %v", err)
+	}
+	if err := subscribeFacebook(ctx, appCfg.Facebook, streamerStore, logger); err != nil {
+		logger.Printf("Facebook page subscriptions disabled: %v", err)
+	}
 
 	select {
 	case <-ctx.Done():
@@ -110,6 +317,210 @@ func Run(ctx context.Context, opts Options) error {
 	}
 }
 
+// openStreamersRepository opens the configured streamer backend. Selecting SQLite imports
+// any existing streamers.json on first start.
+func openStreamersRepository(cfg config.StorageConfig, backups filestore.Backups, lockTimeout time.Duration, events *streamers.EventBus, logger logging.Logger) (streamers.Repository, func(), error) {
+	if cfg.Backend != config.StorageSQLite {
+		path := cfg.Path
+		if path == "" {
+			path = streamers.DefaultFilePath
+		}
+		store := streamers.NewStore(
+			path,
+			streamers.WithBackups(backups),
+			streamers.WithLockTimeout(lockTimeout),
+			streamers.WithEvents(events),
+		)
+		if err := recoverStore("streamers", store, logger); err != nil {
+			return nil, nil, err
+		}
+		return store, func() {}, nil
+	}
+
+	store, err := streamers.OpenSQLiteStore(cfg.Path, streamers.WithSQLiteEvents(events))
+	if err != nil {
+		return nil, nil, err
+	}
+	migrated, err := streamers.MigrateJSONToSQLite(cfg.JSONPath, store)
+	if err != nil {
+		store.Close()
+		return nil, nil, fmt.Errorf("migrate %s: %w", cfg.JSONPath, err)
+	}
+	if migrated > 0 {
+		logger.Printf("Imported %d streamers from %s into %s", migrated, cfg.JSONPath, store.Path())
+	}
+	return store, func() { _ = store.Close() }, nil
+}
+
+// openSessionsStore builds the session history store with the configured retention.
+// Negative limits disable the corresponding pruning.
+func openSessionsStore(cfg config.SessionsConfig, backups filestore.Backups, lockTimeout time.Duration) *sessions.Store {
+	var retention sessions.Retention
+	if cfg.RetentionDays > 0 {
+		retention.MaxAge = time.Duration(cfg.RetentionDays) * 24 * time.Hour
+	}
+	if cfg.MaxPerStreamer > 0 {
+		retention.MaxPerStreamer = cfg.MaxPerStreamer
+	}
+	return sessions.NewStore(
+		cfg.Path,
+		sessions.WithRetention(retention),
+		sessions.WithBackups(backups),
+		sessions.WithLockTimeout(lockTimeout),
+	)
+}
+
+// openRetryStore builds the notification retry queue's job store. A negative dead-letter
+// limit keeps every dead job.
+func openRetryStore(cfg config.RetryQueueConfig, backups filestore.Backups, lockTimeout time.Duration) *jobs.Store {
+	return jobs.NewStore(
+		cfg.Path,
+		jobs.WithDeadLetterLimit(cfg.DeadLetterLimit),
+		jobs.WithBackups(backups),
+		jobs.WithLockTimeout(lockTimeout),
+	)
+}
+
+// seedAdminUser creates the first admin account from the admin email and password in
+// config.json. Once any account exists those settings are ignored.
+func seedAdminUser(store *users.Store, cfg config.AdminConfig, logger logging.Logger) error {
+	existing, err := store.List()
+	if err != nil {
+		return fmt.Errorf("read admin users: %w", err)
+	}
+	if len(existing) > 0 {
+		if cfg.Password != "" {
+			logger.Printf("Ignoring admin.password in config: %s already holds admin accounts", store.Path())
+		}
+		return nil
+	}
+	if strings.TrimSpace(cfg.Email) == "" || cfg.Password == "" {
+		logger.Printf("No admin accounts configured; set admin.email and admin.password to create the first one")
+		return nil
+	}
+	user, err := store.Create(cfg.Email, cfg.Password, users.RoleAdmin)
+	if errors.Is(err, users.ErrInvalid) {
+		// Keep serving alerts; only admin logins stay unavailable until the config is fixed.
+		logger.Printf("Cannot create admin account from config: %v", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("seed admin user: %w", err)
+	}
+	logger.Printf("Created admin account %s in %s; admin.password can now be removed from config", user.Email, store.Path())
+	return nil
+}
+
+func newLoginThrottle(cfg config.LoginThrottleConfig) *adminauth.Throttle {
+	return adminauth.NewThrottle(adminauth.ThrottleConfig{
+		MaxAccountFailures: cfg.MaxAccountFailures,
+		MaxIPFailures:      cfg.MaxIPFailures,
+		BaseLockout:        time.Duration(cfg.BaseLockoutSeconds) * time.Second,
+		MaxLockout:         time.Duration(cfg.MaxLockoutSeconds) * time.Second,
+		Window:             time.Duration(cfg.WindowSeconds) * time.Second,
+	})
+}
+
+type recoverable interface {
+	Path() string
+	Recover() (string, error)
+}
+
+// recoverStore restores a JSON store from its newest valid backup when the data file
+// no longer parses.
+func recoverStore(name string, store recoverable, logger logging.Logger) error {
+	restored, err := store.Recover()
+	if err != nil {
+		return fmt.Errorf("recover %s file: %w", name, err)
+	}
+	if restored != "" {
+		logger.Printf("Recovered %s from backup %s after %s failed to parse", name, restored, store.Path())
+	}
+	return nil
+}
+
+func buildDispatcher(cfg config.NotificationsConfig, lockTimeout time.Duration, logger logging.Logger) (*notifications.Dispatcher, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	sinks := make([]notifications.Sink, 0, len(cfg.Sinks))
+	names := make(map[string]struct{}, len(cfg.Sinks))
+	for _, sinkCfg := range cfg.Sinks {
+		sink, err := notifications.NewSink(notifications.SinkConfig{
+			Name:      sinkCfg.Name,
+			Type:      sinkCfg.Type,
+			URL:       sinkCfg.URL,
+			Template:  sinkCfg.Template,
+			Templates: sinkCfg.Templates,
+		}, client)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := names[sink.Name()]; ok {
+			return nil, fmt.Errorf("duplicate sink name %q", sink.Name())
+		}
+		names[sink.Name()] = struct{}{}
+		sinks = append(sinks, sink)
+	}
+	return notifications.NewDispatcher(notifications.DispatcherConfig{
+		Outbox:      notifications.NewOutbox(cfg.OutboxPath, notifications.WithOutboxLockTimeout(lockTimeout)),
+		Sinks:       sinks,
+		Logger:      logger,
+		MaxAttempts: cfg.MaxAttempts,
+	}), nil
+}
+
+// subscribeTwitch registers EventSub subscriptions for every stored Twitch broadcaster
+// in the background. It returns an error only when the Twitch config is incomplete.
+func subscribeTwitch(ctx context.Context, cfg config.TwitchConfig, store streamers.Repository, logger logging.Logger) error {
+	if cfg.ClientID == "" || cfg.CallbackURL == "" || cfg.EventSubSecret == "" {
+		return errors.New("twitch client_id, callback_url and eventsub_secret are required")
+	}
+	client, err := twitchapi.NewHelixClient(twitchapi.HelixClientOptions{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		BaseURL:      cfg.HelixURL,
+		AuthURL:      cfg.AuthURL,
+	})
+	if err != nil {
+		return err
+	}
+	subscriber := twitchservice.Subscriber{
+		Client:      client,
+		CallbackURL: cfg.CallbackURL,
+		Secret:      cfg.EventSubSecret,
+		Logger:      logger,
+	}
+	go func() {
+		records, err := store.List()
+		if err != nil {
+			logger.Printf("Twitch EventSub: list streamers: %v", err)
+			return
+		}
+		_ = subscriber.SubscribeRecords(ctx, records)
+	}()
+	return nil
+}
+
+// subscribeFacebook subscribes the app to live_videos changes for every stored Facebook
+// page in the background. It returns an error only when the Facebook config is incomplete.
+func subscribeFacebook(ctx context.Context, cfg config.FacebookConfig, store streamers.Repository, logger logging.Logger) error {
+	if cfg.AppSecret == "" || cfg.VerifyToken == "" {
+		return errors.New("facebook app_secret and verify_token are required")
+	}
+	subscriber := fbservice.Subscriber{
+		Client: fbapi.NewGraphClient(fbapi.GraphClientOptions{BaseURL: cfg.GraphURL}),
+		Logger: logger,
+	}
+	go func() {
+		records, err := store.List()
+		if err != nil {
+			logger.Printf("Facebook webhook: list streamers: %v", err)
+			return
+		}
+		_ = subscriber.SubscribeRecords(ctx, records)
+	}()
+	return nil
+}
+
 func (o Options) withDefaults() Options {
 	if o.ConfigPath == "" {
 		o.ConfigPath = defaultConfigPath
@@ -0,0 +1,326 @@
+// Package audit records who changed what through the API in an append-only JSONL file,
+// rotated by size, and answers filtered queries over it.
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"live-stream-alerts/internal/filestore"
+	"live-stream-alerts/internal/logging"
+)
+
+const (
+	// DefaultFilePath is where audit entries are appended.
+	DefaultFilePath = "data/audit.jsonl"
+	// DefaultMaxFileBytes is the size at which the log is rotated.
+	DefaultMaxFileBytes = 10 << 20
+	// DefaultMaxFiles is how many rotated files are kept besides the active one.
+	DefaultMaxFiles = 5
+	// DefaultQueryLimit and MaxQueryLimit bound how many entries a query returns.
+	DefaultQueryLimit = 100
+	MaxQueryLimit     = 1000
+)
+
+// Actor identifies who made a change. Anonymous API callers only have RemoteAddr.
+type Actor struct {
+	UserID     string `json:"userId,omitempty"`
+	Email      string `json:"email,omitempty"`
+	Role       string `json:"role,omitempty"`
+	RemoteAddr string `json:"remoteAddr,omitempty"`
+}
+
+// Entry is one recorded change. Before and After hold JSON snapshots of the target; either
+// is omitted when the target did not exist on that side of the change.
+type Entry struct {
+	ID         string          `json:"id"`
+	Time       time.Time       `json:"time"`
+	Actor      Actor           `json:"actor"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"targetType"`
+	TargetID   string          `json:"targetId,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+}
+
+// Recorder stores audit entries. Record fills in ID, Time and, from ctx, Actor. It never
+// fails the caller's change: errors are logged by the implementation.
+type Recorder interface {
+	Record(ctx context.Context, entry Entry)
+}
+
+type actorKey struct{}
+
+// WithActor returns a context carrying actor for Record.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor stored by WithActor, or the zero Actor.
+func ActorFrom(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	return actor
+}
+
+// Snapshot marshals v for Entry.Before or Entry.After. It returns nil for nil values and
+// values that cannot be encoded.
+func Snapshot(v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return nil
+	}
+	return data
+}
+
+// Options configures a Log.
+type Options struct {
+	Path string
+	// MaxFileBytes rotates the active file once an append would take it past this size.
+	MaxFileBytes int64
+	// MaxFiles rotated files (path.1 newest to path.N oldest) are kept; older ones are
+	// deleted.
+	MaxFiles    int
+	LockTimeout time.Duration
+	Logger      logging.Logger
+	Now         func() time.Time
+}
+
+// Log appends entries to a JSONL file. Entries are never rewritten; the only mutation is
+// rotation, which renames whole files.
+type Log struct {
+	opts Options
+	mu   sync.Mutex
+	seq  atomic.Uint64
+}
+
+// NewLog returns a Log writing to opts.Path.
+func NewLog(opts Options) *Log {
+	if opts.Path == "" {
+		opts.Path = DefaultFilePath
+	}
+	opts.Path = filepath.Clean(opts.Path)
+	if opts.MaxFileBytes <= 0 {
+		opts.MaxFileBytes = DefaultMaxFileBytes
+	}
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = DefaultMaxFiles
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &Log{opts: opts}
+}
+
+// Path returns the active log file.
+func (l *Log) Path() string {
+	if l == nil {
+		return ""
+	}
+	return l.opts.Path
+}
+
+// Record appends entry, logging instead of returning failures. A nil Log drops entries.
+func (l *Log) Record(ctx context.Context, entry Entry) {
+	if l == nil {
+		return
+	}
+	if err := l.Append(ctx, entry); err != nil && l.opts.Logger != nil {
+		l.opts.Logger.Printf("audit: failed to record %s %s/%s: %v", entry.Action, entry.TargetType, entry.TargetID, err)
+	}
+}
+
+// Append writes entry as one JSON line, rotating the file first when it is full.
+func (l *Log) Append(ctx context.Context, entry Entry) error {
+	if l == nil {
+		return errors.New("audit log is nil")
+	}
+	now := l.opts.Now().UTC()
+	if entry.Time.IsZero() {
+		entry.Time = now
+	}
+	if entry.ID == "" {
+		entry.ID = fmt.Sprintf("aud_%d_%d", now.UnixNano(), l.seq.Add(1))
+	}
+	if entry.Actor == (Actor{}) {
+		entry.Actor = ActorFrom(ctx)
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.opts.Path), 0o755); err != nil {
+		return fmt.Errorf("create audit dir: %w", err)
+	}
+	lock, err := filestore.LockFile(l.opts.Path, l.opts.LockTimeout)
+	if err != nil {
+		return fmt.Errorf("lock audit log: %w", err)
+	}
+	defer lock.Unlock()
+
+	if info, err := os.Stat(l.opts.Path); err == nil && info.Size() > 0 && info.Size()+int64(len(line)) > l.opts.MaxFileBytes {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(l.opts.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return fmt.Errorf("write audit log: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("sync audit log: %w", err)
+	}
+	return file.Close()
+}
+
+// rotate shifts path.N-1 to path.N (dropping the oldest) and the active file to path.1.
+func (l *Log) rotate() error {
+	oldest := l.rotated(l.opts.MaxFiles)
+	if err := os.Remove(oldest); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove %s: %w", oldest, err)
+	}
+	for i := l.opts.MaxFiles - 1; i >= 1; i-- {
+		if err := os.Rename(l.rotated(i), l.rotated(i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("rotate audit log: %w", err)
+		}
+	}
+	if err := os.Rename(l.opts.Path, l.rotated(1)); err != nil {
+		return fmt.Errorf("rotate audit log: %w", err)
+	}
+	return nil
+}
+
+func (l *Log) rotated(n int) string {
+	return fmt.Sprintf("%s.%d", l.opts.Path, n)
+}
+
+// Filter narrows a query. Empty fields match everything.
+type Filter struct {
+	// Actor matches the actor's email (case-insensitively), user ID or remote address.
+	Actor string
+	// Action matches exactly, or as a prefix when it ends in "." (e.g. "streamer.").
+	Action     string
+	TargetType string
+	TargetID   string
+	// Since and Until bound the entry time; Since is inclusive and Until exclusive, so the
+	// oldest time of one page can be passed as Until for the next.
+	Since time.Time
+	Until time.Time
+	// Limit caps the result (DefaultQueryLimit when zero, at most MaxQueryLimit).
+	Limit int
+}
+
+// Match reports whether entry passes the filter.
+func (f Filter) Match(entry Entry) bool {
+	if f.Actor != "" && !strings.EqualFold(entry.Actor.Email, f.Actor) && entry.Actor.UserID != f.Actor && entry.Actor.RemoteAddr != f.Actor {
+		return false
+	}
+	if f.Action != "" {
+		if strings.HasSuffix(f.Action, ".") {
+			if !strings.HasPrefix(entry.Action, f.Action) {
+				return false
+			}
+		} else if entry.Action != f.Action {
+			return false
+		}
+	}
+	if f.TargetType != "" && entry.TargetType != f.TargetType {
+		return false
+	}
+	if f.TargetID != "" && entry.TargetID != f.TargetID {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !entry.Time.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Query returns matching entries from the active and rotated files, newest first.
+// Lines that do not decode, such as one cut short by a crash, are skipped.
+func (l *Log) Query(filter Filter) ([]Entry, error) {
+	if l == nil {
+		return nil, errors.New("audit log is nil")
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultQueryLimit
+	}
+	limit = min(limit, MaxQueryLimit)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var matches []Entry
+	for i := l.opts.MaxFiles; i >= 0; i-- {
+		path := l.opts.Path
+		if i > 0 {
+			path = l.rotated(i)
+		}
+		err := scanFile(path, func(entry Entry) {
+			if !filter.Match(entry) {
+				return
+			}
+			matches = append(matches, entry)
+			if len(matches) > 2*limit {
+				matches = append(matches[:0], matches[len(matches)-limit:]...)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(matches) > limit {
+		matches = matches[len(matches)-limit:]
+	}
+	for i, j := 0, len(matches)-1; i < j; i, j = i+1, j-1 {
+		matches[i], matches[j] = matches[j], matches[i]
+	}
+	return matches, nil
+}
+
+func scanFile(path string, fn func(Entry)) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4<<20)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		fn(entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	return nil
+}
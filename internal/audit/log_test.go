@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLogAppendsAndQueriesNewestFirst(t *testing.T) {
+	now := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	log := NewLog(Options{
+		Path: filepath.Join(t.TempDir(), "audit.jsonl"),
+		Now:  func() time.Time { return now },
+	})
+	ctx := WithActor(context.Background(), Actor{UserID: "usr_1", Email: "admin@example.com", Role: "admin"})
+
+	log.Record(ctx, Entry{Action: "streamer.update", TargetType: "streamer", TargetID: "s1", Before: Snapshot(map[string]string{"alias": "old"}), After: Snapshot(map[string]string{"alias": "new"})})
+	now = now.Add(time.Minute)
+	log.Record(ctx, Entry{Action: "streamer.delete", TargetType: "streamer", TargetID: "s1", Before: Snapshot(map[string]string{"alias": "new"})})
+	now = now.Add(time.Minute)
+	log.Record(WithActor(context.Background(), Actor{RemoteAddr: "192.0.2.1"}), Entry{Action: "submission.create", TargetType: "submission", TargetID: "sub1"})
+
+	all, err := log.Query(Filter{})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if len(all) != 3 || all[0].Action != "submission.create" || all[2].Action != "streamer.update" {
+		t.Fatalf("expected newest first, got %+v", all)
+	}
+	if all[2].Actor.Email != "admin@example.com" || string(all[2].Before) != `{"alias":"old"}` || all[1].After != nil {
+		t.Fatalf("unexpected entry %+v", all[2])
+	}
+
+	cases := []struct {
+		name   string
+		filter Filter
+		want   int
+	}{
+		{name: "actor email", filter: Filter{Actor: "ADMIN@example.com"}, want: 2},
+		{name: "actor address", filter: Filter{Actor: "192.0.2.1"}, want: 1},
+		{name: "action prefix", filter: Filter{Action: "streamer."}, want: 2},
+		{name: "exact action", filter: Filter{Action: "streamer"}, want: 0},
+		{name: "target", filter: Filter{TargetType: "streamer", TargetID: "s1"}, want: 2},
+		{name: "time range", filter: Filter{Since: all[1].Time, Until: all[0].Time}, want: 1},
+		{name: "limit", filter: Filter{Limit: 1}, want: 1},
+	}
+	for _, tc := range cases {
+		got, err := log.Query(tc.filter)
+		if err != nil || len(got) != tc.want {
+			t.Fatalf("%s: expected %d entries, got %d (%v)", tc.name, tc.want, len(got), err)
+		}
+	}
+}
+
+func TestLogRotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log := NewLog(Options{Path: path, MaxFileBytes: 300, MaxFiles: 2})
+	ctx := context.Background()
+	for i := 0; i < 12; i++ {
+		if err := log.Append(ctx, Entry{Action: "streamer.update", TargetType: "streamer", TargetID: "s1"}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("expected %s to exist: %v", name, err)
+		}
+		if info.Size() > 300 {
+			t.Fatalf("expected %s to stay under the limit, got %d bytes", name, info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected only two rotated files, got %v", err)
+	}
+
+	entries, err := log.Query(Filter{Limit: MaxQueryLimit})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if len(entries) == 0 || len(entries) >= 12 {
+		t.Fatalf("expected the oldest entries to be dropped, got %d", len(entries))
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].ID == entries[i-1].ID {
+			t.Fatalf("expected distinct entries, got duplicate %s", entries[i].ID)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// Package calendar renders streamer broadcasts as iCalendar (RFC 5545) feeds that
+// calendar apps can subscribe to.
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"live-stream-alerts/internal/sessions"
+	"live-stream-alerts/internal/streamers"
+)
+
+const (
+	// DefaultDuration is the length assumed for broadcasts whose end is not known yet.
+	DefaultDuration = 2 * time.Hour
+
+	productID     = "-//Sharpen Live//live-stream-alerts//EN"
+	uidDomain     = "live-stream-alerts"
+	refreshPeriod = "PT15M"
+	maxLineOctets = 75
+	timeLayout    = "20060102T150405Z"
+)
+
+// Event is a single VEVENT.
+type Event struct {
+	// UID stays the same for a broadcast across feed refreshes, so calendar clients
+	// update the event instead of adding a duplicate.
+	UID         string
+	Summary     string
+	Description string
+	URL         string
+	Start       time.Time
+	End         time.Time
+	// Modified is when the underlying broadcast data last changed.
+	Modified time.Time
+}
+
+// Calendar is a VCALENDAR holding events.
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// UID returns the stable event identifier for a platform video.
+func UID(platform, videoID string) string {
+	return fmt.Sprintf("%s-%s@%s", strings.ToLower(platform), videoID, uidDomain)
+}
+
+// Events builds one event per scheduled, live, or past YouTube broadcast of records,
+// ordered by start time. Past broadcasts come from history; sessions of streamers not
+// in records are ignored. now bounds the end of broadcasts that are still live.
+func Events(records []streamers.Record, history []sessions.Session, now time.Time) []Event {
+	var events []Event
+	aliases := make(map[string]string, len(records))
+	for _, record := range records {
+		aliases[record.Streamer.ID] = record.Streamer.Alias
+		alias := record.Streamer.Alias
+		for _, broadcast := range record.Schedule {
+			events = append(events, Event{
+				UID:         UID(broadcast.Platform, broadcast.VideoID),
+				Summary:     summary(alias, broadcast.Title),
+				Description: broadcast.URL,
+				URL:         broadcast.URL,
+				Start:       broadcast.ScheduledStart,
+				End:         broadcast.ScheduledStart.Add(DefaultDuration),
+				Modified:    broadcast.UpdatedAt,
+			})
+		}
+		if status := record.Status; status != nil && status.YouTube != nil && status.YouTube.Live && status.YouTube.VideoID != "" && !status.YouTube.StartedAt.IsZero() {
+			yt := status.YouTube
+			end := yt.StartedAt.Add(DefaultDuration)
+			if now.After(end) {
+				end = now
+			}
+			watchURL := "https://www.youtube.com/watch?v=" + yt.VideoID
+			events = append(events, Event{
+				UID:         UID("youtube", yt.VideoID),
+				Summary:     summary(alias, yt.Title),
+				Description: watchURL,
+				URL:         watchURL,
+				Start:       yt.StartedAt,
+				End:         end,
+				Modified:    record.UpdatedAt,
+			})
+		}
+	}
+	seen := make(map[string]struct{}, len(events))
+	for _, event := range events {
+		seen[event.UID] = struct{}{}
+	}
+	for _, session := range history {
+		alias, ok := aliases[session.StreamerID]
+		if !ok || session.Open() || session.Platform != "youtube" {
+			continue
+		}
+		uid := UID(session.Platform, session.VideoID)
+		if _, dup := seen[uid]; dup {
+			continue
+		}
+		seen[uid] = struct{}{}
+		watchURL := "https://www.youtube.com/watch?v=" + session.VideoID
+		events = append(events, Event{
+			UID:         uid,
+			Summary:     summary(alias, session.Title),
+			Description: watchURL,
+			URL:         watchURL,
+			Start:       session.StartedAt,
+			End:         session.EndedAt,
+			Modified:    session.EndedAt,
+		})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Start.Before(events[j].Start)
+	})
+	return events
+}
+
+func summary(alias, title string) string {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return alias + " live on YouTube"
+	}
+	return alias + ": " + title
+}
+
+// Encode writes cal as an iCalendar document. stamp is used as each event's DTSTAMP.
+func Encode(w io.Writer, cal Calendar, stamp time.Time) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", productID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		line("X-WR-CALNAME", escapeText(cal.Name))
+	}
+	line("REFRESH-INTERVAL;VALUE=DURATION", refreshPeriod)
+	line("X-PUBLISHED-TTL", refreshPeriod)
+	for _, event := range cal.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", event.UID)
+		line("DTSTAMP", formatTime(stamp))
+		line("DTSTART", formatTime(event.Start))
+		line("DTEND", formatTime(event.End))
+		if !event.Modified.IsZero() {
+			line("LAST-MODIFIED", formatTime(event.Modified))
+		}
+		line("SUMMARY", escapeText(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION", escapeText(event.Description))
+		}
+		if event.URL != "" {
+			line("URL", event.URL)
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeText(value string) string {
+	return textEscaper.Replace(value)
+}
+
+// writeFolded writes a content line terminated by CRLF, folding it so no physical line
+// exceeds 75 octets without splitting a UTF-8 sequence.
+func writeFolded(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit.
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
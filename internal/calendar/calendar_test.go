@@ -0,0 +1,78 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"live-stream-alerts/internal/sessions"
+	"live-stream-alerts/internal/streamers"
+)
+
+func TestEventsUsesStableUIDs(t *testing.T) {
+	start := time.Date(2025, 11, 21, 18, 0, 0, 0, time.UTC)
+	now := start.Add(3 * time.Hour)
+	records := []streamers.Record{{
+		Streamer: streamers.Streamer{ID: "demo", Alias: "Demo"},
+		Schedule: []streamers.ScheduledBroadcast{{Platform: "youtube", VideoID: "up1", Title: "Friday show", URL: "https://www.youtube.com/watch?v=up1", ScheduledStart: start.Add(48 * time.Hour)}},
+		Status: &streamers.Status{Live: true, YouTube: &streamers.YouTubeStatus{
+			Live: true, VideoID: "live1", StartedAt: start,
+		}},
+	}}
+
+	history := []sessions.Session{
+		{ID: "youtube-past1", StreamerID: "demo", Platform: "youtube", VideoID: "past1", Title: "Last week", StartedAt: start.AddDate(0, 0, -7), EndedAt: start.AddDate(0, 0, -7).Add(time.Hour)},
+		{ID: "youtube-live1", StreamerID: "demo", Platform: "youtube", VideoID: "live1", StartedAt: start},
+		{ID: "youtube-gone", StreamerID: "removed", Platform: "youtube", VideoID: "gone", StartedAt: start, EndedAt: now},
+	}
+
+	events := Events(records, history, now)
+	if len(events) != 3 {
+		t.Fatalf("expected three events, got %+v", events)
+	}
+	past, live, upcoming := events[0], events[1], events[2]
+	if past.UID != UID("youtube", "past1") || past.Summary != "Demo: Last week" || !past.End.Equal(past.Start.Add(time.Hour)) {
+		t.Fatalf("unexpected past event %+v", past)
+	}
+	if live.UID != "youtube-live1@live-stream-alerts" || live.Summary != "Demo live on YouTube" || !live.End.Equal(now) {
+		t.Fatalf("unexpected live event %+v", live)
+	}
+	if upcoming.UID != UID("youtube", "up1") || upcoming.Summary != "Demo: Friday show" || !upcoming.End.Equal(upcoming.Start.Add(DefaultDuration)) {
+		t.Fatalf("unexpected upcoming event %+v", upcoming)
+	}
+}
+
+func TestEncodeEscapesAndFolds(t *testing.T) {
+	start := time.Date(2025, 11, 21, 18, 0, 0, 0, time.FixedZone("CET", 3600))
+	var buf bytes.Buffer
+	err := Encode(&buf, Calendar{Name: "Demo", Events: []Event{{
+		UID:     UID("youtube", "abc"),
+		Summary: "Demo: Q&A; chat, games " + strings.Repeat("é", 40),
+		URL:     "https://www.youtube.com/watch?v=abc",
+		Start:   start,
+		End:     start.Add(time.Hour),
+	}}}, start)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n") || !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+		t.Fatalf("unexpected framing:\n%s", out)
+	}
+	if !strings.Contains(out, "DTSTART:20251121T170000Z\r\n") {
+		t.Fatalf("expected UTC start time:\n%s", out)
+	}
+	if !strings.Contains(out, `SUMMARY:Demo: Q&A\; chat\, games`) {
+		t.Fatalf("expected escaped summary:\n%s", out)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Fatalf("line exceeds %d octets: %q", maxLineOctets, line)
+		}
+	}
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	if !strings.Contains(unfolded, strings.Repeat("é", 40)+"\r\n") {
+		t.Fatalf("folding corrupted the summary:\n%s", out)
+	}
+}
@@ -0,0 +1,99 @@
+// Package clientip resolves the address of the client behind a request, honouring
+// X-Forwarded-For only when the request arrived through a trusted proxy.
+package clientip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Resolver extracts client addresses. The zero value and a nil Resolver trust no proxies
+// and always use the connection's remote address.
+type Resolver struct {
+	trusted []netip.Prefix
+}
+
+// NewResolver returns a Resolver trusting the given proxies, each an IP address or a CIDR
+// range such as 10.0.0.0/8.
+func NewResolver(proxies []string) (*Resolver, error) {
+	r := &Resolver{}
+	for _, proxy := range proxies {
+		prefix, err := ParseProxy(proxy)
+		if err != nil {
+			return nil, err
+		}
+		r.trusted = append(r.trusted, prefix)
+	}
+	return r, nil
+}
+
+// ParseProxy parses a trusted proxy entry: an IP address or a CIDR range.
+func ParseProxy(value string) (netip.Prefix, error) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// IP returns the client address for req. When the connection comes from a trusted proxy,
+// X-Forwarded-For is walked from the right and the first address that is not itself a
+// trusted proxy wins; otherwise the header is ignored, since any client can set it.
+func (r *Resolver) IP(req *http.Request) string {
+	remote := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	if r == nil || len(r.trusted) == 0 || !r.isTrusted(remote) {
+		return remote
+	}
+	hops := forwardedFor(req)
+	for i := len(hops) - 1; i >= 0; i-- {
+		if !r.isTrusted(hops[i]) {
+			return hops[i]
+		}
+	}
+	if len(hops) > 0 {
+		return hops[0]
+	}
+	return remote
+}
+
+func (r *Resolver) isTrusted(value string) bool {
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range r.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor returns the X-Forwarded-For hops in order, across repeated headers.
+func forwardedFor(req *http.Request) []string {
+	var hops []string
+	for _, header := range req.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(header, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	return hops
+}
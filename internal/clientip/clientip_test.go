@@ -0,0 +1,54 @@
+package clientip
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolverHonoursOnlyTrustedProxies(t *testing.T) {
+	resolver, err := NewResolver([]string{"10.0.0.0/8", "192.0.2.10"})
+	if err != nil {
+		t.Fatalf("new resolver: %v", err)
+	}
+	cases := []struct {
+		name   string
+		remote string
+		xff    []string
+		want   string
+	}{
+		{name: "direct client", remote: "203.0.113.5:4000", want: "203.0.113.5"},
+		{name: "spoofed header from untrusted peer", remote: "203.0.113.5:4000", xff: []string{"198.51.100.1"}, want: "203.0.113.5"},
+		{name: "trusted proxy", remote: "192.0.2.10:443", xff: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "chain of proxies", remote: "10.1.2.3:443", xff: []string{"1.1.1.1, 198.51.100.1, 10.9.9.9"}, want: "198.51.100.1"},
+		{name: "repeated headers", remote: "10.1.2.3:443", xff: []string{"1.1.1.1", "198.51.100.1"}, want: "198.51.100.1"},
+		{name: "trusted proxy without header", remote: "10.1.2.3:443", want: "10.1.2.3"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tc.remote
+			for _, value := range tc.xff {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			if got := resolver.IP(req); got != tc.want {
+				t.Fatalf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNilResolverUsesRemoteAddr(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "203.0.113.5:4000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	var resolver *Resolver
+	if got := resolver.IP(req); got != "203.0.113.5" {
+		t.Fatalf("expected remote address, got %s", got)
+	}
+}
+
+func TestParseProxyRejectsGarbage(t *testing.T) {
+	if _, err := NewResolver([]string{"not-an-ip"}); err == nil {
+		t.Fatalf("expected an invalid entry to fail")
+	}
+}
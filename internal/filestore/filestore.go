@@ -0,0 +1,268 @@
+// Package filestore provides crash-safe writes and rolling backups for the JSON files
+// that back the streamer and submission stores.
+package filestore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBackupDirName is the directory, next to the data file, that holds backups.
+	DefaultBackupDirName = "backups"
+	// DefaultRetention is how many backups are kept per file when none is configured.
+	DefaultRetention = 10
+
+	backupTimeLayout = "20060102T150405.000000000Z"
+)
+
+// WriteAtomic replaces path with data by writing a temp file in the same directory,
+// syncing it, and renaming it over the original. Readers observe either the old or the
+// new contents, never a partial write. An existing file keeps its mode; perm only applies
+// when path is created.
+func WriteAtomic(path string, data []byte, perm os.FileMode) (err error) {
+	if info, statErr := os.Stat(path); statErr == nil {
+		perm = info.Mode().Perm()
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmpName)
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpName, path); err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes the directory entry so the rename survives a power loss. Not every
+// platform supports syncing directories, so failures are ignored.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
+
+// Backups writes timestamped copies of a data file and prunes old ones.
+type Backups struct {
+	// Dir holds the backups. When empty, a "backups" directory next to the data file is used.
+	Dir string
+	// Retention is the number of backups kept per data file. Zero means DefaultRetention;
+	// a negative value disables backups.
+	Retention int
+	// Interval is the least time between two backups of one file. Saves that come sooner
+	// are skipped, so a busy store does not rewrite its backups on every change. Zero
+	// backs up every save.
+	Interval time.Duration
+	// Now overrides the clock used to name backups.
+	Now func() time.Time
+}
+
+// Enabled reports whether backups should be written.
+func (b Backups) Enabled() bool {
+	return b.Retention >= 0
+}
+
+func (b Backups) dirFor(path string) string {
+	if b.Dir != "" {
+		return b.Dir
+	}
+	return filepath.Join(filepath.Dir(path), DefaultBackupDirName)
+}
+
+func (b Backups) retention() int {
+	if b.Retention == 0 {
+		return DefaultRetention
+	}
+	return b.Retention
+}
+
+func (b Backups) now() time.Time {
+	if b.Now != nil {
+		return b.Now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+// backupPrefix and backupSuffix bracket the timestamp in a backup name, e.g.
+// "streamers-20240102T030405.000000000Z.json" for "streamers.json".
+func backupPrefix(path string) (string, string) {
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// Save writes data as a new backup of path and removes backups beyond the retention limit.
+// The backup gets the permissions of path, so private files stay private. It returns ""
+// when backups are disabled or the newest backup is younger than Interval.
+func (b Backups) Save(path string, data []byte) (string, error) {
+	if !b.Enabled() {
+		return "", nil
+	}
+	now := b.now()
+	if b.Interval > 0 {
+		if newest, ok := b.newest(path); ok && now.Sub(newest) < b.Interval {
+			return "", nil
+		}
+	}
+	perm := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	prefix, suffix := backupPrefix(path)
+	name := filepath.Join(b.dirFor(path), prefix+now.Format(backupTimeLayout)+suffix)
+	if err := WriteAtomic(name, data, perm); err != nil {
+		return "", fmt.Errorf("write backup: %w", err)
+	}
+	if err := b.prune(path); err != nil {
+		return name, err
+	}
+	return name, nil
+}
+
+// List returns the backups of path, newest first.
+func (b Backups) List(path string) ([]string, error) {
+	dir := b.dirFor(path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("list backups: %w", err)
+	}
+	prefix, suffix := backupPrefix(path)
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix)
+		if _, err := time.Parse(backupTimeLayout, stamp); err != nil {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	for i, name := range names {
+		names[i] = filepath.Join(dir, name)
+	}
+	return names, nil
+}
+
+// newest returns when the newest backup of path was taken.
+func (b Backups) newest(path string) (time.Time, bool) {
+	names, err := b.List(path)
+	if err != nil || len(names) == 0 {
+		return time.Time{}, false
+	}
+	prefix, suffix := backupPrefix(path)
+	stamp := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(names[0]), prefix), suffix)
+	taken, err := time.Parse(backupTimeLayout, stamp)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return taken, true
+}
+
+func (b Backups) prune(path string) error {
+	names, err := b.List(path)
+	if err != nil {
+		return err
+	}
+	keep := b.retention()
+	for i := keep; i < len(names); i++ {
+		if err := os.Remove(names[i]); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("prune backup: %w", err)
+		}
+	}
+	return nil
+}
+
+// Recover restores path from its newest backup that passes validate. The unreadable file
+// is kept alongside as path+".corrupt-<timestamp>". The restored file gets the damaged
+// file's permissions, or the backup's when path is gone, so private files stay private.
+// It returns the backup that was restored.
+func (b Backups) Recover(path string, validate func([]byte) error) (string, error) {
+	names, err := b.List(path)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil || validate(data) != nil {
+			continue
+		}
+		perm := os.FileMode(0o600)
+		if info, err := os.Stat(name); err == nil {
+			perm = info.Mode().Perm()
+		}
+		if info, err := os.Stat(path); err == nil {
+			perm = info.Mode().Perm()
+			corrupt := path + ".corrupt-" + b.now().Format(backupTimeLayout)
+			if err := os.Rename(path, corrupt); err != nil {
+				return "", fmt.Errorf("preserve corrupt file: %w", err)
+			}
+		}
+		if err := WriteAtomic(path, data, perm); err != nil {
+			return "", fmt.Errorf("restore backup: %w", err)
+		}
+		return name, nil
+	}
+	return "", errors.New("no valid backup found")
+}
+
+// RecoverCorrupt restores path from its newest valid backup when the file's contents fail
+// decode. A missing file, or one that decodes, is left alone. Read errors such as a
+// permission problem are returned without touching the file, since restoring a backup
+// cannot fix them. It returns the backup that was restored, or "".
+func (b Backups) RecoverCorrupt(path string, decode func([]byte) error) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+	decodeErr := decode(data)
+	if decodeErr == nil {
+		return "", nil
+	}
+	restored, err := b.Recover(path, decode)
+	if err != nil {
+		return "", fmt.Errorf("%w (%v)", decodeErr, err)
+	}
+	return restored, nil
+}
@@ -0,0 +1,226 @@
+package filestore
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteAtomicReplacesFileWithoutTempLeftovers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+	if err := WriteAtomic(path, []byte("one"), 0o644); err != nil {
+		t.Fatalf("first write: %v", err)
+	}
+	if err := WriteAtomic(path, []byte("two"), 0o644); err != nil {
+		t.Fatalf("second write: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "two" {
+		t.Fatalf("expected replaced contents, got %q, %v", data, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("expected only the data file, got %d entries", len(entries))
+	}
+}
+
+func TestWriteAtomicKeepsExistingMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	if err := WriteAtomic(path, []byte("one"), 0o644); err != nil {
+		t.Fatalf("first write: %v", err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	if err := WriteAtomic(path, []byte("two"), 0o644); err != nil {
+		t.Fatalf("second write: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("expected the operator's 0600 to be kept, got %o", perm)
+	}
+}
+
+type steppingClock struct{ now time.Time }
+
+func (c *steppingClock) Now() time.Time {
+	c.now = c.now.Add(time.Second)
+	return c.now
+}
+
+func TestBackupsSaveAppliesRetention(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "streamers.json")
+	clock := &steppingClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	backups := Backups{Retention: 2, Now: clock.Now}
+
+	for _, body := range []string{"a", "b", "c"} {
+		if _, err := backups.Save(path, []byte(body)); err != nil {
+			t.Fatalf("save %s: %v", body, err)
+		}
+	}
+	names, err := backups.List(path)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(names) != 2 {
+		t.Fatalf("expected 2 backups after pruning, got %v", names)
+	}
+	if filepath.Dir(names[0]) != filepath.Join(dir, DefaultBackupDirName) {
+		t.Fatalf("expected default backups dir, got %s", names[0])
+	}
+	newest, _ := os.ReadFile(names[0])
+	if string(newest) != "c" {
+		t.Fatalf("expected newest backup first, got %q", newest)
+	}
+}
+
+func TestBackupsDisabled(t *testing.T) {
+	dir := t.TempDir()
+	name, err := Backups{Retention: -1}.Save(filepath.Join(dir, "x.json"), []byte("x"))
+	if err != nil || name != "" {
+		t.Fatalf("expected no backup, got %q, %v", name, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, DefaultBackupDirName)); !os.IsNotExist(err) {
+		t.Fatalf("backups dir should not be created")
+	}
+}
+
+func TestRecoverUsesNewestValidBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "streamers.json")
+	clock := &steppingClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	backups := Backups{Now: clock.Now}
+	if _, err := backups.Save(path, []byte(`{"ok":1}`)); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, err := backups.Save(path, []byte(`{"ok":`)); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("garbage"), 0o644); err != nil {
+		t.Fatalf("write corrupt file: %v", err)
+	}
+
+	validJSON := func(data []byte) error {
+		var v any
+		return json.Unmarshal(data, &v)
+	}
+	restored, err := backups.Recover(path, validJSON)
+	if err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+	if !strings.HasSuffix(restored, ".json") {
+		t.Fatalf("unexpected restored name %q", restored)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != `{"ok":1}` {
+		t.Fatalf("expected the older valid backup, got %q", data)
+	}
+	matches, _ := filepath.Glob(path + ".corrupt-*")
+	if len(matches) != 1 {
+		t.Fatalf("expected corrupt file to be preserved, got %v", matches)
+	}
+
+	if _, err := (Backups{Dir: t.TempDir()}).Recover(path, validJSON); err == nil {
+		t.Fatalf("expected error when no backups exist")
+	}
+}
+
+func TestRecoverKeepsPrivatePermissions(t *testing.T) {
+	dir := t.TempDir()
+	backups := Backups{}
+	validJSON := func(data []byte) error {
+		var v any
+		return json.Unmarshal(data, &v)
+	}
+	for _, name := range []string{"damaged.json", "missing.json"} {
+		path := filepath.Join(dir, name)
+		if err := WriteAtomic(path, []byte(`{"ok":1}`), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if _, err := backups.Save(path, []byte(`{"ok":1}`)); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if name == "missing.json" {
+			if err := os.Remove(path); err != nil {
+				t.Fatalf("remove: %v", err)
+			}
+		} else if err := os.WriteFile(path, []byte("garbage"), 0o600); err != nil {
+			t.Fatalf("write corrupt file: %v", err)
+		}
+		if _, err := backups.Recover(path, validJSON); err != nil {
+			t.Fatalf("recover %s: %v", name, err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("stat: %v", err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o600 {
+			t.Fatalf("%s: expected 0600 after recovery, got %o", name, perm)
+		}
+	}
+}
+
+func TestRecoverCorruptOnlyRestoresUndecodableFiles(t *testing.T) {
+	dir := t.TempDir()
+	validJSON := func(data []byte) error {
+		var v any
+		return json.Unmarshal(data, &v)
+	}
+	backups := Backups{Dir: filepath.Join(dir, "backups")}
+	unreadable := filepath.Join(dir, "unreadable.json")
+	if _, err := backups.Save(unreadable, []byte(`{"ok":1}`)); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	// Reading a directory fails without the contents being corrupt.
+	if err := os.Mkdir(unreadable, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if restored, err := backups.RecoverCorrupt(unreadable, validJSON); err == nil || restored != "" {
+		t.Fatalf("expected the read error without a restore, got %q, %v", restored, err)
+	}
+	if info, err := os.Stat(unreadable); err != nil || !info.IsDir() {
+		t.Fatalf("expected the unreadable path to be left alone: %v", err)
+	}
+
+	if restored, err := backups.RecoverCorrupt(filepath.Join(dir, "missing.json"), validJSON); err != nil || restored != "" {
+		t.Fatalf("expected a missing file to be left alone, got %q, %v", restored, err)
+	}
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if _, err := backups.Save(corrupt, []byte(`{"ok":1}`)); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := os.WriteFile(corrupt, []byte("garbage"), 0o644); err != nil {
+		t.Fatalf("write corrupt file: %v", err)
+	}
+	if restored, err := backups.RecoverCorrupt(corrupt, validJSON); err != nil || restored == "" {
+		t.Fatalf("expected the corrupt file to be restored, got %q, %v", restored, err)
+	}
+}
+
+func TestBackupsSaveHonoursInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "streamers.json")
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	backups := Backups{Interval: time.Minute, Now: func() time.Time { return now }}
+	for _, step := range []time.Duration{0, 30 * time.Second, 31 * time.Second} {
+		now = now.Add(step)
+		if _, err := backups.Save(path, []byte("x")); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+	names, err := backups.List(path)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(names) != 2 {
+		t.Fatalf("expected the save 30s after the first to be skipped, got %v", names)
+	}
+}
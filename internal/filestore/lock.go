@@ -0,0 +1,71 @@
+package filestore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// DefaultLockTimeout bounds how long a writer waits for another process to release a file.
+	DefaultLockTimeout = 5 * time.Second
+
+	lockRetryInterval = 10 * time.Millisecond
+)
+
+// ErrLockTimeout reports that another holder kept the lock for longer than the timeout.
+var ErrLockTimeout = errors.New("timed out waiting for file lock")
+
+// Lock is an advisory, exclusive, cross-process lock on a data file.
+type Lock struct {
+	file *os.File
+}
+
+// LockFile takes an exclusive advisory lock guarding path, waiting up to timeout (zero
+// means DefaultLockTimeout). The lock lives on a sibling path+".lock" file because the
+// data file itself is replaced on every atomic write.
+func LockFile(path string, timeout time.Duration) (*Lock, error) {
+	if timeout <= 0 {
+		timeout = DefaultLockTimeout
+	}
+	lockPath := path + ".lock"
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		acquired, err := tryLock(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("lock %s: %w", lockPath, err)
+		}
+		if acquired {
+			return &Lock{file: file}, nil
+		}
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("%w: %s is held by another process after %s", ErrLockTimeout, lockPath, timeout)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// Unlock releases the lock.
+func (l *Lock) Unlock() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	err := unlock(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	return err
+}
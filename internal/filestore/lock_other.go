@@ -0,0 +1,14 @@
+//go:build !unix
+
+package filestore
+
+import "os"
+
+// Advisory locking relies on flock(2); other platforms only get in-process serialisation.
+func tryLock(file *os.File) (bool, error) {
+	return true, nil
+}
+
+func unlock(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,73 @@
+//go:build unix
+
+package filestore
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockFileTimesOutWhileHeld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "streamers.json")
+	held, err := LockFile(path, time.Second)
+	if err != nil {
+		t.Fatalf("first lock: %v", err)
+	}
+
+	if _, err := LockFile(path, 30*time.Millisecond); !errors.Is(err, ErrLockTimeout) {
+		t.Fatalf("expected lock timeout, got %v", err)
+	}
+
+	if err := held.Unlock(); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+	again, err := LockFile(path, 30*time.Millisecond)
+	if err != nil {
+		t.Fatalf("expected lock after release: %v", err)
+	}
+	_ = again.Unlock()
+}
+
+// TestLockFileHelperProcess holds the lock on behalf of TestLockFileAcrossProcesses.
+func TestLockFileHelperProcess(t *testing.T) {
+	path := os.Getenv("FILESTORE_LOCK_HELPER_PATH")
+	if path == "" {
+		t.Skip("helper process only")
+	}
+	lock, err := LockFile(path, time.Second)
+	if err != nil {
+		os.Exit(2)
+	}
+	os.Stdout.WriteString("locked\n")
+	time.Sleep(2 * time.Second)
+	_ = lock.Unlock()
+}
+
+func TestLockFileAcrossProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "streamers.json")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestLockFileHelperProcess$")
+	cmd.Env = append(os.Environ(), "FILESTORE_LOCK_HELPER_PATH="+path)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("stdout pipe: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start helper: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+	buf := make([]byte, len("locked\n"))
+	if _, err := stdout.Read(buf); err != nil {
+		t.Fatalf("wait for helper: %v", err)
+	}
+
+	if _, err := LockFile(path, 50*time.Millisecond); !errors.Is(err, ErrLockTimeout) {
+		t.Fatalf("expected lock held by the helper process, got %v", err)
+	}
+}
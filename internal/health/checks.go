@@ -0,0 +1,127 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"live-stream-alerts/internal/platforms/youtube/monitoring"
+	"live-stream-alerts/internal/streamers"
+)
+
+// StoreCheck reads every streamer through repo and verifies that the store file and
+// its directory accept writes, without modifying the store.
+func StoreCheck(name string, repo streamers.Repository) Check {
+	return Check{Name: name, Run: func(context.Context) Result {
+		records, err := repo.List()
+		if err != nil {
+			return Result{Status: StatusFail, Detail: fmt.Sprintf("read failed: %v", err)}
+		}
+		if err := probeWritable(repo.Path()); err != nil {
+			return Result{Status: StatusFail, Detail: err.Error()}
+		}
+		return Result{Status: StatusOK, Detail: fmt.Sprintf("%d streamers in %s", len(records), repo.Path())}
+	}}
+}
+
+// FileWritableCheck verifies that path (for example the log file) can be opened for
+// appending and that its directory accepts new files.
+func FileWritableCheck(name, path string) Check {
+	return Check{Name: name, Run: func(context.Context) Result {
+		if err := probeWritable(path); err != nil {
+			return Result{Status: StatusFail, Detail: err.Error()}
+		}
+		return Result{Status: StatusOK, Detail: path}
+	}}
+}
+
+// probeWritable opens path for appending without writing, and creates and removes a
+// temp file next to it, since atomic saves and rotation need the directory too.
+func probeWritable(path string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	switch {
+	case err == nil:
+		file.Close()
+	case errors.Is(err, fs.ErrNotExist):
+		// Not created yet; the directory check below decides.
+	default:
+		return fmt.Errorf("%s not writable: %v", path, err)
+	}
+	dir := filepath.Dir(path)
+	probe, err := os.CreateTemp(dir, ".readyz-*")
+	if err != nil {
+		return fmt.Errorf("directory %s not writable: %v", dir, err)
+	}
+	name := probe.Name()
+	probe.Close()
+	return os.Remove(name)
+}
+
+// HeartbeatCheck fails when the worker behind last has not ticked within maxAge or has
+// never ticked.
+func HeartbeatCheck(name string, last func() time.Time, maxAge time.Duration, now func() time.Time) Check {
+	if now == nil {
+		now = time.Now
+	}
+	return Check{Name: name, Run: func(context.Context) Result {
+		beat := last()
+		if beat.IsZero() {
+			return Result{Status: StatusFail, Detail: "no heartbeat yet"}
+		}
+		age := now().Sub(beat)
+		detail := fmt.Sprintf("last heartbeat %s ago", age.Round(time.Second))
+		if age > maxAge {
+			return Result{Status: StatusFail, Detail: detail}
+		}
+		return Result{Status: StatusOK, Detail: detail}
+	}}
+}
+
+// OverviewSource summarises YouTube lease health. monitoring.Service satisfies it.
+type OverviewSource interface {
+	Overview(ctx context.Context) (monitoring.Overview, error)
+}
+
+// HubVerificationCheck reports the most recent hub verification across all YouTube
+// leases. It warns rather than fails when that lease has expired: taking the server out
+// of rotation would only stop the hub from reaching the callback.
+func HubVerificationCheck(name string, source OverviewSource) Check {
+	return Check{Name: name, Run: func(ctx context.Context) Result {
+		overview, err := source.Overview(ctx)
+		if err != nil {
+			return Result{Status: StatusFail, Detail: fmt.Sprintf("lease overview failed: %v", err)}
+		}
+		if len(overview.Records) == 0 {
+			return Result{Status: StatusOK, Detail: "no YouTube subscriptions"}
+		}
+		var latest *monitoring.LeaseEntry
+		for i := range overview.Records {
+			entry := &overview.Records[i]
+			if entry.LeaseStart != nil && (latest == nil || entry.LeaseStart.After(*latest.LeaseStart)) {
+				latest = entry
+			}
+		}
+		if latest == nil {
+			return Result{Status: StatusWarn, Detail: "no hub verification recorded"}
+		}
+		detail := fmt.Sprintf("last verified %s (%s)", latest.LeaseStart.Format(time.RFC3339), latest.Alias)
+		if latest.Status == monitoring.LeaseStatusExpired {
+			return Result{Status: StatusWarn, Detail: detail + ", lease expired"}
+		}
+		return Result{Status: StatusOK, Detail: detail}
+	}}
+}
+
+// ConfigCheck reports which configuration file was loaded and when.
+func ConfigCheck(name, path string, loadedAt time.Time) Check {
+	return Check{Name: name, Run: func(context.Context) Result {
+		if loadedAt.IsZero() {
+			return Result{Status: StatusFail, Detail: "configuration not loaded"}
+		}
+		return Result{Status: StatusOK, Detail: fmt.Sprintf("%s loaded at %s", path, loadedAt.UTC().Format(time.RFC3339))}
+	}}
+}
@@ -0,0 +1,146 @@
+// Package health runs the dependency checks behind the readiness endpoint.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Status is the outcome of a single check or of a whole report.
+type Status string
+
+const (
+	// StatusOK means the dependency works.
+	StatusOK Status = "ok"
+	// StatusWarn flags a problem that restarting or unrouting the server would not fix,
+	// such as an unreachable hub. It does not fail readiness.
+	StatusWarn Status = "warn"
+	// StatusFail means the server cannot do its job and should not receive traffic.
+	StatusFail Status = "fail"
+)
+
+const defaultCheckTimeout = 5 * time.Second
+
+// Result is what a check reports.
+type Result struct {
+	Status Status `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Check is a named readiness probe.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) Result
+}
+
+// CheckResult is a check's result as it appears in a report.
+type CheckResult struct {
+	Name string `json:"name"`
+	Result
+	DurationMillis int64 `json:"durationMs"`
+}
+
+// Report aggregates every check. Status is fail when any check failed, warn when any
+// warned, and ok otherwise.
+type Report struct {
+	Status    Status        `json:"status"`
+	CheckedAt time.Time     `json:"checkedAt"`
+	Checks    []CheckResult `json:"checks"`
+}
+
+// Checker holds the registered checks. Checks can be added after the HTTP server has
+// started, so workers launched later can still report in.
+type Checker struct {
+	mu      sync.RWMutex
+	checks  []Check
+	timeout time.Duration
+	now     func() time.Time
+}
+
+// Option customises a Checker.
+type Option func(*Checker)
+
+// WithTimeout bounds how long a single check may run (default 5s).
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Checker) {
+		if timeout > 0 {
+			c.timeout = timeout
+		}
+	}
+}
+
+// WithNow overrides the clock used for report timestamps and check durations.
+func WithNow(now func() time.Time) Option {
+	return func(c *Checker) {
+		if now != nil {
+			c.now = now
+		}
+	}
+}
+
+// NewChecker builds a Checker with the given checks.
+func NewChecker(checks []Check, opts ...Option) *Checker {
+	c := &Checker{checks: append([]Check(nil), checks...), timeout: defaultCheckTimeout, now: time.Now}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Add registers further checks.
+func (c *Checker) Add(checks ...Check) {
+	c.mu.Lock()
+	c.checks = append(c.checks, checks...)
+	c.mu.Unlock()
+}
+
+// Run executes every check concurrently and returns the results in registration order.
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := append([]Check(nil), c.checks...)
+	c.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, CheckedAt: c.now().UTC(), Checks: results}
+	for _, result := range results {
+		switch result.Status {
+		case StatusFail:
+			report.Status = StatusFail
+		case StatusWarn:
+			if report.Status == StatusOK {
+				report.Status = StatusWarn
+			}
+		}
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) CheckResult {
+	checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	started := c.now()
+	done := make(chan Result, 1)
+	go func() { done <- check.Run(checkCtx) }()
+
+	var result Result
+	select {
+	case result = <-done:
+	case <-checkCtx.Done():
+		result = Result{Status: StatusFail, Detail: "check timed out"}
+	}
+	if result.Status == "" {
+		result.Status = StatusOK
+	}
+	return CheckResult{Name: check.Name, Result: result, DurationMillis: c.now().Sub(started).Milliseconds()}
+}
@@ -0,0 +1,124 @@
+package health
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"live-stream-alerts/internal/platforms/youtube/monitoring"
+	"live-stream-alerts/internal/streamers"
+)
+
+func staticCheck(name string, status Status) Check {
+	return Check{Name: name, Run: func(context.Context) Result { return Result{Status: status} }}
+}
+
+func TestCheckerAggregatesWorstStatus(t *testing.T) {
+	checker := NewChecker([]Check{staticCheck("a", StatusOK), staticCheck("b", StatusWarn)})
+	if report := checker.Run(context.Background()); report.Status != StatusWarn {
+		t.Fatalf("expected warn, got %s", report.Status)
+	}
+	checker.Add(staticCheck("c", StatusFail))
+	report := checker.Run(context.Background())
+	if report.Status != StatusFail {
+		t.Fatalf("expected fail, got %s", report.Status)
+	}
+	if len(report.Checks) != 3 || report.Checks[2].Name != "c" {
+		t.Fatalf("expected results in registration order, got %+v", report.Checks)
+	}
+}
+
+func TestCheckerTimesOutSlowChecks(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	checker := NewChecker([]Check{{Name: "slow", Run: func(context.Context) Result {
+		<-block
+		return Result{Status: StatusOK}
+	}}}, WithTimeout(20*time.Millisecond))
+
+	report := checker.Run(context.Background())
+	if report.Status != StatusFail || report.Checks[0].Detail != "check timed out" {
+		t.Fatalf("expected timed out failure, got %+v", report)
+	}
+}
+
+func TestStoreCheck(t *testing.T) {
+	dir := t.TempDir()
+	store := streamers.NewStore(filepath.Join(dir, "streamers.json"))
+	if _, err := store.Append(streamers.Record{Streamer: streamers.Streamer{Alias: "Demo"}}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if result := StoreCheck("store", store).Run(context.Background()); result.Status != StatusOK {
+		t.Fatalf("expected ok, got %+v", result)
+	}
+	if err := os.WriteFile(store.Path(), []byte("{"), 0o644); err != nil {
+		t.Fatalf("corrupt store: %v", err)
+	}
+	if result := StoreCheck("store", store).Run(context.Background()); result.Status != StatusFail {
+		t.Fatalf("expected unreadable store to fail, got %+v", result)
+	}
+}
+
+func TestFileWritableCheckFailsForMissingDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if result := FileWritableCheck("log", filepath.Join(dir, "server.log")).Run(context.Background()); result.Status != StatusOK {
+		t.Fatalf("expected a not-yet-created file in a writable directory to pass, got %+v", result)
+	}
+	if result := FileWritableCheck("log", filepath.Join(dir, "missing", "server.log")).Run(context.Background()); result.Status != StatusFail {
+		t.Fatalf("expected missing directory to fail, got %+v", result)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Fatalf("expected probe files to be cleaned up, found %d entries", len(entries))
+	}
+}
+
+func TestHeartbeatCheck(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	var last time.Time
+	check := HeartbeatCheck("monitor", func() time.Time { return last }, 3*time.Minute, clock)
+
+	if result := check.Run(context.Background()); result.Status != StatusFail {
+		t.Fatalf("expected missing heartbeat to fail, got %+v", result)
+	}
+	last = now.Add(-time.Minute)
+	if result := check.Run(context.Background()); result.Status != StatusOK {
+		t.Fatalf("expected fresh heartbeat to pass, got %+v", result)
+	}
+	last = now.Add(-5 * time.Minute)
+	if result := check.Run(context.Background()); result.Status != StatusFail {
+		t.Fatalf("expected stale heartbeat to fail, got %+v", result)
+	}
+}
+
+type fakeOverview struct {
+	overview monitoring.Overview
+}
+
+func (f fakeOverview) Overview(context.Context) (monitoring.Overview, error) {
+	return f.overview, nil
+}
+
+func TestHubVerificationCheckUsesNewestLease(t *testing.T) {
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(24 * time.Hour)
+	source := fakeOverview{overview: monitoring.Overview{Records: []monitoring.LeaseEntry{
+		{Alias: "Old", LeaseStart: &older, Status: monitoring.LeaseStatusExpired},
+		{Alias: "New", LeaseStart: &newer, Status: monitoring.LeaseStatusHealthy},
+	}}}
+	result := HubVerificationCheck("hub", source).Run(context.Background())
+	if result.Status != StatusOK || result.Detail != "last verified 2024-01-02T00:00:00Z (New)" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	source.overview.Records[1].Status = monitoring.LeaseStatusExpired
+	if result := HubVerificationCheck("hub", source).Run(context.Background()); result.Status != StatusWarn {
+		t.Fatalf("expected expired lease to warn, got %+v", result)
+	}
+	if result := HubVerificationCheck("hub", fakeOverview{}).Run(context.Background()); result.Status != StatusOK {
+		t.Fatalf("expected no subscriptions to pass, got %+v", result)
+	}
+}
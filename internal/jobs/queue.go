@@ -0,0 +1,328 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"live-stream-alerts/internal/logging"
+)
+
+const (
+	defaultWorkers      = 2
+	defaultPollInterval = 15 * time.Second
+	defaultMaxAttempts  = 8
+	defaultBaseBackoff  = 30 * time.Second
+	defaultMaxBackoff   = 30 * time.Minute
+	defaultJobTimeout   = 30 * time.Second
+)
+
+// Handler processes jobs of one kind. Returning an error schedules a retry; wrap it with
+// Permanent to dead-letter the job immediately.
+type Handler interface {
+	Handle(ctx context.Context, job Job) error
+}
+
+// HandlerFunc adapts a function to Handler.
+type HandlerFunc func(ctx context.Context, job Job) error
+
+// Handle implements Handler.
+func (f HandlerFunc) Handle(ctx context.Context, job Job) error { return f(ctx, job) }
+
+// PermanentError marks a failure that retrying cannot fix.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// Permanent wraps err so the queue dead-letters the job without further attempts.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// QueueConfig configures the worker pool.
+type QueueConfig struct {
+	Store *Store
+	// Handlers maps job kinds to their handler. Jobs of unknown kinds are dead-lettered.
+	Handlers     map[string]Handler
+	Workers      int
+	PollInterval time.Duration
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	// JobTimeout bounds a single attempt (default 30s).
+	JobTimeout time.Duration
+	Logger     logging.Logger
+	Now        func() time.Time
+}
+
+// Queue persists jobs through Store and runs due ones on a fixed pool of workers.
+type Queue struct {
+	cfg      QueueConfig
+	wake     chan struct{}
+	due      chan Job
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	inFlight map[string]struct{}
+	seq      uint64
+}
+
+// NewQueue builds a Queue without starting its workers, so jobs can be enqueued before
+// Start and are picked up once it runs.
+func NewQueue(cfg QueueConfig) *Queue {
+	if cfg.Store == nil {
+		cfg.Store = NewStore(DefaultFilePath)
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultWorkers
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = defaultBaseBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+	if cfg.JobTimeout <= 0 {
+		cfg.JobTimeout = defaultJobTimeout
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Queue{
+		cfg:      cfg,
+		wake:     make(chan struct{}, 1),
+		due:      make(chan Job),
+		inFlight: make(map[string]struct{}),
+	}
+}
+
+// Store returns the store backing the queue.
+func (q *Queue) Store() *Store {
+	return q.cfg.Store
+}
+
+// Start launches the scheduler and workers. Jobs left pending by a previous run are
+// picked up first.
+func (q *Queue) Start(ctx context.Context) {
+	runCtx, cancel := context.WithCancel(ctx)
+	q.cancel = cancel
+	q.wg.Add(1 + q.cfg.Workers)
+	go func() {
+		defer q.wg.Done()
+		q.schedule(runCtx)
+	}()
+	for i := 0; i < q.cfg.Workers; i++ {
+		go func() {
+			defer q.wg.Done()
+			q.worker(runCtx)
+		}()
+	}
+}
+
+// Stop cancels the scheduler and workers and waits for running attempts to return.
+func (q *Queue) Stop() {
+	if q == nil || q.cancel == nil {
+		return
+	}
+	q.cancel()
+	q.wg.Wait()
+}
+
+// Enqueue persists a job of kind with payload encoded as JSON and wakes the workers.
+func (q *Queue) Enqueue(kind string, payload any) (Job, error) {
+	if q == nil {
+		return Job{}, errors.New("job queue is nil")
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Job{}, fmt.Errorf("encode %s job: %w", kind, err)
+	}
+	job, err := q.cfg.Store.Add(Job{ID: q.nextID(), Kind: kind, Payload: data})
+	if err != nil {
+		return Job{}, fmt.Errorf("enqueue %s job: %w", kind, err)
+	}
+	q.nudge()
+	return job, nil
+}
+
+// Requeue moves a dead-lettered job back to the pending list and wakes the workers.
+func (q *Queue) Requeue(id string) (Job, error) {
+	if q == nil {
+		return Job{}, errors.New("job queue is nil")
+	}
+	job, err := q.cfg.Store.Requeue(id)
+	if err != nil {
+		return Job{}, err
+	}
+	q.nudge()
+	return job, nil
+}
+
+// List returns the pending and dead-lettered jobs.
+func (q *Queue) List() (File, error) {
+	if q == nil {
+		return File{}, errors.New("job queue is nil")
+	}
+	return q.cfg.Store.List()
+}
+
+func (q *Queue) nextID() string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.seq++
+	return fmt.Sprintf("job_%d_%d", q.cfg.Now().UnixNano(), q.seq)
+}
+
+func (q *Queue) nudge() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// schedule hands due jobs to idle workers, one at a time, skipping jobs already running.
+func (q *Queue) schedule(ctx context.Context) {
+	ticker := time.NewTicker(q.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		q.dispatchDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-q.wake:
+		}
+	}
+}
+
+func (q *Queue) dispatchDue(ctx context.Context) {
+	file, err := q.cfg.Store.List()
+	if err != nil {
+		q.logf("Jobs: read queue: %v", err)
+		return
+	}
+	now := q.cfg.Now()
+	for _, job := range file.Pending {
+		if job.NextAttemptAt.After(now) || !q.claim(job.ID) {
+			continue
+		}
+		select {
+		case q.due <- job:
+		case <-ctx.Done():
+			q.release(job.ID)
+			return
+		}
+	}
+}
+
+func (q *Queue) claim(id string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, busy := q.inFlight[id]; busy {
+		return false
+	}
+	q.inFlight[id] = struct{}{}
+	return true
+}
+
+func (q *Queue) release(id string) {
+	q.mu.Lock()
+	delete(q.inFlight, id)
+	q.mu.Unlock()
+}
+
+func (q *Queue) worker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-q.due:
+			q.run(ctx, job)
+			q.release(job.ID)
+		}
+	}
+}
+
+// run makes one attempt at job and records the outcome. The scheduler works from a
+// snapshot, so the job is re-read first in case another worker finished it meanwhile.
+func (q *Queue) run(ctx context.Context, job Job) {
+	current, err := q.cfg.Store.Pending(job.ID)
+	if err != nil {
+		q.record(err, job.ID)
+		return
+	}
+	if current.NextAttemptAt.After(q.cfg.Now()) {
+		return
+	}
+	job = current
+	handler, ok := q.cfg.Handlers[job.Kind]
+	if !ok {
+		q.logf("Jobs: dead-lettering %s with unknown kind %q", job.ID, job.Kind)
+		q.record(q.cfg.Store.Bury(job.ID, job.Attempts, fmt.Sprintf("no handler for kind %q", job.Kind)), job.ID)
+		return
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, q.cfg.JobTimeout)
+	err = handler.Handle(runCtx, job)
+	cancel()
+	if err == nil {
+		q.logf("Jobs: %s job %s succeeded after %d attempt(s)", job.Kind, job.ID, job.Attempts+1)
+		q.record(q.cfg.Store.Complete(job.ID), job.ID)
+		return
+	}
+	if ctx.Err() != nil {
+		// Shutting down: leave the attempt unrecorded so it runs again after restart.
+		return
+	}
+
+	attempts := job.Attempts + 1
+	var permanent *PermanentError
+	if errors.As(err, &permanent) || attempts >= q.cfg.MaxAttempts {
+		q.logf("Jobs: dead-lettering %s job %s after %d attempt(s): %v", job.Kind, job.ID, attempts, err)
+		q.record(q.cfg.Store.Bury(job.ID, attempts, err.Error()), job.ID)
+		return
+	}
+	next := q.cfg.Now().Add(q.backoff(attempts))
+	q.logf("Jobs: attempt %d of %s job %s failed, retrying at %s: %v", attempts, job.Kind, job.ID, next.Format(time.RFC3339), err)
+	q.record(q.cfg.Store.Retry(job.ID, attempts, next, err.Error()), job.ID)
+}
+
+func (q *Queue) record(err error, id string) {
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		q.logf("Jobs: update %s: %v", id, err)
+	}
+}
+
+// backoff doubles BaseBackoff for every failed attempt, capped at MaxBackoff.
+func (q *Queue) backoff(attempts int) time.Duration {
+	wait := q.cfg.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= q.cfg.MaxBackoff {
+			return q.cfg.MaxBackoff
+		}
+	}
+	return wait
+}
+
+func (q *Queue) logf(format string, args ...any) {
+	if q.cfg.Logger != nil {
+		q.cfg.Logger.Printf(format, args...)
+	}
+}
@@ -0,0 +1,135 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestQueueRetriesThenDeadLetters(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "jobs.json"))
+	var mu sync.Mutex
+	calls := map[string]int{}
+	handler := HandlerFunc(func(ctx context.Context, job Job) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[string(job.Payload)]++
+		switch string(job.Payload) {
+		case `"flaky"`:
+			if calls[`"flaky"`] < 2 {
+				return errors.New("try again")
+			}
+			return nil
+		case `"broken"`:
+			return Permanent(errors.New("cannot parse"))
+		default:
+			return errors.New("always fails")
+		}
+	})
+	queue := NewQueue(QueueConfig{
+		Store:        store,
+		Handlers:     map[string]Handler{"test": handler},
+		Workers:      3,
+		PollInterval: 10 * time.Millisecond,
+		MaxAttempts:  3,
+		BaseBackoff:  time.Millisecond,
+		MaxBackoff:   time.Millisecond,
+	})
+	for _, payload := range []string{"flaky", "broken", "doomed"} {
+		if _, err := queue.Enqueue("test", payload); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+	if _, err := queue.Enqueue("unknown", "x"); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	queue.Start(context.Background())
+	defer queue.Stop()
+
+	waitFor(t, "queue to settle", func() bool {
+		file, err := queue.List()
+		return err == nil && len(file.Pending) == 0 && len(file.Dead) == 3
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls[`"flaky"`] != 2 || calls[`"broken"`] != 1 || calls[`"doomed"`] != 3 {
+		t.Fatalf("unexpected attempt counts %v", calls)
+	}
+	file, _ := queue.List()
+	for _, job := range file.Dead {
+		if job.Kind == "test" && string(job.Payload) == `"doomed"` && (job.Attempts != 3 || job.LastError != "always fails") {
+			t.Fatalf("unexpected dead job %+v", job)
+		}
+	}
+}
+
+func TestQueueRequeueRunsDeadJobAgain(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "jobs.json"))
+	var mu sync.Mutex
+	healthy := false
+	runs := 0
+	queue := NewQueue(QueueConfig{
+		Store: store,
+		Handlers: map[string]Handler{"test": HandlerFunc(func(context.Context, Job) error {
+			mu.Lock()
+			defer mu.Unlock()
+			runs++
+			if !healthy {
+				return errors.New("down")
+			}
+			return nil
+		})},
+		PollInterval: 10 * time.Millisecond,
+		MaxAttempts:  1,
+	})
+	job, err := queue.Enqueue("test", "payload")
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	queue.Start(context.Background())
+	defer queue.Stop()
+	waitFor(t, "job to be dead-lettered", func() bool {
+		file, _ := queue.List()
+		return len(file.Dead) == 1
+	})
+
+	mu.Lock()
+	healthy = true
+	mu.Unlock()
+	if _, err := queue.Requeue(job.ID); err != nil {
+		t.Fatalf("requeue: %v", err)
+	}
+	waitFor(t, "requeued job to complete", func() bool {
+		file, _ := queue.List()
+		return len(file.Dead) == 0 && len(file.Pending) == 0
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	if runs != 2 {
+		t.Fatalf("expected two runs, got %d", runs)
+	}
+}
+
+func TestQueueBackoffIsCapped(t *testing.T) {
+	queue := NewQueue(QueueConfig{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second})
+	for attempts, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		if got := queue.backoff(attempts); got != want {
+			t.Fatalf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
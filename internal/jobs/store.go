@@ -0,0 +1,334 @@
+// Package jobs persists background work to disk and runs it on a worker pool with
+// exponential backoff, moving jobs that keep failing to a dead-letter list.
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"live-stream-alerts/internal/filestore"
+)
+
+const (
+	// DefaultFilePath is where queued and dead-lettered jobs are stored.
+	DefaultFilePath = "data/jobs.json"
+	// DefaultDeadLetterLimit caps how many dead-lettered jobs are kept by default.
+	DefaultDeadLetterLimit = 500
+)
+
+// ErrNotFound indicates the job does not exist in the list it was looked up in.
+var ErrNotFound = errors.New("job not found")
+
+// Job is a unit of work of a given kind. Payload is decoded by the kind's handler.
+type Job struct {
+	ID            string          `json:"id"`
+	Kind          string          `json:"kind"`
+	Payload       json.RawMessage `json:"payload"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"nextAttemptAt"`
+	LastError     string          `json:"lastError,omitempty"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	UpdatedAt     time.Time       `json:"updatedAt"`
+	// FailedAt is set while the job sits in the dead-letter list.
+	FailedAt time.Time `json:"failedAt,omitempty"`
+}
+
+// File is the on-disk job queue format.
+type File struct {
+	Pending []Job `json:"pending"`
+	Dead    []Job `json:"dead"`
+}
+
+// Store persists jobs to disk behind a per-path mutex.
+type Store struct {
+	path        string
+	mu          sync.Mutex
+	now         func() time.Time
+	deadLimit   int
+	backups     filestore.Backups
+	lockTimeout time.Duration
+}
+
+// StoreOption customises the store behaviour.
+type StoreOption func(*Store)
+
+// WithNow overrides the clock used for job timestamps.
+func WithNow(fn func() time.Time) StoreOption {
+	return func(s *Store) {
+		if fn != nil {
+			s.now = fn
+		}
+	}
+}
+
+// WithDeadLetterLimit caps the dead-letter list; the oldest entries are dropped first.
+// Zero or a negative value keeps every dead job.
+func WithDeadLetterLimit(limit int) StoreOption {
+	return func(s *Store) {
+		s.deadLimit = limit
+	}
+}
+
+// WithBackups overrides where backups are written and how many are retained.
+func WithBackups(backups filestore.Backups) StoreOption {
+	return func(s *Store) {
+		s.backups = backups
+	}
+}
+
+// WithLockTimeout bounds how long writes wait for another process holding the file lock.
+func WithLockTimeout(timeout time.Duration) StoreOption {
+	return func(s *Store) {
+		s.lockTimeout = timeout
+	}
+}
+
+// NewStore returns a file-backed job store for the provided path.
+func NewStore(path string, opts ...StoreOption) *Store {
+	if path == "" {
+		path = DefaultFilePath
+	}
+	store := &Store{
+		path:      filepath.Clean(path),
+		now:       time.Now,
+		deadLimit: DefaultDeadLetterLimit,
+	}
+	for _, opt := range opts {
+		opt(store)
+	}
+	return store
+}
+
+// Path returns the path backing the store.
+func (s *Store) Path() string {
+	if s == nil {
+		return ""
+	}
+	return s.path
+}
+
+// Add appends job to the pending list. CreatedAt, UpdatedAt and a zero NextAttemptAt
+// default to now.
+func (s *Store) Add(job Job) (Job, error) {
+	if s == nil {
+		return Job{}, errors.New("jobs store is nil")
+	}
+	if job.ID == "" || job.Kind == "" {
+		return Job{}, errors.New("job id and kind are required")
+	}
+	now := s.now().UTC()
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = now
+	}
+	if job.NextAttemptAt.IsZero() {
+		job.NextAttemptAt = now
+	}
+	job.UpdatedAt = now
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.updateFileLocked(func(file *File) error {
+		file.Pending = append(file.Pending, job)
+		return nil
+	})
+	if err != nil {
+		return Job{}, err
+	}
+	return job, nil
+}
+
+// List returns the pending and dead-lettered jobs.
+func (s *Store) List() (File, error) {
+	if s == nil {
+		return File{}, errors.New("jobs store is nil")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return readFile(s.path)
+}
+
+// Pending returns the pending job with the given ID.
+func (s *Store) Pending(id string) (Job, error) {
+	file, err := s.List()
+	if err != nil {
+		return Job{}, err
+	}
+	for _, job := range file.Pending {
+		if job.ID == id {
+			return job, nil
+		}
+	}
+	return Job{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+// Complete removes a pending job that succeeded.
+func (s *Store) Complete(id string) error {
+	return s.updatePending(id, func(file *File, idx int) {
+		file.Pending = append(file.Pending[:idx], file.Pending[idx+1:]...)
+	})
+}
+
+// Retry records a failed attempt of a pending job and when to try it next.
+func (s *Store) Retry(id string, attempts int, next time.Time, lastErr string) error {
+	now := s.now().UTC()
+	return s.updatePending(id, func(file *File, idx int) {
+		job := &file.Pending[idx]
+		job.Attempts = attempts
+		job.NextAttemptAt = next.UTC()
+		job.LastError = lastErr
+		job.UpdatedAt = now
+	})
+}
+
+// Bury moves a pending job to the dead-letter list.
+func (s *Store) Bury(id string, attempts int, lastErr string) error {
+	now := s.now().UTC()
+	return s.updatePending(id, func(file *File, idx int) {
+		job := file.Pending[idx]
+		job.Attempts = attempts
+		job.LastError = lastErr
+		job.UpdatedAt = now
+		job.FailedAt = now
+		file.Pending = append(file.Pending[:idx], file.Pending[idx+1:]...)
+		file.Dead = append(file.Dead, job)
+		if s.deadLimit > 0 && len(file.Dead) > s.deadLimit {
+			file.Dead = append([]Job(nil), file.Dead[len(file.Dead)-s.deadLimit:]...)
+		}
+	})
+}
+
+// Requeue moves a dead-lettered job back to the pending list with a fresh attempt
+// budget, due immediately. The last error is kept for reference.
+func (s *Store) Requeue(id string) (Job, error) {
+	if s == nil {
+		return Job{}, errors.New("jobs store is nil")
+	}
+	now := s.now().UTC()
+	var requeued Job
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.updateFileLocked(func(file *File) error {
+		for i, job := range file.Dead {
+			if job.ID != id {
+				continue
+			}
+			job.Attempts = 0
+			job.NextAttemptAt = now
+			job.UpdatedAt = now
+			job.FailedAt = time.Time{}
+			file.Dead = append(file.Dead[:i], file.Dead[i+1:]...)
+			file.Pending = append(file.Pending, job)
+			requeued = job
+			return nil
+		}
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	})
+	if err != nil {
+		return Job{}, err
+	}
+	return requeued, nil
+}
+
+func (s *Store) updatePending(id string, fn func(*File, int)) error {
+	if s == nil {
+		return errors.New("jobs store is nil")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updateFileLocked(func(file *File) error {
+		for i := range file.Pending {
+			if file.Pending[i].ID == id {
+				fn(file, i)
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	})
+}
+
+// updateFileLocked runs a read-modify-write cycle. The caller holds s.mu, which serialises
+// goroutines; the advisory file lock extends that to other processes sharing the file.
+func (s *Store) updateFileLocked(updateFn func(*File) error) error {
+	lock, err := filestore.LockFile(s.path, s.lockTimeout)
+	if err != nil {
+		return fmt.Errorf("lock jobs file: %w", err)
+	}
+	defer lock.Unlock()
+
+	file, err := readFile(s.path)
+	if err != nil {
+		return err
+	}
+	if err := updateFn(&file); err != nil {
+		return err
+	}
+	data, err := writeFile(s.path, file)
+	if err != nil {
+		return err
+	}
+	// The data is already durable; a failed backup must not turn the write into an error.
+	_, _ = s.backups.Save(s.path, data)
+	return nil
+}
+
+// Recover checks that the jobs file decodes and, when it does not, restores the newest
+// backup that does. Read errors are returned without restoring anything. It returns the
+// restored backup path, or "" when the file was readable.
+func (s *Store) Recover() (string, error) {
+	if s == nil {
+		return "", errors.New("jobs store is nil")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, err := filestore.LockFile(s.path, s.lockTimeout)
+	if err != nil {
+		return "", fmt.Errorf("lock jobs file: %w", err)
+	}
+	defer lock.Unlock()
+	return s.backups.RecoverCorrupt(s.path, func(data []byte) error {
+		var file File
+		if err := json.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("decode jobs file: %w", err)
+		}
+		return nil
+	})
+}
+
+func readFile(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return File{Pending: []Job{}, Dead: []Job{}}, nil
+		}
+		return File{}, err
+	}
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return File{}, fmt.Errorf("decode jobs file: %w", err)
+	}
+	if file.Pending == nil {
+		file.Pending = []Job{}
+	}
+	if file.Dead == nil {
+		file.Dead = []Job{}
+	}
+	return file, nil
+}
+
+func writeFile(path string, file File) ([]byte, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create jobs dir: %w", err)
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode jobs file: %w", err)
+	}
+	if err := filestore.WriteAtomic(path, data, 0o644); err != nil {
+		return nil, fmt.Errorf("write jobs file: %w", err)
+	}
+	return data, nil
+}
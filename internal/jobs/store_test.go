@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"live-stream-alerts/internal/filestore"
+)
+
+func TestStoreLifecycle(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewStore(filepath.Join(t.TempDir(), "jobs.json"), WithNow(func() time.Time { return now }), WithDeadLetterLimit(1))
+
+	for _, id := range []string{"a", "b", "c"} {
+		if _, err := store.Add(Job{ID: id, Kind: "test"}); err != nil {
+			t.Fatalf("add %s: %v", id, err)
+		}
+	}
+	next := now.Add(time.Minute)
+	if err := store.Retry("a", 1, next, "boom"); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	job, err := store.Pending("a")
+	if err != nil || job.Attempts != 1 || !job.NextAttemptAt.Equal(next) || job.LastError != "boom" {
+		t.Fatalf("unexpected retried job %+v %v", job, err)
+	}
+	if err := store.Complete("c"); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if err := store.Bury("a", 2, "still failing"); err != nil {
+		t.Fatalf("bury a: %v", err)
+	}
+	if err := store.Bury("b", 8, "gave up"); err != nil {
+		t.Fatalf("bury b: %v", err)
+	}
+
+	file, err := store.List()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(file.Pending) != 0 {
+		t.Fatalf("expected no pending jobs, got %+v", file.Pending)
+	}
+	if len(file.Dead) != 1 || file.Dead[0].ID != "b" || file.Dead[0].FailedAt.IsZero() {
+		t.Fatalf("expected the dead-letter limit to keep only the newest job, got %+v", file.Dead)
+	}
+
+	if _, err := store.Requeue("a"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected trimmed job to be gone, got %v", err)
+	}
+	requeued, err := store.Requeue("b")
+	if err != nil {
+		t.Fatalf("requeue: %v", err)
+	}
+	if requeued.Attempts != 0 || !requeued.FailedAt.IsZero() || requeued.LastError != "gave up" {
+		t.Fatalf("unexpected requeued job %+v", requeued)
+	}
+	if err := store.Complete("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStoreRecoverRestoresBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "jobs.json")
+	store := NewStore(path, WithBackups(filestore.Backups{Dir: filepath.Join(dir, "bak"), Retention: 2}))
+	if _, err := store.Add(Job{ID: "a", Kind: "test"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatalf("corrupt: %v", err)
+	}
+	restored, err := store.Recover()
+	if err != nil || restored == "" {
+		t.Fatalf("expected a backup to be restored, got %q %v", restored, err)
+	}
+	if _, err := store.Pending("a"); err != nil {
+		t.Fatalf("expected restored job, got %v", err)
+	}
+}
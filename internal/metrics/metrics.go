@@ -0,0 +1,240 @@
+// Package metrics exposes Prometheus metrics for WebSub notifications, watch-page
+// lookups, hub requests, lease renewals, and the current lease and live state.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"live-stream-alerts/internal/logging"
+	"live-stream-alerts/internal/platforms/youtube/monitoring"
+	"live-stream-alerts/internal/streamers"
+)
+
+const (
+	namespace      = "live_stream_alerts"
+	collectTimeout = 5 * time.Second
+)
+
+// OverviewSource summarises YouTube lease health. monitoring.Service satisfies it.
+type OverviewSource interface {
+	Overview(ctx context.Context) (monitoring.Overview, error)
+}
+
+// Options configures the gauges computed at scrape time.
+type Options struct {
+	// Overview feeds the lease-status gauges; they are omitted when nil.
+	Overview OverviewSource
+	// Streamers feeds the live-streamers gauge; it is omitted when nil.
+	Streamers streamers.Repository
+	Logger    logging.Logger
+}
+
+// Metrics owns a dedicated registry so tests and multiple routers never collide on the
+// process-wide default one. All methods are safe to call on a nil *Metrics.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	notificationsReceived *prometheus.CounterVec
+	notificationsRejected *prometheus.CounterVec
+	fetchDuration         *prometheus.HistogramVec
+	hubRequests           *prometheus.CounterVec
+	renewals              *prometheus.CounterVec
+}
+
+// New registers every metric on a fresh registry.
+func New(opts Options) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		notificationsReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "websub_notifications_received_total",
+			Help:      "Push notifications received on /alerts, by platform.",
+		}, []string{"platform"}),
+		notificationsRejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "websub_notifications_rejected_total",
+			Help:      "Push notifications on /alerts that were not processed, by platform and reason.",
+		}, []string{"platform", "reason"}),
+		fetchDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "liveinfo_fetch_duration_seconds",
+			Help:      "Latency of YouTube watch-page lookups, by outcome.",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 5, 10},
+		}, []string{"outcome"}),
+		hubRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "hub_requests_total",
+			Help:      "WebSub hub subscribe/unsubscribe requests, by mode and response status.",
+		}, []string{"mode", "status"}),
+		renewals: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "lease_renewals_total",
+			Help:      "Subscription renewals started by the platform monitor, by platform and result.",
+		}, []string{"platform", "result"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.notificationsReceived,
+		m.notificationsRejected,
+		m.fetchDuration,
+		m.hubRequests,
+		m.renewals,
+		&stateCollector{opts: opts},
+	)
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	if m == nil {
+		return http.NotFoundHandler()
+	}
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Registry exposes the underlying registry, e.g. for tests.
+func (m *Metrics) Registry() *prometheus.Registry {
+	if m == nil {
+		return nil
+	}
+	return m.registry
+}
+
+// ObserveNotification records a push notification and, when status is not a success,
+// its rejection reason.
+func (m *Metrics) ObserveNotification(platform string, status int) {
+	if m == nil {
+		return
+	}
+	if platform == "" {
+		platform = "unknown"
+	}
+	m.notificationsReceived.WithLabelValues(platform).Inc()
+	if reason := rejectionReason(status); reason != "" {
+		m.notificationsRejected.WithLabelValues(platform, reason).Inc()
+	}
+}
+
+func rejectionReason(status int) string {
+	switch {
+	case status < 400:
+		return ""
+	case status == http.StatusForbidden:
+		return "signature"
+	case status == http.StatusBadRequest:
+		return "invalid"
+	case status == http.StatusMethodNotAllowed:
+		return "unknown_sender"
+	case status >= 500:
+		return "error"
+	default:
+		return strconv.Itoa(status)
+	}
+}
+
+// ObserveFetch records one watch-page lookup.
+func (m *Metrics) ObserveFetch(duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	m.fetchDuration.WithLabelValues(outcome).Observe(duration.Seconds())
+}
+
+// ObserveRenewal records the result of a subscription renewal.
+func (m *Metrics) ObserveRenewal(platform string, err error) {
+	if m == nil {
+		return
+	}
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	m.renewals.WithLabelValues(platform, result).Inc()
+}
+
+func (m *Metrics) observeHubResponse(mode string, status string) {
+	if m == nil {
+		return
+	}
+	if mode == "" {
+		mode = "unknown"
+	}
+	m.hubRequests.WithLabelValues(mode, status).Inc()
+}
+
+var (
+	leasesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "youtube_leases"),
+		"YouTube hub leases by status, as reported by the lease overview.",
+		[]string{"status"}, nil,
+	)
+	liveStreamersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "live_streamers"),
+		"Streamers currently live on at least one platform.",
+		nil, nil,
+	)
+)
+
+// stateCollector derives gauges from the stores on every scrape, so they never drift
+// from what the admin overview reports.
+type stateCollector struct {
+	opts Options
+}
+
+func (c *stateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- leasesDesc
+	ch <- liveStreamersDesc
+}
+
+func (c *stateCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.opts.Overview != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+		overview, err := c.opts.Overview.Overview(ctx)
+		cancel()
+		if err != nil {
+			c.logf("metrics: lease overview failed: %v", err)
+		} else {
+			summary := overview.Summary
+			for status, count := range map[monitoring.LeaseStatus]int{
+				monitoring.LeaseStatusHealthy:  summary.Healthy,
+				monitoring.LeaseStatusRenewing: summary.Renewing,
+				monitoring.LeaseStatusExpired:  summary.Expired,
+				monitoring.LeaseStatusPending:  summary.Pending,
+			} {
+				ch <- prometheus.MustNewConstMetric(leasesDesc, prometheus.GaugeValue, float64(count), string(status))
+			}
+		}
+	}
+	if c.opts.Streamers != nil {
+		records, err := c.opts.Streamers.List()
+		if err != nil {
+			c.logf("metrics: failed to list streamers: %v", err)
+			return
+		}
+		live := 0
+		for _, record := range records {
+			if record.Status != nil && record.Status.Live {
+				live++
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(liveStreamersDesc, prometheus.GaugeValue, float64(live))
+	}
+}
+
+func (c *stateCollector) logf(format string, args ...any) {
+	if c.opts.Logger != nil {
+		c.opts.Logger.Printf(format, args...)
+	}
+}
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"live-stream-alerts/internal/platforms/youtube/monitoring"
+	"live-stream-alerts/internal/streamers"
+)
+
+type fakeOverview struct {
+	summary monitoring.Summary
+}
+
+func (f fakeOverview) Overview(context.Context) (monitoring.Overview, error) {
+	return monitoring.Overview{Summary: f.summary}, nil
+}
+
+func TestObserveNotificationCountsRejections(t *testing.T) {
+	m := New(Options{})
+	m.ObserveNotification("youtube", http.StatusNoContent)
+	m.ObserveNotification("youtube", http.StatusForbidden)
+	m.ObserveNotification("", http.StatusMethodNotAllowed)
+
+	if got := testutil.ToFloat64(m.notificationsReceived.WithLabelValues("youtube")); got != 2 {
+		t.Fatalf("expected 2 youtube notifications, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.notificationsRejected.WithLabelValues("youtube", "signature")); got != 1 {
+		t.Fatalf("expected 1 signature rejection, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.notificationsRejected.WithLabelValues("unknown", "unknown_sender")); got != 1 {
+		t.Fatalf("expected 1 unknown sender rejection, got %v", got)
+	}
+}
+
+func TestObserveFetchAndRenewal(t *testing.T) {
+	m := New(Options{})
+	m.ObserveFetch(200*time.Millisecond, nil)
+	m.ObserveFetch(time.Second, errors.New("boom"))
+	m.ObserveRenewal("youtube", nil)
+	m.ObserveRenewal("youtube", errors.New("hub down"))
+
+	if got := testutil.CollectAndCount(m.fetchDuration); got != 2 {
+		t.Fatalf("expected success and error series, got %d", got)
+	}
+	if got := testutil.ToFloat64(m.renewals.WithLabelValues("youtube", "failure")); got != 1 {
+		t.Fatalf("expected 1 failed renewal, got %v", got)
+	}
+}
+
+func TestNilMetricsIsNoop(t *testing.T) {
+	var m *Metrics
+	m.ObserveNotification("youtube", http.StatusOK)
+	m.ObserveFetch(time.Second, nil)
+	m.ObserveRenewal("youtube", nil)
+	if m.HubTransport(nil, "https://hub.example.com") != http.DefaultTransport {
+		t.Fatalf("expected nil metrics to leave the transport untouched")
+	}
+}
+
+func TestHubTransportCountsByModeAndStatus(t *testing.T) {
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/other" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer hub.Close()
+
+	m := New(Options{})
+	client := &http.Client{Transport: m.HubTransport(nil, hub.URL+"/subscribe")}
+	form := url.Values{"hub.mode": {"subscribe"}, "hub.topic": {"https://example.com/feed"}}
+	for _, target := range []string{hub.URL + "/subscribe", hub.URL + "/other"} {
+		resp, err := client.Post(target, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+		if err != nil {
+			t.Fatalf("post %s: %v", target, err)
+		}
+		resp.Body.Close()
+	}
+
+	if got := testutil.ToFloat64(m.hubRequests.WithLabelValues("subscribe", "202")); got != 1 {
+		t.Fatalf("expected 1 accepted subscribe, got %v", got)
+	}
+	if got := testutil.CollectAndCount(m.hubRequests); got != 1 {
+		t.Fatalf("requests to other paths must not be counted, got %d series", got)
+	}
+}
+
+func TestStateGaugesReflectStores(t *testing.T) {
+	dir := t.TempDir()
+	store := streamers.NewStore(dir + "/streamers.json")
+	if _, err := store.Append(streamers.Record{Streamer: streamers.Streamer{Alias: "Live"}, Status: &streamers.Status{Live: true}}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if _, err := store.Append(streamers.Record{Streamer: streamers.Streamer{Alias: "Offline"}}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	m := New(Options{
+		Overview:  fakeOverview{summary: monitoring.Summary{Total: 3, Healthy: 2, Expired: 1}},
+		Streamers: store,
+	})
+
+	expected := `
+# HELP live_stream_alerts_live_streamers Streamers currently live on at least one platform.
+# TYPE live_stream_alerts_live_streamers gauge
+live_stream_alerts_live_streamers 1
+# HELP live_stream_alerts_youtube_leases YouTube hub leases by status, as reported by the lease overview.
+# TYPE live_stream_alerts_youtube_leases gauge
+live_stream_alerts_youtube_leases{status="expired"} 1
+live_stream_alerts_youtube_leases{status="healthy"} 2
+live_stream_alerts_youtube_leases{status="pending"} 0
+live_stream_alerts_youtube_leases{status="renewing"} 0
+`
+	if err := testutil.GatherAndCompare(m.Registry(), strings.NewReader(expected), "live_stream_alerts_live_streamers", "live_stream_alerts_youtube_leases"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// HubTransport wraps base so that POSTs to hubURL are counted by hub.mode and response
+// status. Other requests pass through untouched, so the same client can also scrape
+// channel pages.
+func (m *Metrics) HubTransport(base http.RoundTripper, hubURL string) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if m == nil {
+		return base
+	}
+	hub, err := url.Parse(strings.TrimSpace(hubURL))
+	if err != nil || hub.Host == "" {
+		return base
+	}
+	return &hubTransport{base: base, metrics: m, hub: hub}
+}
+
+type hubTransport struct {
+	base    http.RoundTripper
+	metrics *Metrics
+	hub     *url.URL
+}
+
+func (t *hubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodPost || !strings.EqualFold(req.URL.Host, t.hub.Host) || req.URL.Path != t.hub.Path {
+		return t.base.RoundTrip(req)
+	}
+	mode := hubMode(req)
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.metrics.observeHubResponse(mode, "error")
+		return resp, err
+	}
+	t.metrics.observeHubResponse(mode, strconv.Itoa(resp.StatusCode))
+	return resp, nil
+}
+
+// hubMode reads hub.mode from a copy of the form body.
+func hubMode(req *http.Request) string {
+	if req.GetBody == nil {
+		return ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+	data, err := io.ReadAll(io.LimitReader(body, 64<<10))
+	if err != nil {
+		return ""
+	}
+	form, err := url.ParseQuery(string(data))
+	if err != nil {
+		return ""
+	}
+	switch mode := form.Get("hub.mode"); mode {
+	case "subscribe", "unsubscribe":
+		return mode
+	default:
+		return ""
+	}
+}
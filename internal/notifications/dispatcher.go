@@ -0,0 +1,247 @@
+// Package notifications fans go-live alerts out to Discord, Slack and generic
+// webhooks through a persisted outbox with retries.
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"live-stream-alerts/internal/logging"
+)
+
+const (
+	defaultPollInterval = 15 * time.Second
+	defaultMaxAttempts  = 6
+	defaultBaseBackoff  = 30 * time.Second
+	defaultMaxBackoff   = 30 * time.Minute
+	defaultSendTimeout  = 15 * time.Second
+)
+
+// Alert describes a streamer going live on a platform. Its fields are available to
+// sink templates, e.g. {{.Alias}} or {{.URL}}.
+type Alert struct {
+	StreamerID string    `json:"streamerId"`
+	Alias      string    `json:"alias"`
+	Platform   string    `json:"platform"`
+	ChannelID  string    `json:"channelId,omitempty"`
+	VideoID    string    `json:"videoId,omitempty"`
+	Title      string    `json:"title,omitempty"`
+	URL        string    `json:"url,omitempty"`
+	StartedAt  time.Time `json:"startedAt,omitempty"`
+}
+
+// key identifies the broadcast so repeated notifications for it are collapsed.
+func (a Alert) key() string {
+	id := strings.TrimSpace(a.VideoID)
+	if id == "" {
+		return ""
+	}
+	return a.Platform + ":" + id
+}
+
+// DispatcherConfig configures the alert dispatcher.
+type DispatcherConfig struct {
+	Outbox       *Outbox
+	Sinks        []Sink
+	Logger       logging.Logger
+	PollInterval time.Duration
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	Now          func() time.Time
+}
+
+// Dispatcher persists alerts to the outbox and delivers them from a background loop.
+type Dispatcher struct {
+	cfg    DispatcherConfig
+	sinks  map[string]Sink
+	wake   chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	seq    uint64
+	seqMu  sync.Mutex
+}
+
+// NewDispatcher builds a Dispatcher without starting its delivery loop.
+func NewDispatcher(cfg DispatcherConfig) *Dispatcher {
+	if cfg.Outbox == nil {
+		cfg.Outbox = NewOutbox(DefaultOutboxPath)
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = defaultBaseBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	sinks := make(map[string]Sink, len(cfg.Sinks))
+	for _, sink := range cfg.Sinks {
+		sinks[sink.Name()] = sink
+	}
+	return &Dispatcher{cfg: cfg, sinks: sinks, wake: make(chan struct{}, 1)}
+}
+
+// Start launches the delivery loop. Entries left in the outbox by a previous run are
+// delivered first.
+func (d *Dispatcher) Start(ctx context.Context) {
+	runCtx, cancel := context.WithCancel(ctx)
+	d.cancel = cancel
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.run(runCtx)
+	}()
+}
+
+// Stop cancels the delivery loop and waits for it to exit.
+func (d *Dispatcher) Stop() {
+	if d == nil || d.cancel == nil {
+		return
+	}
+	d.cancel()
+	d.wg.Wait()
+}
+
+// Notify queues alert for every configured sink. Alerts for a broadcast that was
+// already queued are ignored.
+func (d *Dispatcher) Notify(ctx context.Context, alert Alert) error {
+	if d == nil || len(d.sinks) == 0 {
+		return nil
+	}
+	now := d.cfg.Now().UTC()
+	entries := make([]Entry, 0, len(d.cfg.Sinks))
+	for _, sink := range d.cfg.Sinks {
+		entries = append(entries, Entry{
+			ID:            d.nextID(now),
+			Sink:          sink.Name(),
+			Alert:         alert,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+	}
+	added, err := d.cfg.Outbox.Enqueue(alert.key(), entries, now)
+	if err != nil {
+		return fmt.Errorf("enqueue alert: %w", err)
+	}
+	if added {
+		select {
+		case d.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) nextID(now time.Time) string {
+	d.seqMu.Lock()
+	defer d.seqMu.Unlock()
+	d.seq++
+	return fmt.Sprintf("ntf_%d_%d", now.UnixNano(), d.seq)
+}
+
+func (d *Dispatcher) run(ctx context.Context) {
+	d.deliverDue(ctx)
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+		d.deliverDue(ctx)
+	}
+}
+
+// deliverDue attempts every entry whose NextAttemptAt has passed and returns the
+// number delivered successfully.
+func (d *Dispatcher) deliverDue(ctx context.Context) int {
+	entries, err := d.cfg.Outbox.List()
+	if err != nil {
+		d.logf("Notifications: read outbox: %v", err)
+		return 0
+	}
+	delivered := 0
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return delivered
+		}
+		if entry.NextAttemptAt.After(d.cfg.Now()) {
+			continue
+		}
+		if d.deliver(ctx, entry) {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, entry Entry) bool {
+	sink, ok := d.sinks[entry.Sink]
+	if !ok {
+		d.logf("Notifications: dropping %s for unknown sink %q", entry.ID, entry.Sink)
+		d.remove(entry.ID)
+		return false
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, defaultSendTimeout)
+	err := sink.Send(sendCtx, entry.Alert)
+	cancel()
+	if err == nil {
+		d.logf("Notifications: delivered %s alert for %s to %s", entry.Alert.Platform, entry.Alert.StreamerID, entry.Sink)
+		d.remove(entry.ID)
+		return true
+	}
+
+	attempts := entry.Attempts + 1
+	var permanent *PermanentError
+	if errors.As(err, &permanent) || attempts >= d.cfg.MaxAttempts {
+		d.logf("Notifications: giving up on %s to %s after %d attempt(s): %v", entry.ID, entry.Sink, attempts, err)
+		d.remove(entry.ID)
+		return false
+	}
+	next := d.cfg.Now().Add(d.backoff(attempts))
+	d.logf("Notifications: attempt %d for %s to %s failed, retrying at %s: %v", attempts, entry.ID, entry.Sink, next.Format(time.RFC3339), err)
+	if err := d.cfg.Outbox.Reschedule(entry.ID, attempts, next, err.Error()); err != nil {
+		d.logf("Notifications: reschedule %s: %v", entry.ID, err)
+	}
+	return false
+}
+
+// backoff doubles BaseBackoff for every failed attempt, capped at MaxBackoff.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.cfg.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= d.cfg.MaxBackoff {
+			return d.cfg.MaxBackoff
+		}
+	}
+	return wait
+}
+
+func (d *Dispatcher) remove(id string) {
+	if err := d.cfg.Outbox.Remove(id); err != nil && !errors.Is(err, ErrEntryNotFound) {
+		d.logf("Notifications: remove %s: %v", id, err)
+	}
+}
+
+func (d *Dispatcher) logf(format string, args ...any) {
+	if d.cfg.Logger != nil {
+		d.cfg.Logger.Printf(format, args...)
+	}
+}
@@ -0,0 +1,159 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type stubSink struct {
+	name string
+	mu   sync.Mutex
+	errs []error
+	sent []Alert
+}
+
+func (s *stubSink) Name() string { return s.name }
+
+func (s *stubSink) Send(ctx context.Context, alert Alert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return err
+	}
+	s.sent = append(s.sent, alert)
+	return nil
+}
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func newTestDispatcher(t *testing.T, outbox *Outbox, clock *fakeClock, sinks ...Sink) *Dispatcher {
+	t.Helper()
+	return NewDispatcher(DispatcherConfig{
+		Outbox:      outbox,
+		Sinks:       sinks,
+		MaxAttempts: 3,
+		BaseBackoff: time.Minute,
+		MaxBackoff:  90 * time.Second,
+		Now:         clock.Now,
+	})
+}
+
+func TestDispatcherDeliversAndDeduplicates(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	outbox := NewOutbox(filepath.Join(t.TempDir(), "outbox.json"))
+	discord := &stubSink{name: "discord"}
+	slack := &stubSink{name: "slack"}
+	d := newTestDispatcher(t, outbox, clock, discord, slack)
+
+	alert := Alert{StreamerID: "demo", Platform: "youtube", VideoID: "abc"}
+	if err := d.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	if err := d.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("notify duplicate: %v", err)
+	}
+	if delivered := d.deliverDue(context.Background()); delivered != 2 {
+		t.Fatalf("expected 2 deliveries, got %d", delivered)
+	}
+	if len(discord.sent) != 1 || len(slack.sent) != 1 {
+		t.Fatalf("expected one alert per sink, got discord=%d slack=%d", len(discord.sent), len(slack.sent))
+	}
+	entries, _ := outbox.List()
+	if len(entries) != 0 {
+		t.Fatalf("expected empty outbox, got %+v", entries)
+	}
+}
+
+func TestDispatcherRetriesWithBackoff(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	outbox := NewOutbox(filepath.Join(t.TempDir(), "outbox.json"))
+	sink := &stubSink{name: "hook", errs: []error{errors.New("boom"), errors.New("boom again")}}
+	d := newTestDispatcher(t, outbox, clock, sink)
+
+	_ = d.Notify(context.Background(), Alert{Platform: "youtube", VideoID: "abc"})
+	d.deliverDue(context.Background())
+	entries, _ := outbox.List()
+	if len(entries) != 1 || entries[0].Attempts != 1 || !entries[0].NextAttemptAt.Equal(clock.now.Add(time.Minute)) {
+		t.Fatalf("expected rescheduled entry, got %+v", entries)
+	}
+
+	// Not yet due.
+	if d.deliverDue(context.Background()) != 0 || len(sink.errs) != 1 {
+		t.Fatalf("entry should wait for its backoff")
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	d.deliverDue(context.Background())
+	entries, _ = outbox.List()
+	if len(entries) != 1 || entries[0].Attempts != 2 || !entries[0].NextAttemptAt.Equal(clock.now.Add(90*time.Second)) {
+		t.Fatalf("expected capped backoff, got %+v", entries)
+	}
+
+	clock.now = clock.now.Add(90 * time.Second)
+	if d.deliverDue(context.Background()) != 1 {
+		t.Fatalf("expected third attempt to succeed")
+	}
+}
+
+func TestDispatcherDropsAfterMaxAttemptsOrPermanentError(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	outbox := NewOutbox(filepath.Join(t.TempDir(), "outbox.json"))
+	sink := &stubSink{name: "hook", errs: []error{&PermanentError{Err: errors.New("gone")}}}
+	d := newTestDispatcher(t, outbox, clock, sink)
+
+	_ = d.Notify(context.Background(), Alert{Platform: "youtube", VideoID: "abc"})
+	d.deliverDue(context.Background())
+	if entries, _ := outbox.List(); len(entries) != 0 {
+		t.Fatalf("permanent failure should drop the entry, got %+v", entries)
+	}
+
+	sink.errs = []error{errors.New("1"), errors.New("2"), errors.New("3")}
+	_ = d.Notify(context.Background(), Alert{Platform: "youtube", VideoID: "def"})
+	for i := 0; i < 3; i++ {
+		d.deliverDue(context.Background())
+		clock.now = clock.now.Add(time.Hour)
+	}
+	if entries, _ := outbox.List(); len(entries) != 0 {
+		t.Fatalf("entry should be dropped after max attempts, got %+v", entries)
+	}
+	if len(sink.sent) != 0 {
+		t.Fatalf("nothing should have been delivered")
+	}
+}
+
+func TestDispatcherResumesOutboxAfterRestart(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	first := newTestDispatcher(t, NewOutbox(path), clock, &stubSink{name: "hook"})
+	if err := first.Notify(context.Background(), Alert{Platform: "twitch", VideoID: "stream1"}); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+
+	sink := &stubSink{name: "hook"}
+	second := newTestDispatcher(t, NewOutbox(path), clock, sink)
+	ctx, cancel := context.WithCancel(context.Background())
+	second.Start(ctx)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		sink.mu.Lock()
+		n := len(sink.sent)
+		sink.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pending alert was not delivered after restart")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	second.Stop()
+}
@@ -0,0 +1,216 @@
+package notifications
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"live-stream-alerts/internal/filestore"
+)
+
+// DefaultOutboxPath is where pending deliveries are persisted.
+const DefaultOutboxPath = "data/outbox.json"
+
+// sentRetention controls how long alert keys are remembered for de-duplication.
+const sentRetention = 48 * time.Hour
+
+// ErrEntryNotFound indicates the outbox entry no longer exists.
+var ErrEntryNotFound = errors.New("outbox entry not found")
+
+// Entry is a single pending delivery of an alert to one sink.
+type Entry struct {
+	ID            string    `json:"id"`
+	Sink          string    `json:"sink"`
+	Alert         Alert     `json:"alert"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+	LastError     string    `json:"lastError,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// OutboxFile is the on-disk outbox format.
+type OutboxFile struct {
+	Entries []Entry `json:"entries"`
+	// Sent maps alert keys to the time they were enqueued so repeated hub
+	// notifications for the same broadcast do not alert twice.
+	Sent map[string]time.Time `json:"sent,omitempty"`
+}
+
+// Outbox persists pending deliveries to a JSON file so they survive restarts.
+type Outbox struct {
+	path        string
+	mu          sync.Mutex
+	lockTimeout time.Duration
+}
+
+// OutboxOption customises the outbox behaviour.
+type OutboxOption func(*Outbox)
+
+// WithOutboxLockTimeout bounds how long writes wait for another process holding the file lock.
+func WithOutboxLockTimeout(timeout time.Duration) OutboxOption {
+	return func(o *Outbox) {
+		o.lockTimeout = timeout
+	}
+}
+
+// NewOutbox returns a file-backed outbox for the provided path.
+func NewOutbox(path string, opts ...OutboxOption) *Outbox {
+	if path == "" {
+		path = DefaultOutboxPath
+	}
+	outbox := &Outbox{path: filepath.Clean(path)}
+	for _, opt := range opts {
+		opt(outbox)
+	}
+	return outbox
+}
+
+// Path returns the file path backing the outbox.
+func (o *Outbox) Path() string {
+	if o == nil {
+		return ""
+	}
+	return o.path
+}
+
+// Enqueue stores entries for the alert unless key was already enqueued recently.
+// It reports whether the entries were added.
+func (o *Outbox) Enqueue(key string, entries []Entry, now time.Time) (bool, error) {
+	if o == nil {
+		return false, errors.New("outbox is nil")
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	added := false
+	err := o.updateFileLocked(func(file *OutboxFile) error {
+		for k, at := range file.Sent {
+			if now.Sub(at) > sentRetention {
+				delete(file.Sent, k)
+			}
+		}
+		if key != "" {
+			if _, ok := file.Sent[key]; ok {
+				return nil
+			}
+			file.Sent[key] = now.UTC()
+		}
+		file.Entries = append(file.Entries, entries...)
+		added = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return added, nil
+}
+
+// List returns every pending entry.
+func (o *Outbox) List() ([]Entry, error) {
+	if o == nil {
+		return nil, errors.New("outbox is nil")
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	file, err := o.readFileLocked()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Entry, len(file.Entries))
+	copy(out, file.Entries)
+	return out, nil
+}
+
+// Remove deletes the entry with the provided ID.
+func (o *Outbox) Remove(id string) error {
+	return o.update(id, func(file *OutboxFile, idx int) {
+		file.Entries = append(file.Entries[:idx], file.Entries[idx+1:]...)
+	})
+}
+
+// Reschedule records a failed attempt and the time of the next one.
+func (o *Outbox) Reschedule(id string, attempts int, next time.Time, lastErr string) error {
+	return o.update(id, func(file *OutboxFile, idx int) {
+		file.Entries[idx].Attempts = attempts
+		file.Entries[idx].NextAttemptAt = next.UTC()
+		file.Entries[idx].LastError = lastErr
+	})
+}
+
+func (o *Outbox) update(id string, fn func(*OutboxFile, int)) error {
+	if o == nil {
+		return errors.New("outbox is nil")
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.updateFileLocked(func(file *OutboxFile) error {
+		for i := range file.Entries {
+			if file.Entries[i].ID == id {
+				fn(file, i)
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s", ErrEntryNotFound, id)
+	})
+}
+
+// updateFileLocked runs a read-modify-write cycle. The caller holds o.mu, which serialises
+// goroutines; the advisory file lock extends that to other processes sharing the file.
+// Nothing is written when updateFn fails.
+func (o *Outbox) updateFileLocked(updateFn func(*OutboxFile) error) error {
+	lock, err := filestore.LockFile(o.path, o.lockTimeout)
+	if err != nil {
+		return fmt.Errorf("lock outbox file: %w", err)
+	}
+	defer lock.Unlock()
+
+	file, err := o.readFileLocked()
+	if err != nil {
+		return err
+	}
+	if err := updateFn(&file); err != nil {
+		return err
+	}
+	return o.writeFileLocked(file)
+}
+
+func (o *Outbox) readFileLocked() (OutboxFile, error) {
+	file := OutboxFile{Entries: []Entry{}, Sent: map[string]time.Time{}}
+	data, err := os.ReadFile(o.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return file, nil
+		}
+		return OutboxFile{}, fmt.Errorf("read outbox file: %w", err)
+	}
+	if len(data) == 0 {
+		return file, nil
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return OutboxFile{}, fmt.Errorf("parse outbox file: %w", err)
+	}
+	if file.Entries == nil {
+		file.Entries = []Entry{}
+	}
+	if file.Sent == nil {
+		file.Sent = map[string]time.Time{}
+	}
+	return file, nil
+}
+
+func (o *Outbox) writeFileLocked(file OutboxFile) error {
+	if err := os.MkdirAll(filepath.Dir(o.path), 0o755); err != nil {
+		return fmt.Errorf("create outbox dir: %w", err)
+	}
+	encoded, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode outbox file: %w", err)
+	}
+	if err := filestore.WriteAtomic(o.path, encoded, 0o644); err != nil {
+		return fmt.Errorf("write outbox file: %w", err)
+	}
+	return nil
+}
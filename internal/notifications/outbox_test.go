@@ -0,0 +1,47 @@
+package notifications
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOutboxDeduplicatesAcrossInstances(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "outbox.json")
+	now := time.Now()
+	var wg sync.WaitGroup
+	added := make(chan bool, 8)
+	for i := 0; i < cap(added); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Separate instances share only the file lock, like separate processes.
+			ok, err := NewOutbox(path).Enqueue("youtube:vid", []Entry{{ID: "e", Sink: "ops"}}, now)
+			if err != nil {
+				t.Errorf("enqueue: %v", err)
+			}
+			added <- ok
+		}()
+	}
+	wg.Wait()
+	close(added)
+	count := 0
+	for ok := range added {
+		if ok {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Fatalf("expected exactly one enqueue to win, got %d", count)
+	}
+	entries, err := NewOutbox(path).List()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected one entry, got %v (%v)", entries, err)
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(dir, ".outbox.json.tmp-*"))
+	if len(leftovers) != 0 {
+		t.Fatalf("expected no temp files, got %v", leftovers)
+	}
+}
@@ -0,0 +1,161 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Sink types supported by NewSink.
+const (
+	SinkDiscord = "discord"
+	SinkSlack   = "slack"
+	SinkWebhook = "webhook"
+)
+
+// DefaultTemplate renders the message text when a sink does not configure one.
+const DefaultTemplate = `{{.Alias}} is live on {{.Platform}}{{if .Title}}: {{.Title}}{{end}} {{.URL}}`
+
+// Sink delivers a rendered alert to an external service.
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, alert Alert) error
+}
+
+// SinkConfig describes one outbound destination.
+type SinkConfig struct {
+	Name     string
+	Type     string
+	URL      string
+	Template string
+	// Templates overrides Template for specific streamer IDs.
+	Templates map[string]string
+}
+
+// PermanentError marks a delivery failure that retrying cannot fix, such as a 4xx
+// response for a deleted webhook.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+type webhookSink struct {
+	name      string
+	kind      string
+	url       string
+	fallback  *template.Template
+	overrides map[string]*template.Template
+	client    *http.Client
+}
+
+// NewSink validates cfg and parses its templates.
+func NewSink(cfg SinkConfig, client *http.Client) (Sink, error) {
+	kind := strings.ToLower(strings.TrimSpace(cfg.Type))
+	switch kind {
+	case SinkDiscord, SinkSlack, SinkWebhook:
+	default:
+		return nil, fmt.Errorf("unsupported sink type %q", cfg.Type)
+	}
+	url := strings.TrimSpace(cfg.URL)
+	if url == "" {
+		return nil, errors.New("sink url is required")
+	}
+	name := strings.TrimSpace(cfg.Name)
+	if name == "" {
+		name = kind
+	}
+	text := cfg.Template
+	if strings.TrimSpace(text) == "" {
+		text = DefaultTemplate
+	}
+	fallback, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse template for sink %s: %w", name, err)
+	}
+	overrides := make(map[string]*template.Template, len(cfg.Templates))
+	for streamerID, text := range cfg.Templates {
+		tmpl, err := template.New(name + "/" + streamerID).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("parse template for sink %s streamer %s: %w", name, streamerID, err)
+		}
+		overrides[streamerID] = tmpl
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &webhookSink{
+		name:      name,
+		kind:      kind,
+		url:       url,
+		fallback:  fallback,
+		overrides: overrides,
+		client:    client,
+	}, nil
+}
+
+func (s *webhookSink) Name() string { return s.name }
+
+func (s *webhookSink) Send(ctx context.Context, alert Alert) error {
+	message, err := s.render(alert)
+	if err != nil {
+		return &PermanentError{Err: err}
+	}
+	var payload any
+	switch s.kind {
+	case SinkDiscord:
+		payload = map[string]string{"content": message}
+	case SinkSlack:
+		payload = map[string]string{"text": message}
+	default:
+		payload = struct {
+			Alert
+			Message string `json:"message"`
+		}{Alert: alert, Message: message}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return &PermanentError{Err: fmt.Errorf("encode payload: %w", err)}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return &PermanentError{Err: fmt.Errorf("build request: %w", err)}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post to %s: %w", s.name, err)
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("%s returned status %d: %s", s.name, resp.StatusCode, strings.TrimSpace(string(snippet)))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return &PermanentError{Err: err}
+	}
+	return err
+}
+
+func (s *webhookSink) render(alert Alert) (string, error) {
+	tmpl := s.fallback
+	if override, ok := s.overrides[alert.StreamerID]; ok {
+		tmpl = override
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, alert); err != nil {
+		return "", fmt.Errorf("render template: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
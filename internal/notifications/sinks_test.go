@@ -0,0 +1,107 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func captureServer(t *testing.T, status int, got *map[string]any) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSinkPayloads(t *testing.T) {
+	alert := Alert{StreamerID: "demo", Alias: "Demo", Platform: "youtube", Title: "Speedrun", URL: "https://youtu.be/x"}
+	tests := []struct {
+		kind  string
+		field string
+	}{
+		{SinkDiscord, "content"},
+		{SinkSlack, "text"},
+		{SinkWebhook, "message"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			var got map[string]any
+			srv := captureServer(t, http.StatusNoContent, &got)
+			sink, err := NewSink(SinkConfig{Type: tt.kind, URL: srv.URL}, srv.Client())
+			if err != nil {
+				t.Fatalf("new sink: %v", err)
+			}
+			if err := sink.Send(context.Background(), alert); err != nil {
+				t.Fatalf("send: %v", err)
+			}
+			if got[tt.field] != "Demo is live on youtube: Speedrun https://youtu.be/x" {
+				t.Fatalf("unexpected %s: %v", tt.field, got)
+			}
+			if tt.kind == SinkWebhook && got["streamerId"] != "demo" {
+				t.Fatalf("generic webhook should include alert fields: %v", got)
+			}
+		})
+	}
+}
+
+func TestSinkPerStreamerTemplate(t *testing.T) {
+	var got map[string]any
+	srv := captureServer(t, http.StatusOK, &got)
+	sink, err := NewSink(SinkConfig{
+		Type:      SinkSlack,
+		URL:       srv.URL,
+		Template:  "default {{.Alias}}",
+		Templates: map[string]string{"vip": ":rotating_light: {{.Alias}} {{.URL}}"},
+	}, srv.Client())
+	if err != nil {
+		t.Fatalf("new sink: %v", err)
+	}
+	if err := sink.Send(context.Background(), Alert{StreamerID: "vip", Alias: "VIP", URL: "u"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if got["text"] != ":rotating_light: VIP u" {
+		t.Fatalf("expected override template, got %v", got["text"])
+	}
+	if err := sink.Send(context.Background(), Alert{StreamerID: "other", Alias: "Other"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if got["text"] != "default Other" {
+		t.Fatalf("expected default template, got %v", got["text"])
+	}
+}
+
+func TestSinkClassifiesFailures(t *testing.T) {
+	var got map[string]any
+	gone := captureServer(t, http.StatusNotFound, &got)
+	sink, _ := NewSink(SinkConfig{Type: SinkDiscord, URL: gone.URL}, gone.Client())
+	var permanent *PermanentError
+	if err := sink.Send(context.Background(), Alert{}); !errors.As(err, &permanent) {
+		t.Fatalf("expected permanent error for 404, got %v", err)
+	}
+
+	busy := captureServer(t, http.StatusTooManyRequests, &got)
+	sink, _ = NewSink(SinkConfig{Type: SinkDiscord, URL: busy.URL}, busy.Client())
+	if err := sink.Send(context.Background(), Alert{}); err == nil || errors.As(err, &permanent) {
+		t.Fatalf("expected retryable error for 429, got %v", err)
+	}
+}
+
+func TestNewSinkValidates(t *testing.T) {
+	if _, err := NewSink(SinkConfig{Type: "pager", URL: "https://x"}, nil); err == nil {
+		t.Fatalf("expected unsupported type error")
+	}
+	if _, err := NewSink(SinkConfig{Type: SinkSlack}, nil); err == nil {
+		t.Fatalf("expected missing url error")
+	}
+	if _, err := NewSink(SinkConfig{Type: SinkSlack, URL: "https://x", Template: "{{.Broken"}, nil); err == nil {
+		t.Fatalf("expected template parse error")
+	}
+}
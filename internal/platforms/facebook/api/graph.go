@@ -0,0 +1,172 @@
+// Package api implements the subset of the Facebook Graph API used by the alert server.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultGraphURL is the production Graph API base URL, pinned to a version.
+	DefaultGraphURL = "https://graph.facebook.com/v19.0"
+
+	// FieldLiveVideos is the Page webhook field that reports live video changes.
+	FieldLiveVideos = "live_videos"
+)
+
+// Live video statuses reported by the Graph API.
+const (
+	LiveStatusLive        = "LIVE"
+	LiveStatusLiveStopped = "LIVE_STOPPED"
+	LiveStatusVOD         = "VOD"
+)
+
+// GraphClientOptions configures a GraphClient.
+type GraphClientOptions struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// GraphClient calls the Graph API with per-page access tokens.
+type GraphClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewGraphClient builds a GraphClient, defaulting to the production Graph URL.
+func NewGraphClient(opts GraphClientOptions) *GraphClient {
+	baseURL := strings.TrimRight(strings.TrimSpace(opts.BaseURL), "/")
+	if baseURL == "" {
+		baseURL = DefaultGraphURL
+	}
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &GraphClient{baseURL: baseURL, httpClient: httpClient}
+}
+
+// LiveVideo is the subset of live video fields the alert server uses.
+type LiveVideo struct {
+	ID           string
+	Title        string
+	Status       string
+	PermalinkURL string
+	CreationTime time.Time
+}
+
+// LiveVideo fetches a live video's title, status, permalink, and creation time.
+func (c *GraphClient) LiveVideo(ctx context.Context, videoID, accessToken string) (LiveVideo, error) {
+	videoID = strings.TrimSpace(videoID)
+	if videoID == "" {
+		return LiveVideo{}, errors.New("video id is required")
+	}
+	query := url.Values{}
+	query.Set("fields", "id,title,status,permalink_url,creation_time")
+	query.Set("access_token", accessToken)
+
+	var resp struct {
+		ID           string `json:"id"`
+		Title        string `json:"title"`
+		Status       string `json:"status"`
+		PermalinkURL string `json:"permalink_url"`
+		CreationTime string `json:"creation_time"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/"+url.PathEscape(videoID)+"?"+query.Encode(), &resp); err != nil {
+		return LiveVideo{}, err
+	}
+	video := LiveVideo{ID: resp.ID, Title: resp.Title, Status: resp.Status, PermalinkURL: resp.PermalinkURL}
+	// Graph timestamps use a +0000 offset rather than RFC 3339's +00:00.
+	if created, err := time.Parse("2006-01-02T15:04:05-0700", resp.CreationTime); err == nil {
+		video.CreationTime = created.UTC()
+	}
+	if strings.HasPrefix(video.PermalinkURL, "/") {
+		video.PermalinkURL = "https://www.facebook.com" + video.PermalinkURL
+	}
+	return video, nil
+}
+
+// SubscribePage subscribes the app to the page's live_videos webhook field.
+func (c *GraphClient) SubscribePage(ctx context.Context, pageID, accessToken string) error {
+	pageID = strings.TrimSpace(pageID)
+	if pageID == "" {
+		return errors.New("page id is required")
+	}
+	if strings.TrimSpace(accessToken) == "" {
+		return errors.New("page access token is required")
+	}
+	query := url.Values{}
+	query.Set("subscribed_fields", FieldLiveVideos)
+	query.Set("access_token", accessToken)
+
+	var resp struct {
+		Success bool `json:"success"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/"+url.PathEscape(pageID)+"/subscribed_apps?"+query.Encode(), &resp); err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New("graph did not confirm the page subscription")
+	}
+	return nil
+}
+
+// StatusError reports a non-2xx Graph response.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("graph returned status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("graph returned status %d: %s", e.StatusCode, e.Message)
+}
+
+func (c *GraphClient) do(ctx context.Context, method, pathAndQuery string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+pathAndQuery, nil)
+	if err != nil {
+		return fmt.Errorf("build graph request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// The URL carries the access token, so keep it out of logged errors.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("graph request: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("read graph response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &StatusError{StatusCode: resp.StatusCode, Message: graphErrorMessage(data)}
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode graph response: %w", err)
+	}
+	return nil
+}
+
+func graphErrorMessage(data []byte) string {
+	var payload struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &payload); err == nil && payload.Error.Message != "" {
+		return payload.Error.Message
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.Handler) *GraphClient {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return NewGraphClient(GraphClientOptions{BaseURL: srv.URL + "/v19.0/", HTTPClient: srv.Client()})
+}
+
+func TestLiveVideo(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v19.0/555" || r.URL.Query().Get("access_token") != "page-token" {
+			t.Fatalf("unexpected request %s", r.URL)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"id":            "555",
+			"title":         "Morning show",
+			"status":        LiveStatusLive,
+			"permalink_url": "/somepage/videos/555/",
+			"creation_time": "2024-01-01T10:00:00+0000",
+		})
+	}))
+
+	video, err := client.LiveVideo(context.Background(), "555", "page-token")
+	if err != nil {
+		t.Fatalf("live video: %v", err)
+	}
+	if video.Title != "Morning show" || video.Status != LiveStatusLive {
+		t.Fatalf("unexpected video %+v", video)
+	}
+	if video.PermalinkURL != "https://www.facebook.com/somepage/videos/555/" {
+		t.Fatalf("expected absolute permalink, got %q", video.PermalinkURL)
+	}
+	if !video.CreationTime.Equal(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected creation time %v", video.CreationTime)
+	}
+}
+
+func TestSubscribePage(t *testing.T) {
+	var gotFields string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v19.0/100200/subscribed_apps" {
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL)
+		}
+		gotFields = r.URL.Query().Get("subscribed_fields")
+		_, _ = w.Write([]byte(`{"success":true}`))
+	}))
+	if err := client.SubscribePage(context.Background(), "100200", "page-token"); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if gotFields != FieldLiveVideos {
+		t.Fatalf("expected live_videos field, got %q", gotFields)
+	}
+	if err := client.SubscribePage(context.Background(), "100200", ""); err == nil {
+		t.Fatalf("expected error without access token")
+	}
+}
+
+func TestGraphErrorsAreStatusErrors(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"message":"Invalid OAuth access token.","code":190}}`))
+	}))
+	_, err := client.LiveVideo(context.Background(), "555", "secret-token")
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected StatusError, got %v", err)
+	}
+	if statusErr.Message != "Invalid OAuth access token." {
+		t.Fatalf("unexpected message %q", statusErr.Message)
+	}
+	if strings.Contains(err.Error(), "secret-token") {
+		t.Fatalf("error leaks the access token: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+// Package handlers exposes the Facebook Graph API webhook endpoint.
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"io"
+	"net/http"
+
+	"live-stream-alerts/internal/logging"
+	"live-stream-alerts/internal/platforms/facebook/webhook"
+)
+
+type webhookProcessor interface {
+	Process(ctx context.Context, payload webhook.Payload) error
+}
+
+// WebhookOptions configure the Facebook webhook handler.
+type WebhookOptions struct {
+	Logger      logging.Logger
+	AppSecret   string
+	VerifyToken string
+	Processor   webhookProcessor
+}
+
+// NewWebhookHandler returns the /alerts/facebook handler. GET requests answer the
+// hub.challenge endpoint verification; POST requests are signature-checked and handed
+// to the processor.
+func NewWebhookHandler(opts WebhookOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handleVerification(w, r, opts)
+		case http.MethodPost:
+			handleDelivery(w, r, opts)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func handleVerification(w http.ResponseWriter, r *http.Request, opts WebhookOptions) {
+	if opts.VerifyToken == "" {
+		http.Error(w, "facebook webhook is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	query := r.URL.Query()
+	token := query.Get(webhook.ParamVerifyToken)
+	if query.Get(webhook.ParamMode) != webhook.ModeSubscribe || subtle.ConstantTimeCompare([]byte(token), []byte(opts.VerifyToken)) != 1 {
+		logf(opts.Logger, "Rejected Facebook webhook verification (mode %q)", query.Get(webhook.ParamMode))
+		http.Error(w, "verification failed", http.StatusForbidden)
+		return
+	}
+	logf(opts.Logger, "Facebook webhook endpoint verified")
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(query.Get(webhook.ParamChallenge)))
+}
+
+func handleDelivery(w http.ResponseWriter, r *http.Request, opts WebhookOptions) {
+	if opts.AppSecret == "" || opts.Processor == nil {
+		http.Error(w, "facebook webhook is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "failed to read notification", http.StatusBadRequest)
+		return
+	}
+	if err := webhook.VerifySignature(opts.AppSecret, body, r.Header.Get(webhook.HeaderSignature)); err != nil {
+		logf(opts.Logger, "Rejected Facebook webhook delivery: %v", err)
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+	payload, err := webhook.DecodePayload(body)
+	if err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if err := opts.Processor.Process(r.Context(), payload); err != nil {
+		// A non-2xx response makes Facebook retry the delivery.
+		logf(opts.Logger, "Failed to process Facebook webhook delivery: %v", err)
+		http.Error(w, "failed to process notification", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func logf(logger logging.Logger, format string, args ...any) {
+	if logger != nil {
+		logger.Printf(format, args...)
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"live-stream-alerts/internal/platforms/facebook/webhook"
+)
+
+const (
+	testSecret      = "app-secret"
+	testVerifyToken = "verify-me"
+)
+
+type recordingProcessor struct {
+	payloads []webhook.Payload
+	err      error
+}
+
+func (p *recordingProcessor) Process(ctx context.Context, payload webhook.Payload) error {
+	p.payloads = append(p.payloads, payload)
+	return p.err
+}
+
+func newHandler(proc *recordingProcessor) http.Handler {
+	return NewWebhookHandler(WebhookOptions{AppSecret: testSecret, VerifyToken: testVerifyToken, Processor: proc})
+}
+
+func TestWebhookHandlerAnswersVerification(t *testing.T) {
+	handler := newHandler(&recordingProcessor{})
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/alerts/facebook?hub.mode=subscribe&hub.verify_token=verify-me&hub.challenge=1158201444", nil))
+	if rr.Code != http.StatusOK || rr.Body.String() != "1158201444" {
+		t.Fatalf("expected challenge echo, got %d %q", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/alerts/facebook?hub.mode=subscribe&hub.verify_token=wrong&hub.challenge=1", nil))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for wrong token, got %d", rr.Code)
+	}
+}
+
+func TestWebhookHandlerProcessesSignedDelivery(t *testing.T) {
+	proc := &recordingProcessor{}
+	handler := newHandler(proc)
+	body := `{"object":"page","entry":[{"id":"100200","changes":[{"field":"live_videos","value":{"id":"555","status":"live"}}]}]}`
+	req := httptest.NewRequest(http.MethodPost, "/alerts/facebook", strings.NewReader(body))
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(testSecret, []byte(body)))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if len(proc.payloads) != 1 || proc.payloads[0].Entry[0].ID != "100200" {
+		t.Fatalf("unexpected payloads %+v", proc.payloads)
+	}
+}
+
+func TestWebhookHandlerRejectsBadSignature(t *testing.T) {
+	proc := &recordingProcessor{}
+	handler := newHandler(proc)
+	req := httptest.NewRequest(http.MethodPost, "/alerts/facebook", strings.NewReader(`{"object":"page"}`))
+	req.Header.Set(webhook.HeaderSignature, "sha256=00")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rr.Code)
+	}
+	if len(proc.payloads) != 0 {
+		t.Fatalf("processor should not run for bad signatures")
+	}
+}
+
+func TestWebhookHandlerUnconfigured(t *testing.T) {
+	handler := NewWebhookHandler(WebhookOptions{})
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(method, "/alerts/facebook", strings.NewReader("{}")))
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Fatalf("%s: expected 503, got %d", method, rr.Code)
+		}
+	}
+}
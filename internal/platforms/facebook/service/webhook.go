@@ -0,0 +1,211 @@
+// Package service applies Facebook live_videos webhook changes to the streamer store and
+// manages the page subscriptions that produce them.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"live-stream-alerts/internal/logging"
+	"live-stream-alerts/internal/notifications"
+	fbapi "live-stream-alerts/internal/platforms/facebook/api"
+	"live-stream-alerts/internal/platforms/facebook/webhook"
+	"live-stream-alerts/internal/streamers"
+)
+
+// StatusStore persists Facebook live status changes and resolves page tokens.
+type StatusStore interface {
+	List() ([]streamers.Record, error)
+	UpdateFacebookLiveStatus(pageID string, status streamers.FacebookLiveStatus) (streamers.Record, error)
+}
+
+// VideoLookup fetches live video details from the Graph API.
+type VideoLookup interface {
+	LiveVideo(ctx context.Context, videoID, accessToken string) (fbapi.LiveVideo, error)
+}
+
+// LiveNotifier dispatches go-live alerts to outbound sinks.
+type LiveNotifier interface {
+	Notify(ctx context.Context, alert notifications.Alert) error
+}
+
+// WebhookProcessor applies live_videos changes to the store.
+type WebhookProcessor struct {
+	Streamers StatusStore
+	// Graph, when set, is used to enrich go-live alerts with the video title and permalink.
+	Graph  VideoLookup
+	Logger logging.Logger
+	// Notifier, when set, receives an alert whenever a page goes live.
+	Notifier LiveNotifier
+	Now      func() time.Time
+}
+
+// Process handles a verified delivery. Changes for pages we do not track are logged and
+// ignored so Facebook does not keep redelivering them.
+func (p WebhookProcessor) Process(ctx context.Context, payload webhook.Payload) error {
+	if p.Streamers == nil {
+		return errors.New("streamers store is not configured")
+	}
+	if payload.Object != webhook.ObjectPage {
+		p.logf("Ignoring Facebook webhook for object %q", payload.Object)
+		return nil
+	}
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			if change.Field != fbapi.FieldLiveVideos {
+				continue
+			}
+			if err := p.processChange(ctx, entry, change.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p WebhookProcessor) processChange(ctx context.Context, entry webhook.Entry, value webhook.ChangeValue) error {
+	var live bool
+	// Webhooks send lowercase statuses while the Graph API uses uppercase.
+	switch strings.ToUpper(strings.TrimSpace(value.Status)) {
+	case fbapi.LiveStatusLive:
+		live = true
+	case fbapi.LiveStatusLiveStopped, fbapi.LiveStatusVOD:
+		live = false
+	default:
+		p.logf("Ignoring Facebook live video %s with status %q", value.LiveVideoID(), value.Status)
+		return nil
+	}
+
+	pageID := strings.TrimSpace(entry.ID)
+	videoID := value.LiveVideoID()
+	startedAt := p.now()
+	if entry.Time > 0 {
+		startedAt = time.Unix(entry.Time, 0)
+	}
+	var video fbapi.LiveVideo
+	if live {
+		video = p.lookupVideo(ctx, pageID, videoID)
+		if !video.CreationTime.IsZero() {
+			startedAt = video.CreationTime
+		}
+	}
+
+	record, err := p.Streamers.UpdateFacebookLiveStatus(pageID, streamers.FacebookLiveStatus{
+		Live:      live,
+		VideoID:   videoID,
+		StartedAt: startedAt,
+	})
+	if err != nil {
+		if errors.Is(err, streamers.ErrStreamerNotFound) {
+			p.logf("Facebook live video %s for unknown page %s ignored", videoID, pageID)
+			return nil
+		}
+		return fmt.Errorf("update facebook status: %w", err)
+	}
+	p.logf("Facebook live video %s is %s for page %s (streamer %s)", videoID, strings.ToLower(value.Status), pageID, record.Streamer.ID)
+	if live && p.Notifier != nil {
+		alert := notifications.Alert{
+			StreamerID: record.Streamer.ID,
+			Alias:      record.Streamer.Alias,
+			Platform:   "facebook",
+			ChannelID:  pageID,
+			VideoID:    videoID,
+			Title:      video.Title,
+			URL:        video.PermalinkURL,
+			StartedAt:  startedAt.UTC(),
+		}
+		if alert.URL == "" && videoID != "" {
+			alert.URL = "https://www.facebook.com/" + pageID + "/videos/" + videoID
+		}
+		if err := p.Notifier.Notify(ctx, alert); err != nil {
+			p.logf("Failed to queue Facebook go-live alert for %s: %v", record.Streamer.ID, err)
+		}
+	}
+	return nil
+}
+
+// lookupVideo fetches the video details with the page's access token. Failures are
+// logged and only cost the alert its title.
+func (p WebhookProcessor) lookupVideo(ctx context.Context, pageID, videoID string) fbapi.LiveVideo {
+	if p.Graph == nil || videoID == "" {
+		return fbapi.LiveVideo{}
+	}
+	records, err := p.Streamers.List()
+	if err != nil {
+		p.logf("Failed to list streamers for Facebook page %s: %v", pageID, err)
+		return fbapi.LiveVideo{}
+	}
+	for _, record := range records {
+		fb := record.Platforms.Facebook
+		if fb == nil || strings.TrimSpace(fb.PageID) != pageID || strings.TrimSpace(fb.AccessToken) == "" {
+			continue
+		}
+		video, err := p.Graph.LiveVideo(ctx, videoID, fb.AccessToken)
+		if err != nil {
+			p.logf("Failed to look up Facebook live video %s: %v", videoID, err)
+			return fbapi.LiveVideo{}
+		}
+		return video
+	}
+	return fbapi.LiveVideo{}
+}
+
+func (p WebhookProcessor) now() time.Time {
+	if p.Now != nil {
+		return p.Now()
+	}
+	return time.Now()
+}
+
+func (p WebhookProcessor) logf(format string, args ...any) {
+	if p.Logger != nil {
+		p.Logger.Printf(format, args...)
+	}
+}
+
+type pageSubscriber interface {
+	SubscribePage(ctx context.Context, pageID, accessToken string) error
+}
+
+// Subscriber subscribes the app to the live_videos field of each tracked page.
+type Subscriber struct {
+	Client pageSubscriber
+	Logger logging.Logger
+}
+
+// SubscribeRecords subscribes every record with a Facebook page ID and access token and
+// returns the first error encountered after attempting all of them.
+func (s Subscriber) SubscribeRecords(ctx context.Context, records []streamers.Record) error {
+	if s.Client == nil {
+		return errors.New("graph client is not configured")
+	}
+	var firstErr error
+	for _, record := range records {
+		fb := record.Platforms.Facebook
+		if fb == nil || strings.TrimSpace(fb.PageID) == "" {
+			continue
+		}
+		if strings.TrimSpace(fb.AccessToken) == "" {
+			s.logf("Facebook page %s for streamer %s has no access token; skipping subscription", fb.PageID, record.Streamer.ID)
+			continue
+		}
+		if err := s.Client.SubscribePage(ctx, fb.PageID, fb.AccessToken); err != nil {
+			s.logf("Facebook subscription for streamer %s failed: %v", record.Streamer.ID, err)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("subscribe page %s: %w", fb.PageID, err)
+			}
+			continue
+		}
+		s.logf("Facebook live_videos subscription active for page %s", fb.PageID)
+	}
+	return firstErr
+}
+
+func (s Subscriber) logf(format string, args ...any) {
+	if s.Logger != nil {
+		s.Logger.Printf(format, args...)
+	}
+}
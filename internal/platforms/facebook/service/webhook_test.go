@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"live-stream-alerts/internal/notifications"
+	fbapi "live-stream-alerts/internal/platforms/facebook/api"
+	"live-stream-alerts/internal/platforms/facebook/webhook"
+	"live-stream-alerts/internal/streamers"
+)
+
+type stubGraph struct {
+	tokens []string
+}
+
+func (g *stubGraph) LiveVideo(ctx context.Context, videoID, accessToken string) (fbapi.LiveVideo, error) {
+	g.tokens = append(g.tokens, accessToken)
+	return fbapi.LiveVideo{
+		ID:           videoID,
+		Title:        "Morning show",
+		PermalinkURL: "https://www.facebook.com/somepage/videos/" + videoID,
+		CreationTime: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+	}, nil
+}
+
+type recordingNotifier struct {
+	alerts []notifications.Alert
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, alert notifications.Alert) error {
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
+func TestWebhookProcessorTogglesFacebookStatus(t *testing.T) {
+	store := streamers.NewStore(filepath.Join(t.TempDir(), "streamers.json"))
+	if _, err := store.Append(streamers.Record{
+		Streamer:  streamers.Streamer{Alias: "Demo"},
+		Platforms: streamers.Platforms{Facebook: &streamers.FacebookPlatform{PageID: "100200", AccessToken: "page-token"}},
+	}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	graph := &stubGraph{}
+	notifier := &recordingNotifier{}
+	proc := WebhookProcessor{Streamers: store, Graph: graph, Notifier: notifier}
+
+	live, _ := webhook.DecodePayload([]byte(`{"object":"page","entry":[{"id":"100200","time":1704103200,"changes":[{"field":"live_videos","value":{"id":"555","status":"live"}}]}]}`))
+	if err := proc.Process(context.Background(), live); err != nil {
+		t.Fatalf("process live: %v", err)
+	}
+	records, _ := store.List()
+	status := records[0].Status
+	if status == nil || !status.Live || status.Facebook == nil || !status.Facebook.Live || status.Facebook.VideoID != "555" {
+		t.Fatalf("expected facebook live status, got %+v", status)
+	}
+	if len(graph.tokens) != 1 || graph.tokens[0] != "page-token" {
+		t.Fatalf("expected graph lookup with the page token, got %v", graph.tokens)
+	}
+	if len(notifier.alerts) != 1 || notifier.alerts[0].Platform != "facebook" || notifier.alerts[0].Title != "Morning show" {
+		t.Fatalf("unexpected alerts %+v", notifier.alerts)
+	}
+
+	stopped, _ := webhook.DecodePayload([]byte(`{"object":"page","entry":[{"id":"100200","changes":[{"field":"live_videos","value":{"id":"555","status":"live_stopped"}}]}]}`))
+	if err := proc.Process(context.Background(), stopped); err != nil {
+		t.Fatalf("process stopped: %v", err)
+	}
+	records, _ = store.List()
+	status = records[0].Status
+	if status.Live || status.Facebook.Live || len(status.Platforms) != 0 {
+		t.Fatalf("expected offline status, got %+v", status)
+	}
+	if len(notifier.alerts) != 1 {
+		t.Fatalf("stopping should not alert, got %d alerts", len(notifier.alerts))
+	}
+
+	unknown, _ := webhook.DecodePayload([]byte(`{"object":"page","entry":[{"id":"404","changes":[{"field":"live_videos","value":{"id":"1","status":"live"}}]}]}`))
+	if err := proc.Process(context.Background(), unknown); err != nil {
+		t.Fatalf("unknown page should be ignored, got %v", err)
+	}
+}
+
+type stubPageSubscriber struct {
+	pages []string
+	err   error
+}
+
+func (s *stubPageSubscriber) SubscribePage(ctx context.Context, pageID, accessToken string) error {
+	s.pages = append(s.pages, pageID)
+	return s.err
+}
+
+func TestSubscriberSubscribesPagesWithTokens(t *testing.T) {
+	client := &stubPageSubscriber{}
+	sub := Subscriber{Client: client}
+	err := sub.SubscribeRecords(context.Background(), []streamers.Record{
+		{Platforms: streamers.Platforms{Facebook: &streamers.FacebookPlatform{PageID: "1", AccessToken: "t"}}},
+		{Platforms: streamers.Platforms{Facebook: &streamers.FacebookPlatform{PageID: "2"}}},
+		{Platforms: streamers.Platforms{Twitch: &streamers.TwitchPlatform{BroadcasterID: "3"}}},
+	})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if len(client.pages) != 1 || client.pages[0] != "1" {
+		t.Fatalf("expected only the page with a token, got %v", client.pages)
+	}
+
+	client.err = errors.New("boom")
+	if err := sub.SubscribeRecords(context.Background(), []streamers.Record{
+		{Platforms: streamers.Platforms{Facebook: &streamers.FacebookPlatform{PageID: "1", AccessToken: "t"}}},
+	}); err == nil {
+		t.Fatalf("expected subscription error")
+	}
+}
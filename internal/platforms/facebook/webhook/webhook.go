@@ -0,0 +1,101 @@
+// Package webhook verifies and decodes Facebook Graph API webhook deliveries.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// HeaderSignature carries the HMAC-SHA256 of the raw body keyed with the app secret.
+const HeaderSignature = "X-Hub-Signature-256"
+
+// Query parameters Facebook sends when verifying a webhook endpoint.
+const (
+	ParamMode        = "hub.mode"
+	ParamVerifyToken = "hub.verify_token"
+	ParamChallenge   = "hub.challenge"
+	ModeSubscribe    = "subscribe"
+)
+
+// ObjectPage is the payload object for Page webhooks.
+const ObjectPage = "page"
+
+// ErrInvalidSignature indicates the HMAC did not match the configured app secret.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// VerifySignature checks the "sha256=<hex>" signature Facebook computes over the raw body.
+func VerifySignature(appSecret string, body []byte, signature string) error {
+	if appSecret == "" {
+		return errors.New("facebook app secret is not configured")
+	}
+	method, value, ok := strings.Cut(strings.TrimSpace(signature), "=")
+	if !ok || !strings.EqualFold(method, "sha256") {
+		return fmt.Errorf("%w: unsupported signature header", ErrInvalidSignature)
+	}
+	expected, err := hex.DecodeString(value)
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", ErrInvalidSignature)
+	}
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Sign returns the signature header value for body. It mirrors what Facebook sends
+// and is used by tests and local tooling.
+func Sign(appSecret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Payload is the envelope of a webhook delivery. Deliveries may batch several entries.
+type Payload struct {
+	Object string  `json:"object"`
+	Entry  []Entry `json:"entry"`
+}
+
+// Entry groups the changes for a single page.
+type Entry struct {
+	ID      string   `json:"id"`
+	Time    int64    `json:"time"`
+	Changes []Change `json:"changes"`
+}
+
+// Change is a single field change within an entry.
+type Change struct {
+	Field string      `json:"field"`
+	Value ChangeValue `json:"value"`
+}
+
+// ChangeValue holds the live_videos change fields the alert server uses.
+type ChangeValue struct {
+	ID      string `json:"id"`
+	VideoID string `json:"video_id"`
+	Status  string `json:"status"`
+}
+
+// LiveVideoID returns the live video ID, which Facebook sends as either id or video_id.
+func (v ChangeValue) LiveVideoID() string {
+	if id := strings.TrimSpace(v.ID); id != "" {
+		return id
+	}
+	return strings.TrimSpace(v.VideoID)
+}
+
+// DecodePayload parses the raw webhook body.
+func DecodePayload(body []byte) (Payload, error) {
+	var payload Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return Payload{}, fmt.Errorf("decode webhook payload: %w", err)
+	}
+	return payload, nil
+}
@@ -0,0 +1,43 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"object":"page","entry":[]}`)
+	sig := Sign("app-secret", body)
+	if err := VerifySignature("app-secret", body, sig); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+	if err := VerifySignature("other-secret", body, sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for wrong secret, got %v", err)
+	}
+	if err := VerifySignature("app-secret", body, "sha1=abcd"); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for sha1 header, got %v", err)
+	}
+	if err := VerifySignature("app-secret", body, "sha256=zz"); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for malformed hex, got %v", err)
+	}
+	if err := VerifySignature("", body, sig); err == nil {
+		t.Fatalf("expected error without app secret")
+	}
+}
+
+func TestDecodePayload(t *testing.T) {
+	payload, err := DecodePayload([]byte(`{"object":"page","entry":[{"id":"100200","time":1700000000,"changes":[{"field":"live_videos","value":{"video_id":"555","status":"live"}}]}]}`))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if payload.Object != ObjectPage || len(payload.Entry) != 1 || len(payload.Entry[0].Changes) != 1 {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+	change := payload.Entry[0].Changes[0]
+	if change.Field != "live_videos" || change.Value.LiveVideoID() != "555" || change.Value.Status != "live" {
+		t.Fatalf("unexpected change %+v", change)
+	}
+	if _, err := DecodePayload([]byte(`{`)); err == nil {
+		t.Fatalf("expected decode error")
+	}
+}
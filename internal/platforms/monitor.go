@@ -0,0 +1,218 @@
+package platforms
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"live-stream-alerts/internal/logging"
+	"live-stream-alerts/internal/streamers"
+)
+
+const (
+	defaultRenewInterval = time.Minute
+	defaultLiveInterval  = 2 * time.Minute
+	renewTimeout         = 15 * time.Second
+	liveCheckTimeout     = 30 * time.Second
+)
+
+// MonitorConfig configures the background subscription renewal and live-check loops.
+type MonitorConfig struct {
+	Registry *Registry
+	Store    streamers.Repository
+	// RenewInterval is how often subscriptions are inspected (default 1m).
+	RenewInterval time.Duration
+	// LiveInterval is how often live records are re-checked (default 2m).
+	LiveInterval time.Duration
+	// Observer, when set, is told the outcome of every renewal.
+	Observer RenewalObserver
+	Logger   logging.Logger
+	Now      func() time.Time
+}
+
+// RenewalObserver receives the result of each subscription renewal.
+type RenewalObserver interface {
+	ObserveRenewal(platform string, err error)
+}
+
+// Monitor renews each provider's subscriptions when they fall due and asks every
+// provider to re-check the records it reports as live.
+type Monitor struct {
+	cfg      MonitorConfig
+	mu       sync.Mutex
+	attempts map[string]time.Time
+	// heartbeat is when the renewal loop last finished a pass.
+	heartbeat time.Time
+	cancel    context.CancelFunc
+	runWg     sync.WaitGroup
+	renewWg   sync.WaitGroup
+}
+
+// StartMonitor launches both loops using the provided context.
+func StartMonitor(ctx context.Context, cfg MonitorConfig) *Monitor {
+	monitor := newMonitor(cfg)
+	runCtx, cancel := context.WithCancel(ctx)
+	monitor.cancel = cancel
+	monitor.runWg.Add(2)
+	go func() {
+		defer monitor.runWg.Done()
+		monitor.loop(runCtx, monitor.cfg.RenewInterval, func(ctx context.Context) {
+			monitor.renew(ctx)
+			monitor.beat()
+		})
+	}()
+	go func() {
+		defer monitor.runWg.Done()
+		monitor.loop(runCtx, monitor.cfg.LiveInterval, monitor.checkLive)
+	}()
+	return monitor
+}
+
+func newMonitor(cfg MonitorConfig) *Monitor {
+	if cfg.Store == nil {
+		cfg.Store = streamers.NewStore(streamers.DefaultFilePath)
+	}
+	if cfg.RenewInterval <= 0 {
+		cfg.RenewInterval = defaultRenewInterval
+	}
+	if cfg.LiveInterval <= 0 {
+		cfg.LiveInterval = defaultLiveInterval
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Monitor{cfg: cfg, attempts: make(map[string]time.Time)}
+}
+
+func (m *Monitor) loop(ctx context.Context, interval time.Duration, tick func(context.Context)) {
+	tick(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			tick(ctx)
+		}
+	}
+}
+
+// renew launches a renewal for every subscription that is due. A subscription is
+// attempted once per due time; it becomes eligible again when the provider reports a
+// new due time (for YouTube, once the hub confirms the renewed lease).
+func (m *Monitor) renew(ctx context.Context) {
+	records, err := m.cfg.Store.List()
+	if err != nil {
+		m.logf("platform monitor: failed to read streamers: %v", err)
+		return
+	}
+	now := m.cfg.Now().UTC()
+	for _, provider := range m.cfg.Registry.Providers() {
+		for _, record := range records {
+			at, ok := provider.RenewAt(record)
+			if !ok {
+				continue
+			}
+			key := provider.Name() + "/" + record.Streamer.ID
+			if now.Before(at) {
+				m.clearAttempt(key)
+				continue
+			}
+			if !m.recordAttempt(key, at) {
+				continue
+			}
+			m.launchRenewal(ctx, provider, record)
+		}
+	}
+}
+
+func (m *Monitor) beat() {
+	m.mu.Lock()
+	m.heartbeat = m.cfg.Now().UTC()
+	m.mu.Unlock()
+}
+
+// Heartbeat returns when the renewal loop last completed a pass, or the zero time before
+// the first one. A stale heartbeat means the loop is stuck or has exited.
+func (m *Monitor) Heartbeat() time.Time {
+	if m == nil {
+		return time.Time{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.heartbeat
+}
+
+// RenewInterval reports the configured pass interval, after defaults are applied.
+func (m *Monitor) RenewInterval() time.Duration {
+	return m.cfg.RenewInterval
+}
+
+func (m *Monitor) clearAttempt(key string) {
+	m.mu.Lock()
+	delete(m.attempts, key)
+	m.mu.Unlock()
+}
+
+// recordAttempt returns false when a renewal for the same due time was already started.
+func (m *Monitor) recordAttempt(key string, at time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if last, ok := m.attempts[key]; ok && last.Equal(at) {
+		return false
+	}
+	m.attempts[key] = at
+	return true
+}
+
+func (m *Monitor) launchRenewal(ctx context.Context, provider Provider, record streamers.Record) {
+	m.renewWg.Add(1)
+	go func() {
+		defer m.renewWg.Done()
+		m.logf("platform monitor: renewing %s subscription for %s", provider.Name(), record.Streamer.Alias)
+		renewCtx, cancel := context.WithTimeout(ctx, renewTimeout)
+		defer cancel()
+		err := provider.Subscribe(renewCtx, record)
+		if err != nil {
+			m.logf("platform monitor: %s renewal failed for %s: %v", provider.Name(), record.Streamer.Alias, err)
+		}
+		if m.cfg.Observer != nil {
+			m.cfg.Observer.ObserveRenewal(provider.Name(), err)
+		}
+	}()
+}
+
+func (m *Monitor) checkLive(ctx context.Context) {
+	records, err := m.cfg.Store.List()
+	if err != nil {
+		m.logf("platform monitor: failed to read streamers: %v", err)
+		return
+	}
+	for _, provider := range m.cfg.Registry.Providers() {
+		checkCtx, cancel := context.WithTimeout(ctx, liveCheckTimeout)
+		if _, err := provider.CheckLive(checkCtx, records); err != nil {
+			m.logf("platform monitor: %s live check failed: %v", provider.Name(), err)
+		}
+		cancel()
+	}
+}
+
+func (m *Monitor) logf(format string, args ...any) {
+	if m.cfg.Logger != nil {
+		m.cfg.Logger.Printf(format, args...)
+	}
+}
+
+// Stop cancels the monitor and waits for all goroutines to finish.
+func (m *Monitor) Stop() {
+	if m == nil {
+		return
+	}
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.runWg.Wait()
+	m.renewWg.Wait()
+}
@@ -0,0 +1,146 @@
+// Package platforms defines the contract every streaming platform integration implements
+// and a registry that the router, submission approval, and background monitors iterate
+// over instead of hard-coding a platform.
+package platforms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"live-stream-alerts/internal/streamers"
+)
+
+// ErrUnsupportedURL is returned by Provider.ParseURL when a URL belongs to another platform.
+var ErrUnsupportedURL = errors.New("url does not belong to this platform")
+
+// Channel identifies a channel parsed from a public URL.
+type Channel struct {
+	Platform string
+	// Handle is the human-readable name (e.g. a YouTube @handle), when the URL has one.
+	Handle string
+	// ID is the platform's stable channel identifier, when the URL has one.
+	ID string
+}
+
+// Provider is implemented by each platform integration.
+type Provider interface {
+	// Name is the platform key used in records and status (e.g. "youtube").
+	Name() string
+	// ParseURL extracts the channel from a public channel URL, or returns ErrUnsupportedURL.
+	ParseURL(rawURL string) (Channel, error)
+	// Onboard attaches the channel at rawURL to record and subscribes to its alerts.
+	Onboard(ctx context.Context, record streamers.Record, rawURL string) error
+	// RenewAt reports when the record's subscription should next be renewed. ok is false
+	// when the record has no subscription on this platform to maintain.
+	RenewAt(record streamers.Record) (at time.Time, ok bool)
+	// Subscribe creates or renews the record's subscription.
+	Subscribe(ctx context.Context, record streamers.Record) error
+	// MatchCallback reports whether a request to the shared /alerts endpoint was sent
+	// by this platform.
+	MatchCallback(r *http.Request) bool
+	// VerifyCallback answers a subscription verification request. It returns false when
+	// the request is not a verification request it understands.
+	VerifyCallback(w http.ResponseWriter, r *http.Request) bool
+	// HandleNotification processes a push notification. It returns false when the
+	// notification could not be handled and no response has been written.
+	HandleNotification(w http.ResponseWriter, r *http.Request) bool
+	// CheckLive re-checks every record the platform reports as live and clears the
+	// status of broadcasts that have ended. It returns how many records went offline.
+	CheckLive(ctx context.Context, records []streamers.Record) (int, error)
+}
+
+// Registry holds the configured providers in registration order.
+type Registry struct {
+	mu        sync.RWMutex
+	providers []Provider
+}
+
+// NewRegistry returns a registry containing providers. It panics on duplicate names,
+// which is a programming error.
+func NewRegistry(providers ...Provider) *Registry {
+	reg := &Registry{}
+	for _, provider := range providers {
+		if err := reg.Register(provider); err != nil {
+			panic(err)
+		}
+	}
+	return reg
+}
+
+// Register adds provider, rejecting a second provider with the same name.
+func (r *Registry) Register(provider Provider) error {
+	if provider == nil {
+		return errors.New("provider is nil")
+	}
+	name := strings.ToLower(strings.TrimSpace(provider.Name()))
+	if name == "" {
+		return errors.New("provider name is required")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.providers {
+		if strings.EqualFold(existing.Name(), name) {
+			return fmt.Errorf("provider %q is already registered", name)
+		}
+	}
+	r.providers = append(r.providers, provider)
+	return nil
+}
+
+// Get returns the provider registered under name.
+func (r *Registry) Get(name string) (Provider, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, provider := range r.providers {
+		if strings.EqualFold(provider.Name(), strings.TrimSpace(name)) {
+			return provider, true
+		}
+	}
+	return nil, false
+}
+
+// Providers returns a snapshot of the registered providers.
+func (r *Registry) Providers() []Provider {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]Provider, len(r.providers))
+	copy(out, r.providers)
+	return out
+}
+
+// ForURL returns the first provider that recognises rawURL along with the parsed channel.
+// Errors other than ErrUnsupportedURL (e.g. a malformed channel path) are returned as-is.
+func (r *Registry) ForURL(rawURL string) (Provider, Channel, error) {
+	for _, provider := range r.Providers() {
+		channel, err := provider.ParseURL(rawURL)
+		if errors.Is(err, ErrUnsupportedURL) {
+			continue
+		}
+		if err != nil {
+			return nil, Channel{}, err
+		}
+		return provider, channel, nil
+	}
+	return nil, Channel{}, fmt.Errorf("%w: %s", ErrUnsupportedURL, rawURL)
+}
+
+// ForCallback returns the provider that sent r, or nil when none claims it.
+func (r *Registry) ForCallback(req *http.Request) Provider {
+	for _, provider := range r.Providers() {
+		if provider.MatchCallback(req) {
+			return provider
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package platforms
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"live-stream-alerts/internal/streamers"
+)
+
+type fakeProvider struct {
+	name    string
+	host    string
+	renewAt time.Time
+
+	mu         sync.Mutex
+	subscribed []string
+	liveChecks int
+}
+
+func (f *fakeProvider) Name() string { return f.name }
+
+func (f *fakeProvider) ParseURL(rawURL string) (Channel, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host != f.host {
+		return Channel{}, ErrUnsupportedURL
+	}
+	return Channel{Platform: f.name, ID: strings.Trim(u.Path, "/")}, nil
+}
+
+func (f *fakeProvider) Onboard(ctx context.Context, record streamers.Record, rawURL string) error {
+	return nil
+}
+
+func (f *fakeProvider) RenewAt(record streamers.Record) (time.Time, bool) {
+	return f.renewAt, !f.renewAt.IsZero()
+}
+
+func (f *fakeProvider) Subscribe(ctx context.Context, record streamers.Record) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.subscribed = append(f.subscribed, record.Streamer.ID)
+	return nil
+}
+
+func (f *fakeProvider) MatchCallback(r *http.Request) bool {
+	return r.Header.Get("User-Agent") == f.name
+}
+
+func (f *fakeProvider) VerifyCallback(w http.ResponseWriter, r *http.Request) bool { return false }
+
+func (f *fakeProvider) HandleNotification(w http.ResponseWriter, r *http.Request) bool { return false }
+
+func (f *fakeProvider) CheckLive(ctx context.Context, records []streamers.Record) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.liveChecks++
+	return 0, nil
+}
+
+func (f *fakeProvider) subscriptions() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.subscribed...)
+}
+
+func TestRegistryDispatch(t *testing.T) {
+	alpha := &fakeProvider{name: "alpha", host: "alpha.example"}
+	beta := &fakeProvider{name: "beta", host: "beta.example"}
+	reg := NewRegistry(alpha, beta)
+
+	if err := reg.Register(&fakeProvider{name: "Alpha"}); err == nil {
+		t.Fatalf("expected duplicate provider names to be rejected")
+	}
+	if p, ok := reg.Get("BETA"); !ok || p != beta {
+		t.Fatalf("expected case-insensitive lookup, got %v", p)
+	}
+
+	provider, channel, err := reg.ForURL("https://beta.example/chan1")
+	if err != nil || provider != beta || channel.ID != "chan1" || channel.Platform != "beta" {
+		t.Fatalf("unexpected ForURL result %v %+v %v", provider, channel, err)
+	}
+	if _, _, err := reg.ForURL("https://gamma.example/x"); !errors.Is(err, ErrUnsupportedURL) {
+		t.Fatalf("expected ErrUnsupportedURL, got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/alerts", nil)
+	req.Header.Set("User-Agent", "alpha")
+	if reg.ForCallback(req) != alpha {
+		t.Fatalf("expected alpha to claim the callback")
+	}
+	req.Header.Set("User-Agent", "curl")
+	if reg.ForCallback(req) != nil {
+		t.Fatalf("expected no provider for an unknown caller")
+	}
+}
+
+func TestMonitorRenewsOncePerDueTime(t *testing.T) {
+	store := streamers.NewStore(filepath.Join(t.TempDir(), "streamers.json"))
+	if _, err := store.Append(streamers.Record{Streamer: streamers.Streamer{ID: "demo", Alias: "Demo"}}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	provider := &fakeProvider{name: "alpha", renewAt: now.Add(-time.Minute)}
+	monitor := newMonitor(MonitorConfig{Registry: NewRegistry(provider), Store: store, Now: func() time.Time { return now }})
+
+	monitor.renew(context.Background())
+	monitor.renew(context.Background())
+	monitor.renewWg.Wait()
+	if got := provider.subscriptions(); len(got) != 1 || got[0] != "demo" {
+		t.Fatalf("expected a single renewal, got %v", got)
+	}
+
+	// A new due time (e.g. after the lease was confirmed) allows another attempt.
+	provider.renewAt = now.Add(-time.Second)
+	monitor.renew(context.Background())
+	monitor.renewWg.Wait()
+	if got := provider.subscriptions(); len(got) != 2 {
+		t.Fatalf("expected a second renewal after the due time moved, got %v", got)
+	}
+
+	provider.renewAt = now.Add(time.Hour)
+	monitor.renew(context.Background())
+	monitor.renewWg.Wait()
+	if got := provider.subscriptions(); len(got) != 2 {
+		t.Fatalf("subscriptions that are not due must not renew, got %v", got)
+	}
+
+	monitor.checkLive(context.Background())
+	if provider.liveChecks != 1 {
+		t.Fatalf("expected one live check, got %d", provider.liveChecks)
+	}
+}
+
+type renewalRecorder struct {
+	mu      sync.Mutex
+	results []string
+}
+
+func (r *renewalRecorder) ObserveRenewal(platform string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, platform)
+}
+
+func (r *renewalRecorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.results)
+}
+
+func TestMonitorReportsHeartbeatAndRenewals(t *testing.T) {
+	store := streamers.NewStore(filepath.Join(t.TempDir(), "streamers.json"))
+	if _, err := store.Append(streamers.Record{Streamer: streamers.Streamer{ID: "demo", Alias: "Demo"}}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	observer := &renewalRecorder{}
+	monitor := StartMonitor(context.Background(), MonitorConfig{
+		Registry: NewRegistry(&fakeProvider{name: "alpha", renewAt: now.Add(-time.Minute)}),
+		Store:    store,
+		Observer: observer,
+		Now:      func() time.Time { return now },
+	})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for monitor.Heartbeat().IsZero() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	monitor.Stop()
+	if !monitor.Heartbeat().Equal(now) {
+		t.Fatalf("expected heartbeat at %v, got %v", now, monitor.Heartbeat())
+	}
+	if observer.count() != 1 {
+		t.Fatalf("expected one observed renewal, got %d", observer.count())
+	}
+}
@@ -0,0 +1,311 @@
+// Package api implements the subset of the Twitch Helix API used by the alert server.
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHelixURL is the production Helix API base URL.
+	DefaultHelixURL = "https://api.twitch.tv/helix"
+	// DefaultAuthURL is the production OAuth token endpoint used for app access tokens.
+	DefaultAuthURL = "https://id.twitch.tv/oauth2/token"
+
+	// EventStreamOnline is the EventSub subscription type fired when a broadcast starts.
+	EventStreamOnline = "stream.online"
+	// EventStreamOffline is the EventSub subscription type fired when a broadcast stops.
+	EventStreamOffline = "stream.offline"
+)
+
+// ErrUserNotFound indicates Helix returned no user for the requested login.
+var ErrUserNotFound = errors.New("twitch user not found")
+
+// HelixClientOptions configures a HelixClient.
+type HelixClientOptions struct {
+	ClientID     string
+	ClientSecret string
+	BaseURL      string
+	AuthURL      string
+	HTTPClient   *http.Client
+	Now          func() time.Time
+}
+
+// HelixClient calls the Helix API using an app access token obtained through the
+// client credentials flow. Tokens are cached until shortly before they expire.
+type HelixClient struct {
+	clientID     string
+	clientSecret string
+	baseURL      string
+	authURL      string
+	httpClient   *http.Client
+	now          func() time.Time
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewHelixClient builds a HelixClient, applying production defaults for empty URLs.
+func NewHelixClient(opts HelixClientOptions) (*HelixClient, error) {
+	if strings.TrimSpace(opts.ClientID) == "" {
+		return nil, errors.New("twitch client id is required")
+	}
+	if strings.TrimSpace(opts.ClientSecret) == "" {
+		return nil, errors.New("twitch client secret is required")
+	}
+	baseURL := strings.TrimRight(strings.TrimSpace(opts.BaseURL), "/")
+	if baseURL == "" {
+		baseURL = DefaultHelixURL
+	}
+	authURL := strings.TrimSpace(opts.AuthURL)
+	if authURL == "" {
+		authURL = DefaultAuthURL
+	}
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	return &HelixClient{
+		clientID:     strings.TrimSpace(opts.ClientID),
+		clientSecret: strings.TrimSpace(opts.ClientSecret),
+		baseURL:      baseURL,
+		authURL:      authURL,
+		httpClient:   httpClient,
+		now:          now,
+	}, nil
+}
+
+// EventSubSubscriptionRequest describes a webhook-transport EventSub subscription.
+type EventSubSubscriptionRequest struct {
+	Type          string
+	Version       string
+	BroadcasterID string
+	CallbackURL   string
+	Secret        string
+}
+
+// EventSubSubscription is the subscription returned by Helix.
+type EventSubSubscription struct {
+	ID        string            `json:"id"`
+	Status    string            `json:"status"`
+	Type      string            `json:"type"`
+	Version   string            `json:"version"`
+	Condition map[string]string `json:"condition"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+type eventSubTransport struct {
+	Method   string `json:"method"`
+	Callback string `json:"callback"`
+	Secret   string `json:"secret"`
+}
+
+type eventSubCreateBody struct {
+	Type      string            `json:"type"`
+	Version   string            `json:"version"`
+	Condition map[string]string `json:"condition"`
+	Transport eventSubTransport `json:"transport"`
+}
+
+// CreateEventSubSubscription registers a webhook subscription for the broadcaster.
+// Helix answers 409 when the subscription already exists; that is reported as an error
+// so callers can decide whether to ignore it.
+func (c *HelixClient) CreateEventSubSubscription(ctx context.Context, req EventSubSubscriptionRequest) (EventSubSubscription, error) {
+	if strings.TrimSpace(req.Type) == "" {
+		return EventSubSubscription{}, errors.New("subscription type is required")
+	}
+	if strings.TrimSpace(req.BroadcasterID) == "" {
+		return EventSubSubscription{}, errors.New("broadcaster id is required")
+	}
+	if strings.TrimSpace(req.CallbackURL) == "" {
+		return EventSubSubscription{}, errors.New("callback url is required")
+	}
+	if len(req.Secret) < 10 || len(req.Secret) > 100 {
+		return EventSubSubscription{}, errors.New("eventsub secret must be between 10 and 100 characters")
+	}
+	version := strings.TrimSpace(req.Version)
+	if version == "" {
+		version = "1"
+	}
+	payload, err := json.Marshal(eventSubCreateBody{
+		Type:      req.Type,
+		Version:   version,
+		Condition: map[string]string{"broadcaster_user_id": strings.TrimSpace(req.BroadcasterID)},
+		Transport: eventSubTransport{
+			Method:   "webhook",
+			Callback: strings.TrimSpace(req.CallbackURL),
+			Secret:   req.Secret,
+		},
+	})
+	if err != nil {
+		return EventSubSubscription{}, fmt.Errorf("encode subscription: %w", err)
+	}
+
+	var resp struct {
+		Data []EventSubSubscription `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/eventsub/subscriptions", payload, &resp); err != nil {
+		return EventSubSubscription{}, err
+	}
+	if len(resp.Data) == 0 {
+		return EventSubSubscription{}, errors.New("helix returned no subscription")
+	}
+	return resp.Data[0], nil
+}
+
+// UserID resolves a Twitch login name to its broadcaster user ID.
+func (c *HelixClient) UserID(ctx context.Context, login string) (string, error) {
+	login = strings.ToLower(strings.TrimSpace(login))
+	if login == "" {
+		return "", errors.New("login is required")
+	}
+	var resp struct {
+		Data []struct {
+			ID    string `json:"id"`
+			Login string `json:"login"`
+		} `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/users?login="+url.QueryEscape(login), nil, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Data) == 0 || resp.Data[0].ID == "" {
+		return "", fmt.Errorf("%w: %s", ErrUserNotFound, login)
+	}
+	return resp.Data[0].ID, nil
+}
+
+// StatusError reports a non-2xx Helix response.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("helix returned status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("helix returned status %d: %s", e.StatusCode, e.Message)
+}
+
+func (c *HelixClient) do(ctx context.Context, method, path string, body []byte, out any) error {
+	token, err := c.appToken(ctx)
+	if err != nil {
+		return err
+	}
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("build helix request: %w", err)
+	}
+	req.Header.Set("Client-Id", c.clientID)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("helix request: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("read helix response: %w", err)
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		c.invalidateToken()
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &StatusError{StatusCode: resp.StatusCode, Message: helixErrorMessage(data)}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode helix response: %w", err)
+	}
+	return nil
+}
+
+func (c *HelixClient) appToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && c.now().Before(c.tokenExpiry) {
+		return c.token, nil
+	}
+
+	form := url.Values{}
+	form.Set("client_id", c.clientID)
+	form.Set("client_secret", c.clientSecret)
+	form.Set("grant_type", "client_credentials")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.authURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("build token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("read token response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", &StatusError{StatusCode: resp.StatusCode, Message: helixErrorMessage(data)}
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(data, &token); err != nil {
+		return "", fmt.Errorf("decode token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("token response missing access_token")
+	}
+	// Refresh a minute early so in-flight requests never carry an expired token.
+	ttl := time.Duration(token.ExpiresIn)*time.Second - time.Minute
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	c.token = token.AccessToken
+	c.tokenExpiry = c.now().Add(ttl)
+	return c.token, nil
+}
+
+func (c *HelixClient) invalidateToken() {
+	c.mu.Lock()
+	c.token = ""
+	c.tokenExpiry = time.Time{}
+	c.mu.Unlock()
+}
+
+func helixErrorMessage(data []byte) string {
+	var payload struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(data, &payload); err == nil && payload.Message != "" {
+		return payload.Message
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+type fakeHelix struct {
+	tokenRequests int32
+	lastBody      eventSubCreateBody
+	status        int
+}
+
+func (f *fakeHelix) handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&f.tokenRequests, 1)
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("parse form: %v", err)
+		}
+		if r.PostForm.Get("grant_type") != "client_credentials" || r.PostForm.Get("client_id") != "cid" {
+			t.Fatalf("unexpected token form: %v", r.PostForm)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "app-token", "expires_in": 3600})
+	})
+	mux.HandleFunc("/helix/eventsub/subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer app-token" || r.Header.Get("Client-Id") != "cid" {
+			t.Fatalf("missing auth headers: %v", r.Header)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&f.lastBody); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		if f.status != 0 {
+			w.WriteHeader(f.status)
+			_ = json.NewEncoder(w).Encode(map[string]string{"message": "subscription already exists"})
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{
+			"id":        "sub-1",
+			"status":    "webhook_callback_verification_pending",
+			"type":      f.lastBody.Type,
+			"version":   f.lastBody.Version,
+			"condition": f.lastBody.Condition,
+		}}})
+	})
+	mux.HandleFunc("/helix/users", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("login") != "somestreamer" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": []any{}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": []map[string]string{{"id": "1234", "login": "somestreamer"}}})
+	})
+	return mux
+}
+
+func newTestClient(t *testing.T, fake *fakeHelix) *HelixClient {
+	t.Helper()
+	srv := httptest.NewServer(fake.handler(t))
+	t.Cleanup(srv.Close)
+	client, err := NewHelixClient(HelixClientOptions{
+		ClientID:     "cid",
+		ClientSecret: "secret",
+		BaseURL:      srv.URL + "/helix",
+		AuthURL:      srv.URL + "/oauth2/token",
+		HTTPClient:   srv.Client(),
+	})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	return client
+}
+
+func TestCreateEventSubSubscription(t *testing.T) {
+	fake := &fakeHelix{}
+	client := newTestClient(t, fake)
+
+	for _, typ := range []string{EventStreamOnline, EventStreamOffline} {
+		sub, err := client.CreateEventSubSubscription(context.Background(), EventSubSubscriptionRequest{
+			Type:          typ,
+			BroadcasterID: "1234",
+			CallbackURL:   "https://example.com/alerts/twitch",
+			Secret:        "0123456789abcdef",
+		})
+		if err != nil {
+			t.Fatalf("create %s: %v", typ, err)
+		}
+		if sub.ID != "sub-1" || sub.Type != typ {
+			t.Fatalf("unexpected subscription: %+v", sub)
+		}
+	}
+	if fake.tokenRequests != 1 {
+		t.Fatalf("expected cached app token, got %d token requests", fake.tokenRequests)
+	}
+	body := fake.lastBody
+	if body.Version != "1" || body.Condition["broadcaster_user_id"] != "1234" {
+		t.Fatalf("unexpected request body: %+v", body)
+	}
+	if body.Transport.Method != "webhook" || body.Transport.Callback != "https://example.com/alerts/twitch" || body.Transport.Secret != "0123456789abcdef" {
+		t.Fatalf("unexpected transport: %+v", body.Transport)
+	}
+}
+
+func TestCreateEventSubSubscriptionReportsStatus(t *testing.T) {
+	fake := &fakeHelix{status: http.StatusConflict}
+	client := newTestClient(t, fake)
+
+	_, err := client.CreateEventSubSubscription(context.Background(), EventSubSubscriptionRequest{
+		Type:          EventStreamOnline,
+		BroadcasterID: "1234",
+		CallbackURL:   "https://example.com/alerts/twitch",
+		Secret:        "0123456789abcdef",
+	})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusConflict {
+		t.Fatalf("expected conflict status error, got %v", err)
+	}
+}
+
+func TestCreateEventSubSubscriptionValidatesSecret(t *testing.T) {
+	client := newTestClient(t, &fakeHelix{})
+	_, err := client.CreateEventSubSubscription(context.Background(), EventSubSubscriptionRequest{
+		Type:          EventStreamOnline,
+		BroadcasterID: "1234",
+		CallbackURL:   "https://example.com/alerts/twitch",
+		Secret:        "short",
+	})
+	if err == nil {
+		t.Fatalf("expected short secret to be rejected")
+	}
+}
+
+func TestUserID(t *testing.T) {
+	client := newTestClient(t, &fakeHelix{})
+	id, err := client.UserID(context.Background(), "SomeStreamer")
+	if err != nil {
+		t.Fatalf("user id: %v", err)
+	}
+	if id != "1234" {
+		t.Fatalf("expected 1234, got %s", id)
+	}
+	if _, err := client.UserID(context.Background(), "missing"); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+// Package eventsub verifies and decodes Twitch EventSub webhook deliveries.
+package eventsub
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Header names Twitch sets on every EventSub webhook delivery.
+const (
+	HeaderMessageID        = "Twitch-Eventsub-Message-Id"
+	HeaderMessageTimestamp = "Twitch-Eventsub-Message-Timestamp"
+	HeaderMessageSignature = "Twitch-Eventsub-Message-Signature"
+	HeaderMessageType      = "Twitch-Eventsub-Message-Type"
+)
+
+// Message types carried in the Twitch-Eventsub-Message-Type header.
+const (
+	MessageTypeNotification = "notification"
+	MessageTypeVerification = "webhook_callback_verification"
+	MessageTypeRevocation   = "revocation"
+)
+
+// DefaultMaxMessageAge is the oldest timestamp Twitch recommends accepting.
+const DefaultMaxMessageAge = 10 * time.Minute
+
+var (
+	// ErrInvalidSignature indicates the HMAC did not match the configured secret.
+	ErrInvalidSignature = errors.New("invalid eventsub signature")
+	// ErrStaleMessage indicates the message timestamp is outside the accepted window.
+	ErrStaleMessage = errors.New("stale eventsub message")
+	// ErrDuplicateMessage indicates the message ID has already been processed.
+	ErrDuplicateMessage = errors.New("duplicate eventsub message")
+)
+
+// VerifySignature checks the "sha256=<hex>" signature Twitch computes over
+// message ID + timestamp + raw body.
+func VerifySignature(secret, messageID, timestamp string, body []byte, signature string) error {
+	if secret == "" {
+		return errors.New("eventsub secret is not configured")
+	}
+	method, value, ok := strings.Cut(strings.TrimSpace(signature), "=")
+	if !ok || !strings.EqualFold(method, "sha256") {
+		return fmt.Errorf("%w: unsupported signature header", ErrInvalidSignature)
+	}
+	expected, err := hex.DecodeString(value)
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", ErrInvalidSignature)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(messageID))
+	mac.Write([]byte(timestamp))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Sign returns the signature header value for the supplied message. It mirrors what
+// Twitch sends and is used by tests and local tooling.
+func Sign(secret, messageID, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(messageID))
+	mac.Write([]byte(timestamp))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ReplayGuard rejects stale timestamps and message IDs that were already seen.
+// Seen IDs are remembered for MaxAge, after which the timestamp check takes over.
+type ReplayGuard struct {
+	MaxAge time.Duration
+	Now    func() time.Time
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// NewReplayGuard builds a guard using DefaultMaxMessageAge.
+func NewReplayGuard() *ReplayGuard {
+	return &ReplayGuard{MaxAge: DefaultMaxMessageAge}
+}
+
+// Check records messageID and returns ErrStaleMessage or ErrDuplicateMessage when the
+// delivery should not be processed.
+func (g *ReplayGuard) Check(messageID, timestamp string) error {
+	messageID = strings.TrimSpace(messageID)
+	if messageID == "" {
+		return errors.New("message id is required")
+	}
+	sentAt, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(timestamp))
+	if err != nil {
+		return fmt.Errorf("parse message timestamp: %w", err)
+	}
+	now := g.now()
+	maxAge := g.maxAge()
+	if now.Sub(sentAt) > maxAge || sentAt.Sub(now) > maxAge {
+		return ErrStaleMessage
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.seen == nil {
+		g.seen = make(map[string]time.Time)
+	}
+	for id, expiry := range g.seen {
+		if now.After(expiry) {
+			delete(g.seen, id)
+		}
+	}
+	if _, ok := g.seen[messageID]; ok {
+		return ErrDuplicateMessage
+	}
+	g.seen[messageID] = now.Add(maxAge)
+	return nil
+}
+
+// Forget drops messageID so a redelivery is processed again. Callers use it when
+// handling failed after Check accepted the message.
+func (g *ReplayGuard) Forget(messageID string) {
+	g.mu.Lock()
+	delete(g.seen, strings.TrimSpace(messageID))
+	g.mu.Unlock()
+}
+
+func (g *ReplayGuard) now() time.Time {
+	if g.Now != nil {
+		return g.Now()
+	}
+	return time.Now()
+}
+
+func (g *ReplayGuard) maxAge() time.Duration {
+	if g.MaxAge > 0 {
+		return g.MaxAge
+	}
+	return DefaultMaxMessageAge
+}
+
+// Subscription is the subscription block included in every EventSub payload.
+type Subscription struct {
+	ID        string            `json:"id"`
+	Status    string            `json:"status"`
+	Type      string            `json:"type"`
+	Version   string            `json:"version"`
+	Condition map[string]string `json:"condition"`
+}
+
+// Message is the envelope of an EventSub webhook delivery.
+type Message struct {
+	Subscription Subscription    `json:"subscription"`
+	Challenge    string          `json:"challenge,omitempty"`
+	Event        json.RawMessage `json:"event,omitempty"`
+}
+
+// StreamEvent holds the fields shared by stream.online and stream.offline events.
+// ID, Type and StartedAt are only present for stream.online.
+type StreamEvent struct {
+	ID                   string    `json:"id"`
+	BroadcasterUserID    string    `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string    `json:"broadcaster_user_login"`
+	BroadcasterUserName  string    `json:"broadcaster_user_name"`
+	Type                 string    `json:"type"`
+	StartedAt            time.Time `json:"started_at"`
+}
+
+// DecodeMessage parses the raw webhook body.
+func DecodeMessage(body []byte) (Message, error) {
+	var msg Message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return Message{}, fmt.Errorf("decode eventsub message: %w", err)
+	}
+	return msg, nil
+}
+
+// StreamEvent decodes the event payload for stream.online/stream.offline messages.
+func (m Message) StreamEvent() (StreamEvent, error) {
+	var event StreamEvent
+	if len(m.Event) == 0 {
+		return event, errors.New("eventsub message has no event")
+	}
+	if err := json.Unmarshal(m.Event, &event); err != nil {
+		return event, fmt.Errorf("decode stream event: %w", err)
+	}
+	if strings.TrimSpace(event.BroadcasterUserID) == "" {
+		return event, errors.New("stream event missing broadcaster_user_id")
+	}
+	return event, nil
+}
@@ -0,0 +1,71 @@
+package eventsub
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"subscription":{"type":"stream.online"}}`)
+	header := Sign("0123456789", "msg-1", "2024-01-01T00:00:00Z", body)
+
+	if err := VerifySignature("0123456789", "msg-1", "2024-01-01T00:00:00Z", body, header); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+	if err := VerifySignature("0123456789", "msg-2", "2024-01-01T00:00:00Z", body, header); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected mismatch for different message id, got %v", err)
+	}
+	if err := VerifySignature("wrong-secret", "msg-1", "2024-01-01T00:00:00Z", body, header); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected mismatch for wrong secret, got %v", err)
+	}
+	if err := VerifySignature("0123456789", "msg-1", "2024-01-01T00:00:00Z", body, "sha1=abc"); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected unsupported algorithm to fail, got %v", err)
+	}
+}
+
+func TestReplayGuard(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	guard := &ReplayGuard{MaxAge: 10 * time.Minute, Now: func() time.Time { return now }}
+	ts := now.Add(-time.Minute).Format(time.RFC3339Nano)
+
+	if err := guard.Check("msg-1", ts); err != nil {
+		t.Fatalf("first delivery rejected: %v", err)
+	}
+	if err := guard.Check("msg-1", ts); !errors.Is(err, ErrDuplicateMessage) {
+		t.Fatalf("expected duplicate, got %v", err)
+	}
+	if err := guard.Check("msg-2", now.Add(-11*time.Minute).Format(time.RFC3339Nano)); !errors.Is(err, ErrStaleMessage) {
+		t.Fatalf("expected stale, got %v", err)
+	}
+
+	guard.Forget("msg-1")
+	if err := guard.Check("msg-1", ts); err != nil {
+		t.Fatalf("forgotten message should be accepted, got %v", err)
+	}
+
+	now = now.Add(11 * time.Minute)
+	if err := guard.Check("msg-3", now.Format(time.RFC3339Nano)); err != nil {
+		t.Fatalf("fresh delivery rejected: %v", err)
+	}
+	if _, ok := guard.seen["msg-1"]; ok {
+		t.Fatalf("expected expired message ids to be pruned")
+	}
+}
+
+func TestMessageStreamEvent(t *testing.T) {
+	msg, err := DecodeMessage([]byte(`{
+		"subscription": {"id":"sub","type":"stream.online","version":"1","condition":{"broadcaster_user_id":"1337"}},
+		"event": {"id":"9001","broadcaster_user_id":"1337","broadcaster_user_login":"cool_user","type":"live","started_at":"2024-01-01T10:00:00Z"}
+	}`))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	event, err := msg.StreamEvent()
+	if err != nil {
+		t.Fatalf("stream event: %v", err)
+	}
+	if event.ID != "9001" || event.BroadcasterUserID != "1337" || event.StartedAt.IsZero() {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+}
@@ -0,0 +1,102 @@
+// Package handlers exposes the Twitch EventSub webhook endpoint.
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"live-stream-alerts/internal/logging"
+	"live-stream-alerts/internal/platforms/twitch/eventsub"
+)
+
+type eventProcessor interface {
+	Process(ctx context.Context, msg eventsub.Message) error
+}
+
+// EventSubOptions configure the Twitch EventSub webhook handler.
+type EventSubOptions struct {
+	Logger    logging.Logger
+	Secret    string
+	Processor eventProcessor
+	Guard     *eventsub.ReplayGuard
+}
+
+// NewEventSubHandler returns the POST /alerts/twitch handler. It verifies the
+// message signature, drops replays, answers verification challenges and hands
+// notifications to the processor.
+func NewEventSubHandler(opts EventSubOptions) http.Handler {
+	guard := opts.Guard
+	if guard == nil {
+		guard = eventsub.NewReplayGuard()
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if opts.Secret == "" || opts.Processor == nil {
+			http.Error(w, "twitch eventsub is not configured", http.StatusServiceUnavailable)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, "failed to read notification", http.StatusBadRequest)
+			return
+		}
+		messageID := r.Header.Get(eventsub.HeaderMessageID)
+		timestamp := r.Header.Get(eventsub.HeaderMessageTimestamp)
+		if err := eventsub.VerifySignature(opts.Secret, messageID, timestamp, body, r.Header.Get(eventsub.HeaderMessageSignature)); err != nil {
+			logf(opts.Logger, "Rejected Twitch EventSub message %s: %v", messageID, err)
+			http.Error(w, "invalid signature", http.StatusForbidden)
+			return
+		}
+		if err := guard.Check(messageID, timestamp); err != nil {
+			if errors.Is(err, eventsub.ErrDuplicateMessage) {
+				// Twitch retries until it sees a 2xx, so acknowledge duplicates.
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			logf(opts.Logger, "Rejected Twitch EventSub message %s: %v", messageID, err)
+			http.Error(w, "stale message", http.StatusBadRequest)
+			return
+		}
+
+		msg, err := eventsub.DecodeMessage(body)
+		if err != nil {
+			guard.Forget(messageID)
+			http.Error(w, "invalid message", http.StatusBadRequest)
+			return
+		}
+
+		switch r.Header.Get(eventsub.HeaderMessageType) {
+		case eventsub.MessageTypeVerification:
+			logf(opts.Logger, "Twitch EventSub %s subscription %s verified", msg.Subscription.Type, msg.Subscription.ID)
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(msg.Challenge))
+		case eventsub.MessageTypeNotification:
+			if err := opts.Processor.Process(r.Context(), msg); err != nil {
+				guard.Forget(messageID)
+				logf(opts.Logger, "Failed to process Twitch EventSub message %s: %v", messageID, err)
+				http.Error(w, "failed to process notification", http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case eventsub.MessageTypeRevocation:
+			logf(opts.Logger, "Twitch revoked %s subscription %s: %s", msg.Subscription.Type, msg.Subscription.ID, msg.Subscription.Status)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+}
+
+func logf(logger logging.Logger, format string, args ...any) {
+	if logger != nil {
+		logger.Printf(format, args...)
+	}
+}
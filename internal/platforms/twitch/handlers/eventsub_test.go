@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"live-stream-alerts/internal/platforms/twitch/eventsub"
+)
+
+const testSecret = "0123456789abcdef"
+
+type recordingProcessor struct {
+	messages []eventsub.Message
+	err      error
+}
+
+func (p *recordingProcessor) Process(ctx context.Context, msg eventsub.Message) error {
+	p.messages = append(p.messages, msg)
+	return p.err
+}
+
+func signedRequest(id, msgType, body string, ts time.Time) *http.Request {
+	timestamp := ts.UTC().Format(time.RFC3339Nano)
+	req := httptest.NewRequest(http.MethodPost, "/alerts/twitch", strings.NewReader(body))
+	req.Header.Set(eventsub.HeaderMessageID, id)
+	req.Header.Set(eventsub.HeaderMessageTimestamp, timestamp)
+	req.Header.Set(eventsub.HeaderMessageType, msgType)
+	req.Header.Set(eventsub.HeaderMessageSignature, eventsub.Sign(testSecret, id, timestamp, []byte(body)))
+	return req
+}
+
+func TestEventSubHandlerAnswersChallenge(t *testing.T) {
+	handler := NewEventSubHandler(EventSubOptions{Secret: testSecret, Processor: &recordingProcessor{}})
+	body := `{"challenge":"pogchamp-kappa-360noscope","subscription":{"id":"sub","type":"stream.online"}}`
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, signedRequest("msg-1", eventsub.MessageTypeVerification, body, time.Now()))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if rr.Body.String() != "pogchamp-kappa-360noscope" {
+		t.Fatalf("expected raw challenge, got %q", rr.Body.String())
+	}
+}
+
+func TestEventSubHandlerProcessesNotificationOnce(t *testing.T) {
+	proc := &recordingProcessor{}
+	handler := NewEventSubHandler(EventSubOptions{Secret: testSecret, Processor: proc})
+	body := `{"subscription":{"type":"stream.online"},"event":{"broadcaster_user_id":"1337"}}`
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, signedRequest("msg-1", eventsub.MessageTypeNotification, body, now))
+		if rr.Code != http.StatusNoContent {
+			t.Fatalf("delivery %d: expected 204, got %d", i, rr.Code)
+		}
+	}
+	if len(proc.messages) != 1 {
+		t.Fatalf("expected replay to be dropped, processed %d", len(proc.messages))
+	}
+}
+
+func TestEventSubHandlerRejectsBadSignature(t *testing.T) {
+	proc := &recordingProcessor{}
+	handler := NewEventSubHandler(EventSubOptions{Secret: testSecret, Processor: proc})
+	req := signedRequest("msg-1", eventsub.MessageTypeNotification, `{"subscription":{"type":"stream.online"}}`, time.Now())
+	req.Header.Set(eventsub.HeaderMessageSignature, "sha256=00")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rr.Code)
+	}
+	if len(proc.messages) != 0 {
+		t.Fatalf("processor should not run for bad signatures")
+	}
+}
+
+func TestEventSubHandlerRejectsStaleMessage(t *testing.T) {
+	handler := NewEventSubHandler(EventSubOptions{Secret: testSecret, Processor: &recordingProcessor{}})
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, signedRequest("msg-1", eventsub.MessageTypeNotification, `{}`, time.Now().Add(-time.Hour)))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestEventSubHandlerAllowsRetryAfterFailure(t *testing.T) {
+	proc := &recordingProcessor{err: errors.New("disk full")}
+	handler := NewEventSubHandler(EventSubOptions{Secret: testSecret, Processor: proc})
+	body := `{"subscription":{"type":"stream.online"},"event":{"broadcaster_user_id":"1337"}}`
+	now := time.Now()
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, signedRequest("msg-1", eventsub.MessageTypeNotification, body, now))
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", rr.Code)
+	}
+	proc.err = nil
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, signedRequest("msg-1", eventsub.MessageTypeNotification, body, now))
+	if rr.Code != http.StatusNoContent || len(proc.messages) != 2 {
+		t.Fatalf("expected retry to be processed, code=%d processed=%d", rr.Code, len(proc.messages))
+	}
+}
+
+func TestEventSubHandlerUnconfigured(t *testing.T) {
+	rr := httptest.NewRecorder()
+	NewEventSubHandler(EventSubOptions{}).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/alerts/twitch", strings.NewReader("{}")))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,169 @@
+// Package service applies Twitch EventSub notifications to the streamer store and
+// manages the subscriptions that produce them.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"live-stream-alerts/internal/logging"
+	"live-stream-alerts/internal/notifications"
+	twitchapi "live-stream-alerts/internal/platforms/twitch/api"
+	"live-stream-alerts/internal/platforms/twitch/eventsub"
+	"live-stream-alerts/internal/streamers"
+)
+
+// StatusStore persists Twitch live status changes.
+type StatusStore interface {
+	UpdateTwitchLiveStatus(broadcasterID string, status streamers.TwitchLiveStatus) (streamers.Record, error)
+}
+
+// EventProcessor applies stream.online/stream.offline notifications to the store.
+type EventProcessor struct {
+	Streamers StatusStore
+	Logger    logging.Logger
+	// Notifier, when set, receives an alert for every stream.online event.
+	Notifier LiveNotifier
+}
+
+// LiveNotifier dispatches go-live alerts to outbound sinks.
+type LiveNotifier interface {
+	Notify(ctx context.Context, alert notifications.Alert) error
+}
+
+// Process handles a verified notification. Unknown broadcasters are logged and
+// ignored so Twitch does not keep redelivering events for streamers we no longer track.
+func (p EventProcessor) Process(ctx context.Context, msg eventsub.Message) error {
+	if p.Streamers == nil {
+		return errors.New("streamers store is not configured")
+	}
+	var live bool
+	switch msg.Subscription.Type {
+	case twitchapi.EventStreamOnline:
+		live = true
+	case twitchapi.EventStreamOffline:
+		live = false
+	default:
+		p.logf("Ignoring Twitch EventSub notification of type %q", msg.Subscription.Type)
+		return nil
+	}
+
+	event, err := msg.StreamEvent()
+	if err != nil {
+		return err
+	}
+	record, err := p.Streamers.UpdateTwitchLiveStatus(event.BroadcasterUserID, streamers.TwitchLiveStatus{
+		Live:      live,
+		StreamID:  event.ID,
+		StartedAt: event.StartedAt,
+	})
+	if err != nil {
+		if errors.Is(err, streamers.ErrStreamerNotFound) {
+			p.logf("Twitch %s for unknown broadcaster %s ignored", msg.Subscription.Type, event.BroadcasterUserID)
+			return nil
+		}
+		return fmt.Errorf("update twitch status: %w", err)
+	}
+	p.logf("Twitch %s for broadcaster %s (streamer %s)", msg.Subscription.Type, event.BroadcasterUserID, record.Streamer.ID)
+	if live && p.Notifier != nil {
+		if err := p.Notifier.Notify(ctx, liveAlert(record, event)); err != nil {
+			p.logf("Failed to queue Twitch go-live alert for %s: %v", record.Streamer.ID, err)
+		}
+	}
+	return nil
+}
+
+func liveAlert(record streamers.Record, event eventsub.StreamEvent) notifications.Alert {
+	login := event.BroadcasterUserLogin
+	if login == "" && record.Platforms.Twitch != nil {
+		login = record.Platforms.Twitch.Username
+	}
+	alert := notifications.Alert{
+		StreamerID: record.Streamer.ID,
+		Alias:      record.Streamer.Alias,
+		Platform:   "twitch",
+		ChannelID:  event.BroadcasterUserID,
+		VideoID:    event.ID,
+		StartedAt:  event.StartedAt,
+	}
+	if login != "" {
+		alert.URL = "https://www.twitch.tv/" + login
+	}
+	return alert
+}
+
+func (p EventProcessor) logf(format string, args ...any) {
+	if p.Logger != nil {
+		p.Logger.Printf(format, args...)
+	}
+}
+
+type subscriptionCreator interface {
+	CreateEventSubSubscription(ctx context.Context, req twitchapi.EventSubSubscriptionRequest) (twitchapi.EventSubSubscription, error)
+}
+
+// Subscriber registers the stream.online/stream.offline subscriptions for broadcasters.
+type Subscriber struct {
+	Client      subscriptionCreator
+	CallbackURL string
+	Secret      string
+	Logger      logging.Logger
+}
+
+// Subscribe creates both stream subscriptions for broadcasterID. Existing
+// subscriptions (409 Conflict) are treated as success.
+func (s Subscriber) Subscribe(ctx context.Context, broadcasterID string) error {
+	if s.Client == nil {
+		return errors.New("helix client is not configured")
+	}
+	broadcasterID = strings.TrimSpace(broadcasterID)
+	if broadcasterID == "" {
+		return errors.New("broadcaster id is required")
+	}
+	for _, typ := range []string{twitchapi.EventStreamOnline, twitchapi.EventStreamOffline} {
+		sub, err := s.Client.CreateEventSubSubscription(ctx, twitchapi.EventSubSubscriptionRequest{
+			Type:          typ,
+			BroadcasterID: broadcasterID,
+			CallbackURL:   s.CallbackURL,
+			Secret:        s.Secret,
+		})
+		if err != nil {
+			var statusErr *twitchapi.StatusError
+			if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusConflict {
+				s.logf("Twitch %s subscription for %s already exists", typ, broadcasterID)
+				continue
+			}
+			return fmt.Errorf("subscribe %s for %s: %w", typ, broadcasterID, err)
+		}
+		s.logf("Twitch %s subscription %s for %s is %s", typ, sub.ID, broadcasterID, sub.Status)
+	}
+	return nil
+}
+
+// SubscribeRecords subscribes every record with a Twitch broadcaster ID and returns the
+// first error encountered after attempting all of them.
+func (s Subscriber) SubscribeRecords(ctx context.Context, records []streamers.Record) error {
+	var firstErr error
+	for _, record := range records {
+		tw := record.Platforms.Twitch
+		if tw == nil || strings.TrimSpace(tw.BroadcasterID) == "" {
+			continue
+		}
+		if err := s.Subscribe(ctx, tw.BroadcasterID); err != nil {
+			s.logf("Twitch subscription for streamer %s failed: %v", record.Streamer.ID, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+func (s Subscriber) logf(format string, args ...any) {
+	if s.Logger != nil {
+		s.Logger.Printf(format, args...)
+	}
+}
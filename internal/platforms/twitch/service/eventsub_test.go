@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	twitchapi "live-stream-alerts/internal/platforms/twitch/api"
+	"live-stream-alerts/internal/platforms/twitch/eventsub"
+	"live-stream-alerts/internal/streamers"
+)
+
+func TestEventProcessorTogglesTwitchStatus(t *testing.T) {
+	store := streamers.NewStore(filepath.Join(t.TempDir(), "streamers.json"))
+	if _, err := store.Append(streamers.Record{
+		Streamer:  streamers.Streamer{Alias: "Demo"},
+		Platforms: streamers.Platforms{Twitch: &streamers.TwitchPlatform{Username: "demo", BroadcasterID: "1337"}},
+	}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	proc := EventProcessor{Streamers: store}
+
+	online, err := eventsub.DecodeMessage([]byte(`{"subscription":{"type":"stream.online"},"event":{"id":"9001","broadcaster_user_id":"1337","type":"live","started_at":"2024-01-01T10:00:00Z"}}`))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if err := proc.Process(context.Background(), online); err != nil {
+		t.Fatalf("process online: %v", err)
+	}
+	records, _ := store.List()
+	status := records[0].Status
+	if status == nil || !status.Live || status.Twitch == nil || !status.Twitch.Live || status.Twitch.StreamID != "9001" {
+		t.Fatalf("expected twitch live status, got %+v", status)
+	}
+	if len(status.Platforms) != 1 || status.Platforms[0] != "twitch" {
+		t.Fatalf("expected twitch in platforms, got %v", status.Platforms)
+	}
+
+	offline, _ := eventsub.DecodeMessage([]byte(`{"subscription":{"type":"stream.offline"},"event":{"broadcaster_user_id":"1337"}}`))
+	if err := proc.Process(context.Background(), offline); err != nil {
+		t.Fatalf("process offline: %v", err)
+	}
+	records, _ = store.List()
+	status = records[0].Status
+	if status.Live || status.Twitch.Live || len(status.Platforms) != 0 {
+		t.Fatalf("expected offline status, got %+v", status)
+	}
+
+	unknown, _ := eventsub.DecodeMessage([]byte(`{"subscription":{"type":"stream.online"},"event":{"broadcaster_user_id":"404"}}`))
+	if err := proc.Process(context.Background(), unknown); err != nil {
+		t.Fatalf("unknown broadcaster should be ignored, got %v", err)
+	}
+}
+
+type stubCreator struct {
+	requests []twitchapi.EventSubSubscriptionRequest
+	err      error
+}
+
+func (s *stubCreator) CreateEventSubSubscription(ctx context.Context, req twitchapi.EventSubSubscriptionRequest) (twitchapi.EventSubSubscription, error) {
+	s.requests = append(s.requests, req)
+	if s.err != nil {
+		return twitchapi.EventSubSubscription{}, s.err
+	}
+	return twitchapi.EventSubSubscription{ID: "sub", Type: req.Type}, nil
+}
+
+func TestSubscriberSubscribesOnlineAndOffline(t *testing.T) {
+	creator := &stubCreator{}
+	sub := Subscriber{Client: creator, CallbackURL: "https://example.com/alerts/twitch", Secret: "0123456789"}
+	err := sub.SubscribeRecords(context.Background(), []streamers.Record{
+		{Platforms: streamers.Platforms{Twitch: &streamers.TwitchPlatform{BroadcasterID: "1337"}}},
+		{Platforms: streamers.Platforms{YouTube: &streamers.YouTubePlatform{ChannelID: "UC"}}},
+	})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if len(creator.requests) != 2 {
+		t.Fatalf("expected 2 subscriptions, got %d", len(creator.requests))
+	}
+	if creator.requests[0].Type != twitchapi.EventStreamOnline || creator.requests[1].Type != twitchapi.EventStreamOffline {
+		t.Fatalf("unexpected subscription types: %+v", creator.requests)
+	}
+}
+
+func TestSubscriberTreatsConflictAsSuccess(t *testing.T) {
+	creator := &stubCreator{err: &twitchapi.StatusError{StatusCode: http.StatusConflict}}
+	sub := Subscriber{Client: creator, CallbackURL: "https://example.com/alerts/twitch", Secret: "0123456789"}
+	if err := sub.Subscribe(context.Background(), "1337"); err != nil {
+		t.Fatalf("expected conflict to be ignored, got %v", err)
+	}
+}
@@ -0,0 +1,382 @@
+// Package feedpoll reconciles YouTube channels by polling their public videos.xml feeds,
+// so broadcasts are still picked up when WebSub deliveries are lost or a lease lapses.
+package feedpoll
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"live-stream-alerts/internal/logging"
+	"live-stream-alerts/internal/platforms/youtube/monitoring"
+	youtubeservice "live-stream-alerts/internal/platforms/youtube/service"
+	"live-stream-alerts/internal/streamers"
+)
+
+const (
+	// DefaultFeedURL is YouTube's per-channel Atom feed of recent uploads and broadcasts.
+	DefaultFeedURL = "https://www.youtube.com/feeds/videos.xml"
+
+	defaultInterval     = 15 * time.Minute
+	defaultFastInterval = 2 * time.Minute
+	defaultMaxBackoff   = time.Hour
+	defaultTick         = 30 * time.Second
+	defaultPollTimeout  = 30 * time.Second
+)
+
+// EntryProcessor runs feed entries through the alert pipeline. youtubeservice.AlertProcessor
+// satisfies it.
+type EntryProcessor interface {
+	ProcessEntries(ctx context.Context, entries []youtubeservice.FeedEntry) (youtubeservice.AlertProcessResult, error)
+}
+
+// LeaseSource reports the WebSub lease state of every channel. monitoring.Service
+// satisfies it.
+type LeaseSource interface {
+	Overview(ctx context.Context) (monitoring.Overview, error)
+}
+
+// PollerConfig configures the feed poller.
+type PollerConfig struct {
+	Store     streamers.Repository
+	Processor EntryProcessor
+	// Leases, when set, selects the cadence per channel: channels whose lease is expired
+	// or pending, or that have no lease at all, are polled every FastInterval.
+	Leases     LeaseSource
+	HTTPClient *http.Client
+	// FeedURL overrides DefaultFeedURL; the channel is passed as ?channel_id=.
+	FeedURL string
+	// Interval is the cadence for channels with a healthy lease (default 15m).
+	Interval time.Duration
+	// FastInterval is the cadence for channels WebSub is not covering (default 2m).
+	FastInterval time.Duration
+	// MaxBackoff caps the delay after repeated failures for one channel (default 1h).
+	MaxBackoff time.Duration
+	Logger     logging.Logger
+	Now        func() time.Time
+}
+
+// Poller periodically fetches each YouTube channel's feed and processes entries it has
+// not seen before.
+type Poller struct {
+	cfg      PollerConfig
+	tick     time.Duration
+	mu       sync.Mutex
+	channels map[string]*channelState
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+type channelState struct {
+	seen     map[string]struct{}
+	failures int
+	nextPoll time.Time
+}
+
+// StartPoller launches the poller using the provided context.
+func StartPoller(ctx context.Context, cfg PollerConfig) *Poller {
+	poller := NewPoller(cfg)
+	runCtx, cancel := context.WithCancel(ctx)
+	poller.cancel = cancel
+	poller.wg.Add(1)
+	go func() {
+		defer poller.wg.Done()
+		poller.run(runCtx)
+	}()
+	return poller
+}
+
+// NewPoller builds a poller without starting its loop, for callers that drive Poll
+// themselves.
+func NewPoller(cfg PollerConfig) *Poller {
+	if cfg.Store == nil {
+		cfg.Store = streamers.NewStore(streamers.DefaultFilePath)
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if strings.TrimSpace(cfg.FeedURL) == "" {
+		cfg.FeedURL = DefaultFeedURL
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.FastInterval <= 0 {
+		cfg.FastInterval = defaultFastInterval
+	}
+	if cfg.FastInterval > cfg.Interval {
+		cfg.FastInterval = cfg.Interval
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	tick := defaultTick
+	if cfg.FastInterval < tick {
+		tick = cfg.FastInterval
+	}
+	return &Poller{cfg: cfg, tick: tick, channels: make(map[string]*channelState)}
+}
+
+// Stop cancels the poller and waits for the polling goroutine to exit.
+func (p *Poller) Stop() {
+	if p == nil {
+		return
+	}
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+}
+
+func (p *Poller) run(ctx context.Context) {
+	p.Poll(ctx)
+
+	ticker := time.NewTicker(p.tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.Poll(ctx)
+		}
+	}
+}
+
+type channel struct {
+	id     string
+	alias  string
+	record streamers.Record
+}
+
+// Poll fetches the feed of every channel that is due and processes new entries. It
+// returns the number of feeds fetched.
+func (p *Poller) Poll(ctx context.Context) int {
+	if p.cfg.Processor == nil {
+		p.logf("feed poller: entry processor is not configured")
+		return 0
+	}
+	records, err := p.cfg.Store.List()
+	if err != nil {
+		p.logf("feed poller: failed to read streamers: %v", err)
+		return 0
+	}
+	channels := collectChannels(records)
+	leases := p.leaseStatuses(ctx)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	active := make(map[string]struct{}, len(channels))
+	var polled int
+	for _, ch := range channels {
+		if ctx.Err() != nil {
+			break
+		}
+		active[ch.id] = struct{}{}
+		state := p.channels[ch.id]
+		if state == nil {
+			state = &channelState{seen: make(map[string]struct{})}
+			p.channels[ch.id] = state
+		}
+		if p.cfg.Now().Before(state.nextPoll) {
+			continue
+		}
+		cadence := p.cadence(leases, ch.id)
+		pollCtx, cancel := context.WithTimeout(ctx, defaultPollTimeout)
+		err := p.pollChannel(pollCtx, ch, state)
+		cancel()
+		polled++
+		if err != nil {
+			state.failures++
+			delay := backoff(cadence, state.failures, p.cfg.MaxBackoff)
+			state.nextPoll = p.cfg.Now().Add(delay)
+			p.logf("feed poller: %s (%s) failed %d time(s), next poll in %s: %v", ch.alias, ch.id, state.failures, delay, err)
+			continue
+		}
+		state.failures = 0
+		state.nextPoll = p.cfg.Now().Add(cadence)
+	}
+	for id := range p.channels {
+		if _, ok := active[id]; !ok {
+			delete(p.channels, id)
+		}
+	}
+	return polled
+}
+
+// pollChannel fetches one feed and processes the entries that are neither seen nor already
+// known to the store. Seen entries are only kept in memory, so the first poll after a
+// start looks up every entry the store does not know: a broadcast scheduled days ago is
+// still picked up. Entries whose processing or lookup failed stay unseen for the next poll.
+func (p *Poller) pollChannel(ctx context.Context, ch channel, state *channelState) error {
+	entries, err := p.fetch(ctx, ch.id)
+	if err != nil {
+		return err
+	}
+	known := knownVideos(ch.record)
+	seen := make(map[string]struct{}, len(entries))
+	var fresh []youtubeservice.FeedEntry
+	for _, entry := range entries {
+		id := strings.TrimSpace(entry.VideoID)
+		if id == "" {
+			continue
+		}
+		seen[id] = struct{}{}
+		if _, ok := state.seen[id]; ok {
+			continue
+		}
+		if _, ok := known[id]; ok {
+			continue
+		}
+		if strings.TrimSpace(entry.ChannelID) == "" {
+			entry.ChannelID = ch.id
+		}
+		fresh = append(fresh, entry)
+	}
+	if len(fresh) == 0 {
+		state.seen = seen
+		return nil
+	}
+
+	result, err := p.cfg.Processor.ProcessEntries(ctx, fresh)
+	if err != nil {
+		// Keep the rest of the feed marked so the retry only covers the failures.
+		for _, entry := range fresh {
+			delete(seen, strings.TrimSpace(entry.VideoID))
+		}
+		state.seen = seen
+		return fmt.Errorf("process %d new entries: %w", len(fresh), err)
+	}
+	for _, id := range result.LookupFailed {
+		delete(seen, id)
+	}
+	state.seen = seen
+	p.logf("feed poller: %s (%s) had %d new entries: %d live, %d upcoming, %d skipped",
+		ch.alias, ch.id, len(fresh), len(result.LiveUpdates), len(result.Upcoming), len(result.SkippedVideos))
+	if len(result.LookupFailed) > 0 {
+		return fmt.Errorf("%w for %s", youtubeservice.ErrLookupFailed, strings.Join(result.LookupFailed, ","))
+	}
+	return nil
+}
+
+func (p *Poller) fetch(ctx context.Context, channelID string) ([]youtubeservice.FeedEntry, error) {
+	feedURL, err := url.Parse(p.cfg.FeedURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse feed url: %w", err)
+	}
+	query := feedURL.Query()
+	query.Set("channel_id", channelID)
+	feedURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("build feed request: %w", err)
+	}
+	resp, err := p.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch feed: unexpected status %d", resp.StatusCode)
+	}
+	return youtubeservice.ParseFeed(resp.Body)
+}
+
+// leaseStatuses maps channel IDs to their lease status. Without a lease source, or when
+// it fails, every channel falls back to the regular cadence.
+func (p *Poller) leaseStatuses(ctx context.Context) map[string]monitoring.LeaseStatus {
+	if p.cfg.Leases == nil {
+		return nil
+	}
+	overview, err := p.cfg.Leases.Overview(ctx)
+	if err != nil {
+		p.logf("feed poller: failed to read lease overview: %v", err)
+		return nil
+	}
+	statuses := make(map[string]monitoring.LeaseStatus, len(overview.Records))
+	for _, entry := range overview.Records {
+		statuses[strings.ToLower(strings.TrimSpace(entry.ChannelID))] = entry.Status
+	}
+	return statuses
+}
+
+func (p *Poller) cadence(leases map[string]monitoring.LeaseStatus, channelID string) time.Duration {
+	if leases == nil {
+		return p.cfg.Interval
+	}
+	switch leases[strings.ToLower(channelID)] {
+	case monitoring.LeaseStatusHealthy, monitoring.LeaseStatusRenewing:
+		return p.cfg.Interval
+	default:
+		return p.cfg.FastInterval
+	}
+}
+
+// backoff doubles cadence for every consecutive failure, capped at max but never below
+// the cadence itself.
+func backoff(cadence time.Duration, failures int, max time.Duration) time.Duration {
+	if max < cadence {
+		max = cadence
+	}
+	wait := cadence
+	for i := 0; i < failures; i++ {
+		wait *= 2
+		if wait >= max {
+			return max
+		}
+	}
+	return wait
+}
+
+func collectChannels(records []streamers.Record) []channel {
+	var channels []channel
+	seen := make(map[string]struct{}, len(records))
+	for _, record := range records {
+		yt := record.Platforms.YouTube
+		if yt == nil {
+			continue
+		}
+		id := strings.TrimSpace(yt.ChannelID)
+		if id == "" {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		channels = append(channels, channel{id: id, alias: record.Streamer.Alias, record: record})
+	}
+	return channels
+}
+
+// knownVideos lists the videos the record already tracks, so entries WebSub delivered
+// are not processed (and announced) a second time.
+func knownVideos(record streamers.Record) map[string]struct{} {
+	known := make(map[string]struct{})
+	if status := record.Status; status != nil && status.YouTube != nil {
+		if id := strings.TrimSpace(status.YouTube.VideoID); id != "" {
+			known[id] = struct{}{}
+		}
+	}
+	for _, broadcast := range record.Schedule {
+		if broadcast.Platform == "youtube" && broadcast.VideoID != "" {
+			known[broadcast.VideoID] = struct{}{}
+		}
+	}
+	return known
+}
+
+func (p *Poller) logf(format string, args ...any) {
+	if p.cfg.Logger == nil {
+		return
+	}
+	p.cfg.Logger.Printf(format, args...)
+}
@@ -0,0 +1,245 @@
+package feedpoll
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"live-stream-alerts/internal/platforms/youtube/monitoring"
+	youtubeservice "live-stream-alerts/internal/platforms/youtube/service"
+	"live-stream-alerts/internal/streamers"
+)
+
+type feedServer struct {
+	mu      sync.Mutex
+	entries map[string][]youtubeservice.FeedEntry
+	fetches map[string]int
+	status  int
+}
+
+func (f *feedServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	channelID := r.URL.Query().Get("channel_id")
+	f.fetches[channelID]++
+	if f.status != 0 {
+		w.WriteHeader(f.status)
+		return
+	}
+	var body strings.Builder
+	body.WriteString(`<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">`)
+	for _, entry := range f.entries[channelID] {
+		fmt.Fprintf(&body, `<entry><yt:videoId>%s</yt:videoId><yt:channelId>%s</yt:channelId><title>%s</title><published>%s</published></entry>`,
+			entry.VideoID, channelID, entry.Title, entry.Published.Format(time.RFC3339))
+	}
+	body.WriteString(`</feed>`)
+	_, _ = w.Write([]byte(body.String()))
+}
+
+func (f *feedServer) count(channelID string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fetches[channelID]
+}
+
+type recordingProcessor struct {
+	err error
+	// lookupFailed lists videos reported in AlertProcessResult.LookupFailed.
+	lookupFailed map[string]bool
+	processed    []string
+}
+
+func (r *recordingProcessor) ProcessEntries(ctx context.Context, entries []youtubeservice.FeedEntry) (youtubeservice.AlertProcessResult, error) {
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.VideoID)
+	}
+	result := youtubeservice.AlertProcessResult{Entries: len(entries), VideoIDs: ids}
+	if r.err != nil {
+		return result, r.err
+	}
+	for _, id := range ids {
+		if r.lookupFailed[id] {
+			result.LookupFailed = append(result.LookupFailed, id)
+			continue
+		}
+		r.processed = append(r.processed, id)
+	}
+	return result, nil
+}
+
+type stubLeases map[string]monitoring.LeaseStatus
+
+func (s stubLeases) Overview(ctx context.Context) (monitoring.Overview, error) {
+	var overview monitoring.Overview
+	for channelID, status := range s {
+		overview.Records = append(overview.Records, monitoring.LeaseEntry{ChannelID: channelID, Status: status})
+	}
+	return overview, nil
+}
+
+func newPollStore(t *testing.T, channels ...string) *streamers.Store {
+	t.Helper()
+	store := streamers.NewStore(filepath.Join(t.TempDir(), "streamers.json"))
+	for _, channelID := range channels {
+		if _, err := store.Append(streamers.Record{
+			Streamer:  streamers.Streamer{Alias: channelID},
+			Platforms: streamers.Platforms{YouTube: &streamers.YouTubePlatform{ChannelID: channelID}},
+		}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	return store
+}
+
+func TestPollerProcessesNewEntriesAtLeaseCadence(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := newPollStore(t, "UChealthy", "UCexpired")
+	if _, err := store.SetYouTubeLive("UCexpired", "known", now.Add(-10*time.Minute)); err != nil {
+		t.Fatalf("set live: %v", err)
+	}
+	feeds := &feedServer{fetches: map[string]int{}, entries: map[string][]youtubeservice.FeedEntry{
+		"UChealthy": {
+			{VideoID: "recent", Published: now.Add(-5 * time.Minute)},
+			{VideoID: "old", Published: now.Add(-48 * time.Hour)},
+		},
+		"UCexpired": {
+			{VideoID: "known", Published: now.Add(-10 * time.Minute)},
+		},
+	}}
+	server := httptest.NewServer(feeds)
+	defer server.Close()
+
+	processor := &recordingProcessor{}
+	poller := NewPoller(PollerConfig{
+		Store:     store,
+		Processor: processor,
+		Leases:    stubLeases{"UChealthy": monitoring.LeaseStatusHealthy, "UCexpired": monitoring.LeaseStatusExpired},
+		FeedURL:   server.URL + "/feeds/videos.xml",
+		Now:       func() time.Time { return now },
+	})
+
+	if got := poller.Poll(context.Background()); got != 2 {
+		t.Fatalf("expected both feeds to be fetched, got %d", got)
+	}
+	if strings.Join(processor.processed, ",") != "recent,old" {
+		t.Fatalf("expected every unknown entry to be processed on the first poll, got %v", processor.processed)
+	}
+
+	feeds.mu.Lock()
+	feeds.entries["UCexpired"] = append([]youtubeservice.FeedEntry{{VideoID: "missed", Published: now}}, feeds.entries["UCexpired"]...)
+	feeds.entries["UChealthy"] = append([]youtubeservice.FeedEntry{{VideoID: "later", Published: now}}, feeds.entries["UChealthy"]...)
+	feeds.mu.Unlock()
+
+	now = now.Add(3 * time.Minute)
+	poller.Poll(context.Background())
+	if feeds.count("UCexpired") != 2 || feeds.count("UChealthy") != 1 {
+		t.Fatalf("expected only the expired lease to be polled again, got expired=%d healthy=%d", feeds.count("UCexpired"), feeds.count("UChealthy"))
+	}
+	if strings.Join(processor.processed, ",") != "recent,old,missed" {
+		t.Fatalf("expected the missed broadcast to be processed, got %v", processor.processed)
+	}
+
+	now = now.Add(15 * time.Minute)
+	poller.Poll(context.Background())
+	if strings.Join(processor.processed, ",") != "recent,old,missed,later" {
+		t.Fatalf("expected seen entries to be skipped, got %v", processor.processed)
+	}
+}
+
+func TestPollerBacksOffAndRetriesFailedEntries(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := newPollStore(t, "UCflaky")
+	feeds := &feedServer{fetches: map[string]int{}, entries: map[string][]youtubeservice.FeedEntry{
+		"UCflaky": {{VideoID: "vid1", Published: now}},
+	}}
+	server := httptest.NewServer(feeds)
+	defer server.Close()
+
+	processor := &recordingProcessor{err: youtubeservice.ErrLookupFailed}
+	poller := NewPoller(PollerConfig{
+		Store:        store,
+		Processor:    processor,
+		FeedURL:      server.URL,
+		Interval:     10 * time.Minute,
+		FastInterval: time.Minute,
+		MaxBackoff:   30 * time.Minute,
+		Now:          func() time.Time { return now },
+	})
+
+	poller.Poll(context.Background())
+	state := poller.channels["UCflaky"]
+	if state.failures != 1 || !state.nextPoll.Equal(now.Add(20*time.Minute)) {
+		t.Fatalf("expected one failure and a doubled delay, got %d failures, next %s", state.failures, state.nextPoll)
+	}
+
+	feeds.status = http.StatusServiceUnavailable
+	now = now.Add(20 * time.Minute)
+	poller.Poll(context.Background())
+	if state.failures != 2 || !state.nextPoll.Equal(now.Add(30*time.Minute)) {
+		t.Fatalf("expected the delay to be capped, got %d failures, next %s", state.failures, state.nextPoll)
+	}
+
+	feeds.status = 0
+	processor.err = nil
+	now = now.Add(30 * time.Minute)
+	poller.Poll(context.Background())
+	if state.failures != 0 || strings.Join(processor.processed, ",") != "vid1" {
+		t.Fatalf("expected the failed entry to be retried, got failures=%d processed=%v", state.failures, processor.processed)
+	}
+}
+
+func TestPollerRetriesEntriesWhoseLookupFailed(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := newPollStore(t, "UCpartial")
+	feeds := &feedServer{fetches: map[string]int{}, entries: map[string][]youtubeservice.FeedEntry{
+		"UCpartial": {{VideoID: "ok", Published: now}, {VideoID: "flaky", Published: now.Add(-72 * time.Hour)}},
+	}}
+	server := httptest.NewServer(feeds)
+	defer server.Close()
+
+	processor := &recordingProcessor{lookupFailed: map[string]bool{"flaky": true}}
+	poller := NewPoller(PollerConfig{
+		Store:     store,
+		Processor: processor,
+		FeedURL:   server.URL,
+		Interval:  10 * time.Minute,
+		Now:       func() time.Time { return now },
+	})
+
+	poller.Poll(context.Background())
+	state := poller.channels["UCpartial"]
+	if _, ok := state.seen["flaky"]; ok || state.failures != 1 {
+		t.Fatalf("expected the failed lookup to stay unseen and back off, got seen=%v failures=%d", state.seen, state.failures)
+	}
+
+	processor.lookupFailed = nil
+	now = now.Add(20 * time.Minute)
+	poller.Poll(context.Background())
+	if strings.Join(processor.processed, ",") != "ok,flaky" || state.failures != 0 {
+		t.Fatalf("expected only the failed entry to be retried, got processed=%v failures=%d", processor.processed, state.failures)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cases := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 0, want: 2 * time.Minute},
+		{failures: 1, want: 4 * time.Minute},
+		{failures: 3, want: 16 * time.Minute},
+		{failures: 10, want: time.Hour},
+	}
+	for _, tc := range cases {
+		if got := backoff(2*time.Minute, tc.failures, time.Hour); got != tc.want {
+			t.Fatalf("backoff(%d) = %s, want %s", tc.failures, got, tc.want)
+		}
+	}
+}
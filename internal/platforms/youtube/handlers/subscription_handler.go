@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"strings"
 
+	"live-stream-alerts/internal/audit"
 	"live-stream-alerts/internal/logging"
 	youtubeservice "live-stream-alerts/internal/platforms/youtube/service"
 	"live-stream-alerts/internal/platforms/youtube/subscriptions"
@@ -22,6 +23,8 @@ type SubscriptionHandlerOptions struct {
 	VerifyMode   string
 	LeaseSeconds int
 	Proxy        subscriptionProxy
+	// Audit records hub requests the hub accepted. When nil nothing is recorded.
+	Audit audit.Recorder
 }
 
 // SubscribeHandlerOptions configures the subscribe handler.
@@ -35,8 +38,20 @@ type subscriptionProxy interface {
 }
 
 type subscriptionHandler struct {
+	mode   string
 	proxy  subscriptionProxy
 	logger logging.Logger
+	audit  audit.Recorder
+}
+
+// subscriptionAudit is what the audit log keeps of a hub request; the secret and verify
+// token are left out.
+type subscriptionAudit struct {
+	Topic        string `json:"topic"`
+	Callback     string `json:"callback,omitempty"`
+	ChannelID    string `json:"channelId,omitempty"`
+	LeaseSeconds int    `json:"leaseSeconds,omitempty"`
+	Status       int    `json:"status"`
 }
 
 // NewSubscribeHandler returns an http.Handler that accepts POST requests and forwards them to YouTube's hub.
@@ -61,7 +76,7 @@ func newSubscriptionHandler(mode string, opts SubscriptionHandlerOptions) http.H
 			LeaseSeconds: opts.LeaseSeconds,
 		})
 	}
-	handler := subscriptionHandler{proxy: proxy, logger: opts.Logger}
+	handler := subscriptionHandler{mode: mode, proxy: proxy, logger: opts.Logger, audit: opts.Audit}
 	return http.HandlerFunc(handler.ServeHTTP)
 }
 
@@ -83,6 +98,20 @@ func (h subscriptionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.respondError(w, err)
 		return
 	}
+	if h.audit != nil && result.StatusCode >= 200 && result.StatusCode < 300 {
+		h.audit.Record(r.Context(), audit.Entry{
+			Action:     "youtube." + h.mode,
+			TargetType: "youtube_topic",
+			TargetID:   req.Topic,
+			After: audit.Snapshot(subscriptionAudit{
+				Topic:        req.Topic,
+				Callback:     req.Callback,
+				ChannelID:    req.ChannelID,
+				LeaseSeconds: req.LeaseSeconds,
+				Status:       result.StatusCode,
+			}),
+		})
+	}
 	writeSubscriptionResponse(w, result)
 }
 
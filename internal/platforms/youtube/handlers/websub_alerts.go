@@ -19,7 +19,7 @@ import (
 // SubscriptionConfirmationOptions configures how hub verification requests are handled.
 type SubscriptionConfirmationOptions struct {
 	Logger         logging.Logger
-	StreamersStore *streamers.Store
+	StreamersStore streamers.Repository
 }
 
 type hubRequest struct {
@@ -175,7 +175,7 @@ func logPlannedResponse(logger logging.Logger, w http.ResponseWriter, challenge
 	logger.Printf("Planned hub response:\n%s", responseDump.String())
 }
 
-func updateLeaseIfNeeded(req hubRequest, exp websub.Expectation, store *streamers.Store, verifiedAt time.Time, logger logging.Logger) string {
+func updateLeaseIfNeeded(req hubRequest, exp websub.Expectation, store streamers.Repository, verifiedAt time.Time, logger logging.Logger) string {
 	channelID := exp.ChannelID
 	if channelID == "" {
 		channelID = websub.ExtractChannelID(req.Topic)
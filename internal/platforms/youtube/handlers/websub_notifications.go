@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"io"
@@ -8,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"live-stream-alerts/internal/jobs"
 	"live-stream-alerts/internal/logging"
 	youtubeservice "live-stream-alerts/internal/platforms/youtube/service"
 	"live-stream-alerts/internal/streamers"
@@ -17,12 +19,34 @@ type alertProcessor interface {
 	Process(ctx context.Context, req youtubeservice.AlertProcessRequest) (youtubeservice.AlertProcessResult, error)
 }
 
+type signatureVerifier interface {
+	Verify(req youtubeservice.SignatureRequest) error
+}
+
+const (
+	// SignatureModeEnforce rejects notifications whose X-Hub-Signature does not match the stored hub secret.
+	SignatureModeEnforce = "enforce"
+	// SignatureModeLog logs signature mismatches but still processes the notification.
+	SignatureModeLog = "log"
+)
+
 // AlertNotificationOptions configure POST /alerts handling.
 type AlertNotificationOptions struct {
 	Logger         logging.Logger
-	StreamersStore *streamers.Store
+	StreamersStore streamers.Repository
 	VideoLookup    youtubeservice.LiveVideoLookup
+	Notifier       youtubeservice.LiveNotifier
 	Processor      alertProcessor
+	Signatures     signatureVerifier
+	SignatureMode  string
+	// Retry, when set, persists notifications whose video lookup failed, in full or for
+	// some videos, so a background worker can process them again instead of dropping them.
+	Retry RetryQueue
+}
+
+// RetryQueue persists a job of the given kind for asynchronous processing.
+type RetryQueue interface {
+	Enqueue(kind string, payload any) (jobs.Job, error)
 }
 
 // HandleAlertNotification processes YouTube hub POST notifications.
@@ -44,22 +68,43 @@ func HandleAlertNotification(w http.ResponseWriter, r *http.Request, opts AlertN
 		proc = &youtubeservice.AlertProcessor{
 			Streamers:   opts.StreamersStore,
 			VideoLookup: opts.VideoLookup,
+			Notifier:    opts.Notifier,
 		}
 	}
 
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "failed to read notification", http.StatusBadRequest)
+		return true
+	}
+	if !verifyAlertSignature(w, r, body, opts) {
+		return true
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
 	result, err := proc.Process(ctx, youtubeservice.AlertProcessRequest{
-		Feed:       io.LimitReader(r.Body, 1<<20),
+		Feed:       bytes.NewReader(body),
 		RemoteAddr: r.RemoteAddr,
 	})
 	if err != nil {
-		handleAlertError(w, err, result, opts.Logger)
+		handleAlertError(w, r, body, err, result, opts)
 		return true
 	}
+	if len(result.LookupFailed) > 0 {
+		if opts.Logger != nil {
+			opts.Logger.Printf("failed to fetch live metadata for videos %s", strings.Join(result.LookupFailed, ","))
+		}
+		if !queueNotification(w, r, body, result.LookupFailed, opts) {
+			return true
+		}
+	}
 
 	if opts.Logger != nil {
+		for _, notifyErr := range result.NotifyErrors {
+			opts.Logger.Printf("failed to queue go-live alert for %s", notifyErr)
+		}
 		if len(result.LiveUpdates) == 0 {
 			opts.Logger.Printf("Processed alert notification for %d video(s); no live streams detected", result.Entries)
 		} else {
@@ -70,11 +115,55 @@ func HandleAlertNotification(w http.ResponseWriter, r *http.Request, opts AlertN
 			)
 		}
 	}
+	if len(result.LookupFailed) > 0 && opts.Retry != nil {
+		w.WriteHeader(http.StatusAccepted)
+		return true
+	}
 	w.WriteHeader(http.StatusNoContent)
 	return true
 }
 
-func handleAlertError(w http.ResponseWriter, err error, result youtubeservice.AlertProcessResult, logger logging.Logger) {
+// verifyAlertSignature checks the hub signature headers and reports whether processing should continue.
+func verifyAlertSignature(w http.ResponseWriter, r *http.Request, body []byte, opts AlertNotificationOptions) bool {
+	verifier := opts.Signatures
+	if verifier == nil {
+		if opts.StreamersStore == nil {
+			return true
+		}
+		verifier = youtubeservice.SignatureVerifier{Streamers: opts.StreamersStore}
+	}
+	err := verifier.Verify(youtubeservice.SignatureRequest{
+		Body:         body,
+		Signature:    r.Header.Get("X-Hub-Signature"),
+		Signature256: r.Header.Get("X-Hub-Signature-256"),
+	})
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, youtubeservice.ErrInvalidFeed):
+		http.Error(w, "invalid atom feed", http.StatusBadRequest)
+		return false
+	case errors.Is(err, youtubeservice.ErrInvalidSignature):
+		logOnly := strings.EqualFold(strings.TrimSpace(opts.SignatureMode), SignatureModeLog)
+		if opts.Logger != nil {
+			opts.Logger.Printf("alert notification signature mismatch from %s (log only=%v): %v", r.RemoteAddr, logOnly, err)
+		}
+		if logOnly {
+			return true
+		}
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return false
+	default:
+		if opts.Logger != nil {
+			opts.Logger.Printf("failed to verify notification signature: %v", err)
+		}
+		http.Error(w, "failed to process notification", http.StatusInternalServerError)
+		return false
+	}
+}
+
+func handleAlertError(w http.ResponseWriter, r *http.Request, body []byte, err error, result youtubeservice.AlertProcessResult, opts AlertNotificationOptions) {
+	logger := opts.Logger
 	switch {
 	case errors.Is(err, youtubeservice.ErrInvalidFeed):
 		http.Error(w, "invalid atom feed", http.StatusBadRequest)
@@ -82,6 +171,9 @@ func handleAlertError(w http.ResponseWriter, err error, result youtubeservice.Al
 		if logger != nil && len(result.VideoIDs) > 0 {
 			logger.Printf("failed to fetch live metadata for videos %s: %v", strings.Join(result.VideoIDs, ","), err)
 		}
+		if !queueNotification(w, r, body, nil, opts) {
+			return
+		}
 		w.WriteHeader(http.StatusAccepted)
 	default:
 		if logger != nil {
@@ -90,3 +182,29 @@ func handleAlertError(w http.ResponseWriter, err error, result youtubeservice.Al
 		http.Error(w, "failed to process notification", http.StatusInternalServerError)
 	}
 }
+
+// queueNotification persists the notification for a retry, limited to videoIDs when set.
+// It reports whether the caller should go on to respond; on a queue failure it has already
+// answered 500, so the hub redelivers instead of the notification being lost.
+func queueNotification(w http.ResponseWriter, r *http.Request, body []byte, videoIDs []string, opts AlertNotificationOptions) bool {
+	if opts.Retry == nil {
+		return true
+	}
+	job, err := opts.Retry.Enqueue(youtubeservice.NotificationJobKind, youtubeservice.NotificationJob{
+		Feed:       string(body),
+		RemoteAddr: r.RemoteAddr,
+		ReceivedAt: time.Now().UTC(),
+		VideoIDs:   videoIDs,
+	})
+	if err != nil {
+		if opts.Logger != nil {
+			opts.Logger.Printf("failed to queue notification for retry: %v", err)
+		}
+		http.Error(w, "failed to process notification", http.StatusInternalServerError)
+		return false
+	}
+	if opts.Logger != nil {
+		opts.Logger.Printf("queued notification as retry job %s", job.ID)
+	}
+	return true
+}
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"live-stream-alerts/internal/jobs"
 	youtubeservice "live-stream-alerts/internal/platforms/youtube/service"
 )
 
@@ -78,6 +79,66 @@ func TestHandleAlertNotificationHandlesLookupError(t *testing.T) {
 	}
 }
 
+type stubRetryQueue struct {
+	kinds    []string
+	payloads []any
+	err      error
+}
+
+func (s *stubRetryQueue) Enqueue(kind string, payload any) (jobs.Job, error) {
+	if s.err != nil {
+		return jobs.Job{}, s.err
+	}
+	s.kinds = append(s.kinds, kind)
+	s.payloads = append(s.payloads, payload)
+	return jobs.Job{ID: "job_1", Kind: kind}, nil
+}
+
+func TestHandleAlertNotificationQueuesLookupFailures(t *testing.T) {
+	stub := &stubAlertProcessor{err: youtubeservice.ErrLookupFailed}
+	queue := &stubRetryQueue{}
+	opts := AlertNotificationOptions{Processor: stub, Retry: queue}
+	req := httptest.NewRequest(http.MethodPost, "/alerts", bytes.NewBufferString("<feed/>"))
+	rr := httptest.NewRecorder()
+
+	HandleAlertNotification(rr, req, opts)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rr.Code)
+	}
+	if len(queue.kinds) != 1 || queue.kinds[0] != youtubeservice.NotificationJobKind {
+		t.Fatalf("expected one queued notification job, got %v", queue.kinds)
+	}
+	if job := queue.payloads[0].(youtubeservice.NotificationJob); job.Feed != "<feed/>" {
+		t.Fatalf("expected the raw feed to be queued, got %+v", job)
+	}
+
+	queue.err = errors.New("disk full")
+	rr = httptest.NewRecorder()
+	HandleAlertNotification(rr, httptest.NewRequest(http.MethodPost, "/alerts", bytes.NewBufferString("<feed/>")), opts)
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 so the hub redelivers when queueing fails, got %d", rr.Code)
+	}
+}
+
+func TestHandleAlertNotificationQueuesPartialLookupFailures(t *testing.T) {
+	stub := &stubAlertProcessor{result: youtubeservice.AlertProcessResult{
+		VideoIDs:     []string{"vid1", "vid2"},
+		LookupFailed: []string{"vid2"},
+	}}
+	queue := &stubRetryQueue{}
+	rr := httptest.NewRecorder()
+	HandleAlertNotification(rr, httptest.NewRequest(http.MethodPost, "/alerts", bytes.NewBufferString("<feed/>")), AlertNotificationOptions{Processor: stub, Retry: queue})
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rr.Code)
+	}
+	if len(queue.payloads) != 1 {
+		t.Fatalf("expected one queued notification job, got %d", len(queue.payloads))
+	}
+	if job := queue.payloads[0].(youtubeservice.NotificationJob); len(job.VideoIDs) != 1 || job.VideoIDs[0] != "vid2" {
+		t.Fatalf("expected only the failed video to be queued, got %+v", job)
+	}
+}
+
 func TestHandleAlertNotificationHandlesUnknownError(t *testing.T) {
 	stub := &stubAlertProcessor{err: errors.New("boom")}
 	opts := AlertNotificationOptions{Processor: stub}
@@ -98,3 +159,49 @@ func TestHandleAlertNotificationSkipsUnsupportedPaths(t *testing.T) {
 		t.Fatalf("expected handler to ignore unsupported paths")
 	}
 }
+
+type stubSignatureVerifier struct {
+	err   error
+	calls int
+}
+
+func (s *stubSignatureVerifier) Verify(req youtubeservice.SignatureRequest) error {
+	s.calls++
+	return s.err
+}
+
+func TestHandleAlertNotificationRejectsInvalidSignature(t *testing.T) {
+	stub := &stubAlertProcessor{}
+	verifier := &stubSignatureVerifier{err: youtubeservice.ErrInvalidSignature}
+	opts := AlertNotificationOptions{Processor: stub, Signatures: verifier}
+	req := httptest.NewRequest(http.MethodPost, "/alerts", bytes.NewBufferString("<feed/>"))
+	rr := httptest.NewRecorder()
+
+	if !HandleAlertNotification(rr, req, opts) {
+		t.Fatalf("expected handler to process request")
+	}
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rr.Code)
+	}
+	if stub.calls != 0 {
+		t.Fatalf("expected processor to be skipped, got %d calls", stub.calls)
+	}
+}
+
+func TestHandleAlertNotificationLogOnlySignatureMode(t *testing.T) {
+	stub := &stubAlertProcessor{}
+	verifier := &stubSignatureVerifier{err: youtubeservice.ErrInvalidSignature}
+	opts := AlertNotificationOptions{Processor: stub, Signatures: verifier, SignatureMode: SignatureModeLog}
+	req := httptest.NewRequest(http.MethodPost, "/alerts", bytes.NewBufferString("<feed/>"))
+	rr := httptest.NewRecorder()
+
+	if !HandleAlertNotification(rr, req, opts) {
+		t.Fatalf("expected handler to process request")
+	}
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rr.Code)
+	}
+	if stub.calls != 1 || verifier.calls != 1 {
+		t.Fatalf("expected verifier and processor to run once, got %d/%d", verifier.calls, stub.calls)
+	}
+}
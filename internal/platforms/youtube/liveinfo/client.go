@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,6 +22,13 @@ type Client struct {
 	HTTPClient *http.Client
 	BaseURL    string
 	Logger     logging.Logger
+	// Observer, when set, is told the latency and outcome of every watch-page fetch.
+	Observer FetchObserver
+}
+
+// FetchObserver receives the duration and result of each watch-page fetch.
+type FetchObserver interface {
+	ObserveFetch(duration time.Duration, err error)
 }
 
 // VideoInfo represents the parsed metadata for a video.
@@ -28,18 +37,80 @@ type VideoInfo struct {
 	ChannelID            string
 	Title                string
 	LiveBroadcastContent string
-	ActualStartTime      time.Time
+	// ScheduledStartTime is the announced start of an upcoming broadcast.
+	ScheduledStartTime time.Time
+	ActualStartTime    time.Time
+	ActualEndTime      time.Time
+	// Playability is the watch page's playabilityStatus, e.g. "OK" or "ERROR".
+	Playability string
+}
+
+// LookupError lists the video IDs whose watch page could not be fetched or parsed.
+// Fetch returns it together with the metadata of every other ID, so callers can act on
+// what was learned and retry the rest. A failed ID says nothing about the broadcast.
+type LookupError struct {
+	Failed map[string]error
+}
+
+func (e *LookupError) Error() string {
+	ids := e.FailedIDs()
+	if len(ids) == 0 {
+		return "video lookup failed"
+	}
+	return fmt.Sprintf("lookup failed for %s: %v", strings.Join(ids, ", "), e.Failed[ids[0]])
+}
+
+// Unwrap returns the per-ID errors.
+func (e *LookupError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed))
+	for _, id := range e.FailedIDs() {
+		errs = append(errs, e.Failed[id])
+	}
+	return errs
+}
+
+// FailedIDs returns the IDs whose lookup failed, sorted.
+func (e *LookupError) FailedIDs() []string {
+	if e == nil {
+		return nil
+	}
+	ids := make([]string, 0, len(e.Failed))
+	for id := range e.Failed {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
 }
 
 // IsLive reports whether the video is currently live.
 func (v VideoInfo) IsLive() bool {
+	if v.Ended() || v.IsUpcoming() || v.Unplayable() {
+		return false
+	}
 	if strings.EqualFold(v.LiveBroadcastContent, "live") {
 		return true
 	}
 	return !v.ActualStartTime.IsZero()
 }
 
-// Fetch retrieves metadata for each supplied video ID.
+// IsUpcoming reports whether the video is a scheduled broadcast that has not started.
+func (v VideoInfo) IsUpcoming() bool {
+	return !v.Ended() && strings.EqualFold(v.LiveBroadcastContent, "upcoming")
+}
+
+// Unplayable reports whether YouTube refuses to play the video, as it does once a video
+// has been deleted or a broadcast has failed.
+func (v VideoInfo) Unplayable() bool {
+	return strings.EqualFold(v.Playability, "ERROR") || strings.EqualFold(v.Playability, "UNPLAYABLE")
+}
+
+// Ended reports whether the broadcast has finished.
+func (v VideoInfo) Ended() bool {
+	return !v.ActualEndTime.IsZero()
+}
+
+// Fetch retrieves metadata for each supplied video ID. When some IDs fail, it returns the
+// others' metadata along with a *LookupError naming the failures.
 func (c *Client) Fetch(ctx context.Context, videoIDs []string) (map[string]VideoInfo, error) {
 	ids := sanitizeIDs(videoIDs)
 	if len(ids) == 0 {
@@ -57,21 +128,23 @@ func (c *Client) Fetch(ctx context.Context, videoIDs []string) (map[string]Video
 	}
 
 	results := make(map[string]VideoInfo, len(ids))
-	var firstErr error
+	failed := make(map[string]error)
 	for _, id := range ids {
+		started := time.Now()
 		info, err := c.fetchSingle(ctx, httpClient, baseURL, id)
+		if c.Observer != nil {
+			c.Observer.ObserveFetch(time.Since(started), err)
+		}
 		if err != nil {
 			c.logf("Fetch for video %s failed: %v", id, err)
-			if firstErr == nil {
-				firstErr = fmt.Errorf("fetch %s: %w", id, err)
-			}
+			failed[id] = err
 			continue
 		}
 		c.logf("Fetched metadata for %s: channel=%s title=%q live=%v start=%s", id, info.ChannelID, info.Title, info.IsLive(), info.ActualStartTime)
 		results[id] = info
 	}
-	if len(results) == 0 && firstErr != nil {
-		return nil, firstErr
+	if len(failed) > 0 {
+		return results, &LookupError{Failed: failed}
 	}
 	return results, nil
 }
@@ -122,14 +195,30 @@ func (c *Client) fetchSingle(ctx context.Context, client *http.Client, baseURL,
 	}
 
 	info := VideoInfo{
-		ID:        id,
-		ChannelID: payload.VideoDetails.ChannelID,
-		Title:     payload.VideoDetails.Title,
+		ID:          id,
+		ChannelID:   payload.VideoDetails.ChannelID,
+		Title:       payload.VideoDetails.Title,
+		Playability: payload.PlayabilityStatus.Status,
 	}
-	if payload.VideoDetails.IsLiveContent || payload.VideoDetails.IsLive {
+	details := payload.Microformat.PlayerMicroformatRenderer.LiveBroadcastDetails
+	if payload.VideoDetails.IsLiveContent || payload.VideoDetails.IsLive || details.IsLiveNow {
 		info.LiveBroadcastContent = "live"
 	}
-	info.ActualStartTime = parseRFC3339(payload.Microformat.PlayerMicroformatRenderer.LiveBroadcastDetails.StartTimestamp)
+	info.ActualStartTime = parseRFC3339(details.StartTimestamp)
+	info.ActualEndTime = parseRFC3339(details.EndTimestamp)
+	if info.Ended() {
+		info.LiveBroadcastContent = "none"
+		return info, nil
+	}
+	if payload.VideoDetails.IsUpcoming {
+		// For upcoming broadcasts startTimestamp holds the scheduled start, not the actual one.
+		info.LiveBroadcastContent = "upcoming"
+		info.ScheduledStartTime = parseUnixSeconds(payload.PlayabilityStatus.LiveStreamability.Renderer.OfflineSlate.Renderer.ScheduledStartTime)
+		if info.ScheduledStartTime.IsZero() {
+			info.ScheduledStartTime = info.ActualStartTime
+		}
+		info.ActualStartTime = time.Time{}
+	}
 	return info, nil
 }
 
@@ -187,6 +276,14 @@ func parseRFC3339(value string) time.Time {
 	return ts
 }
 
+func parseUnixSeconds(value string) time.Time {
+	secs, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || secs <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(secs, 0).UTC()
+}
+
 type playerResponse struct {
 	VideoDetails struct {
 		VideoID       string `json:"videoId"`
@@ -194,11 +291,26 @@ type playerResponse struct {
 		Title         string `json:"title"`
 		IsLive        bool   `json:"isLive"`
 		IsLiveContent bool   `json:"isLiveContent"`
+		IsUpcoming    bool   `json:"isUpcoming"`
 	} `json:"videoDetails"`
+	PlayabilityStatus struct {
+		Status            string `json:"status"`
+		LiveStreamability struct {
+			Renderer struct {
+				OfflineSlate struct {
+					Renderer struct {
+						ScheduledStartTime string `json:"scheduledStartTime"`
+					} `json:"liveStreamOfflineSlateRenderer"`
+				} `json:"offlineSlate"`
+			} `json:"liveStreamabilityRenderer"`
+		} `json:"liveStreamability"`
+	} `json:"playabilityStatus"`
 	Microformat struct {
 		PlayerMicroformatRenderer struct {
 			LiveBroadcastDetails struct {
+				IsLiveNow      bool   `json:"isLiveNow"`
 				StartTimestamp string `json:"startTimestamp"`
+				EndTimestamp   string `json:"endTimestamp"`
 			} `json:"liveBroadcastDetails"`
 		} `json:"playerMicroformatRenderer"`
 	} `json:"microformat"`
@@ -2,6 +2,7 @@ package liveinfo
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -45,6 +46,30 @@ func TestClientFetchParsesLivePage(t *testing.T) {
 	}
 }
 
+func TestClientFetchParsesUpcomingPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<!doctype html><script>var ytInitialPlayerResponse = {"playabilityStatus":{"status":"LIVE_STREAM_OFFLINE","liveStreamability":{"liveStreamabilityRenderer":{"offlineSlate":{"liveStreamOfflineSlateRenderer":{"scheduledStartTime":"1767261600"}}}}},"videoDetails":{"videoId":"up123","channelId":"UCdemo","title":"Premiere","isLiveContent":true,"isUpcoming":true},"microformat":{"playerMicroformatRenderer":{"liveBroadcastDetails":{"isLiveNow":false,"startTimestamp":"2026-01-01T10:00:00Z"}}}};</script>`))
+	}))
+	defer server.Close()
+
+	client := &Client{HTTPClient: server.Client(), BaseURL: server.URL + "/watch"}
+	info, err := client.Fetch(context.Background(), []string{"up123"})
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	entry := info["up123"]
+	if entry.IsLive() || !entry.IsUpcoming() {
+		t.Fatalf("expected an upcoming, not live, broadcast: %+v", entry)
+	}
+	if want := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC); !entry.ScheduledStartTime.Equal(want) {
+		t.Fatalf("expected scheduled start %s, got %s", want, entry.ScheduledStartTime)
+	}
+	if !entry.ActualStartTime.IsZero() {
+		t.Fatalf("upcoming broadcasts have no actual start, got %s", entry.ActualStartTime)
+	}
+}
+
 func TestClientFetchSkipsFailures(t *testing.T) {
 	calls := 0
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -58,18 +83,32 @@ func TestClientFetchSkipsFailures(t *testing.T) {
 	}))
 	defer server.Close()
 
+	observer := &recordingObserver{}
 	client := &Client{
 		HTTPClient: server.Client(),
 		BaseURL:    server.URL + "/watch",
+		Observer:   observer,
 	}
 
 	info, err := client.Fetch(context.Background(), []string{"abc123", "def456"})
-	if err != nil {
-		t.Fatalf("expected partial success, got error %v", err)
+	var lookupErr *LookupError
+	if !errors.As(err, &lookupErr) || len(lookupErr.FailedIDs()) != 1 || lookupErr.FailedIDs()[0] != "abc123" {
+		t.Fatalf("expected a lookup error naming abc123, got %v", err)
 	}
-	if len(info) != 1 {
-		t.Fatalf("expected one successful entry, got %d", len(info))
+	if _, ok := info["def456"]; !ok || len(info) != 1 {
+		t.Fatalf("expected the successful entry alongside the error, got %+v", info)
 	}
+	if len(observer.errs) != 2 || observer.errs[0] == nil || observer.errs[1] != nil {
+		t.Fatalf("expected the observer to see one failed and one successful fetch, got %v", observer.errs)
+	}
+}
+
+type recordingObserver struct {
+	errs []error
+}
+
+func (r *recordingObserver) ObserveFetch(_ time.Duration, err error) {
+	r.errs = append(r.errs, err)
 }
 
 func TestVideoInfoIsLive(t *testing.T) {
@@ -82,4 +121,45 @@ func TestVideoInfoIsLive(t *testing.T) {
 	if (VideoInfo{}).IsLive() {
 		t.Fatalf("expected zero value to be offline")
 	}
+	if (VideoInfo{LiveBroadcastContent: "live", ActualStartTime: time.Now(), ActualEndTime: time.Now()}).IsLive() {
+		t.Fatalf("expected ended broadcast to be offline")
+	}
+}
+
+func TestClientFetchParsesEndedBroadcast(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<!doctype html><script>var ytInitialPlayerResponse = {"videoDetails":{"videoId":"abc123","channelId":"UCdemo","title":"Past stream","isLiveContent":true},"microformat":{"playerMicroformatRenderer":{"liveBroadcastDetails":{"isLiveNow":false,"startTimestamp":"2025-11-16T09:02:41Z","endTimestamp":"2025-11-16T11:00:00Z"}}}};</script>`))
+	}))
+	defer server.Close()
+
+	client := &Client{HTTPClient: server.Client(), BaseURL: server.URL + "/watch"}
+	info, err := client.Fetch(context.Background(), []string{"abc123"})
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	entry := info["abc123"]
+	if entry.IsLive() || !entry.Ended() {
+		t.Fatalf("expected ended broadcast, got %+v", entry)
+	}
+	if want := time.Date(2025, 11, 16, 11, 0, 0, 0, time.UTC); !entry.ActualEndTime.Equal(want) {
+		t.Fatalf("expected end time %s, got %s", want, entry.ActualEndTime)
+	}
+}
+
+func TestClientFetchParsesUnplayableVideo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<!doctype html><script>var ytInitialPlayerResponse = {"playabilityStatus":{"status":"ERROR","reason":"Video unavailable"}};</script>`))
+	}))
+	defer server.Close()
+
+	client := &Client{HTTPClient: server.Client(), BaseURL: server.URL + "/watch"}
+	info, err := client.Fetch(context.Background(), []string{"gone"})
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if entry := info["gone"]; !entry.Unplayable() || entry.IsLive() {
+		t.Fatalf("expected an unplayable, offline video, got %+v", entry)
+	}
 }
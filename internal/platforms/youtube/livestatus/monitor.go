@@ -0,0 +1,326 @@
+// Package livestatus watches YouTube broadcasts recorded as live and clears them once they
+// end. It also re-checks scheduled broadcasts around their start time so they are promoted
+// to live or dropped from the schedule.
+package livestatus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"live-stream-alerts/internal/logging"
+	"live-stream-alerts/internal/notifications"
+	"live-stream-alerts/internal/platforms/youtube/liveinfo"
+	"live-stream-alerts/internal/streamers"
+)
+
+const (
+	defaultInterval     = 2 * time.Minute
+	defaultCheckTimeout = 30 * time.Second
+	// scheduleLookahead is how far ahead of its start a scheduled broadcast is re-checked.
+	scheduleLookahead = 10 * time.Minute
+	// scheduleGrace is how long a broadcast may stay upcoming past its start before it
+	// is treated as abandoned.
+	scheduleGrace = 24 * time.Hour
+)
+
+// VideoLookup fetches metadata for YouTube video IDs. liveinfo.Client satisfies it.
+type VideoLookup interface {
+	Fetch(ctx context.Context, videoIDs []string) (map[string]liveinfo.VideoInfo, error)
+}
+
+// Notifier dispatches go-live alerts for scheduled broadcasts that started.
+type Notifier interface {
+	Notify(ctx context.Context, alert notifications.Alert) error
+}
+
+// StreamEndMonitorConfig configures the background stream-end checker.
+type StreamEndMonitorConfig struct {
+	Store    streamers.Repository
+	Lookup   VideoLookup
+	Interval time.Duration
+	Logger   logging.Logger
+	Now      func() time.Time
+	// Notifier, when set, receives an alert when a scheduled broadcast goes live.
+	Notifier Notifier
+}
+
+// StreamEndMonitor periodically re-polls every live YouTube record and clears its
+// status once the broadcast is no longer live.
+type StreamEndMonitor struct {
+	cfg    StreamEndMonitorConfig
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// StartStreamEndMonitor launches the stream-end monitor using the provided context.
+func StartStreamEndMonitor(ctx context.Context, cfg StreamEndMonitorConfig) *StreamEndMonitor {
+	monitor := newStreamEndMonitor(cfg)
+	runCtx, cancel := context.WithCancel(ctx)
+	monitor.cancel = cancel
+	monitor.wg.Add(1)
+	go func() {
+		defer monitor.wg.Done()
+		monitor.run(runCtx)
+	}()
+	return monitor
+}
+
+// NewStreamEndMonitor builds a monitor without starting its polling loop, for callers
+// that drive CheckRecords themselves.
+func NewStreamEndMonitor(cfg StreamEndMonitorConfig) *StreamEndMonitor {
+	return newStreamEndMonitor(cfg)
+}
+
+func newStreamEndMonitor(cfg StreamEndMonitorConfig) *StreamEndMonitor {
+	if cfg.Store == nil {
+		cfg.Store = streamers.NewStore(streamers.DefaultFilePath)
+	}
+	if cfg.Lookup == nil {
+		cfg.Lookup = &liveinfo.Client{Logger: cfg.Logger}
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &StreamEndMonitor{cfg: cfg}
+}
+
+func (m *StreamEndMonitor) run(ctx context.Context) {
+	m.check(ctx)
+
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.check(ctx)
+		}
+	}
+}
+
+type liveVideo struct {
+	alias     string
+	channelID string
+	videoID   string
+}
+
+type scheduledVideo struct {
+	record    streamers.Record
+	channelID string
+	broadcast streamers.ScheduledBroadcast
+}
+
+// check polls every live YouTube record once and clears those whose broadcast has ended.
+// It returns the number of records that were marked offline.
+func (m *StreamEndMonitor) check(ctx context.Context) int {
+	records, err := m.cfg.Store.List()
+	if err != nil {
+		m.logf("stream end monitor: failed to read streamers: %v", err)
+		return 0
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, defaultCheckTimeout)
+	defer cancel()
+	ended, err := m.CheckRecords(checkCtx, records)
+	if err != nil {
+		m.logf("stream end monitor: %v", err)
+	}
+	return ended
+}
+
+// CheckRecords polls the live YouTube broadcasts among records and clears those that
+// have ended. Scheduled broadcasts that are due are promoted, rescheduled, or dropped.
+// Only metadata showing a video offline, ended, or unplayable ends or drops it; videos
+// whose lookup failed or that the lookup left out are checked again next time. It
+// returns the number of records that were marked offline, and the lookup error naming
+// the videos that could not be checked.
+func (m *StreamEndMonitor) CheckRecords(ctx context.Context, records []streamers.Record) (int, error) {
+	live := collectLiveVideos(records)
+	scheduled := collectDueBroadcasts(records, m.cfg.Now().Add(scheduleLookahead))
+	if len(live) == 0 && len(scheduled) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]string, 0, len(live)+len(scheduled))
+	for _, video := range live {
+		ids = append(ids, video.videoID)
+	}
+	for _, video := range scheduled {
+		ids = append(ids, video.broadcast.VideoID)
+	}
+	infos, err := m.cfg.Lookup.Fetch(ctx, ids)
+	var lookupErr *liveinfo.LookupError
+	if err != nil && !errors.As(err, &lookupErr) {
+		return 0, fmt.Errorf("live lookup failed for %s: %w", strings.Join(ids, ","), err)
+	}
+
+	var ended int
+	for _, video := range live {
+		info, ok := infos[video.videoID]
+		if !ok || info.IsLive() {
+			continue
+		}
+		endedAt := info.ActualEndTime
+		if endedAt.IsZero() {
+			endedAt = m.cfg.Now()
+		}
+		channelID := video.channelID
+		if channelID == "" {
+			channelID = strings.TrimSpace(info.ChannelID)
+		}
+		if _, err := m.cfg.Store.EndYouTubeLive(channelID, video.videoID, endedAt); err != nil {
+			m.logf("stream end monitor: failed to clear live status for %s: %v", video.alias, err)
+			continue
+		}
+		ended++
+		m.logf("stream end monitor: %s is no longer live (video=%s)", video.alias, video.videoID)
+	}
+	for _, video := range scheduled {
+		if info, ok := infos[video.broadcast.VideoID]; ok {
+			m.checkScheduled(ctx, video, info)
+		}
+	}
+	if lookupErr != nil {
+		return ended, lookupErr
+	}
+	return ended, nil
+}
+
+// checkScheduled reconciles a due scheduled broadcast with its current metadata.
+func (m *StreamEndMonitor) checkScheduled(ctx context.Context, video scheduledVideo, info liveinfo.VideoInfo) {
+	alias := video.record.Streamer.Alias
+	videoID := video.broadcast.VideoID
+	switch {
+	case info.IsLive():
+		startedAt := info.ActualStartTime
+		if startedAt.IsZero() {
+			startedAt = m.cfg.Now()
+		}
+		title := strings.TrimSpace(info.Title)
+		if title == "" {
+			title = video.broadcast.Title
+		}
+		record, err := m.cfg.Store.UpdateYouTubeLiveStatus(video.channelID, streamers.YouTubeLiveStatus{
+			Live:      true,
+			VideoID:   videoID,
+			Title:     title,
+			StartedAt: startedAt,
+		})
+		if err != nil {
+			m.logf("stream end monitor: failed to promote scheduled broadcast for %s: %v", alias, err)
+			return
+		}
+		m.logf("stream end monitor: scheduled broadcast for %s is now live (video=%s)", alias, videoID)
+		m.notify(ctx, record, video, title, startedAt)
+	case info.IsUpcoming() && !info.ScheduledStartTime.IsZero() && m.cfg.Now().Sub(info.ScheduledStartTime) < scheduleGrace:
+		if info.ScheduledStartTime.Equal(video.broadcast.ScheduledStart) {
+			return
+		}
+		broadcast := video.broadcast
+		broadcast.ScheduledStart = info.ScheduledStartTime
+		if title := strings.TrimSpace(info.Title); title != "" {
+			broadcast.Title = title
+		}
+		if _, err := m.cfg.Store.SetYouTubeUpcoming(video.channelID, broadcast); err != nil {
+			m.logf("stream end monitor: failed to reschedule broadcast for %s: %v", alias, err)
+			return
+		}
+		m.logf("stream end monitor: broadcast for %s rescheduled to %s (video=%s)", alias, broadcast.ScheduledStart.Format(time.RFC3339), videoID)
+	default:
+		if _, err := m.cfg.Store.RemoveYouTubeUpcoming(video.channelID, videoID); err != nil {
+			m.logf("stream end monitor: failed to drop scheduled broadcast for %s: %v", alias, err)
+			return
+		}
+		m.logf("stream end monitor: dropped scheduled broadcast for %s (video=%s)", alias, videoID)
+	}
+}
+
+func (m *StreamEndMonitor) notify(ctx context.Context, record streamers.Record, video scheduledVideo, title string, startedAt time.Time) {
+	if m.cfg.Notifier == nil {
+		return
+	}
+	alert := notifications.Alert{
+		StreamerID: record.Streamer.ID,
+		Alias:      record.Streamer.Alias,
+		Platform:   "youtube",
+		ChannelID:  video.channelID,
+		VideoID:    video.broadcast.VideoID,
+		Title:      title,
+		URL:        "https://www.youtube.com/watch?v=" + video.broadcast.VideoID,
+		StartedAt:  startedAt,
+	}
+	if err := m.cfg.Notifier.Notify(ctx, alert); err != nil {
+		m.logf("stream end monitor: failed to queue go-live alert for %s: %v", record.Streamer.Alias, err)
+	}
+}
+
+// collectDueBroadcasts returns the scheduled YouTube broadcasts starting before cutoff.
+func collectDueBroadcasts(records []streamers.Record, cutoff time.Time) []scheduledVideo {
+	var due []scheduledVideo
+	for _, record := range records {
+		yt := record.Platforms.YouTube
+		if yt == nil || strings.TrimSpace(yt.ChannelID) == "" {
+			continue
+		}
+		for _, broadcast := range record.Schedule {
+			if broadcast.Platform != "youtube" || broadcast.ScheduledStart.After(cutoff) {
+				continue
+			}
+			due = append(due, scheduledVideo{
+				record:    record,
+				channelID: strings.TrimSpace(yt.ChannelID),
+				broadcast: broadcast,
+			})
+		}
+	}
+	return due
+}
+
+func collectLiveVideos(records []streamers.Record) []liveVideo {
+	var live []liveVideo
+	for _, record := range records {
+		status := record.Status
+		if status == nil || status.YouTube == nil || !status.YouTube.Live {
+			continue
+		}
+		videoID := strings.TrimSpace(status.YouTube.VideoID)
+		if videoID == "" {
+			continue
+		}
+		var channelID string
+		if yt := record.Platforms.YouTube; yt != nil {
+			channelID = strings.TrimSpace(yt.ChannelID)
+		}
+		live = append(live, liveVideo{
+			alias:     record.Streamer.Alias,
+			channelID: channelID,
+			videoID:   videoID,
+		})
+	}
+	return live
+}
+
+func (m *StreamEndMonitor) logf(format string, args ...any) {
+	if m.cfg.Logger == nil {
+		return
+	}
+	m.cfg.Logger.Printf(format, args...)
+}
+
+// Stop cancels the monitor and waits for the polling goroutine to exit.
+func (m *StreamEndMonitor) Stop() {
+	if m == nil {
+		return
+	}
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
+}
@@ -0,0 +1,219 @@
+package livestatus
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"live-stream-alerts/internal/notifications"
+	"live-stream-alerts/internal/platforms/youtube/liveinfo"
+	"live-stream-alerts/internal/streamers"
+)
+
+type stubLookup struct {
+	infos map[string]liveinfo.VideoInfo
+	err   error
+	calls int
+}
+
+func (s *stubLookup) Fetch(ctx context.Context, videoIDs []string) (map[string]liveinfo.VideoInfo, error) {
+	s.calls++
+	return s.infos, s.err
+}
+
+func newLiveStore(t *testing.T) *streamers.Store {
+	t.Helper()
+	store := streamers.NewStore(filepath.Join(t.TempDir(), "streamers.json"))
+	if _, err := store.Append(streamers.Record{
+		Streamer:  streamers.Streamer{Alias: "Live"},
+		Platforms: streamers.Platforms{YouTube: &streamers.YouTubePlatform{ChannelID: "UClive"}},
+	}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if _, err := store.SetYouTubeLive("UClive", "vid1", time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("set live: %v", err)
+	}
+	return store
+}
+
+func TestStreamEndMonitorClearsEndedBroadcasts(t *testing.T) {
+	store := newLiveStore(t)
+	ended := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	monitor := newStreamEndMonitor(StreamEndMonitorConfig{
+		Store: store,
+		Lookup: &stubLookup{infos: map[string]liveinfo.VideoInfo{
+			"vid1": {ID: "vid1", ChannelID: "UClive", ActualEndTime: ended},
+		}},
+	})
+
+	if got := monitor.check(context.Background()); got != 1 {
+		t.Fatalf("expected 1 record to end, got %d", got)
+	}
+	records, err := store.List()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	status := records[0].Status
+	if status.Live || status.YouTube.Live || status.YouTube.VideoID != "" {
+		t.Fatalf("expected live status to be cleared: %+v", status)
+	}
+	if !status.YouTube.EndedAt.Equal(ended) {
+		t.Fatalf("expected endedAt %s, got %s", ended, status.YouTube.EndedAt)
+	}
+	if len(status.Platforms) != 0 {
+		t.Fatalf("expected no live platforms, got %v", status.Platforms)
+	}
+}
+
+func TestStreamEndMonitorKeepsLiveBroadcasts(t *testing.T) {
+	store := newLiveStore(t)
+	monitor := newStreamEndMonitor(StreamEndMonitorConfig{
+		Store: store,
+		Lookup: &stubLookup{infos: map[string]liveinfo.VideoInfo{
+			"vid1": {ID: "vid1", LiveBroadcastContent: "live"},
+		}},
+	})
+
+	if got := monitor.check(context.Background()); got != 0 {
+		t.Fatalf("expected no records to end, got %d", got)
+	}
+	record, err := store.Get(mustFirstID(t, store))
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !record.Status.Live {
+		t.Fatalf("expected record to stay live")
+	}
+}
+
+func TestStreamEndMonitorEndsUnplayableVideos(t *testing.T) {
+	store := newLiveStore(t)
+	monitor := newStreamEndMonitor(StreamEndMonitorConfig{
+		Store: store,
+		Lookup: &stubLookup{infos: map[string]liveinfo.VideoInfo{
+			"vid1": {ID: "vid1", LiveBroadcastContent: "live", Playability: "ERROR"},
+		}},
+	})
+	if got := monitor.check(context.Background()); got != 1 {
+		t.Fatalf("expected a deleted video to end its broadcast, got %d", got)
+	}
+	if status := mustList(t, store)[0].Status; status.YouTube.Live || status.YouTube.EndedAt.IsZero() {
+		t.Fatalf("expected live status to be cleared: %+v", status.YouTube)
+	}
+}
+
+func TestStreamEndMonitorKeepsVideosWhoseLookupFailed(t *testing.T) {
+	for name, lookup := range map[string]*stubLookup{
+		"failed":  {infos: map[string]liveinfo.VideoInfo{}, err: &liveinfo.LookupError{Failed: map[string]error{"vid1": errors.New("timeout")}}},
+		"missing": {infos: map[string]liveinfo.VideoInfo{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			store := newLiveStore(t)
+			monitor := newStreamEndMonitor(StreamEndMonitorConfig{Store: store, Lookup: lookup})
+			if got := monitor.check(context.Background()); got != 0 {
+				t.Fatalf("expected the broadcast to stay live without evidence, got %d ended", got)
+			}
+			if status := mustList(t, store)[0].Status; !status.YouTube.Live {
+				t.Fatalf("expected live status to be kept: %+v", status.YouTube)
+			}
+		})
+	}
+}
+
+func TestStreamEndMonitorIgnoresLookupFailures(t *testing.T) {
+	store := newLiveStore(t)
+	monitor := newStreamEndMonitor(StreamEndMonitorConfig{
+		Store:  store,
+		Lookup: &stubLookup{err: errors.New("boom")},
+	})
+	if got := monitor.check(context.Background()); got != 0 {
+		t.Fatalf("expected lookup failure to leave records untouched, got %d", got)
+	}
+}
+
+func TestStreamEndMonitorSkipsWhenNothingLive(t *testing.T) {
+	store := streamers.NewStore(filepath.Join(t.TempDir(), "streamers.json"))
+	lookup := &stubLookup{}
+	monitor := newStreamEndMonitor(StreamEndMonitorConfig{Store: store, Lookup: lookup})
+	monitor.check(context.Background())
+	if lookup.calls != 0 {
+		t.Fatalf("expected no lookups without live records, got %d", lookup.calls)
+	}
+}
+
+func mustFirstID(t *testing.T, store *streamers.Store) string {
+	t.Helper()
+	records, err := store.List()
+	if err != nil || len(records) == 0 {
+		t.Fatalf("list: %v", err)
+	}
+	return records[0].Streamer.ID
+}
+
+type recordingNotifier struct {
+	alerts []notifications.Alert
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, alert notifications.Alert) error {
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
+func TestStreamEndMonitorReconcilesScheduledBroadcasts(t *testing.T) {
+	now := time.Date(2025, 11, 21, 18, 0, 0, 0, time.UTC)
+	store := streamers.NewStore(filepath.Join(t.TempDir(), "streamers.json"))
+	if _, err := store.Append(streamers.Record{
+		Streamer:  streamers.Streamer{Alias: "Sched"},
+		Platforms: streamers.Platforms{YouTube: &streamers.YouTubePlatform{ChannelID: "UCsched"}},
+	}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	for _, b := range []streamers.ScheduledBroadcast{
+		{VideoID: "starting", ScheduledStart: now.Add(-time.Minute)},
+		{VideoID: "cancelled", ScheduledStart: now.Add(5 * time.Minute)},
+		{VideoID: "deleted", ScheduledStart: now.Add(-2 * time.Minute)},
+		{VideoID: "unknown", ScheduledStart: now.Add(-3 * time.Minute)},
+		{VideoID: "later", ScheduledStart: now.Add(24 * time.Hour)},
+	} {
+		if _, err := store.SetYouTubeUpcoming("UCsched", b); err != nil {
+			t.Fatalf("set upcoming: %v", err)
+		}
+	}
+	notifier := &recordingNotifier{}
+	monitor := newStreamEndMonitor(StreamEndMonitorConfig{
+		Store:    store,
+		Notifier: notifier,
+		Now:      func() time.Time { return now },
+		Lookup: &stubLookup{infos: map[string]liveinfo.VideoInfo{
+			"starting":  {ID: "starting", LiveBroadcastContent: "live", ActualStartTime: now},
+			"cancelled": {ID: "cancelled"},
+			"deleted":   {ID: "deleted", Playability: "ERROR"},
+			// "unknown" is missing from the lookup, so it stays scheduled.
+		}},
+	})
+
+	if _, err := monitor.CheckRecords(context.Background(), mustList(t, store)); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	record := mustList(t, store)[0]
+	if record.Status == nil || !record.Status.YouTube.Live || record.Status.YouTube.VideoID != "starting" {
+		t.Fatalf("expected the started broadcast to be promoted to live: %+v", record.Status)
+	}
+	if len(record.Schedule) != 2 || record.Schedule[0].VideoID != "unknown" || record.Schedule[1].VideoID != "later" {
+		t.Fatalf("expected only the unchecked and later broadcasts to remain scheduled: %+v", record.Schedule)
+	}
+	if len(notifier.alerts) != 1 || notifier.alerts[0].VideoID != "starting" {
+		t.Fatalf("expected a go-live alert for the promoted broadcast, got %+v", notifier.alerts)
+	}
+}
+
+func mustList(t *testing.T, store *streamers.Store) []streamers.Record {
+	t.Helper()
+	records, err := store.List()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	return records
+}
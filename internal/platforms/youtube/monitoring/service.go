@@ -14,7 +14,7 @@ const defaultRenewWindow = 0.05
 
 // ServiceOptions configures the YouTube lease overview service.
 type ServiceOptions struct {
-	StreamersStore      *streamers.Store
+	StreamersStore      streamers.Repository
 	DefaultLeaseSeconds int
 	RenewWindow         float64
 	Now                 func() time.Time
@@ -68,7 +68,7 @@ type LeaseEntry struct {
 
 // Service exposes lease overview data for admin endpoints.
 type Service struct {
-	store               *streamers.Store
+	store               streamers.Repository
 	defaultLeaseSeconds int
 	renewWindow         float64
 	now                 func() time.Time
@@ -1,3 +1,4 @@
+// Package onboarding attaches YouTube channels to streamer records and subscribes them.
 package onboarding
 
 import (
@@ -24,7 +25,7 @@ type Options struct {
 	VerifyMode   string
 	LeaseSeconds int
 	Logger       logging.Logger
-	Store        *streamers.Store
+	Store        streamers.Repository
 }
 
 // FromURL parses the provided channel URL, resolves missing metadata, updates the streamer record,
@@ -39,7 +40,7 @@ func FromURL(ctx context.Context, record streamers.Record, channelURL string, op
 		return errors.New("streamers store is required")
 	}
 
-	handle, channelID, err := parseYouTubeURL(channelURL)
+	handle, channelID, err := ParseChannelURL(channelURL)
 	if err != nil {
 		return err
 	}
@@ -105,7 +106,9 @@ func FromURL(ctx context.Context, record streamers.Record, channelURL string, op
 	return subscriptions.ManageSubscription(ctx, updatedRecord, subscribeOpts)
 }
 
-func parseYouTubeURL(raw string) (handle string, channelID string, err error) {
+// ParseChannelURL extracts the @handle and/or channel ID from a YouTube channel URL.
+// Either may be empty when the URL does not carry it.
+func ParseChannelURL(raw string) (handle string, channelID string, err error) {
 	u, err := url.Parse(raw)
 	if err != nil {
 		return "", "", fmt.Errorf("invalid youtube url: %w", err)
@@ -135,7 +138,7 @@ func parseYouTubeURL(raw string) (handle string, channelID string, err error) {
 	return handle, channelID, nil
 }
 
-func setYouTubePlatform(store *streamers.Store, streamerID string, yt streamers.YouTubePlatform) (streamers.Record, error) {
+func setYouTubePlatform(store streamers.Repository, streamerID string, yt streamers.YouTubePlatform) (streamers.Record, error) {
 	var updated streamers.Record
 	err := store.UpdateFile(func(file *streamers.File) error {
 		for i := range file.Records {
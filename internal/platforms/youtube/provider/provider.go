@@ -0,0 +1,156 @@
+// Package provider adapts the YouTube WebSub integration to platforms.Provider.
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"live-stream-alerts/internal/logging"
+	"live-stream-alerts/internal/platforms"
+	youtubehandlers "live-stream-alerts/internal/platforms/youtube/handlers"
+	"live-stream-alerts/internal/platforms/youtube/livestatus"
+	"live-stream-alerts/internal/platforms/youtube/onboarding"
+	"live-stream-alerts/internal/platforms/youtube/subscriptions"
+	"live-stream-alerts/internal/streamers"
+)
+
+// Name is the platform key YouTube records and statuses use.
+const Name = "youtube"
+
+// Options configures the YouTube provider.
+type Options struct {
+	Store        streamers.Repository
+	Client       *http.Client
+	HubURL       string
+	CallbackURL  string
+	VerifyMode   string
+	LeaseSeconds int
+	Logger       logging.Logger
+	// Notifications configures POST /alerts handling. Its Logger and StreamersStore
+	// default to the provider's.
+	Notifications youtubehandlers.AlertNotificationOptions
+	// Lookup re-checks live videos; it defaults to a liveinfo.Client.
+	Lookup livestatus.VideoLookup
+	// Notifier receives the go-live alert when CheckLive finds a scheduled broadcast has
+	// started. Without it such broadcasts are promoted silently.
+	Notifier livestatus.Notifier
+	// RenewWindow is the fraction of the lease left when renewal starts (default 5%).
+	RenewWindow float64
+}
+
+// Provider implements platforms.Provider for YouTube.
+type Provider struct {
+	opts      Options
+	streamEnd *livestatus.StreamEndMonitor
+}
+
+var _ platforms.Provider = (*Provider)(nil)
+
+// New builds the YouTube provider.
+func New(opts Options) *Provider {
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if opts.Notifications.Logger == nil {
+		opts.Notifications.Logger = opts.Logger
+	}
+	if opts.Notifications.StreamersStore == nil {
+		opts.Notifications.StreamersStore = opts.Store
+	}
+	return &Provider{
+		opts: opts,
+		streamEnd: livestatus.NewStreamEndMonitor(livestatus.StreamEndMonitorConfig{
+			Store:    opts.Store,
+			Lookup:   opts.Lookup,
+			Logger:   opts.Logger,
+			Notifier: opts.Notifier,
+		}),
+	}
+}
+
+// Name implements platforms.Provider.
+func (p *Provider) Name() string { return Name }
+
+// ParseURL accepts youtube.com channel URLs carrying an @handle, /channel/<id>, or
+// ?channel_id=.
+func (p *Provider) ParseURL(rawURL string) (platforms.Channel, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || !isYouTubeHost(u.Hostname()) {
+		return platforms.Channel{}, platforms.ErrUnsupportedURL
+	}
+	handle, channelID, err := onboarding.ParseChannelURL(rawURL)
+	if err != nil {
+		return platforms.Channel{}, err
+	}
+	if handle == "" && channelID == "" {
+		return platforms.Channel{}, platforms.ErrUnsupportedURL
+	}
+	return platforms.Channel{Platform: Name, Handle: handle, ID: channelID}, nil
+}
+
+func isYouTubeHost(host string) bool {
+	host = strings.ToLower(host)
+	return host == "youtube.com" || strings.HasSuffix(host, ".youtube.com")
+}
+
+// Onboard resolves the channel, stores it on the record, and subscribes via WebSub.
+func (p *Provider) Onboard(ctx context.Context, record streamers.Record, rawURL string) error {
+	return onboarding.FromURL(ctx, record, rawURL, onboarding.Options{
+		Client:       p.opts.Client,
+		HubURL:       strings.TrimSpace(p.opts.HubURL),
+		CallbackURL:  strings.TrimSpace(p.opts.CallbackURL),
+		VerifyMode:   strings.TrimSpace(p.opts.VerifyMode),
+		LeaseSeconds: p.opts.LeaseSeconds,
+		Logger:       p.opts.Logger,
+		Store:        p.opts.Store,
+	})
+}
+
+// RenewAt reports when the record's hub lease should be renewed.
+func (p *Provider) RenewAt(record streamers.Record) (time.Time, bool) {
+	return subscriptions.RenewAt(record, p.subscribeOptions(), p.opts.RenewWindow)
+}
+
+// Subscribe (re)subscribes the record's channel with the hub.
+func (p *Provider) Subscribe(ctx context.Context, record streamers.Record) error {
+	return subscriptions.ManageSubscription(ctx, record, p.subscribeOptions())
+}
+
+func (p *Provider) subscribeOptions() subscriptions.Options {
+	return subscriptions.Options{
+		Client:       p.opts.Client,
+		HubURL:       p.opts.HubURL,
+		Logger:       p.opts.Logger,
+		Mode:         "subscribe",
+		Verify:       p.opts.VerifyMode,
+		LeaseSeconds: p.opts.LeaseSeconds,
+	}
+}
+
+// MatchCallback recognises Google's feed fetcher, which delivers WebSub challenges and
+// notifications for YouTube.
+func (p *Provider) MatchCallback(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("User-Agent"), "FeedFetcher-Google") && r.Header.Get("From") == "googlebot(at)googlebot.com"
+}
+
+// VerifyCallback answers hub.challenge verification requests.
+func (p *Provider) VerifyCallback(w http.ResponseWriter, r *http.Request) bool {
+	return youtubehandlers.HandleSubscriptionConfirmation(w, r, youtubehandlers.SubscriptionConfirmationOptions{
+		Logger:         p.opts.Logger,
+		StreamersStore: p.opts.Store,
+	})
+}
+
+// HandleNotification processes signed Atom feed notifications.
+func (p *Provider) HandleNotification(w http.ResponseWriter, r *http.Request) bool {
+	return youtubehandlers.HandleAlertNotification(w, r, p.opts.Notifications)
+}
+
+// CheckLive clears the YouTube status of records whose broadcast has ended and
+// promotes or drops scheduled broadcasts that are due.
+func (p *Provider) CheckLive(ctx context.Context, records []streamers.Record) (int, error) {
+	return p.streamEnd.CheckRecords(ctx, records)
+}
@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"live-stream-alerts/internal/notifications"
+	"live-stream-alerts/internal/platforms"
+	"live-stream-alerts/internal/platforms/youtube/liveinfo"
+	"live-stream-alerts/internal/streamers"
+)
+
+func TestParseURL(t *testing.T) {
+	p := New(Options{})
+	cases := []struct {
+		url    string
+		handle string
+		id     string
+	}{
+		{url: "https://www.youtube.com/@SomeStreamer", handle: "@SomeStreamer"},
+		{url: "https://youtube.com/channel/UC123", id: "UC123"},
+		{url: "https://m.youtube.com/feeds?channel_id=UC456", id: "UC456"},
+	}
+	for _, tc := range cases {
+		channel, err := p.ParseURL(tc.url)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.url, err)
+		}
+		if channel.Platform != Name || channel.Handle != tc.handle || channel.ID != tc.id {
+			t.Fatalf("%s: unexpected channel %+v", tc.url, channel)
+		}
+	}
+	for _, raw := range []string{"https://www.twitch.tv/someone", "https://notyoutube.com/@x", "https://www.youtube.com/watch?v=abc"} {
+		if _, err := p.ParseURL(raw); !errors.Is(err, platforms.ErrUnsupportedURL) {
+			t.Fatalf("%s: expected ErrUnsupportedURL, got %v", raw, err)
+		}
+	}
+}
+
+func TestMatchCallback(t *testing.T) {
+	p := New(Options{})
+	req := httptest.NewRequest(http.MethodPost, "/alerts", nil)
+	req.Header.Set("User-Agent", "FeedFetcher-Google; (+http://www.google.com/feedfetcher.html)")
+	req.Header.Set("From", "googlebot(at)googlebot.com")
+	if !p.MatchCallback(req) {
+		t.Fatalf("expected Google's feed fetcher to match")
+	}
+	req.Header.Del("From")
+	if p.MatchCallback(req) {
+		t.Fatalf("requests without the googlebot From header must not match")
+	}
+}
+
+func TestRenewAtUsesLeaseWindow(t *testing.T) {
+	p := New(Options{LeaseSeconds: 1000})
+	leaseStart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	record := streamers.Record{Platforms: streamers.Platforms{YouTube: &streamers.YouTubePlatform{
+		ChannelID:    "UC123",
+		HubLeaseDate: leaseStart.Format(time.RFC3339),
+	}}}
+	at, ok := p.RenewAt(record)
+	if !ok || !at.Equal(leaseStart.Add(950*time.Second)) {
+		t.Fatalf("expected renewal 5%% before expiry, got %v %v", at, ok)
+	}
+	record.Platforms.YouTube.HubLeaseDate = ""
+	if _, ok := p.RenewAt(record); ok {
+		t.Fatalf("records without a confirmed lease have nothing to renew")
+	}
+}
+
+type stubLookup map[string]liveinfo.VideoInfo
+
+func (s stubLookup) Fetch(ctx context.Context, videoIDs []string) (map[string]liveinfo.VideoInfo, error) {
+	return s, nil
+}
+
+type recordingNotifier struct {
+	alerts []notifications.Alert
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, alert notifications.Alert) error {
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
+func TestCheckLiveAlertsWhenScheduledBroadcastStarts(t *testing.T) {
+	store := streamers.NewStore(filepath.Join(t.TempDir(), "streamers.json"))
+	if _, err := store.Append(streamers.Record{
+		Streamer:  streamers.Streamer{Alias: "Sched"},
+		Platforms: streamers.Platforms{YouTube: &streamers.YouTubePlatform{ChannelID: "UCsched"}},
+	}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if _, err := store.SetYouTubeUpcoming("UCsched", streamers.ScheduledBroadcast{VideoID: "vid", ScheduledStart: time.Now().Add(-time.Minute)}); err != nil {
+		t.Fatalf("set upcoming: %v", err)
+	}
+	notifier := &recordingNotifier{}
+	p := New(Options{
+		Store:    store,
+		Lookup:   stubLookup{"vid": {ID: "vid", LiveBroadcastContent: "live", ActualStartTime: time.Now()}},
+		Notifier: notifier,
+	})
+	records, err := store.List()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if _, err := p.CheckLive(context.Background(), records); err != nil {
+		t.Fatalf("check live: %v", err)
+	}
+	if len(notifier.alerts) != 1 || notifier.alerts[0].VideoID != "vid" {
+		t.Fatalf("expected a go-live alert, got %+v", notifier.alerts)
+	}
+}
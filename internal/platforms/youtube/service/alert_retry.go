@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"live-stream-alerts/internal/jobs"
+)
+
+// NotificationJobKind identifies queued WebSub notifications in the job store.
+const NotificationJobKind = "youtube.notification"
+
+// NotificationJob is the payload of a WebSub notification queued for another attempt.
+type NotificationJob struct {
+	// Feed is the raw Atom body exactly as the hub delivered it.
+	Feed       string    `json:"feed"`
+	RemoteAddr string    `json:"remoteAddr,omitempty"`
+	ReceivedAt time.Time `json:"receivedAt"`
+	// VideoIDs, when set, are the only entries of Feed left to process; the rest were
+	// handled when the notification arrived.
+	VideoIDs []string `json:"videoIds,omitempty"`
+}
+
+// NotificationRetryHandler re-runs queued notifications through the alert processor.
+// A failed video lookup or store update is retried, including a lookup that failed for
+// only some of the videos; a malformed feed never will parse, so it is dead-lettered
+// straight away.
+type NotificationRetryHandler struct {
+	Processor interface {
+		Process(ctx context.Context, req AlertProcessRequest) (AlertProcessResult, error)
+	}
+}
+
+// Handle implements jobs.Handler.
+func (h NotificationRetryHandler) Handle(ctx context.Context, job jobs.Job) error {
+	var payload NotificationJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobs.Permanent(fmt.Errorf("decode notification job: %w", err))
+	}
+	result, err := h.Processor.Process(ctx, AlertProcessRequest{
+		Feed:       strings.NewReader(payload.Feed),
+		RemoteAddr: payload.RemoteAddr,
+		VideoIDs:   payload.VideoIDs,
+	})
+	if errors.Is(err, ErrInvalidFeed) {
+		return jobs.Permanent(err)
+	}
+	if err == nil && len(result.LookupFailed) > 0 {
+		return fmt.Errorf("%w for %s", ErrLookupFailed, strings.Join(result.LookupFailed, ","))
+	}
+	return err
+}
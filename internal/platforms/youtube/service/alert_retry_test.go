@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"live-stream-alerts/internal/jobs"
+	"live-stream-alerts/internal/platforms/youtube/liveinfo"
+	"live-stream-alerts/internal/streamers"
+)
+
+func notificationJob(t *testing.T, feed string) jobs.Job {
+	t.Helper()
+	payload, err := json.Marshal(NotificationJob{Feed: feed})
+	if err != nil {
+		t.Fatalf("encode payload: %v", err)
+	}
+	return jobs.Job{ID: "job_1", Kind: NotificationJobKind, Payload: payload}
+}
+
+func TestNotificationRetryHandler(t *testing.T) {
+	store := streamers.NewStore(filepath.Join(t.TempDir(), "streamers.json"))
+	if _, err := store.Append(streamers.Record{
+		Streamer:  streamers.Streamer{Alias: "Demo"},
+		Platforms: streamers.Platforms{YouTube: &streamers.YouTubePlatform{ChannelID: "UCdemo"}},
+	}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	feed := `<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+ <entry><yt:videoId>vid1</yt:videoId><yt:channelId>UCdemo</yt:channelId></entry>
+</feed>`
+	lookup := &stubVideoLookup{err: errors.New("youtube unavailable")}
+	handler := NotificationRetryHandler{Processor: AlertProcessor{Streamers: store, VideoLookup: lookup}}
+
+	err := handler.Handle(context.Background(), notificationJob(t, feed))
+	var permanent *jobs.PermanentError
+	if !errors.Is(err, ErrLookupFailed) || errors.As(err, &permanent) {
+		t.Fatalf("expected a retryable lookup failure, got %v", err)
+	}
+
+	lookup.err = nil
+	lookup.infos = map[string]liveinfo.VideoInfo{"vid1": {ID: "vid1", ChannelID: "UCdemo", LiveBroadcastContent: "live"}}
+	if err := handler.Handle(context.Background(), notificationJob(t, feed)); err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	records, err := store.List()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if records[0].Status == nil || !records[0].Status.Live {
+		t.Fatalf("expected streamer to be live after the retry, got %+v", records[0].Status)
+	}
+
+	if err := handler.Handle(context.Background(), notificationJob(t, "not xml")); !errors.As(err, &permanent) {
+		t.Fatalf("expected malformed feeds to be dead-lettered, got %v", err)
+	}
+}
+
+func TestNotificationRetryHandlerRetriesPartialLookupFailures(t *testing.T) {
+	store := streamers.NewStore(filepath.Join(t.TempDir(), "streamers.json"))
+	feed := `<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+ <entry><yt:videoId>vid1</yt:videoId><yt:channelId>UCdemo</yt:channelId></entry>
+ <entry><yt:videoId>vid2</yt:videoId><yt:channelId>UCdemo</yt:channelId></entry>
+</feed>`
+	lookup := &stubVideoLookup{
+		infos: map[string]liveinfo.VideoInfo{"vid1": {ID: "vid1"}},
+		err:   &liveinfo.LookupError{Failed: map[string]error{"vid2": errors.New("timeout")}},
+	}
+	handler := NotificationRetryHandler{Processor: AlertProcessor{Streamers: store, VideoLookup: lookup}}
+	payload, err := json.Marshal(NotificationJob{Feed: feed, VideoIDs: []string{"vid1", "vid2"}})
+	if err != nil {
+		t.Fatalf("encode payload: %v", err)
+	}
+
+	err = handler.Handle(context.Background(), jobs.Job{ID: "job_1", Kind: NotificationJobKind, Payload: payload})
+	var permanent *jobs.PermanentError
+	if !errors.Is(err, ErrLookupFailed) || errors.As(err, &permanent) {
+		t.Fatalf("expected the failed video to keep the job retrying, got %v", err)
+	}
+}
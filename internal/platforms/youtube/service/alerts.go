@@ -9,14 +9,22 @@ import (
 	"strings"
 	"time"
 
+	"live-stream-alerts/internal/notifications"
 	"live-stream-alerts/internal/platforms/youtube/liveinfo"
 	"live-stream-alerts/internal/streamers"
 )
 
 // AlertProcessor orchestrates WebSub notification handling.
 type AlertProcessor struct {
-	Streamers   *streamers.Store
+	Streamers   streamers.Repository
 	VideoLookup LiveVideoLookup
+	// Notifier, when set, receives an alert for every live update.
+	Notifier LiveNotifier
+}
+
+// LiveNotifier dispatches go-live alerts to outbound sinks.
+type LiveNotifier interface {
+	Notify(ctx context.Context, alert notifications.Alert) error
 }
 
 // LiveVideoLookup fetches metadata for YouTube video IDs.
@@ -28,22 +36,44 @@ type LiveVideoLookup interface {
 type AlertProcessRequest struct {
 	Feed       io.Reader
 	RemoteAddr string
+	// VideoIDs, when set, limits processing to the feed's entries for these videos, so a
+	// retry only covers the lookups that failed.
+	VideoIDs []string
 }
 
 // AlertProcessResult captures the outcomes of processing a feed.
 type AlertProcessResult struct {
-	Entries       int
-	VideoIDs      []string
-	LiveUpdates   []LiveUpdate
+	Entries     int
+	VideoIDs    []string
+	LiveUpdates []LiveUpdate
+	// Upcoming lists scheduled broadcasts that were stored or rescheduled.
+	Upcoming []UpcomingUpdate
+	// Cancelled lists scheduled broadcasts that were dropped because they will not air.
+	Cancelled     []string
 	SkippedVideos []SkippedVideo
+	// LookupFailed lists videos whose metadata could not be fetched. Nothing was decided
+	// about them, so they are worth processing again.
+	LookupFailed []string
+	// NotifyErrors lists alerts that could not be queued for delivery.
+	NotifyErrors []string
 }
 
 // LiveUpdate describes a streamer whose live status was updated.
 type LiveUpdate struct {
-	ChannelID string
-	VideoID   string
-	Title     string
-	StartedAt time.Time
+	StreamerID string
+	ChannelID  string
+	VideoID    string
+	Title      string
+	StartedAt  time.Time
+}
+
+// UpcomingUpdate describes a scheduled broadcast recorded for a streamer.
+type UpcomingUpdate struct {
+	StreamerID     string
+	ChannelID      string
+	VideoID        string
+	Title          string
+	ScheduledStart time.Time
 }
 
 // SkippedVideo describes a video that could not be processed.
@@ -73,22 +103,49 @@ func (p AlertProcessor) Process(ctx context.Context, req AlertProcessRequest) (A
 		return AlertProcessResult{}, fmt.Errorf("%w: feed reader is nil", ErrInvalidFeed)
 	}
 
+	entries, err := ParseFeed(req.Feed)
+	if err != nil {
+		return AlertProcessResult{}, err
+	}
+	if len(req.VideoIDs) > 0 {
+		entries = filterEntries(entries, req.VideoIDs)
+	}
+	return p.ProcessEntries(ctx, entries)
+}
+
+// ParseFeed decodes the entries of a YouTube Atom feed, either a WebSub notification
+// or a channel's videos.xml.
+func ParseFeed(r io.Reader) ([]FeedEntry, error) {
 	var feed youtubeFeed
-	decoder := xml.NewDecoder(io.LimitReader(req.Feed, maxFeedSize))
+	decoder := xml.NewDecoder(io.LimitReader(r, maxFeedSize))
 	if err := decoder.Decode(&feed); err != nil {
-		return AlertProcessResult{}, fmt.Errorf("%w: %v", ErrInvalidFeed, err)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFeed, err)
+	}
+	return feed.Entries, nil
+}
+
+// ProcessEntries fetches metadata for the feed entries and updates streamers, exactly
+// as Process does for a decoded notification. It fails with ErrLookupFailed only when no
+// metadata came back at all; videos whose own lookup failed are listed in LookupFailed.
+func (p AlertProcessor) ProcessEntries(ctx context.Context, entries []FeedEntry) (AlertProcessResult, error) {
+	if p.Streamers == nil {
+		return AlertProcessResult{}, errors.New("streamers store is not configured")
 	}
-	result := AlertProcessResult{Entries: len(feed.Entries)}
-	if len(feed.Entries) == 0 {
+	if p.VideoLookup == nil {
+		return AlertProcessResult{}, errors.New("video lookup is not configured")
+	}
+	result := AlertProcessResult{Entries: len(entries)}
+	if len(entries) == 0 {
 		return result, nil
 	}
-	videoIDs := extractVideoIDs(feed)
+	videoIDs := extractVideoIDs(entries)
 	result.VideoIDs = videoIDs
 	info, err := p.VideoLookup.Fetch(ctx, videoIDs)
-	if err != nil {
+	var lookupErr *liveinfo.LookupError
+	if err != nil && (!errors.As(err, &lookupErr) || len(info) == 0) {
 		return result, fmt.Errorf("%w: %v", ErrLookupFailed, err)
 	}
-	for _, entry := range feed.Entries {
+	for _, entry := range entries {
 		id := strings.TrimSpace(entry.VideoID)
 		channelID := strings.TrimSpace(entry.ChannelID)
 		if id == "" || channelID == "" {
@@ -96,10 +153,18 @@ func (p AlertProcessor) Process(ctx context.Context, req AlertProcessRequest) (A
 		}
 		video, ok := info[id]
 		if !ok {
-			result.SkippedVideos = append(result.SkippedVideos, SkippedVideo{VideoID: id, Reason: "metadata missing"})
+			result.LookupFailed = append(result.LookupFailed, id)
+			continue
+		}
+		if video.IsUpcoming() {
+			p.recordUpcoming(&result, channelID, entry, video)
 			continue
 		}
 		if !video.IsLive() {
+			if p.dropScheduled(channelID, id) {
+				result.Cancelled = append(result.Cancelled, id)
+				continue
+			}
 			result.SkippedVideos = append(result.SkippedVideos, SkippedVideo{VideoID: id, Reason: "not live"})
 			continue
 		}
@@ -107,40 +172,152 @@ func (p AlertProcessor) Process(ctx context.Context, req AlertProcessRequest) (A
 		if startedAt.IsZero() {
 			startedAt = entry.Updated
 		}
-		_, updateErr := p.Streamers.UpdateYouTubeLiveStatus(channelID, streamers.YouTubeLiveStatus{
+		title := strings.TrimSpace(entry.Title)
+		if title == "" {
+			title = strings.TrimSpace(video.Title)
+		}
+		record, updateErr := p.Streamers.UpdateYouTubeLiveStatus(channelID, streamers.YouTubeLiveStatus{
 			Live:      true,
 			VideoID:   id,
+			Title:     title,
 			StartedAt: startedAt,
 		})
 		if updateErr != nil {
 			result.SkippedVideos = append(result.SkippedVideos, SkippedVideo{VideoID: id, Reason: updateErr.Error()})
 			continue
 		}
-		result.LiveUpdates = append(result.LiveUpdates, LiveUpdate{
-			ChannelID: channelID,
-			VideoID:   id,
-			Title:     entry.Title,
-			StartedAt: startedAt,
-		})
+		update := LiveUpdate{
+			StreamerID: record.Streamer.ID,
+			ChannelID:  channelID,
+			VideoID:    id,
+			Title:      title,
+			StartedAt:  startedAt,
+		}
+		result.LiveUpdates = append(result.LiveUpdates, update)
+		if p.Notifier != nil {
+			if err := p.Notifier.Notify(ctx, liveAlert(record, update)); err != nil {
+				result.NotifyErrors = append(result.NotifyErrors, fmt.Sprintf("%s: %v", id, err))
+			}
+		}
 	}
 	return result, nil
 }
 
+func (p AlertProcessor) recordUpcoming(result *AlertProcessResult, channelID string, entry FeedEntry, video liveinfo.VideoInfo) {
+	if video.ScheduledStartTime.IsZero() {
+		result.SkippedVideos = append(result.SkippedVideos, SkippedVideo{VideoID: video.ID, Reason: "upcoming without a scheduled start"})
+		return
+	}
+	title := strings.TrimSpace(video.Title)
+	if title == "" {
+		title = strings.TrimSpace(entry.Title)
+	}
+	record, err := p.Streamers.SetYouTubeUpcoming(channelID, streamers.ScheduledBroadcast{
+		VideoID:        video.ID,
+		Title:          title,
+		URL:            watchURL(video.ID),
+		ScheduledStart: video.ScheduledStartTime,
+	})
+	if err != nil {
+		result.SkippedVideos = append(result.SkippedVideos, SkippedVideo{VideoID: video.ID, Reason: err.Error()})
+		return
+	}
+	result.Upcoming = append(result.Upcoming, UpcomingUpdate{
+		StreamerID:     record.Streamer.ID,
+		ChannelID:      channelID,
+		VideoID:        video.ID,
+		Title:          title,
+		ScheduledStart: video.ScheduledStartTime,
+	})
+}
+
+// dropScheduled removes videoID from the channel's schedule when it was stored as
+// upcoming, reporting whether anything was removed.
+func (p AlertProcessor) dropScheduled(channelID, videoID string) bool {
+	records, err := p.Streamers.List()
+	if err != nil {
+		return false
+	}
+	for _, record := range records {
+		if !record.Platforms.YouTube.MatchesChannel(channelID) {
+			continue
+		}
+		for _, broadcast := range record.Schedule {
+			if broadcast.VideoID != videoID {
+				continue
+			}
+			_, err := p.Streamers.RemoveYouTubeUpcoming(channelID, videoID)
+			return err == nil
+		}
+	}
+	return false
+}
+
+func watchURL(videoID string) string {
+	return "https://www.youtube.com/watch?v=" + videoID
+}
+
+func liveAlert(record streamers.Record, update LiveUpdate) notifications.Alert {
+	return notifications.Alert{
+		StreamerID: record.Streamer.ID,
+		Alias:      record.Streamer.Alias,
+		Platform:   "youtube",
+		ChannelID:  update.ChannelID,
+		VideoID:    update.VideoID,
+		Title:      update.Title,
+		URL:        watchURL(update.VideoID),
+		StartedAt:  update.StartedAt,
+	}
+}
+
 type youtubeFeed struct {
-	Entries []youtubeEntry `xml:"entry"`
+	Links   []atomLink  `xml:"link"`
+	Entries []FeedEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+// selfLink returns the hub topic advertised by the feed's rel="self" link.
+func (f youtubeFeed) selfLink() string {
+	for _, link := range f.Links {
+		if strings.EqualFold(link.Rel, "self") {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+	return ""
 }
 
-type youtubeEntry struct {
+// FeedEntry is one video entry of a YouTube Atom feed.
+type FeedEntry struct {
 	VideoID   string    `xml:"http://www.youtube.com/xml/schemas/2015 videoId"`
 	ChannelID string    `xml:"http://www.youtube.com/xml/schemas/2015 channelId"`
 	Title     string    `xml:"title"`
+	Published time.Time `xml:"published"`
 	Updated   time.Time `xml:"updated"`
 }
 
-func extractVideoIDs(feed youtubeFeed) []string {
-	ids := make([]string, 0, len(feed.Entries))
-	seen := make(map[string]struct{}, len(feed.Entries))
-	for _, entry := range feed.Entries {
+// filterEntries keeps the entries for the given video IDs.
+func filterEntries(entries []FeedEntry, videoIDs []string) []FeedEntry {
+	wanted := make(map[string]struct{}, len(videoIDs))
+	for _, id := range videoIDs {
+		wanted[strings.TrimSpace(id)] = struct{}{}
+	}
+	kept := make([]FeedEntry, 0, len(videoIDs))
+	for _, entry := range entries {
+		if _, ok := wanted[strings.TrimSpace(entry.VideoID)]; ok {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+func extractVideoIDs(entries []FeedEntry) []string {
+	ids := make([]string, 0, len(entries))
+	seen := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
 		id := strings.TrimSpace(entry.VideoID)
 		if id == "" {
 			continue
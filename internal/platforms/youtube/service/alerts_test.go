@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"live-stream-alerts/internal/notifications"
 	"live-stream-alerts/internal/platforms/youtube/liveinfo"
 	"live-stream-alerts/internal/streamers"
 )
@@ -18,10 +19,16 @@ type stubVideoLookup struct {
 }
 
 func (s *stubVideoLookup) Fetch(ctx context.Context, videoIDs []string) (map[string]liveinfo.VideoInfo, error) {
-	if s.err != nil {
-		return nil, s.err
-	}
-	return s.infos, nil
+	return s.infos, s.err
+}
+
+type recordingNotifier struct {
+	alerts []notifications.Alert
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, alert notifications.Alert) error {
+	n.alerts = append(n.alerts, alert)
+	return nil
 }
 
 func TestAlertProcessorUpdatesLiveStatus(t *testing.T) {
@@ -44,8 +51,10 @@ func TestAlertProcessorUpdatesLiveStatus(t *testing.T) {
  </entry>
 </feed>`
 	started := time.Date(2025, 11, 16, 9, 2, 41, 0, time.UTC)
+	notifier := &recordingNotifier{}
 	processor := AlertProcessor{
 		Streamers: store,
+		Notifier:  notifier,
 		VideoLookup: &stubVideoLookup{
 			infos: map[string]liveinfo.VideoInfo{
 				"fbfHCxvsny0": {
@@ -73,6 +82,65 @@ func TestAlertProcessorUpdatesLiveStatus(t *testing.T) {
 	if len(records) != 1 || !records[0].Status.Live {
 		t.Fatalf("expected store live status to be set")
 	}
+	if len(notifier.alerts) != 1 {
+		t.Fatalf("expected one go-live alert, got %d", len(notifier.alerts))
+	}
+	alert := notifier.alerts[0]
+	if alert.Alias != "Demo" || alert.Platform != "youtube" || alert.VideoID != "fbfHCxvsny0" || alert.URL != "https://www.youtube.com/watch?v=fbfHCxvsny0" {
+		t.Fatalf("unexpected alert: %+v", alert)
+	}
+}
+
+func TestAlertProcessorTracksUpcomingBroadcasts(t *testing.T) {
+	store := streamers.NewStore(filepath.Join(t.TempDir(), "streamers.json"))
+	if _, err := store.Append(streamers.Record{
+		Streamer:  streamers.Streamer{Alias: "Demo"},
+		Platforms: streamers.Platforms{YouTube: &streamers.YouTubePlatform{ChannelID: "UCdemo"}},
+	}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	body := `<?xml version='1.0' encoding='UTF-8'?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+ <entry>
+  <yt:videoId>up123</yt:videoId>
+  <yt:channelId>UCdemo</yt:channelId>
+  <title>Friday premiere</title>
+  <updated>2025-11-16T09:02:41+00:00</updated>
+ </entry>
+</feed>`
+	scheduled := time.Date(2025, 11, 21, 18, 0, 0, 0, time.UTC)
+	lookup := &stubVideoLookup{infos: map[string]liveinfo.VideoInfo{
+		"up123": {ID: "up123", ChannelID: "UCdemo", LiveBroadcastContent: "upcoming", ScheduledStartTime: scheduled},
+	}}
+	notifier := &recordingNotifier{}
+	processor := AlertProcessor{Streamers: store, VideoLookup: lookup, Notifier: notifier}
+
+	result, err := processor.Process(context.Background(), AlertProcessRequest{Feed: bytes.NewBufferString(body)})
+	if err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	if len(result.Upcoming) != 1 || len(result.LiveUpdates) != 0 || len(notifier.alerts) != 0 {
+		t.Fatalf("expected one upcoming broadcast and no alerts, got %+v", result)
+	}
+	records, _ := store.List()
+	schedule := records[0].Schedule
+	if len(schedule) != 1 || schedule[0].Title != "Friday premiere" || !schedule[0].ScheduledStart.Equal(scheduled) {
+		t.Fatalf("unexpected schedule %+v", schedule)
+	}
+
+	// The broadcast is cancelled: the video is no longer live or upcoming.
+	lookup.infos["up123"] = liveinfo.VideoInfo{ID: "up123", ChannelID: "UCdemo"}
+	result, err = processor.Process(context.Background(), AlertProcessRequest{Feed: bytes.NewBufferString(body)})
+	if err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	if len(result.Cancelled) != 1 || len(result.SkippedVideos) != 0 {
+		t.Fatalf("expected the broadcast to be cancelled, got %+v", result)
+	}
+	records, _ = store.List()
+	if len(records[0].Schedule) != 0 {
+		t.Fatalf("expected the schedule to be cleared, got %+v", records[0].Schedule)
+	}
 }
 
 func TestAlertProcessorHandlesInvalidFeed(t *testing.T) {
@@ -109,3 +177,39 @@ func TestAlertProcessorHandlesLookupFailure(t *testing.T) {
 		t.Fatalf("expected lookup error, got %v", err)
 	}
 }
+
+func TestAlertProcessorReportsPartialLookupFailures(t *testing.T) {
+	store := streamers.NewStore(filepath.Join(t.TempDir(), "streamers.json"))
+	if _, err := store.Append(streamers.Record{
+		Streamer:  streamers.Streamer{Alias: "Demo"},
+		Platforms: streamers.Platforms{YouTube: &streamers.YouTubePlatform{ChannelID: "UCdemo"}},
+	}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	processor := AlertProcessor{
+		Streamers: store,
+		VideoLookup: &stubVideoLookup{
+			infos: map[string]liveinfo.VideoInfo{"vid1": {ID: "vid1", LiveBroadcastContent: "live"}},
+			err:   &liveinfo.LookupError{Failed: map[string]error{"vid2": errors.New("timeout")}},
+		},
+	}
+	body := `<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+ <entry><yt:videoId>vid1</yt:videoId><yt:channelId>UCdemo</yt:channelId></entry>
+ <entry><yt:videoId>vid2</yt:videoId><yt:channelId>UCdemo</yt:channelId></entry>
+</feed>`
+	result, err := processor.Process(context.Background(), AlertProcessRequest{Feed: bytes.NewBufferString(body)})
+	if err != nil {
+		t.Fatalf("expected a partial lookup failure to be processed, got %v", err)
+	}
+	if len(result.LiveUpdates) != 1 || len(result.LookupFailed) != 1 || result.LookupFailed[0] != "vid2" {
+		t.Fatalf("expected vid1 to go live and vid2 to be reported for a retry, got %+v", result)
+	}
+
+	result, err = processor.Process(context.Background(), AlertProcessRequest{Feed: bytes.NewBufferString(body), VideoIDs: []string{"vid2"}})
+	if err != nil {
+		t.Fatalf("process retry: %v", err)
+	}
+	if len(result.VideoIDs) != 1 || result.VideoIDs[0] != "vid2" {
+		t.Fatalf("expected the retry to cover only vid2, got %v", result.VideoIDs)
+	}
+}
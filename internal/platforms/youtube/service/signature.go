@@ -0,0 +1,151 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+
+	"live-stream-alerts/internal/streamers"
+)
+
+// ErrInvalidSignature indicates the X-Hub-Signature header did not match the stored hub secret.
+var ErrInvalidSignature = errors.New("invalid hub signature")
+
+// SignatureVerifier checks WebSub notification signatures against the hub secrets
+// stored on streamer records.
+type SignatureVerifier struct {
+	Streamers streamers.Repository
+}
+
+// SignatureRequest carries the raw notification body and the signature headers sent by the hub.
+type SignatureRequest struct {
+	Body         []byte
+	Signature    string // X-Hub-Signature, e.g. "sha1=<hex>"
+	Signature256 string // X-Hub-Signature-256, e.g. "sha256=<hex>"
+}
+
+// Verify resolves the hub secret for the feed's topic or channel IDs and checks the
+// HMAC of the raw body. Records are matched with the same rule the store uses to apply
+// updates, so a feed cannot reach a record without also carrying that record's
+// signature. Feeds that do not reference a record with a stored secret are accepted
+// because there is nothing to verify them against.
+func (v SignatureVerifier) Verify(req SignatureRequest) error {
+	if v.Streamers == nil {
+		return errors.New("streamers store is not configured")
+	}
+	var feed youtubeFeed
+	if err := xml.NewDecoder(bytes.NewReader(req.Body)).Decode(&feed); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFeed, err)
+	}
+	secrets, err := v.secretsForFeed(feed)
+	if err != nil {
+		return err
+	}
+	for _, secret := range secrets {
+		if err := checkSignatures(secret, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SignatureVerifier) secretsForFeed(feed youtubeFeed) ([]string, error) {
+	topic := feed.selfLink()
+	channelIDs := make([]string, 0, len(feed.Entries)+1)
+	if id := strings.TrimSpace(streamers.ChannelIDFromTopic(topic)); id != "" {
+		channelIDs = append(channelIDs, id)
+	}
+	for _, entry := range feed.Entries {
+		if id := strings.TrimSpace(entry.ChannelID); id != "" {
+			channelIDs = append(channelIDs, id)
+		}
+	}
+	if topic == "" && len(channelIDs) == 0 {
+		return nil, nil
+	}
+
+	records, err := v.Streamers.List()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{})
+	var secrets []string
+	for _, record := range records {
+		yt := record.Platforms.YouTube
+		if yt == nil || !youtubeRecordMatches(yt, topic, channelIDs) {
+			continue
+		}
+		secret := strings.TrimSpace(yt.HubSecret)
+		if secret == "" {
+			continue
+		}
+		if _, ok := seen[secret]; ok {
+			continue
+		}
+		seen[secret] = struct{}{}
+		secrets = append(secrets, secret)
+	}
+	return secrets, nil
+}
+
+func youtubeRecordMatches(yt *streamers.YouTubePlatform, topic string, channelIDs []string) bool {
+	if topic != "" && strings.TrimSpace(yt.Topic) == topic {
+		return true
+	}
+	for _, id := range channelIDs {
+		if yt.MatchesChannel(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSignatures validates every signature header that was supplied. At least one
+// header must be present and all present headers must match.
+func checkSignatures(secret string, req SignatureRequest) error {
+	headers := []string{strings.TrimSpace(req.Signature), strings.TrimSpace(req.Signature256)}
+	var checked int
+	for _, header := range headers {
+		if header == "" {
+			continue
+		}
+		if !validSignature(secret, header, req.Body) {
+			return ErrInvalidSignature
+		}
+		checked++
+	}
+	if checked == 0 {
+		return fmt.Errorf("%w: signature header missing", ErrInvalidSignature)
+	}
+	return nil
+}
+
+func validSignature(secret, header string, body []byte) bool {
+	method, signature, ok := strings.Cut(header, "=")
+	if !ok {
+		return false
+	}
+	var newHash func() hash.Hash
+	switch strings.ToLower(strings.TrimSpace(method)) {
+	case "sha1":
+		newHash = sha1.New
+	case "sha256":
+		newHash = sha256.New
+	default:
+		return false
+	}
+	expected, err := hex.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"live-stream-alerts/internal/streamers"
+)
+
+const signedFeed = `<?xml version='1.0' encoding='UTF-8'?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+ <link rel="self" href="https://www.youtube.com/xml/feeds/videos.xml?channel_id=UCsigned"/>
+ <entry>
+  <yt:videoId>vid123</yt:videoId>
+  <yt:channelId>UCsigned</yt:channelId>
+ </entry>
+</feed>`
+
+func newSignedStore(t *testing.T, secret string) *streamers.Store {
+	t.Helper()
+	store := streamers.NewStore(filepath.Join(t.TempDir(), "streamers.json"))
+	if _, err := store.Append(streamers.Record{
+		Streamer: streamers.Streamer{Alias: "Signed"},
+		Platforms: streamers.Platforms{YouTube: &streamers.YouTubePlatform{
+			ChannelID: "UCsigned",
+			HubSecret: secret,
+			Topic:     "https://www.youtube.com/xml/feeds/videos.xml?channel_id=UCsigned",
+		}},
+	}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	return store
+}
+
+func sign(secret, body string, sha256Hash bool) string {
+	if sha256Hash {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(body))
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestSignatureVerifierAcceptsValidSignatures(t *testing.T) {
+	verifier := SignatureVerifier{Streamers: newSignedStore(t, "s3cret")}
+	err := verifier.Verify(SignatureRequest{
+		Body:         []byte(signedFeed),
+		Signature:    sign("s3cret", signedFeed, false),
+		Signature256: sign("s3cret", signedFeed, true),
+	})
+	if err != nil {
+		t.Fatalf("expected signature to verify, got %v", err)
+	}
+}
+
+func TestSignatureVerifierRejectsMismatches(t *testing.T) {
+	verifier := SignatureVerifier{Streamers: newSignedStore(t, "s3cret")}
+	cases := map[string]SignatureRequest{
+		"missing header": {Body: []byte(signedFeed)},
+		"wrong secret":   {Body: []byte(signedFeed), Signature: sign("other", signedFeed, false)},
+		"bad sha256":     {Body: []byte(signedFeed), Signature: sign("s3cret", signedFeed, false), Signature256: sign("other", signedFeed, true)},
+		"unknown method": {Body: []byte(signedFeed), Signature: "md5=abcd"},
+	}
+	for name, req := range cases {
+		t.Run(name, func(t *testing.T) {
+			if err := verifier.Verify(req); !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("expected ErrInvalidSignature, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSignatureVerifierSkipsRecordsWithoutSecret(t *testing.T) {
+	verifier := SignatureVerifier{Streamers: newSignedStore(t, "")}
+	if err := verifier.Verify(SignatureRequest{Body: []byte(signedFeed)}); err != nil {
+		t.Fatalf("expected unsigned feed to pass when no secret is stored, got %v", err)
+	}
+}
+
+func TestSignatureVerifierRejectsInvalidFeed(t *testing.T) {
+	verifier := SignatureVerifier{Streamers: newSignedStore(t, "s3cret")}
+	if err := verifier.Verify(SignatureRequest{Body: []byte("not xml")}); !errors.Is(err, ErrInvalidFeed) {
+		t.Fatalf("expected ErrInvalidFeed, got %v", err)
+	}
+}
+
+func TestSignatureVerifierMatchesRecordsLikeTheStore(t *testing.T) {
+	const feed = `<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+ <link rel="self" href="https://example.com/feed?channel_id=%s"/>
+ <entry><yt:videoId>vid123</yt:videoId><yt:channelId>%s</yt:channelId></entry>
+</feed>`
+	topicOnly := streamers.NewStore(filepath.Join(t.TempDir(), "streamers.json"))
+	if _, err := topicOnly.Append(streamers.Record{
+		Streamer: streamers.Streamer{Alias: "Topic"},
+		Platforms: streamers.Platforms{YouTube: &streamers.YouTubePlatform{
+			HubSecret: "s3cret",
+			Topic:     "https://www.youtube.com/xml/feeds/videos.xml?channel_id=UCtopic",
+		}},
+	}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	cases := map[string]struct {
+		store *streamers.Store
+		body  string
+	}{
+		"channel id without prefix": {store: newSignedStore(t, "s3cret"), body: fmt.Sprintf(feed, "other", "signed")},
+		"record with only a topic":  {store: topicOnly, body: fmt.Sprintf(feed, "other", "UCtopic")},
+		"channel only in self link": {store: newSignedStore(t, "s3cret"), body: fmt.Sprintf(feed, "UCsigned", "")},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			verifier := SignatureVerifier{Streamers: tc.store}
+			if err := verifier.Verify(SignatureRequest{Body: []byte(tc.body)}); !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("expected unsigned feed to be rejected, got %v", err)
+			}
+		})
+	}
+}
@@ -10,7 +10,7 @@ import (
 )
 
 // RecordLease stores the verification timestamp for the supplied channel ID.
-func RecordLease(store *streamers.Store, channelID string, verifiedAt time.Time) error {
+func RecordLease(store streamers.Repository, channelID string, verifiedAt time.Time) error {
 	channelID = strings.TrimSpace(channelID)
 	if channelID == "" {
 		return errors.New("channelID is required")
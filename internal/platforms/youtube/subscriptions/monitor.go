@@ -12,6 +12,9 @@ import (
 
 // LeaseMonitorConfig configures the background YouTube lease renewal watcher.
 type LeaseMonitorConfig struct {
+	// Store supplies the streamer records to inspect. When nil, a JSON store is
+	// opened at StreamersPath.
+	Store         streamers.Repository
 	StreamersPath string
 	Interval      time.Duration
 	RenewWindow   float64
@@ -52,6 +55,9 @@ func newLeaseMonitor(cfg LeaseMonitorConfig) *LeaseMonitor {
 	if cfg.StreamersPath == "" {
 		cfg.StreamersPath = streamers.DefaultFilePath
 	}
+	if cfg.Store == nil {
+		cfg.Store = streamers.NewStore(cfg.StreamersPath)
+	}
 	if cfg.Interval <= 0 {
 		cfg.Interval = time.Minute
 	}
@@ -98,10 +104,10 @@ func (m *LeaseMonitor) run(ctx context.Context) {
 }
 
 func (m *LeaseMonitor) evaluate(ctx context.Context) {
-	records, err := streamers.List(m.cfg.StreamersPath)
+	records, err := m.cfg.Store.List()
 	if err != nil {
 		if m.logger != nil {
-			m.logger.Printf("lease monitor: failed to read streamers file: %v", err)
+			m.logger.Printf("lease monitor: failed to read streamers: %v", err)
 		}
 		return
 	}
@@ -154,16 +160,38 @@ func (m *LeaseMonitor) shouldRenew(leaseStart time.Time, leaseSeconds int, now t
 	if leaseSeconds <= 0 {
 		return false
 	}
+	return !now.Before(renewDeadline(leaseStart, leaseSeconds, m.cfg.RenewWindow))
+}
+
+// RenewAt reports when the record's confirmed YouTube lease should be renewed: window
+// (a fraction of the lease, 5% when out of range) before it expires. ok is false when
+// the record has no channel or no confirmed lease.
+func RenewAt(record streamers.Record, opts Options, window float64) (time.Time, bool) {
+	yt := record.Platforms.YouTube
+	if yt == nil || strings.TrimSpace(yt.ChannelID) == "" {
+		return time.Time{}, false
+	}
+	leaseSeconds := resolveLeaseSeconds("subscribe", yt, opts)
+	if leaseSeconds <= 0 {
+		return time.Time{}, false
+	}
+	leaseStart, err := time.Parse(time.RFC3339, strings.TrimSpace(yt.HubLeaseDate))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return renewDeadline(leaseStart, leaseSeconds, window), true
+}
+
+func renewDeadline(leaseStart time.Time, leaseSeconds int, window float64) time.Time {
 	leaseDuration := time.Duration(leaseSeconds) * time.Second
-	margin := time.Duration(float64(leaseDuration) * m.cfg.RenewWindow)
+	margin := time.Duration(float64(leaseDuration) * window)
 	if margin <= 0 || margin >= leaseDuration {
 		margin = leaseDuration / 20
 		if margin <= 0 {
 			margin = time.Second
 		}
 	}
-	renewAt := leaseStart.Add(leaseDuration - margin)
-	return !now.Before(renewAt)
+	return leaseStart.Add(leaseDuration - margin)
 }
 
 func (m *LeaseMonitor) awaitingRenewal(channelID string, leaseStart time.Time) bool {
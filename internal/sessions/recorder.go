@@ -0,0 +1,175 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"live-stream-alerts/internal/logging"
+	"live-stream-alerts/internal/streamers"
+)
+
+// RecorderConfig configures the background session recorder.
+type RecorderConfig struct {
+	Store     *Store
+	Streamers streamers.Repository
+	// Events must be the bus attached to Streamers.
+	Events *streamers.EventBus
+	Logger logging.Logger
+	Now    func() time.Time
+}
+
+// Recorder turns streamer status changes into session history. It follows the event
+// bus and falls back to a full resync from the repository at startup and whenever it
+// missed events.
+type Recorder struct {
+	cfg    RecorderConfig
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// StartRecorder syncs the history with the current live state and then follows cfg.Events
+// until ctx is cancelled or Stop is called.
+func StartRecorder(ctx context.Context, cfg RecorderConfig) (*Recorder, error) {
+	if cfg.Store == nil || cfg.Streamers == nil || cfg.Events == nil {
+		return nil, errors.New("sessions recorder requires a store, a streamers repository and an event bus")
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	recorder := &Recorder{cfg: cfg}
+	runCtx, cancel := context.WithCancel(ctx)
+	recorder.cancel = cancel
+
+	// Subscribe before the initial sync so no change slips between the two.
+	sub, _, _ := cfg.Events.Subscribe(0, false)
+	lastID := cfg.Events.LastID()
+	recorder.Sync()
+
+	recorder.wg.Add(1)
+	go func() {
+		defer recorder.wg.Done()
+		recorder.run(runCtx, sub, lastID)
+	}()
+	return recorder, nil
+}
+
+func (r *Recorder) run(ctx context.Context, sub *streamers.Subscription, lastID uint64) {
+	defer func() { sub.Close() }()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.C:
+			if ok {
+				lastID = event.ID
+				r.record(event)
+				continue
+			}
+			// Dropped for falling behind: resume from the last event seen, or resync when
+			// the gap has left the replay buffer.
+			var replay []streamers.Event
+			var complete bool
+			sub, replay, complete = r.cfg.Events.Subscribe(lastID, true)
+			if !complete {
+				r.logf("sessions: missed streamer events, resyncing")
+				lastID = r.cfg.Events.LastID()
+				r.Sync()
+				continue
+			}
+			for _, event := range replay {
+				lastID = event.ID
+				r.record(event)
+			}
+		}
+	}
+}
+
+func (r *Recorder) record(event streamers.Event) {
+	if event.Type == streamers.EventResync {
+		r.Sync()
+		return
+	}
+	snap := SnapshotFromRecord(event.Record, event.At)
+	if event.Type == streamers.EventStreamerDeleted {
+		snap.Live = nil
+		snap.EndedAt = nil
+	}
+	if err := r.cfg.Store.Apply(snap); err != nil {
+		r.logf("sessions: failed to record %s for %s: %v", event.Type, event.Record.Streamer.Alias, err)
+	}
+}
+
+// Sync reconciles the history with every stored record and closes sessions of streamers
+// that no longer exist.
+func (r *Recorder) Sync() {
+	records, err := r.cfg.Streamers.List()
+	if err != nil {
+		r.logf("sessions: failed to read streamers: %v", err)
+		return
+	}
+	now := r.cfg.Now().UTC()
+	known := make(map[string]struct{}, len(records))
+	for _, record := range records {
+		known[record.Streamer.ID] = struct{}{}
+		if err := r.cfg.Store.Apply(SnapshotFromRecord(record, now)); err != nil {
+			r.logf("sessions: failed to sync %s: %v", record.Streamer.Alias, err)
+		}
+	}
+	open, err := r.cfg.Store.OpenSessions()
+	if err != nil {
+		r.logf("sessions: failed to read open sessions: %v", err)
+		return
+	}
+	for _, session := range open {
+		if _, ok := known[session.StreamerID]; ok {
+			continue
+		}
+		known[session.StreamerID] = struct{}{}
+		if err := r.cfg.Store.Apply(Snapshot{StreamerID: session.StreamerID, At: now}); err != nil {
+			r.logf("sessions: failed to close sessions of removed streamer %s: %v", session.StreamerID, err)
+		}
+	}
+}
+
+// SnapshotFromRecord extracts the broadcasts a record reports as live.
+func SnapshotFromRecord(record streamers.Record, at time.Time) Snapshot {
+	snap := Snapshot{StreamerID: record.Streamer.ID, At: at}
+	status := record.Status
+	if status == nil {
+		return snap
+	}
+	if yt := status.YouTube; yt != nil {
+		if yt.Live && strings.TrimSpace(yt.VideoID) != "" {
+			snap.Live = append(snap.Live, Session{Platform: "youtube", VideoID: yt.VideoID, Title: yt.Title, StartedAt: yt.StartedAt})
+		} else if !yt.EndedAt.IsZero() {
+			snap.EndedAt = map[string]time.Time{"youtube": yt.EndedAt}
+		}
+	}
+	if tw := status.Twitch; tw != nil && tw.Live && strings.TrimSpace(tw.StreamID) != "" {
+		snap.Live = append(snap.Live, Session{Platform: "twitch", VideoID: tw.StreamID, StartedAt: tw.StartedAt})
+	}
+	if fb := status.Facebook; fb != nil && fb.Live && strings.TrimSpace(fb.VideoID) != "" {
+		snap.Live = append(snap.Live, Session{Platform: "facebook", VideoID: fb.VideoID, StartedAt: fb.StartedAt})
+	}
+	return snap
+}
+
+func (r *Recorder) logf(format string, args ...any) {
+	if r.cfg.Logger != nil {
+		r.cfg.Logger.Printf(format, args...)
+	}
+}
+
+// Stop cancels the recorder and waits for it to exit.
+func (r *Recorder) Stop() {
+	if r == nil {
+		return
+	}
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+}
@@ -0,0 +1,61 @@
+package sessions
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"live-stream-alerts/internal/streamers"
+)
+
+func TestRecorderFollowsStatusChanges(t *testing.T) {
+	dir := t.TempDir()
+	events := streamers.NewEventBus(0)
+	repo := streamers.NewStore(filepath.Join(dir, "streamers.json"), streamers.WithEvents(events))
+	record, err := repo.Append(streamers.Record{
+		Streamer:  streamers.Streamer{Alias: "Demo"},
+		Platforms: streamers.Platforms{YouTube: &streamers.YouTubePlatform{ChannelID: "UCdemo"}},
+	})
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	started := time.Date(2025, 11, 16, 9, 0, 0, 0, time.UTC)
+	// Already live before the recorder starts: picked up by the initial sync.
+	if _, err := repo.UpdateYouTubeLiveStatus("UCdemo", streamers.YouTubeLiveStatus{Live: true, VideoID: "v1", Title: "Launch", StartedAt: started}); err != nil {
+		t.Fatalf("go live: %v", err)
+	}
+
+	store := NewStore(filepath.Join(dir, "sessions.json"))
+	recorder, err := StartRecorder(context.Background(), RecorderConfig{Store: store, Streamers: repo, Events: events})
+	if err != nil {
+		t.Fatalf("start recorder: %v", err)
+	}
+	t.Cleanup(recorder.Stop)
+
+	if open, _ := store.OpenSessions(); len(open) != 1 || open[0].VideoID != "v1" || open[0].Title != "Launch" || open[0].StreamerID != record.Streamer.ID {
+		t.Fatalf("expected the live broadcast to be recorded at startup, got %+v", open)
+	}
+
+	ended := started.Add(time.Hour)
+	if _, err := repo.EndYouTubeLive("UCdemo", "v1", ended); err != nil {
+		t.Fatalf("end live: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		page, err := store.List(record.Streamer.ID, Query{})
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(page.Sessions) == 1 && !page.Sessions[0].Open() {
+			if !page.Sessions[0].EndedAt.Equal(ended) || page.Sessions[0].DurationSeconds != 3600 {
+				t.Fatalf("unexpected closed session %+v", page.Sessions[0])
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("session was not closed: %+v", page.Sessions)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
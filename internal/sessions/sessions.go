@@ -0,0 +1,451 @@
+// Package sessions keeps a history of every broadcast (start, end, duration, title,
+// platform and video ID) so past streams survive the live status being overwritten.
+package sessions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"live-stream-alerts/internal/filestore"
+)
+
+const (
+	// DefaultFilePath is where session history is stored.
+	DefaultFilePath = "data/sessions.json"
+	// DefaultMaxAge is how long ended sessions are kept by default.
+	DefaultMaxAge = 365 * 24 * time.Hour
+	// DefaultMaxPerStreamer caps how many ended sessions are kept per streamer by default.
+	DefaultMaxPerStreamer = 500
+)
+
+// errUnchanged aborts a read-modify-write cycle without rewriting the file.
+var errUnchanged = errors.New("sessions unchanged")
+
+// Session is a single broadcast on one platform.
+type Session struct {
+	ID         string    `json:"id"`
+	StreamerID string    `json:"streamerId"`
+	Platform   string    `json:"platform"`
+	VideoID    string    `json:"videoId"`
+	Title      string    `json:"title,omitempty"`
+	StartedAt  time.Time `json:"startedAt"`
+	// EndedAt is zero while the broadcast is still live.
+	EndedAt         time.Time `json:"endedAt,omitempty"`
+	DurationSeconds int64     `json:"durationSeconds,omitempty"`
+}
+
+// Open reports whether the broadcast is still live.
+func (s Session) Open() bool {
+	return s.EndedAt.IsZero()
+}
+
+// File represents the on-disk sessions format.
+type File struct {
+	Sessions []Session `json:"sessions"`
+}
+
+// Retention bounds how much ended history is kept. Open sessions are never pruned.
+type Retention struct {
+	// MaxAge drops sessions that ended longer ago than this; zero keeps them forever.
+	MaxAge time.Duration
+	// MaxPerStreamer keeps only the newest ended sessions of each streamer; zero is unlimited.
+	MaxPerStreamer int
+}
+
+// Query selects a page of a streamer's sessions.
+type Query struct {
+	// From and To keep sessions that overlap the range; zero values leave it open.
+	From   time.Time
+	To     time.Time
+	Limit  int
+	Offset int
+}
+
+// Page is one page of sessions, newest first.
+type Page struct {
+	Sessions []Session
+	// Total counts every session matching the query, across all pages.
+	Total int
+}
+
+// Store persists session history to disk behind a per-path mutex.
+type Store struct {
+	path        string
+	mu          sync.Mutex
+	now         func() time.Time
+	retention   Retention
+	backups     filestore.Backups
+	lockTimeout time.Duration
+}
+
+// StoreOption customises the store behaviour.
+type StoreOption func(*Store)
+
+// WithNow overrides the clock used for retention.
+func WithNow(fn func() time.Time) StoreOption {
+	return func(s *Store) {
+		if fn != nil {
+			s.now = fn
+		}
+	}
+}
+
+// WithRetention overrides the default retention policy.
+func WithRetention(retention Retention) StoreOption {
+	return func(s *Store) {
+		s.retention = retention
+	}
+}
+
+// WithBackups overrides where backups are written and how many are retained.
+func WithBackups(backups filestore.Backups) StoreOption {
+	return func(s *Store) {
+		s.backups = backups
+	}
+}
+
+// WithLockTimeout bounds how long writes wait for another process holding the file lock.
+func WithLockTimeout(timeout time.Duration) StoreOption {
+	return func(s *Store) {
+		s.lockTimeout = timeout
+	}
+}
+
+// NewStore returns a file-backed session store for the provided path.
+func NewStore(path string, opts ...StoreOption) *Store {
+	if path == "" {
+		path = DefaultFilePath
+	}
+	store := &Store{
+		path: filepath.Clean(path),
+		now:  time.Now,
+		retention: Retention{
+			MaxAge:         DefaultMaxAge,
+			MaxPerStreamer: DefaultMaxPerStreamer,
+		},
+	}
+	for _, opt := range opts {
+		opt(store)
+	}
+	return store
+}
+
+// Path returns the path backing the store.
+func (s *Store) Path() string {
+	if s == nil {
+		return ""
+	}
+	return s.path
+}
+
+// SessionID derives the stable identifier of a platform broadcast.
+func SessionID(platform, videoID string) string {
+	return strings.ToLower(platform) + "-" + videoID
+}
+
+// Snapshot is the live state of one streamer observed at a point in time.
+type Snapshot struct {
+	StreamerID string
+	// Live holds one session per platform that is broadcasting.
+	Live []Session
+	// EndedAt optionally carries platform-reported end times, keyed by platform.
+	EndedAt map[string]time.Time
+	At      time.Time
+}
+
+// Apply reconciles the streamer's open sessions with snap: platforms that went offline
+// are closed, new broadcasts are opened (closing any previous one on that platform),
+// and titles of running broadcasts are refreshed. The file is only rewritten when
+// something changed.
+func (s *Store) Apply(snap Snapshot) error {
+	if s == nil {
+		return errors.New("sessions store is nil")
+	}
+	if strings.TrimSpace(snap.StreamerID) == "" {
+		return errors.New("streamer id is required")
+	}
+	at := snap.At
+	if at.IsZero() {
+		at = s.now()
+	}
+	at = at.UTC()
+	live := make(map[string]Session, len(snap.Live))
+	for _, session := range snap.Live {
+		if session.Platform == "" || session.VideoID == "" {
+			continue
+		}
+		live[session.Platform] = session
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updateFileLocked(func(file *File) error {
+		changed := false
+		for i := range file.Sessions {
+			session := &file.Sessions[i]
+			if session.StreamerID != snap.StreamerID || !session.Open() {
+				continue
+			}
+			current, ok := live[session.Platform]
+			if ok && current.VideoID == session.VideoID {
+				if title := strings.TrimSpace(current.Title); title != "" && title != session.Title {
+					session.Title = title
+					changed = true
+				}
+				delete(live, session.Platform)
+				continue
+			}
+			endedAt := snap.EndedAt[session.Platform]
+			if ok {
+				// A new broadcast replaced this one without an offline update in between.
+				endedAt = current.StartedAt
+			}
+			closeSession(session, endedAt, at)
+			changed = true
+		}
+		for _, platform := range sortedKeys(live) {
+			session := live[platform]
+			id := SessionID(platform, session.VideoID)
+			if idx := indexOf(file.Sessions, id); idx >= 0 {
+				// The broadcast was closed earlier (e.g. a dropped connection) and is live
+				// again; resume it rather than recording a duplicate.
+				file.Sessions[idx].EndedAt = time.Time{}
+				file.Sessions[idx].DurationSeconds = 0
+				changed = true
+				continue
+			}
+			startedAt := session.StartedAt
+			if startedAt.IsZero() {
+				startedAt = at
+			}
+			file.Sessions = append(file.Sessions, Session{
+				ID:         id,
+				StreamerID: snap.StreamerID,
+				Platform:   platform,
+				VideoID:    session.VideoID,
+				Title:      strings.TrimSpace(session.Title),
+				StartedAt:  startedAt.UTC(),
+			})
+			changed = true
+		}
+		if s.prune(file) {
+			changed = true
+		}
+		if !changed {
+			return errUnchanged
+		}
+		return nil
+	})
+}
+
+func closeSession(session *Session, endedAt, fallback time.Time) {
+	if endedAt.IsZero() || endedAt.Before(session.StartedAt) {
+		endedAt = fallback
+	}
+	if endedAt.Before(session.StartedAt) {
+		endedAt = session.StartedAt
+	}
+	session.EndedAt = endedAt.UTC()
+	session.DurationSeconds = int64(session.EndedAt.Sub(session.StartedAt) / time.Second)
+}
+
+func indexOf(list []Session, id string) int {
+	for i := range list {
+		if list[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func sortedKeys(m map[string]Session) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// prune applies the retention policy and reports whether anything was removed.
+func (s *Store) prune(file *File) bool {
+	var cutoff time.Time
+	if s.retention.MaxAge > 0 {
+		cutoff = s.now().Add(-s.retention.MaxAge)
+	}
+	sort.SliceStable(file.Sessions, func(i, j int) bool {
+		return file.Sessions[i].StartedAt.After(file.Sessions[j].StartedAt)
+	})
+	kept := file.Sessions[:0]
+	perStreamer := make(map[string]int)
+	removed := false
+	for _, session := range file.Sessions {
+		if !session.Open() {
+			if !cutoff.IsZero() && session.EndedAt.Before(cutoff) {
+				removed = true
+				continue
+			}
+			perStreamer[session.StreamerID]++
+			if s.retention.MaxPerStreamer > 0 && perStreamer[session.StreamerID] > s.retention.MaxPerStreamer {
+				removed = true
+				continue
+			}
+		}
+		kept = append(kept, session)
+	}
+	file.Sessions = kept
+	return removed
+}
+
+// OpenSessions returns every session that is still live.
+func (s *Store) OpenSessions() ([]Session, error) {
+	file, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	var open []Session
+	for _, session := range file.Sessions {
+		if session.Open() {
+			open = append(open, session)
+		}
+	}
+	return open, nil
+}
+
+// List returns a page of sessions, newest first. An empty streamerID lists every
+// streamer's sessions.
+func (s *Store) List(streamerID string, q Query) (Page, error) {
+	file, err := s.read()
+	if err != nil {
+		return Page{}, err
+	}
+	var matched []Session
+	for _, session := range file.Sessions {
+		if streamerID != "" && session.StreamerID != streamerID {
+			continue
+		}
+		if !q.To.IsZero() && session.StartedAt.After(q.To) {
+			continue
+		}
+		if !q.From.IsZero() && !session.Open() && session.EndedAt.Before(q.From) {
+			continue
+		}
+		matched = append(matched, session)
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].StartedAt.After(matched[j].StartedAt)
+	})
+	page := Page{Total: len(matched), Sessions: []Session{}}
+	offset := q.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(matched) {
+		return page, nil
+	}
+	end := len(matched)
+	if q.Limit > 0 && offset+q.Limit < end {
+		end = offset + q.Limit
+	}
+	page.Sessions = append(page.Sessions, matched[offset:end]...)
+	return page, nil
+}
+
+func (s *Store) read() (File, error) {
+	if s == nil {
+		return File{}, errors.New("sessions store is nil")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return readFile(s.path)
+}
+
+// updateFileLocked runs a read-modify-write cycle. The caller holds s.mu, which serialises
+// goroutines; the advisory file lock extends that to other processes sharing the file.
+func (s *Store) updateFileLocked(updateFn func(*File) error) error {
+	lock, err := filestore.LockFile(s.path, s.lockTimeout)
+	if err != nil {
+		return fmt.Errorf("lock sessions file: %w", err)
+	}
+	defer lock.Unlock()
+
+	file, err := readFile(s.path)
+	if err != nil {
+		return err
+	}
+	if err := updateFn(&file); err != nil {
+		if errors.Is(err, errUnchanged) {
+			return nil
+		}
+		return err
+	}
+	data, err := writeFile(s.path, file)
+	if err != nil {
+		return err
+	}
+	// The data is already durable; a failed backup must not turn the write into an error.
+	_, _ = s.backups.Save(s.path, data)
+	return nil
+}
+
+// Recover checks that the sessions file decodes and, when it does not, restores the
+// newest backup that does. Read errors are returned without restoring anything. It
+// returns the restored backup path, or "" when the file was readable.
+func (s *Store) Recover() (string, error) {
+	if s == nil {
+		return "", errors.New("sessions store is nil")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, err := filestore.LockFile(s.path, s.lockTimeout)
+	if err != nil {
+		return "", fmt.Errorf("lock sessions file: %w", err)
+	}
+	defer lock.Unlock()
+	return s.backups.RecoverCorrupt(s.path, func(data []byte) error {
+		var file File
+		if err := json.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("decode sessions file: %w", err)
+		}
+		return nil
+	})
+}
+
+func readFile(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return File{Sessions: []Session{}}, nil
+		}
+		return File{}, err
+	}
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return File{}, fmt.Errorf("decode sessions file: %w", err)
+	}
+	if file.Sessions == nil {
+		file.Sessions = []Session{}
+	}
+	return file, nil
+}
+
+func writeFile(path string, file File) ([]byte, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create sessions dir: %w", err)
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode sessions file: %w", err)
+	}
+	if err := filestore.WriteAtomic(path, data, 0o644); err != nil {
+		return nil, fmt.Errorf("write sessions file: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,110 @@
+package sessions
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestApplyOpensAndClosesSessions(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "sessions.json"))
+	start := time.Date(2025, 11, 16, 9, 0, 0, 0, time.UTC)
+
+	live := Snapshot{StreamerID: "demo", At: start, Live: []Session{{Platform: "youtube", VideoID: "v1", StartedAt: start}}}
+	if err := store.Apply(live); err != nil {
+		t.Fatalf("apply live: %v", err)
+	}
+	// A repeated update refreshes the title instead of opening a second session.
+	live.Live[0].Title = "Morning stream"
+	if err := store.Apply(live); err != nil {
+		t.Fatalf("apply title: %v", err)
+	}
+	// A new broadcast without an offline update in between closes the previous one.
+	next := start.Add(2 * time.Hour)
+	if err := store.Apply(Snapshot{StreamerID: "demo", At: next, Live: []Session{{Platform: "youtube", VideoID: "v2", StartedAt: next}}}); err != nil {
+		t.Fatalf("apply next: %v", err)
+	}
+	end := next.Add(90 * time.Minute)
+	if err := store.Apply(Snapshot{StreamerID: "demo", At: end.Add(time.Minute), EndedAt: map[string]time.Time{"youtube": end}}); err != nil {
+		t.Fatalf("apply offline: %v", err)
+	}
+
+	page, err := store.List("demo", Query{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if page.Total != 2 || len(page.Sessions) != 2 {
+		t.Fatalf("expected two sessions, got %+v", page)
+	}
+	latest, first := page.Sessions[0], page.Sessions[1]
+	if latest.ID != "youtube-v2" || !latest.EndedAt.Equal(end) || latest.DurationSeconds != 90*60 {
+		t.Fatalf("unexpected latest session %+v", latest)
+	}
+	if first.Title != "Morning stream" || !first.EndedAt.Equal(next) || first.DurationSeconds != 2*60*60 {
+		t.Fatalf("unexpected first session %+v", first)
+	}
+	if open, _ := store.OpenSessions(); len(open) != 0 {
+		t.Fatalf("expected no open sessions, got %+v", open)
+	}
+}
+
+func TestListFiltersAndPaginates(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "sessions.json"), WithRetention(Retention{}))
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for day := 0; day < 5; day++ {
+		start := base.AddDate(0, 0, day)
+		id := string(rune('a' + day))
+		if err := store.Apply(Snapshot{StreamerID: "demo", At: start, Live: []Session{{Platform: "twitch", VideoID: id, StartedAt: start}}}); err != nil {
+			t.Fatalf("apply: %v", err)
+		}
+		if err := store.Apply(Snapshot{StreamerID: "demo", At: start.Add(time.Hour)}); err != nil {
+			t.Fatalf("apply offline: %v", err)
+		}
+	}
+	if err := store.Apply(Snapshot{StreamerID: "other", At: base, Live: []Session{{Platform: "twitch", VideoID: "z", StartedAt: base}}}); err != nil {
+		t.Fatalf("apply other: %v", err)
+	}
+
+	page, err := store.List("demo", Query{From: base.AddDate(0, 0, 1), To: base.AddDate(0, 0, 3).Add(30 * time.Minute), Limit: 2})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if page.Total != 3 || len(page.Sessions) != 2 || page.Sessions[0].VideoID != "d" || page.Sessions[1].VideoID != "c" {
+		t.Fatalf("unexpected first page %+v", page)
+	}
+	page, _ = store.List("demo", Query{From: base.AddDate(0, 0, 1), To: base.AddDate(0, 0, 3).Add(30 * time.Minute), Limit: 2, Offset: 2})
+	if page.Total != 3 || len(page.Sessions) != 1 || page.Sessions[0].VideoID != "b" {
+		t.Fatalf("unexpected second page %+v", page)
+	}
+}
+
+func TestRetentionPrunesEndedSessions(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	store := NewStore(filepath.Join(t.TempDir(), "sessions.json"),
+		WithNow(func() time.Time { return now }),
+		WithRetention(Retention{MaxAge: 30 * 24 * time.Hour, MaxPerStreamer: 2}))
+
+	starts := []time.Time{now.AddDate(0, -2, 0), now.AddDate(0, 0, -3), now.AddDate(0, 0, -2), now.AddDate(0, 0, -1)}
+	for i, start := range starts {
+		id := string(rune('a' + i))
+		if err := store.Apply(Snapshot{StreamerID: "demo", At: start, Live: []Session{{Platform: "youtube", VideoID: id, StartedAt: start}}}); err != nil {
+			t.Fatalf("apply: %v", err)
+		}
+		if err := store.Apply(Snapshot{StreamerID: "demo", At: start.Add(time.Hour)}); err != nil {
+			t.Fatalf("apply offline: %v", err)
+		}
+	}
+	// Open sessions are never pruned.
+	if err := store.Apply(Snapshot{StreamerID: "demo", At: now, Live: []Session{{Platform: "youtube", VideoID: "live", StartedAt: now}}}); err != nil {
+		t.Fatalf("apply live: %v", err)
+	}
+
+	page, _ := store.List("", Query{})
+	var ids []string
+	for _, session := range page.Sessions {
+		ids = append(ids, session.VideoID)
+	}
+	if len(ids) != 3 || ids[0] != "live" || ids[1] != "d" || ids[2] != "c" {
+		t.Fatalf("expected the live session and the two newest ended ones, got %v", ids)
+	}
+}
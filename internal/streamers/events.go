@@ -0,0 +1,211 @@
+package streamers
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Event types published whenever a store mutation changes a record.
+const (
+	EventStreamerCreated = "streamer.created"
+	EventStreamerUpdated = "streamer.updated"
+	EventStreamerDeleted = "streamer.deleted"
+	EventStatusLive      = "status.live"
+	EventStatusOffline   = "status.offline"
+	// EventResync carries no record. It is published when the store was changed outside
+	// this process, where no per-record event describes the change, so subscribers
+	// should reload every record.
+	EventResync = "resync"
+)
+
+const (
+	// DefaultEventReplay is how many recent events are kept for Last-Event-ID resume.
+	DefaultEventReplay = 256
+	// subscriberBuffer bounds how far a subscriber may fall behind before it is dropped.
+	subscriberBuffer = 64
+)
+
+// Event describes a single record change.
+type Event struct {
+	ID     uint64    `json:"id"`
+	Type   string    `json:"type"`
+	Record Record    `json:"record"`
+	At     time.Time `json:"at"`
+}
+
+// EventBus fans record changes out to in-process subscribers and keeps a bounded
+// replay buffer so reconnecting clients can resume where they left off.
+type EventBus struct {
+	mu     sync.Mutex
+	lastID uint64
+	replay []Event
+	size   int
+	subs   map[*Subscription]struct{}
+	now    func() time.Time
+}
+
+// NewEventBus returns a bus that retains the last replay events (DefaultEventReplay when <= 0).
+func NewEventBus(replay int) *EventBus {
+	if replay <= 0 {
+		replay = DefaultEventReplay
+	}
+	return &EventBus{
+		size: replay,
+		subs: make(map[*Subscription]struct{}),
+		now:  time.Now,
+	}
+}
+
+// Subscription receives events published after it was created. C is closed when the
+// subscriber falls too far behind or Close is called.
+type Subscription struct {
+	C   <-chan Event
+	ch  chan Event
+	bus *EventBus
+}
+
+// Close unregisters the subscription.
+func (s *Subscription) Close() {
+	if s == nil || s.bus == nil {
+		return
+	}
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.removeLocked(s)
+}
+
+// Publish records an event and delivers it to every subscriber. Subscribers whose buffer
+// is full are dropped rather than blocking the writer; they can resume via Subscribe.
+func (b *EventBus) Publish(eventType string, record Record) Event {
+	if b == nil {
+		return Event{}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastID++
+	event := Event{ID: b.lastID, Type: eventType, Record: record, At: b.now().UTC()}
+	if len(b.replay) == b.size {
+		copy(b.replay, b.replay[1:])
+		b.replay = b.replay[:b.size-1]
+	}
+	b.replay = append(b.replay, event)
+	for sub := range b.subs {
+		select {
+		case sub.ch <- event:
+		default:
+			b.removeLocked(sub)
+		}
+	}
+	return event
+}
+
+// LastID returns the ID of the most recently published event.
+func (b *EventBus) LastID() uint64 {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastID
+}
+
+// Subscribe registers a new subscriber. When resume is true, the events published after
+// lastID are returned for replay; complete is false if some of them have already left the
+// replay buffer (or lastID is unknown), in which case the caller should resynchronise.
+func (b *EventBus) Subscribe(lastID uint64, resume bool) (sub *Subscription, replay []Event, complete bool) {
+	ch := make(chan Event, subscriberBuffer)
+	sub = &Subscription{C: ch, ch: ch, bus: b}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	complete = true
+	if resume {
+		replay, complete = b.sinceLocked(lastID)
+	}
+	b.subs[sub] = struct{}{}
+	return sub, replay, complete
+}
+
+func (b *EventBus) sinceLocked(lastID uint64) ([]Event, bool) {
+	if lastID > b.lastID {
+		return nil, false
+	}
+	if lastID == b.lastID {
+		return nil, true
+	}
+	if len(b.replay) == 0 || lastID+1 < b.replay[0].ID {
+		return nil, false
+	}
+	start := int(lastID + 1 - b.replay[0].ID)
+	out := make([]Event, len(b.replay)-start)
+	copy(out, b.replay[start:])
+	return out, true
+}
+
+func (b *EventBus) removeLocked(sub *Subscription) {
+	if _, ok := b.subs[sub]; !ok {
+		return
+	}
+	delete(b.subs, sub)
+	close(sub.ch)
+}
+
+type recordSnapshot struct {
+	live bool
+	data []byte
+}
+
+// snapshotRecords captures each record's encoded form so in-place mutations can be
+// diffed afterwards.
+func snapshotRecords(records []Record) map[string]recordSnapshot {
+	out := make(map[string]recordSnapshot, len(records))
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			continue
+		}
+		out[record.Streamer.ID] = recordSnapshot{live: record.Status != nil && record.Status.Live, data: data}
+	}
+	return out
+}
+
+// publishChanges compares the records before and after a mutation and publishes one
+// event per created, updated, deleted, or live-toggled record.
+func (b *EventBus) publishChanges(before map[string]recordSnapshot, after []Record) {
+	if b == nil {
+		return
+	}
+	seen := make(map[string]struct{}, len(after))
+	for _, record := range after {
+		id := record.Streamer.ID
+		seen[id] = struct{}{}
+		prev, existed := before[id]
+		if !existed {
+			b.Publish(EventStreamerCreated, record)
+			continue
+		}
+		data, err := json.Marshal(record)
+		if err != nil || string(data) == string(prev.data) {
+			continue
+		}
+		live := record.Status != nil && record.Status.Live
+		switch {
+		case live && !prev.live:
+			b.Publish(EventStatusLive, record)
+		case !live && prev.live:
+			b.Publish(EventStatusOffline, record)
+		default:
+			b.Publish(EventStreamerUpdated, record)
+		}
+	}
+	for id, prev := range before {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(prev.data, &record); err != nil {
+			continue
+		}
+		b.Publish(EventStreamerDeleted, record)
+	}
+}
@@ -0,0 +1,144 @@
+package streamers
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func drain(sub *Subscription) []Event {
+	var out []Event
+	for {
+		select {
+		case event, ok := <-sub.C:
+			if !ok {
+				return out
+			}
+			out = append(out, event)
+		default:
+			return out
+		}
+	}
+}
+
+func TestEventBusReplaysFromLastEventID(t *testing.T) {
+	bus := NewEventBus(3)
+	for i := 0; i < 5; i++ {
+		bus.Publish(EventStreamerUpdated, Record{})
+	}
+
+	sub, replay, complete := bus.Subscribe(3, true)
+	defer sub.Close()
+	if !complete || len(replay) != 2 || replay[0].ID != 4 || replay[1].ID != 5 {
+		t.Fatalf("expected events 4 and 5, got complete=%v %+v", complete, replay)
+	}
+
+	if _, _, complete := bus.Subscribe(1, true); complete {
+		t.Fatalf("event 2 has left the buffer; expected incomplete replay")
+	}
+	if _, _, complete := bus.Subscribe(99, true); complete {
+		t.Fatalf("unknown future id should require a resync")
+	}
+	if _, replay, complete := bus.Subscribe(5, true); !complete || len(replay) != 0 {
+		t.Fatalf("up-to-date client should get an empty replay")
+	}
+}
+
+func TestEventBusDropsSlowSubscribers(t *testing.T) {
+	bus := NewEventBus(0)
+	sub, _, _ := bus.Subscribe(0, false)
+	for i := 0; i < subscriberBuffer+1; i++ {
+		bus.Publish(EventStreamerUpdated, Record{})
+	}
+	if got := len(drain(sub)); got != subscriberBuffer {
+		t.Fatalf("expected %d buffered events before the drop, got %d", subscriberBuffer, got)
+	}
+	if _, ok := <-sub.C; ok {
+		t.Fatalf("expected channel to be closed for a slow subscriber")
+	}
+	sub.Close()
+}
+
+func TestStorePublishesTypedEvents(t *testing.T) {
+	bus := NewEventBus(0)
+	sub, _, _ := bus.Subscribe(0, false)
+	defer sub.Close()
+	store := NewStore(filepath.Join(t.TempDir(), "streamers.json"), WithEvents(bus))
+
+	if _, err := store.Append(Record{
+		Streamer:  Streamer{ID: "demo", Alias: "Demo"},
+		Platforms: Platforms{YouTube: &YouTubePlatform{ChannelID: "UCdemo"}},
+	}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	alias := "Renamed"
+	if _, err := store.Update(UpdateFields{StreamerID: "demo", Alias: &alias}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if _, err := store.SetYouTubeLive("UCdemo", "video", time.Now()); err != nil {
+		t.Fatalf("set live: %v", err)
+	}
+	if _, err := store.ClearYouTubeLive("UCdemo"); err != nil {
+		t.Fatalf("clear live: %v", err)
+	}
+	if _, err := store.Update(UpdateFields{StreamerID: "demo", Alias: &alias}); err != nil {
+		t.Fatalf("no-op update: %v", err)
+	}
+	if err := store.Delete("demo"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	events := drain(sub)
+	want := []string{EventStreamerCreated, EventStreamerUpdated, EventStatusLive, EventStatusOffline, EventStreamerUpdated, EventStreamerDeleted}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %+v", len(want), events)
+	}
+	for i, event := range events {
+		if event.Type != want[i] {
+			t.Fatalf("event %d: expected %s, got %s", i, want[i], event.Type)
+		}
+		if event.Record.Streamer.ID != "demo" {
+			t.Fatalf("event %d: expected demo record, got %+v", i, event.Record)
+		}
+	}
+	if events[1].Record.Streamer.Alias != "Renamed" {
+		t.Fatalf("update event should carry the changed record")
+	}
+}
+
+func TestChangeWatcherPublishesResyncForExternalWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "streamers.json")
+	bus := NewEventBus(0)
+	store := NewStore(path, WithEvents(bus))
+	if _, err := store.Append(Record{Streamer: Streamer{ID: "own", Alias: "Own"}}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	sub, _, _ := bus.Subscribe(0, false)
+	defer sub.Close()
+	watcher := StartChangeWatcher(context.Background(), ChangeWatcherConfig{Source: store, Events: bus, Interval: 10 * time.Millisecond})
+	defer watcher.Stop()
+
+	if _, err := store.Append(Record{Streamer: Streamer{ID: "mine", Alias: "Mine"}}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if changed, err := store.ExternalChange(); err != nil || changed {
+		t.Fatalf("expected the store's own write not to count, got %v, %v", changed, err)
+	}
+
+	// A second store on the same path stands in for another process or a hand edit.
+	if _, err := NewStore(path).Append(Record{Streamer: Streamer{ID: "other", Alias: "Other"}}); err != nil {
+		t.Fatalf("external append: %v", err)
+	}
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-sub.C:
+			if event.Type == EventResync {
+				return
+			}
+		case <-deadline:
+			t.Fatalf("expected a resync event after the external write")
+		}
+	}
+}
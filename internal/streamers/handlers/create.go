@@ -2,8 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
+	"live-stream-alerts/internal/streamers"
 	streamersvc "live-stream-alerts/internal/streamers/service"
 )
 
@@ -16,13 +20,24 @@ type createRequest struct {
 	Platforms struct {
 		URL string `json:"url"`
 	} `json:"platforms"`
+	// Website is the honeypot: the UI hides it from people, so only bots fill it in.
+	Website     string `json:"website"`
+	ProofOfWork string `json:"proofOfWork"`
+}
+
+// CreateErrorResponse is the body of a refused POST /api/streamers, so that the UI can
+// explain the refusal. Error is a stable code such as "rate_limited" or "duplicate_url".
+type CreateErrorResponse struct {
+	Error             string `json:"error"`
+	Message           string `json:"message"`
+	RetryAfterSeconds int    `json:"retryAfterSeconds,omitempty"`
 }
 
 func (h *streamersHTTPHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 	var req createRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		respondCreateError(w, http.StatusBadRequest, CreateErrorResponse{Error: "invalid_json", Message: "invalid JSON body"})
 		return
 	}
 	createReq := streamersvc.CreateRequest{
@@ -30,11 +45,18 @@ func (h *streamersHTTPHandler) handleCreate(w http.ResponseWriter, r *http.Reque
 		Description: req.Streamer.Description,
 		Languages:   req.Streamer.Languages,
 		PlatformURL: req.Platforms.URL,
+		ClientIP:    h.proxies.IP(r),
+		Honeypot:    req.Website,
+		ProofOfWork: req.ProofOfWork,
 	}
-	if _, err := h.service.Create(r.Context(), createReq); err != nil {
-		h.respondError(w, err, "failed to queue submission")
+	result, err := h.service.Create(r.Context(), createReq)
+	if err != nil {
+		h.respondCreateRefusal(w, createReq.ClientIP, err)
 		return
 	}
+	if result.Discarded && h.logger != nil {
+		h.logger.Printf("discarded submission from %s: honeypot field filled in", createReq.ClientIP)
+	}
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(http.StatusAccepted)
 	_ = json.NewEncoder(w).Encode(map[string]string{
@@ -42,3 +64,48 @@ func (h *streamersHTTPHandler) handleCreate(w http.ResponseWriter, r *http.Reque
 		"message": "Submission received and pending approval.",
 	})
 }
+
+func (h *streamersHTTPHandler) respondCreateRefusal(w http.ResponseWriter, clientIP string, err error) {
+	var refusal *streamersvc.SubmissionError
+	switch {
+	case errors.As(err, &refusal):
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, streamersvc.ErrRateLimited):
+			status = http.StatusTooManyRequests
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(refusal)))
+		case errors.Is(err, streamersvc.ErrQueueFull):
+			status = http.StatusServiceUnavailable
+		case errors.Is(err, streamersvc.ErrDuplicateSubmission):
+			status = http.StatusConflict
+		}
+		if h.logger != nil && status != http.StatusBadRequest {
+			h.logger.Printf("refused submission from %s: %s", clientIP, refusal.Code)
+		}
+		body := CreateErrorResponse{Error: refusal.Code, Message: refusal.Message}
+		if refusal.RetryAfter > 0 {
+			body.RetryAfterSeconds = retryAfterSeconds(refusal)
+		}
+		respondCreateError(w, status, body)
+	case errors.Is(err, streamersvc.ErrValidation):
+		respondCreateError(w, http.StatusBadRequest, CreateErrorResponse{Error: "invalid_submission", Message: err.Error()})
+	case errors.Is(err, streamers.ErrDuplicateAlias):
+		respondCreateError(w, http.StatusConflict, CreateErrorResponse{Error: "duplicate_alias", Message: "a streamer with that alias already exists"})
+	default:
+		if h.logger != nil {
+			h.logger.Printf("failed to queue submission: %v", err)
+		}
+		respondCreateError(w, http.StatusInternalServerError, CreateErrorResponse{Error: "internal", Message: "failed to queue submission"})
+	}
+}
+
+// retryAfterSeconds rounds up so clients never retry before the limit has lifted.
+func retryAfterSeconds(refusal *streamersvc.SubmissionError) int {
+	return max(1, int(math.Ceil(refusal.RetryAfter.Seconds())))
+}
+
+func respondCreateError(w http.ResponseWriter, status int, body CreateErrorResponse) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
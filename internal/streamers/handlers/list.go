@@ -13,6 +13,13 @@ func (h *streamersHTTPHandler) handleList(w http.ResponseWriter, r *http.Request
 		h.respondError(w, err, "failed to read streamer data")
 		return
 	}
+	if !h.fullRecords {
+		public := make([]streamers.Record, len(records))
+		for i, record := range records {
+			public[i] = record.Public()
+		}
+		records = public
+	}
 
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	response := struct {
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"live-stream-alerts/internal/clientip"
 	"live-stream-alerts/internal/logging"
 	"live-stream-alerts/internal/streamers"
 	streamersvc "live-stream-alerts/internal/streamers/service"
@@ -23,11 +24,18 @@ type StreamerService interface {
 type StreamOptions struct {
 	Service StreamerService
 	Logger  logging.Logger
+	// Proxies resolves the submitter's address for the per-address submission limit.
+	Proxies *clientip.Resolver
+	// FullRecords lists records with their secrets and contact details instead of
+	// Record.Public. Only set it on handlers behind admin auth.
+	FullRecords bool
 }
 
 type streamersHTTPHandler struct {
-	service StreamerService
-	logger  logging.Logger
+	service     StreamerService
+	logger      logging.Logger
+	proxies     *clientip.Resolver
+	fullRecords bool
 }
 
 // StreamersHandler returns a handler for GET/POST /api/streamers.
@@ -37,10 +45,29 @@ func StreamersHandler(opts StreamOptions) http.Handler {
 			http.Error(w, "streamer service not configured", http.StatusInternalServerError)
 		})
 	}
-	h := &streamersHTTPHandler{service: opts.Service, logger: opts.Logger}
+	h := &streamersHTTPHandler{service: opts.Service, logger: opts.Logger, proxies: opts.Proxies, fullRecords: opts.FullRecords}
 	return http.HandlerFunc(h.serveHTTP)
 }
 
+// ListHandler returns a handler that only serves GET, for listing records on a route
+// other than /api/streamers.
+func ListHandler(opts StreamOptions) http.Handler {
+	if opts.Service == nil {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "streamer service not configured", http.StatusInternalServerError)
+		})
+	}
+	h := &streamersHTTPHandler{service: opts.Service, logger: opts.Logger, fullRecords: opts.FullRecords}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.handleList(w, r)
+	})
+}
+
 func (h *streamersHTTPHandler) serveHTTP(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
@@ -7,10 +7,14 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"live-stream-alerts/internal/clientip"
 	"live-stream-alerts/internal/streamers"
 	streamersvc "live-stream-alerts/internal/streamers/service"
+	"live-stream-alerts/internal/submissions"
 )
 
 type fakeService struct {
@@ -57,6 +61,43 @@ func TestStreamersHandlerList(t *testing.T) {
 	}
 }
 
+func TestStreamersHandlerListRedactsSecrets(t *testing.T) {
+	record := streamers.Record{
+		Streamer: streamers.Streamer{Alias: "one", Email: "one@example.com"},
+		Platforms: streamers.Platforms{
+			YouTube:  &streamers.YouTubePlatform{ChannelID: "UCone", HubSecret: "hub-secret"},
+			Facebook: &streamers.FacebookPlatform{PageID: "page", AccessToken: "fb-token"},
+		},
+	}
+	service := &fakeService{listResp: []streamers.Record{record}}
+
+	resp := httptest.NewRecorder()
+	StreamersHandler(StreamOptions{Service: service}).ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/api/streamers", nil))
+	body := resp.Body.String()
+	for _, secret := range []string{"hub-secret", "fb-token", "one@example.com"} {
+		if bytes.Contains([]byte(body), []byte(secret)) {
+			t.Fatalf("expected %q to be redacted: %s", secret, body)
+		}
+	}
+	if !bytes.Contains([]byte(body), []byte("UCone")) {
+		t.Fatalf("expected public fields to remain: %s", body)
+	}
+	if record.Platforms.YouTube.HubSecret != "hub-secret" {
+		t.Fatal("expected redaction to leave the stored record untouched")
+	}
+
+	resp = httptest.NewRecorder()
+	ListHandler(StreamOptions{Service: service, FullRecords: true}).ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/api/admin/streamers", nil))
+	if !bytes.Contains(resp.Body.Bytes(), []byte("hub-secret")) {
+		t.Fatalf("expected full records: %s", resp.Body.String())
+	}
+	resp = httptest.NewRecorder()
+	ListHandler(StreamOptions{Service: service, FullRecords: true}).ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/api/admin/streamers", nil))
+	if resp.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", resp.Code)
+	}
+}
+
 func TestStreamersHandlerCreateValidatesJSON(t *testing.T) {
 	handler := StreamersHandler(StreamOptions{Service: &fakeService{}})
 	req := httptest.NewRequest(http.MethodPost, "/api/streamers", bytes.NewBufferString("not json"))
@@ -144,3 +185,49 @@ func TestStreamersHandlerPropagatesInternalErrors(t *testing.T) {
 		t.Fatalf("expected 500, got %d", resp.Code)
 	}
 }
+
+func TestStreamersHandlerCreateReturnsStructuredRefusals(t *testing.T) {
+	dir := t.TempDir()
+	service := streamersvc.New(streamersvc.Options{
+		Streamers:   streamers.NewStore(filepath.Join(dir, "streamers.json")),
+		Submissions: submissions.NewStore(filepath.Join(dir, "submissions.json")),
+		Limits:      streamersvc.SubmissionLimits{MaxPerIP: 1, Window: time.Minute},
+	})
+	proxies, err := clientip.NewResolver([]string{"10.0.0.1"})
+	if err != nil {
+		t.Fatalf("resolver: %v", err)
+	}
+	handler := StreamersHandler(StreamOptions{Service: service, Proxies: proxies})
+	submit := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/streamers", bytes.NewBufferString(body))
+		req.RemoteAddr = "10.0.0.1:4000"
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		return resp
+	}
+	decode := func(resp *httptest.ResponseRecorder) CreateErrorResponse {
+		var got CreateErrorResponse
+		if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+			t.Fatalf("decode %q: %v", resp.Body.String(), err)
+		}
+		return got
+	}
+
+	if resp := submit(`{"streamer":{"alias":"One"}}`); resp.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", resp.Code)
+	}
+	if resp := submit(`{"streamer":{"alias":"One"}}`); resp.Code != http.StatusConflict || decode(resp).Error != "duplicate_alias" {
+		t.Fatalf("expected a structured duplicate_alias conflict, got %d %s", resp.Code, resp.Body.String())
+	}
+	resp := submit(`{"streamer":{"alias":"Two"}}`)
+	if resp.Code != http.StatusTooManyRequests || resp.Header().Get("Retry-After") != "60" {
+		t.Fatalf("expected 429 with Retry-After 60, got %d %q", resp.Code, resp.Header().Get("Retry-After"))
+	}
+	if got := decode(resp); got.Error != "rate_limited" || got.Message == "" || got.RetryAfterSeconds != 60 {
+		t.Fatalf("unexpected body %+v", got)
+	}
+	if resp := submit(`{"streamer":{"alias":"Bot"},"website":"http://spam.example"}`); resp.Code != http.StatusAccepted {
+		t.Fatalf("expected the honeypot submission to look accepted, got %d", resp.Code)
+	}
+}
@@ -0,0 +1,553 @@
+package streamers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Repository is the storage contract for streamer records. Store persists records in a
+// JSON file and SQLiteStore keeps them in a SQLite database; callers should depend on
+// this interface rather than either implementation.
+type Repository interface {
+	// Path returns the file backing the repository.
+	Path() string
+	List() ([]Record, error)
+	Get(streamerID string) (Record, error)
+	Append(record Record) (Record, error)
+	Update(fields UpdateFields) (Record, error)
+	Delete(streamerID string) error
+	// UpdateFile applies updateFn to every record in a single read-modify-write cycle.
+	UpdateFile(updateFn func(*File) error) error
+	UpdateYouTubeLiveStatus(channelID string, liveStatus YouTubeLiveStatus) (Record, error)
+	SetYouTubeLive(channelID, videoID string, startedAt time.Time) (Record, error)
+	ClearYouTubeLive(channelID string) (Record, error)
+	EndYouTubeLive(channelID, videoID string, endedAt time.Time) (Record, error)
+	UpdateTwitchLiveStatus(broadcasterID string, liveStatus TwitchLiveStatus) (Record, error)
+	UpdateFacebookLiveStatus(pageID string, liveStatus FacebookLiveStatus) (Record, error)
+	SetYouTubeUpcoming(channelID string, broadcast ScheduledBroadcast) (Record, error)
+	RemoveYouTubeUpcoming(channelID, videoID string) (Record, error)
+}
+
+var (
+	_ Repository = (*Store)(nil)
+	_ Repository = (*SQLiteStore)(nil)
+)
+
+// mutator is implemented by each backend so the record operations below are shared.
+type mutator interface {
+	mutate(scope recordScope, updateFn func(*File) error) error
+}
+
+// recordScope names the records an operation reads or changes. The JSON store always
+// hands updateFn the whole file, while the SQLite store loads only the rows that match
+// one of the set fields. The zero value selects every record.
+type recordScope struct {
+	id       string
+	alias    string
+	youtube  string
+	twitch   string
+	facebook string
+}
+
+func (s recordScope) all() bool {
+	return s == recordScope{}
+}
+
+// YouTubeLiveStatus describes the live state to persist for a YouTube channel.
+type YouTubeLiveStatus struct {
+	Live      bool
+	VideoID   string
+	Title     string
+	StartedAt time.Time
+}
+
+// TwitchLiveStatus describes the live state to persist for a Twitch broadcaster.
+type TwitchLiveStatus struct {
+	Live      bool
+	StreamID  string
+	StartedAt time.Time
+}
+
+// FacebookLiveStatus describes the Facebook Live state reported for a page.
+type FacebookLiveStatus struct {
+	Live      bool
+	VideoID   string
+	StartedAt time.Time
+}
+
+const (
+	platformYouTube  = "youtube"
+	platformTwitch   = "twitch"
+	platformFacebook = "facebook"
+)
+
+func appendRecord(m mutator, record Record) (Record, error) {
+	if record.Streamer.ID == "" {
+		record.Streamer.ID = GenerateID()
+	}
+	scope := recordScope{id: record.Streamer.ID, alias: record.Streamer.Alias}
+	err := m.mutate(scope, func(fileData *File) error {
+		if fileData.SchemaRef == "" {
+			fileData.SchemaRef = DefaultSchemaPath
+		}
+		now := time.Now().UTC()
+		record.CreatedAt = now
+		record.UpdatedAt = now
+
+		newAliasKey := NormaliseAlias(record.Streamer.Alias)
+		for _, existing := range fileData.Records {
+			if strings.EqualFold(existing.Streamer.ID, record.Streamer.ID) {
+				return fmt.Errorf("%w: %s", ErrDuplicateStreamerID, record.Streamer.ID)
+			}
+			if newAliasKey != "" && newAliasKey == NormaliseAlias(existing.Streamer.Alias) {
+				return fmt.Errorf("%w: %s", ErrDuplicateAlias, record.Streamer.Alias)
+			}
+		}
+		fileData.Records = append(fileData.Records, record)
+		return nil
+	})
+	if err != nil {
+		return Record{}, err
+	}
+	return record, nil
+}
+
+func findRecord(records []Record, streamerID string) (Record, error) {
+	for _, record := range records {
+		if strings.EqualFold(record.Streamer.ID, streamerID) {
+			return record, nil
+		}
+	}
+	return Record{}, fmt.Errorf("%w: %s", ErrStreamerNotFound, streamerID)
+}
+
+func updateRecord(m mutator, fields UpdateFields) (Record, error) {
+	id := strings.TrimSpace(fields.StreamerID)
+	if id == "" {
+		return Record{}, errors.New("streamer id is required")
+	}
+	if fields.Alias == nil && fields.Description == nil && fields.Languages == nil {
+		return Record{}, errors.New("no fields provided to update")
+	}
+
+	var updated Record
+	err := m.mutate(recordScope{id: id}, func(file *File) error {
+		for i := range file.Records {
+			if !strings.EqualFold(file.Records[i].Streamer.ID, id) {
+				continue
+			}
+			if fields.Alias != nil {
+				file.Records[i].Streamer.Alias = *fields.Alias
+			}
+			if fields.Description != nil {
+				file.Records[i].Streamer.Description = *fields.Description
+			}
+			if fields.Languages != nil {
+				file.Records[i].Streamer.Languages = append([]string(nil), (*fields.Languages)...)
+			}
+			file.Records[i].UpdatedAt = time.Now().UTC()
+			updated = file.Records[i]
+			return nil
+		}
+		return fmt.Errorf("%w: %s", ErrStreamerNotFound, id)
+	})
+	if err != nil {
+		return Record{}, err
+	}
+	return updated, nil
+}
+
+func deleteRecord(m mutator, streamerID string) error {
+	streamerID = strings.TrimSpace(streamerID)
+	if streamerID == "" {
+		return errors.New("streamer id is required")
+	}
+	return m.mutate(recordScope{id: streamerID}, func(file *File) error {
+		for i := range file.Records {
+			if strings.EqualFold(file.Records[i].Streamer.ID, streamerID) {
+				file.Records = append(file.Records[:i], file.Records[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s", ErrStreamerNotFound, streamerID)
+	})
+}
+
+// updateYouTubeLiveStatus matches channelID with channelMatches, like every other
+// YouTube update, so the signature check and the store agree on which record a feed
+// belongs to.
+func updateYouTubeLiveStatus(m mutator, channelID string, liveStatus YouTubeLiveStatus) (Record, error) {
+	return updateYouTubeRecord(m, channelID, func(record *Record) {
+		applyYouTubeStatus(record, liveStatus)
+	})
+}
+
+func applyYouTubeStatus(record *Record, liveStatus YouTubeLiveStatus) {
+	if record.Status == nil {
+		record.Status = &Status{}
+	}
+	if record.Status.YouTube == nil {
+		record.Status.YouTube = &YouTubeStatus{}
+	}
+	record.Status.YouTube.Live = liveStatus.Live
+	record.Status.YouTube.VideoID = liveStatus.VideoID
+	record.Status.YouTube.Title = strings.TrimSpace(liveStatus.Title)
+	if liveStatus.Live {
+		record.Status.YouTube.EndedAt = time.Time{}
+		// The broadcast has started, so it is no longer upcoming.
+		record.Schedule = withoutScheduled(record.Schedule, platformYouTube, liveStatus.VideoID)
+	}
+	if liveStatus.StartedAt.IsZero() {
+		record.Status.YouTube.StartedAt = time.Time{}
+	} else {
+		record.Status.YouTube.StartedAt = liveStatus.StartedAt.UTC()
+	}
+
+	if liveStatus.Live {
+		record.Status.Platforms = addPlatform(record.Status.Platforms, platformYouTube)
+	} else {
+		record.Status.Platforms = removePlatform(record.Status.Platforms, platformYouTube)
+	}
+	if !liveStatus.Live && record.Status.YouTube != nil {
+		record.Status.YouTube.Live = false
+		record.Status.YouTube.VideoID = ""
+		record.Status.YouTube.Title = ""
+		record.Status.YouTube.StartedAt = time.Time{}
+	}
+	refreshLiveFlag(record.Status)
+}
+
+func setYouTubeUpcoming(m mutator, channelID string, broadcast ScheduledBroadcast) (Record, error) {
+	broadcast.VideoID = strings.TrimSpace(broadcast.VideoID)
+	if broadcast.VideoID == "" {
+		return Record{}, errors.New("video id is required")
+	}
+	if broadcast.ScheduledStart.IsZero() {
+		return Record{}, errors.New("scheduled start is required")
+	}
+	broadcast.Platform = platformYouTube
+	broadcast.ScheduledStart = broadcast.ScheduledStart.UTC()
+	broadcast.UpdatedAt = time.Now().UTC()
+	return updateYouTubeRecord(m, channelID, func(record *Record) {
+		for i := range record.Schedule {
+			if record.Schedule[i].Platform == platformYouTube && record.Schedule[i].VideoID == broadcast.VideoID {
+				record.Schedule[i] = broadcast
+				sortSchedule(record.Schedule)
+				return
+			}
+		}
+		record.Schedule = append(record.Schedule, broadcast)
+		sortSchedule(record.Schedule)
+	})
+}
+
+func removeYouTubeUpcoming(m mutator, channelID, videoID string) (Record, error) {
+	videoID = strings.TrimSpace(videoID)
+	if videoID == "" {
+		return Record{}, errors.New("video id is required")
+	}
+	return updateYouTubeRecord(m, channelID, func(record *Record) {
+		record.Schedule = withoutScheduled(record.Schedule, platformYouTube, videoID)
+	})
+}
+
+// updateYouTubeRecord applies updateFn to the record owning channelID.
+func updateYouTubeRecord(m mutator, channelID string, updateFn func(*Record)) (Record, error) {
+	channelID = strings.TrimSpace(channelID)
+	if channelID == "" {
+		return Record{}, errors.New("youtube channel id is required")
+	}
+	var updated Record
+	err := m.mutate(recordScope{youtube: channelID}, func(file *File) error {
+		for i := range file.Records {
+			if !channelMatches(file.Records[i].Platforms.YouTube, channelID) {
+				continue
+			}
+			updateFn(&file.Records[i])
+			file.Records[i].UpdatedAt = time.Now().UTC()
+			updated = file.Records[i]
+			return nil
+		}
+		return fmt.Errorf("%w: %s", ErrStreamerNotFound, channelID)
+	})
+	return updated, err
+}
+
+func withoutScheduled(schedule []ScheduledBroadcast, platform, videoID string) []ScheduledBroadcast {
+	if len(schedule) == 0 || videoID == "" {
+		return schedule
+	}
+	out := schedule[:0]
+	for _, broadcast := range schedule {
+		if broadcast.Platform == platform && broadcast.VideoID == videoID {
+			continue
+		}
+		out = append(out, broadcast)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func sortSchedule(schedule []ScheduledBroadcast) {
+	sort.SliceStable(schedule, func(i, j int) bool {
+		return schedule[i].ScheduledStart.Before(schedule[j].ScheduledStart)
+	})
+}
+
+func addPlatform(platforms []string, platform string) []string {
+	platform = strings.ToLower(strings.TrimSpace(platform))
+	if platform == "" {
+		return platforms
+	}
+	for _, existing := range platforms {
+		if strings.EqualFold(existing, platform) {
+			return platforms
+		}
+	}
+	return append(platforms, platform)
+}
+
+func removePlatform(platforms []string, platform string) []string {
+	if len(platforms) == 0 {
+		return platforms
+	}
+	platform = strings.ToLower(strings.TrimSpace(platform))
+	out := platforms[:0]
+	for _, existing := range platforms {
+		if strings.EqualFold(existing, platform) {
+			continue
+		}
+		out = append(out, existing)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func setYouTubeLive(m mutator, channelID, videoID string, startedAt time.Time) (Record, error) {
+	return updateYouTubeStatus(m, channelID, func(status *Status) {
+		if status.YouTube == nil {
+			status.YouTube = &YouTubeStatus{}
+		}
+		status.YouTube.Live = true
+		status.YouTube.VideoID = videoID
+		status.YouTube.EndedAt = time.Time{}
+		if !startedAt.IsZero() {
+			status.YouTube.StartedAt = startedAt
+		} else {
+			status.YouTube.StartedAt = time.Time{}
+		}
+		status.Platforms = addPlatform(status.Platforms, platformYouTube)
+		status.Live = true
+	})
+}
+
+func clearYouTubeLive(m mutator, channelID string) (Record, error) {
+	return updateYouTubeStatus(m, channelID, func(status *Status) {
+		if status.YouTube == nil {
+			status.YouTube = &YouTubeStatus{}
+		}
+		status.YouTube.Live = false
+		status.YouTube.VideoID = ""
+		status.YouTube.StartedAt = time.Time{}
+		status.Platforms = removePlatform(status.Platforms, platformYouTube)
+	})
+}
+
+func endYouTubeLive(m mutator, channelID, videoID string, endedAt time.Time) (Record, error) {
+	videoID = strings.TrimSpace(videoID)
+	if videoID == "" {
+		return Record{}, errors.New("video id is required")
+	}
+	if endedAt.IsZero() {
+		endedAt = time.Now()
+	}
+	return updateYouTubeStatus(m, channelID, func(status *Status) {
+		if status.YouTube == nil || !status.YouTube.Live || status.YouTube.VideoID != videoID {
+			return
+		}
+		status.YouTube.Live = false
+		status.YouTube.VideoID = ""
+		status.YouTube.Title = ""
+		status.YouTube.StartedAt = time.Time{}
+		status.YouTube.EndedAt = endedAt.UTC()
+		status.Platforms = removePlatform(status.Platforms, platformYouTube)
+	})
+}
+
+func updateYouTubeStatus(m mutator, channelID string, updateFn func(*Status)) (Record, error) {
+	channelID = strings.TrimSpace(channelID)
+	if channelID == "" {
+		return Record{}, errors.New("youtube channel id is required")
+	}
+	return updateStatus(m, recordScope{youtube: channelID}, channelID, func(record Record) bool {
+		return channelMatches(record.Platforms.YouTube, channelID)
+	}, updateFn)
+}
+
+// updateStatus applies updateFn to the status of the first record in scope accepted by
+// match and recomputes the aggregate live flag. key is only used in the not-found error.
+func updateStatus(m mutator, scope recordScope, key string, match func(Record) bool, updateFn func(*Status)) (Record, error) {
+	var updated Record
+	err := m.mutate(scope, func(file *File) error {
+		for i := range file.Records {
+			if !match(file.Records[i]) {
+				continue
+			}
+			if file.Records[i].Status == nil {
+				file.Records[i].Status = &Status{}
+			}
+			updateFn(file.Records[i].Status)
+			refreshLiveFlag(file.Records[i].Status)
+			file.Records[i].UpdatedAt = time.Now().UTC()
+			updated = file.Records[i]
+			return nil
+		}
+		return fmt.Errorf("%w: %s", ErrStreamerNotFound, key)
+	})
+	return updated, err
+}
+
+func updateTwitchLiveStatus(m mutator, broadcasterID string, liveStatus TwitchLiveStatus) (Record, error) {
+	broadcasterID = strings.TrimSpace(broadcasterID)
+	if broadcasterID == "" {
+		return Record{}, errors.New("twitch broadcaster id is required")
+	}
+	return updateStatus(m, recordScope{twitch: broadcasterID}, broadcasterID, func(record Record) bool {
+		tw := record.Platforms.Twitch
+		return tw != nil && strings.TrimSpace(tw.BroadcasterID) == broadcasterID
+	}, func(status *Status) {
+		if status.Twitch == nil {
+			status.Twitch = &TwitchStatus{}
+		}
+		if !liveStatus.Live {
+			status.Twitch.Live = false
+			status.Twitch.StreamID = ""
+			status.Twitch.StartedAt = time.Time{}
+			status.Platforms = removePlatform(status.Platforms, platformTwitch)
+			return
+		}
+		status.Twitch.Live = true
+		status.Twitch.StreamID = liveStatus.StreamID
+		if liveStatus.StartedAt.IsZero() {
+			status.Twitch.StartedAt = time.Time{}
+		} else {
+			status.Twitch.StartedAt = liveStatus.StartedAt.UTC()
+		}
+		status.Platforms = addPlatform(status.Platforms, platformTwitch)
+	})
+}
+
+func updateFacebookLiveStatus(m mutator, pageID string, liveStatus FacebookLiveStatus) (Record, error) {
+	pageID = strings.TrimSpace(pageID)
+	if pageID == "" {
+		return Record{}, errors.New("facebook page id is required")
+	}
+	return updateStatus(m, recordScope{facebook: pageID}, pageID, func(record Record) bool {
+		fb := record.Platforms.Facebook
+		return fb != nil && strings.TrimSpace(fb.PageID) == pageID
+	}, func(status *Status) {
+		if status.Facebook == nil {
+			status.Facebook = &FacebookStatus{}
+		}
+		if !liveStatus.Live {
+			// Ignore a stop for a different broadcast than the one we consider live.
+			if liveStatus.VideoID != "" && status.Facebook.VideoID != "" && status.Facebook.VideoID != liveStatus.VideoID {
+				return
+			}
+			status.Facebook.Live = false
+			status.Facebook.VideoID = ""
+			status.Facebook.StartedAt = time.Time{}
+			status.Platforms = removePlatform(status.Platforms, platformFacebook)
+			return
+		}
+		status.Facebook.Live = true
+		status.Facebook.VideoID = liveStatus.VideoID
+		if liveStatus.StartedAt.IsZero() {
+			status.Facebook.StartedAt = time.Time{}
+		} else {
+			status.Facebook.StartedAt = liveStatus.StartedAt.UTC()
+		}
+		status.Platforms = addPlatform(status.Platforms, platformFacebook)
+	})
+}
+
+// MatchesChannel reports whether yt belongs to channelID. It is the rule the store uses to
+// pick the record a YouTube update applies to: IDs compare case-insensitively with or
+// without the "UC" prefix, and a record without a ChannelID falls back to its topic.
+func (yt *YouTubePlatform) MatchesChannel(channelID string) bool {
+	return channelMatches(yt, strings.TrimSpace(channelID))
+}
+
+func channelMatches(yt *YouTubePlatform, target string) bool {
+	if yt == nil {
+		return false
+	}
+	stored := strings.TrimSpace(yt.ChannelID)
+	if stored == "" {
+		stored = ChannelIDFromTopic(yt.Topic)
+	}
+	if stored == "" || target == "" {
+		return false
+	}
+	if strings.EqualFold(stored, target) {
+		return true
+	}
+	return strings.EqualFold(trimChannelPrefix(stored), trimChannelPrefix(target))
+}
+
+// youtubeKey returns the form of yt's channel that channelMatches compares, so two
+// platforms match exactly when their keys are equal and non-empty.
+func youtubeKey(yt *YouTubePlatform) string {
+	if yt == nil {
+		return ""
+	}
+	stored := strings.TrimSpace(yt.ChannelID)
+	if stored == "" {
+		stored = ChannelIDFromTopic(yt.Topic)
+	}
+	return trimChannelPrefix(stored)
+}
+
+func trimChannelPrefix(value string) string {
+	value = strings.TrimSpace(strings.ToUpper(value))
+	return strings.TrimPrefix(value, "UC")
+}
+
+// ChannelIDFromTopic returns the channel_id query parameter of a YouTube hub topic.
+func ChannelIDFromTopic(topic string) string {
+	if topic == "" {
+		return ""
+	}
+	u, err := url.Parse(topic)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get("channel_id")
+}
+
+func refreshLiveFlag(status *Status) {
+	if status == nil {
+		return
+	}
+	if status.YouTube != nil && status.YouTube.Live {
+		status.Platforms = addPlatform(status.Platforms, platformYouTube)
+	}
+	if status.Twitch != nil && status.Twitch.Live {
+		status.Platforms = addPlatform(status.Platforms, platformTwitch)
+	}
+	if status.Facebook != nil && status.Facebook.Live {
+		status.Platforms = addPlatform(status.Platforms, platformFacebook)
+	}
+	if len(status.Platforms) == 0 {
+		status.Platforms = nil
+	}
+	status.Live = len(status.Platforms) > 0
+}
@@ -8,6 +8,8 @@ import (
 	"strings"
 	"time"
 
+	"live-stream-alerts/internal/audit"
+	"live-stream-alerts/internal/platforms"
 	"live-stream-alerts/internal/platforms/youtube/subscriptions"
 	"live-stream-alerts/internal/streamers"
 	"live-stream-alerts/internal/submissions"
@@ -21,18 +23,31 @@ var ErrSubscription = errors.New("subscription error")
 
 // Options configures a Service instance.
 type Options struct {
-	Streamers     *streamers.Store
+	Streamers     streamers.Repository
 	Submissions   *submissions.Store
 	YouTubeClient *http.Client
 	YouTubeHubURL string
+	// Audit records submissions, updates and deletions. When nil nothing is recorded.
+	Audit audit.Recorder
+	// Limits guards Create against floods of anonymous submissions.
+	Limits SubmissionLimits
+	// Platforms recognises channel URLs so Create can spot two pending submissions for one
+	// channel. When nil only identical URLs count as duplicates.
+	Platforms *platforms.Registry
+	// Now overrides the clock used by the per-address submission limit.
+	Now func() time.Time
 }
 
 // Service implements the business logic for streamer operations.
 type Service struct {
-	streamers     *streamers.Store
+	streamers     streamers.Repository
 	submissions   *submissions.Store
 	youtubeClient *http.Client
 	youtubeHubURL string
+	audit         audit.Recorder
+	limits        SubmissionLimits
+	platforms     *platforms.Registry
+	limiter       *submissionLimiter
 }
 
 // CreateRequest captures the fields accepted by Create.
@@ -41,11 +56,20 @@ type CreateRequest struct {
 	Description string
 	Languages   []string
 	PlatformURL string
+	// ClientIP is the submitter's address, used for the per-address limit.
+	ClientIP string
+	// Honeypot is a form field hidden from people; anything in it marks the caller as a bot.
+	Honeypot string
+	// ProofOfWork is the nonce required when SubmissionLimits.ProofOfWorkBits is set.
+	ProofOfWork string
 }
 
 // CreateResult captures the stored submission returned by Create.
 type CreateResult struct {
 	Submission submissions.Submission
+	// Discarded is set when the honeypot was filled in. Nothing was stored, but the
+	// caller is answered as if it had been so that bots learn nothing.
+	Discarded bool
 }
 
 // UpdateRequest captures mutable streamer fields.
@@ -63,11 +87,19 @@ type DeleteRequest struct {
 
 // New instantiates a Service.
 func New(opts Options) *Service {
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
 	return &Service{
 		streamers:     opts.Streamers,
 		submissions:   opts.Submissions,
 		youtubeClient: opts.YouTubeClient,
 		youtubeHubURL: strings.TrimSpace(opts.YouTubeHubURL),
+		audit:         opts.Audit,
+		limits:        opts.Limits,
+		platforms:     opts.Platforms,
+		limiter:       newSubmissionLimiter(opts.Limits.MaxPerIP, opts.Limits.Window, now),
 	}
 }
 
@@ -79,11 +111,15 @@ func (s *Service) List(ctx context.Context) ([]streamers.Record, error) {
 	return s.streamers.List()
 }
 
-// Create enqueues a streamer submission after validating the payload.
+// Create enqueues a streamer submission after validating the payload. Submissions refused
+// by the spam checks in Options.Limits return a *SubmissionError.
 func (s *Service) Create(ctx context.Context, req CreateRequest) (CreateResult, error) {
 	if err := s.ensureStores(); err != nil {
 		return CreateResult{}, err
 	}
+	if strings.TrimSpace(req.Honeypot) != "" {
+		return CreateResult{Discarded: true}, nil
+	}
 	alias := strings.TrimSpace(req.Alias)
 	if alias == "" {
 		return CreateResult{}, fmt.Errorf("%w: streamer.alias is required", ErrValidation)
@@ -91,23 +127,37 @@ func (s *Service) Create(ctx context.Context, req CreateRequest) (CreateResult,
 	if streamers.NormaliseAlias(alias) == "" {
 		return CreateResult{}, fmt.Errorf("%w: streamer.alias must contain a letter or digit", ErrValidation)
 	}
-	langs, err := sanitiseLanguages(req.Languages)
+	langs, err := SanitiseLanguages(req.Languages)
 	if err != nil {
 		return CreateResult{}, fmt.Errorf("%w: %v", ErrValidation, err)
 	}
+	platformURL := strings.TrimSpace(req.PlatformURL)
+	if err := verifyProofOfWork(s.limits.ProofOfWorkBits, alias, platformURL, req.ProofOfWork); err != nil {
+		return CreateResult{}, err
+	}
 	if err := s.ensureUniqueAlias(alias); err != nil {
 		return CreateResult{}, err
 	}
+	// The address is charged last, so a rejected payload does not use up its submissions.
+	if wait, ok := s.limiter.take(req.ClientIP); !ok {
+		refusal := refuse(ErrRateLimited, "rate_limited", "too many submissions from this address; please try again later")
+		refusal.RetryAfter = wait
+		return CreateResult{}, refusal
+	}
 	submission := submissions.Submission{
 		Alias:       alias,
 		Description: strings.TrimSpace(req.Description),
 		Languages:   langs,
-		PlatformURL: strings.TrimSpace(req.PlatformURL),
+		PlatformURL: platformURL,
 	}
-	saved, err := s.submissions.Append(submission)
+	saved, err := s.submissions.AppendChecked(submission, func(existing []submissions.Submission) error {
+		return s.checkPending(existing, platformURL)
+	})
 	if err != nil {
+		s.limiter.refund(req.ClientIP)
 		return CreateResult{}, err
 	}
+	s.record(ctx, "submission.create", "submission", saved.ID, nil, saved)
 	return CreateResult{Submission: saved}, nil
 }
 
@@ -136,7 +186,7 @@ func (s *Service) Update(ctx context.Context, req UpdateRequest) (streamers.Reco
 		hasUpdate = true
 	}
 	if req.Languages != nil {
-		langs, err := sanitiseLanguages(*req.Languages)
+		langs, err := SanitiseLanguages(*req.Languages)
 		if err != nil {
 			return streamers.Record{}, fmt.Errorf("%w: %v", ErrValidation, err)
 		}
@@ -147,7 +197,19 @@ func (s *Service) Update(ctx context.Context, req UpdateRequest) (streamers.Reco
 	if !hasUpdate {
 		return streamers.Record{}, fmt.Errorf("%w: at least one streamer field must be provided", ErrValidation)
 	}
-	return s.streamers.Update(update)
+	var before *streamers.Record
+	if s.audit != nil {
+		if record, err := s.streamers.Get(id); err == nil {
+			public := record.Public()
+			before = &public
+		}
+	}
+	updated, err := s.streamers.Update(update)
+	if err != nil {
+		return streamers.Record{}, err
+	}
+	s.record(ctx, "streamer.update", "streamer", id, before, updated.Public())
+	return updated, nil
 }
 
 // Delete removes a streamer, unsubscribing from alerts when required.
@@ -168,7 +230,27 @@ func (s *Service) Delete(ctx context.Context, req DeleteRequest) error {
 			return err
 		}
 	}
-	return s.streamers.Delete(id)
+	if err := s.streamers.Delete(id); err != nil {
+		return err
+	}
+	s.record(ctx, "streamer.delete", "streamer", id, record.Public(), nil)
+	return nil
+}
+
+// record adds an audit entry when auditing is configured. Nil snapshots are left out.
+// Streamer snapshots must be redacted with Record.Public, since the audit log is readable
+// by every admin.
+func (s *Service) record(ctx context.Context, action, targetType, targetID string, before, after any) {
+	if s.audit == nil {
+		return
+	}
+	s.audit.Record(ctx, audit.Entry{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     audit.Snapshot(before),
+		After:      audit.Snapshot(after),
+	})
 }
 
 func (s *Service) unsubscribe(ctx context.Context, record streamers.Record) error {
@@ -221,14 +303,16 @@ func (s *Service) ensureUniqueAlias(alias string) error {
 		return err
 	}
 	for _, sub := range pending {
-		if key == streamers.NormaliseAlias(sub.Alias) {
+		if sub.Pending() && key == streamers.NormaliseAlias(sub.Alias) {
 			return streamers.ErrDuplicateAlias
 		}
 	}
 	return nil
 }
 
-func sanitiseLanguages(values []string) ([]string, error) {
+// SanitiseLanguages trims and de-duplicates languages, rejecting blank entries and any
+// language outside the list the UI offers.
+func SanitiseLanguages(values []string) ([]string, error) {
 	if len(values) == 0 {
 		return nil, nil
 	}
@@ -5,8 +5,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"live-stream-alerts/internal/audit"
+	"live-stream-alerts/internal/platforms"
+	youtubeprovider "live-stream-alerts/internal/platforms/youtube/provider"
 	"live-stream-alerts/internal/streamers"
 	"live-stream-alerts/internal/submissions"
 )
@@ -36,10 +42,16 @@ func TestServiceCreateRejectsDuplicates(t *testing.T) {
 	if _, err := streamStore.Append(streamers.Record{Streamer: streamers.Streamer{Alias: "Test"}}); err != nil {
 		t.Fatalf("append: %v", err)
 	}
+	if _, err := subStore.Append(submissions.Submission{Alias: "Rejected", Status: submissions.StatusRejected}); err != nil {
+		t.Fatalf("append submission: %v", err)
+	}
 	svc := New(Options{Streamers: streamStore, Submissions: subStore})
 	if _, err := svc.Create(t.Context(), CreateRequest{Alias: "Test"}); err == nil {
 		t.Fatalf("expected duplicate error")
 	}
+	if _, err := svc.Create(t.Context(), CreateRequest{Alias: "Rejected"}); err != nil {
+		t.Fatalf("expected a rejected alias to be submittable again: %v", err)
+	}
 }
 
 func TestServiceUpdateValidatesInput(t *testing.T) {
@@ -54,10 +66,11 @@ func TestServiceDeleteUnsubscribesAndRemoves(t *testing.T) {
 	path := filepath.Join(dir, "streamers.json")
 	streamStore := streamers.NewStore(path)
 	if _, err := streamStore.Append(streamers.Record{
-		Streamer: streamers.Streamer{ID: "to-delete", Alias: "ToDelete"},
+		Streamer: streamers.Streamer{ID: "to-delete", Alias: "ToDelete", Email: "streamer@example.com"},
 		Platforms: streamers.Platforms{YouTube: &streamers.YouTubePlatform{
 			ChannelID:   "UC123",
 			CallbackURL: "https://example.com/hook",
+			HubSecret:   "hub-secret",
 		}},
 	}); err != nil {
 		t.Fatalf("append: %v", err)
@@ -73,11 +86,13 @@ func TestServiceDeleteUnsubscribesAndRemoves(t *testing.T) {
 		w.WriteHeader(http.StatusAccepted)
 	}))
 	defer hub.Close()
+	auditLog := audit.NewLog(audit.Options{Path: filepath.Join(dir, "audit.jsonl")})
 	svc := New(Options{
 		Streamers:     streamStore,
 		Submissions:   subStore,
 		YouTubeClient: hub.Client(),
 		YouTubeHubURL: hub.URL,
+		Audit:         auditLog,
 	})
 	if err := svc.Delete(t.Context(), DeleteRequest{ID: "to-delete"}); err != nil {
 		t.Fatalf("delete: %v", err)
@@ -89,6 +104,16 @@ func TestServiceDeleteUnsubscribesAndRemoves(t *testing.T) {
 	if len(records) != 0 {
 		t.Fatalf("expected record removed")
 	}
+	entries, err := auditLog.Query(audit.Filter{Action: "streamer.delete", TargetID: "to-delete"})
+	if err != nil {
+		t.Fatalf("query audit log: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Before == nil || entries[0].After != nil {
+		t.Fatalf("expected the deletion to be audited with its before snapshot, got %+v", entries)
+	}
+	if snapshot := string(entries[0].Before); strings.Contains(snapshot, "hub-secret") || strings.Contains(snapshot, "streamer@example.com") {
+		t.Fatalf("expected the audit snapshot to be redacted, got %s", snapshot)
+	}
 }
 
 func TestServiceDeleteSubscriptionFailure(t *testing.T) {
@@ -113,3 +138,92 @@ func TestServiceDeleteSubscriptionFailure(t *testing.T) {
 		t.Fatalf("expected subscription error, got %v", err)
 	}
 }
+
+func TestServiceCreateSpamChecks(t *testing.T) {
+	dir := t.TempDir()
+	subStore := submissions.NewStore(filepath.Join(dir, "submissions.json"))
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	svc := New(Options{
+		Streamers:   streamers.NewStore(filepath.Join(dir, "streamers.json")),
+		Submissions: subStore,
+		Limits:      SubmissionLimits{MaxPerIP: 2, Window: time.Hour, MaxPending: 3},
+		Platforms:   platforms.NewRegistry(youtubeprovider.New(youtubeprovider.Options{})),
+		Now:         func() time.Time { return now },
+	})
+	create := func(alias, url, ip string) error {
+		_, err := svc.Create(t.Context(), CreateRequest{Alias: alias, PlatformURL: url, ClientIP: ip})
+		return err
+	}
+	code := func(err error) string {
+		var refusal *SubmissionError
+		if !errors.As(err, &refusal) {
+			return ""
+		}
+		return refusal.Code
+	}
+
+	if err := create("One", "https://www.youtube.com/channel/UC1", "192.0.2.1"); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := create("Two", "https://youtube.com/channel/UC1/", "192.0.2.2"); code(err) != "duplicate_url" {
+		t.Fatalf("expected duplicate_url, got %v", err)
+	}
+	if err := create("Two", "https://m.youtube.com/feeds?channel_id=UC1", "192.0.2.2"); code(err) != "duplicate_channel" || !errors.Is(err, ErrDuplicateSubmission) {
+		t.Fatalf("expected duplicate_channel, got %v", err)
+	}
+	if err := create("Two", "https://www.youtube.com/@two", "192.0.2.1"); err != nil {
+		t.Fatalf("expected the refused duplicates not to use up the address, got %v", err)
+	}
+	err := create("Three", "", "192.0.2.1")
+	var refusal *SubmissionError
+	if !errors.As(err, &refusal) || !errors.Is(err, ErrRateLimited) || refusal.RetryAfter != time.Hour {
+		t.Fatalf("expected the third submission from one address to be rate limited, got %v", err)
+	}
+	if err := create("Three", "", "192.0.2.3"); err != nil {
+		t.Fatalf("create from another address: %v", err)
+	}
+	if err := create("Four", "", "192.0.2.4"); code(err) != "queue_full" {
+		t.Fatalf("expected queue_full, got %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	list, _ := subStore.List()
+	for _, sub := range list {
+		if _, err := subStore.Update(sub.ID, func(s *submissions.Submission) error {
+			s.Status = submissions.StatusRejected
+			return nil
+		}); err != nil {
+			t.Fatalf("reject: %v", err)
+		}
+	}
+	if err := create("Four", "https://www.youtube.com/channel/UC1", "192.0.2.1"); err != nil {
+		t.Fatalf("expected limits to lift once the window passes and the queue drains, got %v", err)
+	}
+}
+
+func TestServiceCreateHoneypotAndProofOfWork(t *testing.T) {
+	dir := t.TempDir()
+	subStore := submissions.NewStore(filepath.Join(dir, "submissions.json"))
+	svc := New(Options{
+		Streamers:   streamers.NewStore(filepath.Join(dir, "streamers.json")),
+		Submissions: subStore,
+		Limits:      SubmissionLimits{ProofOfWorkBits: 8},
+	})
+
+	result, err := svc.Create(t.Context(), CreateRequest{Alias: "Bot", Honeypot: "http://spam.example"})
+	if err != nil || !result.Discarded {
+		t.Fatalf("expected the honeypot submission to be discarded, got %+v %v", result, err)
+	}
+	if _, err := svc.Create(t.Context(), CreateRequest{Alias: "Person"}); !errors.Is(err, ErrProofOfWork) {
+		t.Fatalf("expected a missing proof of work to be refused, got %v", err)
+	}
+	nonce := 0
+	for ; ProofOfWorkDigest("Person", "", strconv.Itoa(nonce))[0] != 0; nonce++ {
+	}
+	if _, err := svc.Create(t.Context(), CreateRequest{Alias: " Person ", ProofOfWork: strconv.Itoa(nonce)}); err != nil {
+		t.Fatalf("expected a valid proof of work to be accepted, got %v", err)
+	}
+	if list, _ := subStore.List(); len(list) != 1 || list[0].Alias != "Person" {
+		t.Fatalf("expected only the real submission to be stored, got %+v", list)
+	}
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/bits"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"live-stream-alerts/internal/platforms"
+	"live-stream-alerts/internal/submissions"
+)
+
+// SubmissionLimits bounds what anonymous callers can queue through Create. Zero fields
+// disable the matching check.
+type SubmissionLimits struct {
+	// MaxPerIP is how many submissions one client address may queue per Window.
+	MaxPerIP int
+	Window   time.Duration
+	// MaxPending caps how many submissions can await review at once.
+	MaxPending int
+	// ProofOfWorkBits requires CreateRequest.ProofOfWork to be a nonce whose
+	// ProofOfWorkDigest starts with this many zero bits.
+	ProofOfWorkBits int
+}
+
+// maxNonceLength bounds CreateRequest.ProofOfWork so verifying it stays cheap.
+const maxNonceLength = 64
+
+var (
+	// ErrRateLimited is wrapped when a client address has used up its submissions.
+	ErrRateLimited = errors.New("too many submissions")
+	// ErrQueueFull is wrapped when SubmissionLimits.MaxPending submissions await review.
+	ErrQueueFull = errors.New("submission queue is full")
+	// ErrDuplicateSubmission is wrapped when another pending submission has the same
+	// platform URL or channel.
+	ErrDuplicateSubmission = errors.New("duplicate submission")
+	// ErrProofOfWork is wrapped when the proof of work is missing or does not verify.
+	ErrProofOfWork = errors.New("invalid proof of work")
+)
+
+// SubmissionError explains why Create refused a submission. Code is a stable identifier
+// the UI can switch on; Message is meant for people.
+type SubmissionError struct {
+	Code    string
+	Message string
+	// RetryAfter is set for rate-limited submissions.
+	RetryAfter time.Duration
+	err        error
+}
+
+func (e *SubmissionError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the sentinel matching Code, such as ErrRateLimited.
+func (e *SubmissionError) Unwrap() error {
+	return e.err
+}
+
+func refuse(err error, code, message string) *SubmissionError {
+	return &SubmissionError{Code: code, Message: message, err: err}
+}
+
+// ProofOfWorkDigest is what a client hashes to earn a submission: the SHA-256 of the
+// trimmed alias, the trimmed platform URL and the nonce, separated by newlines.
+func ProofOfWorkDigest(alias, platformURL, nonce string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(strings.TrimSpace(alias) + "\n" + strings.TrimSpace(platformURL) + "\n" + nonce))
+}
+
+func verifyProofOfWork(difficulty int, alias, platformURL, nonce string) error {
+	if difficulty <= 0 {
+		return nil
+	}
+	if nonce == "" {
+		return refuse(ErrProofOfWork, "proof_of_work_required", "this server requires a proof of work with each submission")
+	}
+	if len(nonce) > maxNonceLength {
+		return refuse(ErrProofOfWork, "proof_of_work_invalid", fmt.Sprintf("proofOfWork must be at most %d characters", maxNonceLength))
+	}
+	digest := ProofOfWorkDigest(alias, platformURL, nonce)
+	zeros := 0
+	for _, b := range digest {
+		if b != 0 {
+			zeros += bits.LeadingZeros8(b)
+			break
+		}
+		zeros += 8
+	}
+	if zeros < difficulty {
+		return refuse(ErrProofOfWork, "proof_of_work_invalid", "proof of work does not meet the required difficulty")
+	}
+	return nil
+}
+
+// submissionLimiter counts accepted submissions per client address over a sliding window.
+type submissionLimiter struct {
+	max    int
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	hits      map[string][]time.Time
+	lastSweep time.Time
+}
+
+func newSubmissionLimiter(max int, window time.Duration, now func() time.Time) *submissionLimiter {
+	if max <= 0 {
+		return nil
+	}
+	if window <= 0 {
+		window = time.Hour
+	}
+	return &submissionLimiter{max: max, window: window, now: now, hits: make(map[string][]time.Time)}
+}
+
+// take reserves a submission for key. When key is out of submissions it reports how long
+// until the oldest one leaves the window.
+func (l *submissionLimiter) take(key string) (time.Duration, bool) {
+	if l == nil || key == "" {
+		return 0, true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.sweep(now)
+	recent := l.recent(key, now)
+	if len(recent) >= l.max {
+		return recent[0].Add(l.window).Sub(now), false
+	}
+	l.hits[key] = append(recent, now)
+	return 0, true
+}
+
+// refund returns the newest reservation for key, for submissions that failed afterwards.
+func (l *submissionLimiter) refund(key string) {
+	if l == nil || key == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if hits := l.hits[key]; len(hits) > 0 {
+		l.hits[key] = hits[:len(hits)-1]
+	}
+}
+
+func (l *submissionLimiter) recent(key string, now time.Time) []time.Time {
+	hits := l.hits[key]
+	cutoff := now.Add(-l.window)
+	i := 0
+	for i < len(hits) && !hits[i].After(cutoff) {
+		i++
+	}
+	return hits[i:]
+}
+
+// sweep drops idle addresses at most once per window so the map cannot grow unbounded.
+func (l *submissionLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	l.lastSweep = now
+	for key := range l.hits {
+		if recent := l.recent(key, now); len(recent) == 0 {
+			delete(l.hits, key)
+		} else {
+			l.hits[key] = recent
+		}
+	}
+}
+
+// checkPending refuses a submission when the queue is full or another pending submission
+// points at the same URL or channel. It runs under the submissions store lock.
+func (s *Service) checkPending(existing []submissions.Submission, platformURL string) error {
+	pending := 0
+	for _, sub := range existing {
+		if sub.Pending() {
+			pending++
+		}
+	}
+	if s.limits.MaxPending > 0 && pending >= s.limits.MaxPending {
+		return refuse(ErrQueueFull, "queue_full", "too many submissions are awaiting review; please try again later")
+	}
+	return CheckDuplicatePending(s.platforms, existing, platformURL)
+}
+
+// CheckDuplicatePending refuses platformURL when a pending submission in existing points
+// at the same URL or, as far as registry can tell, the same channel. Reviewer edits run
+// it too, so correcting a URL cannot create a duplicate that Create would have refused.
+func CheckDuplicatePending(registry *platforms.Registry, existing []submissions.Submission, platformURL string) error {
+	if platformURL == "" {
+		return nil
+	}
+	urlKey := normaliseSubmissionURL(platformURL)
+	channel := channelKey(registry, platformURL)
+	for _, sub := range existing {
+		if !sub.Pending() || sub.PlatformURL == "" {
+			continue
+		}
+		if normaliseSubmissionURL(sub.PlatformURL) == urlKey {
+			return refuse(ErrDuplicateSubmission, "duplicate_url", "a submission for this URL is already awaiting review")
+		}
+		if channel != "" && channelKey(registry, sub.PlatformURL) == channel {
+			return refuse(ErrDuplicateSubmission, "duplicate_channel", "a submission for this channel is already awaiting review")
+		}
+	}
+	return nil
+}
+
+// channelKey identifies the channel a URL points at, so that different URLs for one
+// channel (a /channel/<id> link and a ?channel_id= link, say) count as duplicates. It is
+// empty when no provider recognises the URL.
+func channelKey(registry *platforms.Registry, platformURL string) string {
+	if registry == nil {
+		return ""
+	}
+	_, channel, err := registry.ForURL(platformURL)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case channel.ID != "":
+		return channel.Platform + ":id:" + channel.ID
+	case channel.Handle != "":
+		return channel.Platform + ":handle:" + strings.ToLower(strings.TrimPrefix(channel.Handle, "@"))
+	default:
+		return ""
+	}
+}
+
+// normaliseSubmissionURL reduces a URL to the parts that identify a page: the host
+// without "www." or "m." and the path without a trailing slash, both lowercased.
+func normaliseSubmissionURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		if parsed, err = url.Parse("https://" + raw); err != nil {
+			return strings.ToLower(raw)
+		}
+	}
+	host := strings.ToLower(parsed.Hostname())
+	host = strings.TrimPrefix(strings.TrimPrefix(host, "www."), "m.")
+	return host + strings.TrimRight(strings.ToLower(parsed.EscapedPath()), "/")
+}
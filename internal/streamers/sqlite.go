@@ -0,0 +1,480 @@
+package streamers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	// Registers the "sqlite3" database/sql driver.
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// DefaultSQLitePath is the default database location when the SQLite backend is selected.
+const DefaultSQLitePath = "data/streamers.db"
+
+// sqliteSchemaVersion is stored in PRAGMA user_version. Version 1 made IDs
+// case-insensitive and added the lookup columns mutate queries by.
+const sqliteSchemaVersion = 1
+
+// The alias, youtube, twitch and facebook columns duplicate the keys the record
+// operations match on, so a mutation can load just the rows it needs.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS streamers (
+	id        TEXT PRIMARY KEY COLLATE NOCASE,
+	position  INTEGER NOT NULL,
+	alias_key TEXT NOT NULL DEFAULT '',
+	youtube   TEXT NOT NULL DEFAULT '',
+	twitch    TEXT NOT NULL DEFAULT '',
+	facebook  TEXT NOT NULL DEFAULT '',
+	record    TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS streamers_position ON streamers(position);
+CREATE INDEX IF NOT EXISTS streamers_alias_key ON streamers(alias_key);
+CREATE INDEX IF NOT EXISTS streamers_youtube ON streamers(youtube);
+CREATE INDEX IF NOT EXISTS streamers_twitch ON streamers(twitch);
+CREATE INDEX IF NOT EXISTS streamers_facebook ON streamers(facebook);
+`
+
+// SQLiteStore persists streamer records in a SQLite database, one JSON-encoded row per
+// record, so a mutation only rewrites the rows it changed.
+type SQLiteStore struct {
+	path   string
+	db     *sql.DB
+	mu     sync.Mutex
+	events *EventBus
+	// dataVersion is the PRAGMA data_version seen by the previous ExternalChange call.
+	dataVersion int64
+	versioned   bool
+}
+
+// SQLiteOption customises a SQLiteStore.
+type SQLiteOption func(*SQLiteStore)
+
+// WithSQLiteEvents publishes every record change made through the store to bus.
+func WithSQLiteEvents(bus *EventBus) SQLiteOption {
+	return func(s *SQLiteStore) {
+		s.events = bus
+	}
+}
+
+// OpenSQLiteStore opens (creating if necessary) the database at path.
+func OpenSQLiteStore(path string, opts ...SQLiteOption) (*SQLiteStore, error) {
+	if path == "" {
+		path = DefaultSQLitePath
+	}
+	path = filepath.Clean(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create streamers dir: %w", err)
+	}
+	db, err := sql.Open("sqlite3", "file:"+path+"?_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("open streamers database: %w", err)
+	}
+	// A single connection keeps writers serialised and avoids SQLITE_BUSY inside the process.
+	db.SetMaxOpenConns(1)
+	if err := migrateSQLiteSchema(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	store := &SQLiteStore{path: path, db: db}
+	for _, opt := range opts {
+		opt(store)
+	}
+	return store, nil
+}
+
+// Close releases the database handle.
+func (s *SQLiteStore) Close() error {
+	if s == nil || s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Path returns the database file backing the store.
+func (s *SQLiteStore) Path() string {
+	if s == nil {
+		return ""
+	}
+	return s.path
+}
+
+// ExternalChange reports whether another connection, such as a second server or the
+// sqlite3 shell, committed to the database since the previous call. SQLite bumps
+// PRAGMA data_version for exactly those commits, so writes made through this store do
+// not count. The first call only records the current version.
+func (s *SQLiteStore) ExternalChange() (bool, error) {
+	if s == nil || s.db == nil {
+		return false, errors.New("streamers store is nil")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var version int64
+	if err := s.db.QueryRow(`PRAGMA data_version`).Scan(&version); err != nil {
+		return false, fmt.Errorf("read streamers data version: %w", err)
+	}
+	changed := s.versioned && version != s.dataVersion
+	s.dataVersion, s.versioned = version, true
+	return changed, nil
+}
+
+// migrateSQLiteSchema creates the schema, or rebuilds a table written by an older
+// version so its IDs compare case-insensitively and its lookup columns are filled in.
+func migrateSQLiteSchema(db *sql.DB) (err error) {
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("read streamers schema version: %w", err)
+	}
+	if version >= sqliteSchemaVersion {
+		return nil
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin streamers migration: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var legacy int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'streamers'`).Scan(&legacy); err != nil {
+		return fmt.Errorf("inspect streamers schema: %w", err)
+	}
+	var records []Record
+	if legacy > 0 {
+		records, _, err = loadSQLiteRecords(tx, "", nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DROP TABLE streamers`); err != nil {
+			return fmt.Errorf("drop old streamers table: %w", err)
+		}
+	}
+	if _, err := tx.Exec(sqliteSchema); err != nil {
+		return fmt.Errorf("create streamers schema: %w", err)
+	}
+	for i, record := range records {
+		encoded, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("encode streamer %s: %w", record.Streamer.ID, err)
+		}
+		if err := writeSQLiteRow(tx, i, record, encoded); err != nil {
+			return fmt.Errorf("%w (IDs that differ only in case must be merged by hand)", err)
+		}
+	}
+	if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, sqliteSchemaVersion)); err != nil {
+		return fmt.Errorf("set streamers schema version: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit streamers migration: %w", err)
+	}
+	return nil
+}
+
+type sqliteRow struct {
+	id       string
+	position int
+	data     string
+}
+
+// scopeFilter returns the WHERE clause selecting the rows in scope, or "" for every row.
+func scopeFilter(scope recordScope) (string, []any) {
+	if scope.all() {
+		return "", nil
+	}
+	var (
+		clauses []string
+		args    []any
+	)
+	add := func(clause, value string) {
+		if value != "" {
+			clauses = append(clauses, clause)
+			args = append(args, value)
+		}
+	}
+	add("id = ?", strings.TrimSpace(scope.id))
+	add("alias_key = ?", NormaliseAlias(scope.alias))
+	add("youtube = ?", trimChannelPrefix(scope.youtube))
+	add("twitch = ?", strings.TrimSpace(scope.twitch))
+	add("facebook = ?", strings.TrimSpace(scope.facebook))
+	if len(clauses) == 0 {
+		return " WHERE 0", nil
+	}
+	return " WHERE " + strings.Join(clauses, " OR "), args
+}
+
+// mutate loads the rows in scope inside a transaction, applies updateFn, and writes back
+// only the rows that were added, changed, moved, or removed. Rows outside scope are never
+// read, so updateFn only sees, and may only remove, the records it asked for. New records
+// are placed after every existing row.
+func (s *SQLiteStore) mutate(scope recordScope, updateFn func(*File) error) (err error) {
+	if s == nil || s.db == nil {
+		return errors.New("streamers store is nil")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin streamers transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	where, args := scopeFilter(scope)
+	records, existing, err := loadSQLiteRecords(tx, where, args)
+	if err != nil {
+		return err
+	}
+	next := 0
+	if !scope.all() {
+		if err := tx.QueryRow(`SELECT COALESCE(MAX(position), -1) + 1 FROM streamers`).Scan(&next); err != nil {
+			return fmt.Errorf("read streamers: %w", err)
+		}
+	}
+	var before map[string]recordSnapshot
+	if s.events != nil {
+		before = snapshotRecords(records)
+	}
+	file := File{SchemaRef: DefaultSchemaPath, Records: records}
+	if err := updateFn(&file); err != nil {
+		return err
+	}
+
+	keep := make(map[string]struct{}, len(file.Records))
+	for i, record := range file.Records {
+		key := strings.ToLower(record.Streamer.ID)
+		if key == "" {
+			return errors.New("streamer id is required")
+		}
+		if _, ok := keep[key]; ok {
+			return fmt.Errorf("%w: %s", ErrDuplicateStreamerID, record.Streamer.ID)
+		}
+		keep[key] = struct{}{}
+		encoded, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("encode streamer %s: %w", record.Streamer.ID, err)
+		}
+		row, ok := existing[key]
+		position := i
+		if !scope.all() {
+			if ok {
+				position = row.position
+			} else {
+				position = next
+				next++
+			}
+		}
+		if ok && row.position == position && row.data == string(encoded) {
+			continue
+		}
+		if err := writeSQLiteRow(tx, position, record, encoded); err != nil {
+			return err
+		}
+	}
+	for key, row := range existing {
+		if _, ok := keep[key]; ok {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM streamers WHERE id = ?`, row.id); err != nil {
+			return fmt.Errorf("delete streamer %s: %w", row.id, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit streamers transaction: %w", err)
+	}
+	s.events.publishChanges(before, file.Records)
+	return nil
+}
+
+func writeSQLiteRow(tx *sql.Tx, position int, record Record, encoded []byte) error {
+	var twitch, facebook string
+	if tw := record.Platforms.Twitch; tw != nil {
+		twitch = strings.TrimSpace(tw.BroadcasterID)
+	}
+	if fb := record.Platforms.Facebook; fb != nil {
+		facebook = strings.TrimSpace(fb.PageID)
+	}
+	if _, err := tx.Exec(`INSERT INTO streamers (id, position, alias_key, youtube, twitch, facebook, record)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET position = excluded.position, alias_key = excluded.alias_key,
+			youtube = excluded.youtube, twitch = excluded.twitch, facebook = excluded.facebook,
+			record = excluded.record`,
+		record.Streamer.ID, position, NormaliseAlias(record.Streamer.Alias),
+		youtubeKey(record.Platforms.YouTube), twitch, facebook, string(encoded)); err != nil {
+		return fmt.Errorf("write streamer %s: %w", record.Streamer.ID, err)
+	}
+	return nil
+}
+
+type sqlQuerier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// loadSQLiteRecords reads the rows matching where in position order. The returned map is
+// keyed by the lower-cased streamer ID.
+func loadSQLiteRecords(q sqlQuerier, where string, args []any) ([]Record, map[string]sqliteRow, error) {
+	rows, err := q.Query(`SELECT id, position, record FROM streamers`+where+` ORDER BY position, id`, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read streamers: %w", err)
+	}
+	defer rows.Close()
+	records := []Record{}
+	existing := make(map[string]sqliteRow)
+	for rows.Next() {
+		var row sqliteRow
+		if err := rows.Scan(&row.id, &row.position, &row.data); err != nil {
+			return nil, nil, fmt.Errorf("scan streamer row: %w", err)
+		}
+		var record Record
+		if err := json.Unmarshal([]byte(row.data), &record); err != nil {
+			return nil, nil, fmt.Errorf("parse streamer %s: %w", row.id, err)
+		}
+		records = append(records, record)
+		existing[strings.ToLower(row.id)] = row
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("read streamers: %w", err)
+	}
+	return records, existing, nil
+}
+
+// List returns every stored record in insertion order.
+func (s *SQLiteStore) List() ([]Record, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("streamers store is nil")
+	}
+	records, _, err := loadSQLiteRecords(s.db, "", nil)
+	return records, err
+}
+
+// Get returns a single streamer record by ID.
+func (s *SQLiteStore) Get(streamerID string) (Record, error) {
+	if s == nil || s.db == nil {
+		return Record{}, errors.New("streamers store is nil")
+	}
+	streamerID = strings.TrimSpace(streamerID)
+	if streamerID == "" {
+		return Record{}, errors.New("streamer id is required")
+	}
+	var data string
+	err := s.db.QueryRow(`SELECT record FROM streamers WHERE id = ?`, streamerID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Record{}, fmt.Errorf("%w: %s", ErrStreamerNotFound, streamerID)
+	}
+	if err != nil {
+		return Record{}, fmt.Errorf("read streamer %s: %w", streamerID, err)
+	}
+	var record Record
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return Record{}, fmt.Errorf("parse streamer %s: %w", streamerID, err)
+	}
+	return record, nil
+}
+
+// Append adds a new streamer record and returns a copy with timestamps populated.
+func (s *SQLiteStore) Append(record Record) (Record, error) {
+	return appendRecord(s, record)
+}
+
+// Update applies modifications to an existing streamer.
+func (s *SQLiteStore) Update(fields UpdateFields) (Record, error) {
+	return updateRecord(s, fields)
+}
+
+// Delete removes a streamer by ID.
+func (s *SQLiteStore) Delete(streamerID string) error {
+	return deleteRecord(s, streamerID)
+}
+
+// UpdateFile applies updateFn to the full record set inside a single transaction.
+func (s *SQLiteStore) UpdateFile(updateFn func(*File) error) error {
+	if updateFn == nil {
+		return errors.New("updateFn is required")
+	}
+	return s.mutate(recordScope{}, updateFn)
+}
+
+// UpdateYouTubeLiveStatus updates the stored status for the streamer owning the channel ID.
+func (s *SQLiteStore) UpdateYouTubeLiveStatus(channelID string, liveStatus YouTubeLiveStatus) (Record, error) {
+	return updateYouTubeLiveStatus(s, channelID, liveStatus)
+}
+
+// SetYouTubeLive marks the streamer associated with the provided channel ID as live.
+func (s *SQLiteStore) SetYouTubeLive(channelID, videoID string, startedAt time.Time) (Record, error) {
+	return setYouTubeLive(s, channelID, videoID, startedAt)
+}
+
+// ClearYouTubeLive marks the YouTube platform as offline for the matching channel ID.
+func (s *SQLiteStore) ClearYouTubeLive(channelID string) (Record, error) {
+	return clearYouTubeLive(s, channelID)
+}
+
+// EndYouTubeLive clears the YouTube live status once videoID has ended.
+func (s *SQLiteStore) EndYouTubeLive(channelID, videoID string, endedAt time.Time) (Record, error) {
+	return endYouTubeLive(s, channelID, videoID, endedAt)
+}
+
+// UpdateTwitchLiveStatus updates the stored status for the streamer owning the broadcaster ID.
+func (s *SQLiteStore) UpdateTwitchLiveStatus(broadcasterID string, liveStatus TwitchLiveStatus) (Record, error) {
+	return updateTwitchLiveStatus(s, broadcasterID, liveStatus)
+}
+
+// SetYouTubeUpcoming records (or reschedules) an upcoming broadcast for the channel.
+func (s *SQLiteStore) SetYouTubeUpcoming(channelID string, broadcast ScheduledBroadcast) (Record, error) {
+	return setYouTubeUpcoming(s, channelID, broadcast)
+}
+
+// RemoveYouTubeUpcoming drops a scheduled broadcast that was cancelled or has started.
+func (s *SQLiteStore) RemoveYouTubeUpcoming(channelID, videoID string) (Record, error) {
+	return removeYouTubeUpcoming(s, channelID, videoID)
+}
+
+// UpdateFacebookLiveStatus updates the stored status for the streamer owning the page ID.
+func (s *SQLiteStore) UpdateFacebookLiveStatus(pageID string, liveStatus FacebookLiveStatus) (Record, error) {
+	return updateFacebookLiveStatus(s, pageID, liveStatus)
+}
+
+// MigrateJSONToSQLite copies every record from the JSON file at jsonPath into dst and
+// renames the JSON file to jsonPath+".migrated" so the import only ever runs once. It is
+// a no-op when dst already holds records or the JSON file does not exist.
+func MigrateJSONToSQLite(jsonPath string, dst *SQLiteStore) (int, error) {
+	if dst == nil {
+		return 0, errors.New("streamers store is nil")
+	}
+	if _, err := os.Stat(jsonPath); errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	existing, err := dst.List()
+	if err != nil {
+		return 0, err
+	}
+	if len(existing) > 0 {
+		return 0, nil
+	}
+	source, err := readFile(jsonPath)
+	if err != nil {
+		return 0, err
+	}
+	if err := dst.mutate(recordScope{}, func(file *File) error {
+		file.Records = append(file.Records, source.Records...)
+		return nil
+	}); err != nil {
+		return 0, fmt.Errorf("import streamers: %w", err)
+	}
+	if err := os.Rename(jsonPath, jsonPath+".migrated"); err != nil {
+		return len(source.Records), fmt.Errorf("mark %s as migrated: %w", jsonPath, err)
+	}
+	return len(source.Records), nil
+}
@@ -0,0 +1,284 @@
+package streamers
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestSQLite(t *testing.T) *SQLiteStore {
+	t.Helper()
+	store, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "streamers.db"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestSQLiteStoreCRUD(t *testing.T) {
+	store := openTestSQLite(t)
+
+	first, err := store.Append(Record{Streamer: Streamer{ID: "first", Alias: "First"}})
+	if err != nil {
+		t.Fatalf("append first: %v", err)
+	}
+	if first.CreatedAt.IsZero() {
+		t.Fatalf("expected timestamps to be populated")
+	}
+	if _, err := store.Append(Record{Streamer: Streamer{Alias: "Second"}}); err != nil {
+		t.Fatalf("append second: %v", err)
+	}
+	if _, err := store.Append(Record{Streamer: Streamer{ID: "first", Alias: "Dupe"}}); !errors.Is(err, ErrDuplicateStreamerID) {
+		t.Fatalf("expected duplicate id error, got %v", err)
+	}
+
+	records, err := store.List()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(records) != 2 || records[0].Streamer.Alias != "First" || records[1].Streamer.Alias != "Second" {
+		t.Fatalf("expected insertion order, got %+v", records)
+	}
+
+	alias := "Renamed"
+	if _, err := store.Update(UpdateFields{StreamerID: "first", Alias: &alias}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, err := store.Get("first")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Streamer.Alias != alias {
+		t.Fatalf("expected alias %q, got %q", alias, got.Streamer.Alias)
+	}
+
+	if err := store.Delete("first"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := store.Get("first"); !errors.Is(err, ErrStreamerNotFound) {
+		t.Fatalf("expected not found after delete, got %v", err)
+	}
+	if err := store.Delete("first"); !errors.Is(err, ErrStreamerNotFound) {
+		t.Fatalf("expected not found deleting twice, got %v", err)
+	}
+}
+
+func TestSQLiteStoreLiveStatus(t *testing.T) {
+	store := openTestSQLite(t)
+	if _, err := store.Append(Record{
+		Streamer:  Streamer{Alias: "Live"},
+		Platforms: Platforms{YouTube: &YouTubePlatform{ChannelID: "UCsql"}},
+	}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if _, err := store.SetYouTubeLive("UCsql", "video1", time.Now()); err != nil {
+		t.Fatalf("set live: %v", err)
+	}
+	records, err := store.List()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if records[0].Status == nil || !records[0].Status.Live {
+		t.Fatalf("expected persisted live status, got %+v", records[0].Status)
+	}
+	ended, err := store.EndYouTubeLive("UCsql", "video1", time.Now())
+	if err != nil {
+		t.Fatalf("end live: %v", err)
+	}
+	if ended.Status.Live {
+		t.Fatalf("expected offline status")
+	}
+}
+
+func TestBackendsTreatStreamerIDsCaseInsensitively(t *testing.T) {
+	backends := map[string]Repository{
+		"json":   NewStore(filepath.Join(t.TempDir(), "streamers.json")),
+		"sqlite": openTestSQLite(t),
+	}
+	for name, store := range backends {
+		t.Run(name, func(t *testing.T) {
+			if _, err := store.Append(Record{Streamer: Streamer{ID: "Mixed", Alias: "Mixed"}}); err != nil {
+				t.Fatalf("append: %v", err)
+			}
+			if _, err := store.Append(Record{Streamer: Streamer{ID: "MIXED", Alias: "Other"}}); !errors.Is(err, ErrDuplicateStreamerID) {
+				t.Fatalf("expected duplicate id error, got %v", err)
+			}
+			alias := "Renamed"
+			if _, err := store.Update(UpdateFields{StreamerID: "mixed", Alias: &alias}); err != nil {
+				t.Fatalf("update: %v", err)
+			}
+			if got, err := store.Get("MIXED"); err != nil || got.Streamer.Alias != alias {
+				t.Fatalf("expected renamed record, got %+v, %v", got, err)
+			}
+			if err := store.Delete("mIxEd"); err != nil {
+				t.Fatalf("delete: %v", err)
+			}
+			if records, err := store.List(); err != nil || len(records) != 0 {
+				t.Fatalf("expected no records, got %+v, %v", records, err)
+			}
+		})
+	}
+}
+
+func TestSQLiteStoreMutationsOnlyReadMatchingRows(t *testing.T) {
+	store := openTestSQLite(t)
+	if _, err := store.Append(Record{
+		Streamer:  Streamer{ID: "live", Alias: "Live"},
+		Platforms: Platforms{YouTube: &YouTubePlatform{ChannelID: "UCsql"}, Twitch: &TwitchPlatform{BroadcasterID: "42"}},
+	}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	// A row that cannot be decoded breaks anything that reads it.
+	if _, err := store.db.Exec(`INSERT INTO streamers (id, position, record) VALUES ('broken', 1, 'not json')`); err != nil {
+		t.Fatalf("insert broken row: %v", err)
+	}
+	if _, err := store.List(); err == nil {
+		t.Fatal("expected List to read the broken row")
+	}
+
+	if _, err := store.SetYouTubeLive("sql", "video1", time.Now()); err != nil {
+		t.Fatalf("set live by channel: %v", err)
+	}
+	if _, err := store.UpdateTwitchLiveStatus("42", TwitchLiveStatus{Live: true, StreamID: "s1"}); err != nil {
+		t.Fatalf("twitch live: %v", err)
+	}
+	added, err := store.Append(Record{Streamer: Streamer{Alias: "Later"}})
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if _, err := store.Append(Record{Streamer: Streamer{Alias: "LIVE!"}}); !errors.Is(err, ErrDuplicateAlias) {
+		t.Fatalf("expected duplicate alias error, got %v", err)
+	}
+	var position int
+	if err := store.db.QueryRow(`SELECT position FROM streamers WHERE id = ?`, added.Streamer.ID).Scan(&position); err != nil || position != 2 {
+		t.Fatalf("expected the new record after every row, got %d, %v", position, err)
+	}
+	got, err := store.Get("live")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Status == nil || !got.Status.YouTube.Live || !got.Status.Twitch.Live {
+		t.Fatalf("expected both platforms live, got %+v", got.Status)
+	}
+}
+
+func TestOpenSQLiteStoreMigratesCaseSensitiveSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "streamers.db")
+	db, err := sql.Open("sqlite3", "file:"+path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE streamers (id TEXT PRIMARY KEY, position INTEGER NOT NULL, record TEXT NOT NULL);
+		CREATE INDEX streamers_position ON streamers(position);
+		INSERT INTO streamers VALUES ('Old', 0, '{"streamer":{"id":"Old","alias":"Old"},"platforms":{"youtube":{"channelId":"UCold"}}}');`); err != nil {
+		t.Fatalf("seed legacy schema: %v", err)
+	}
+	db.Close()
+
+	store, err := OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("open migrated: %v", err)
+	}
+	defer store.Close()
+	if _, err := store.Get("old"); err != nil {
+		t.Fatalf("expected case-insensitive lookup after migration: %v", err)
+	}
+	if _, err := store.SetYouTubeLive("UCold", "video1", time.Now()); err != nil {
+		t.Fatalf("expected lookup columns to be filled in: %v", err)
+	}
+	if _, err := store.Append(Record{Streamer: Streamer{ID: "OLD"}}); !errors.Is(err, ErrDuplicateStreamerID) {
+		t.Fatalf("expected duplicate id error, got %v", err)
+	}
+}
+
+func TestSQLiteStoreSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "streamers.db")
+	store, err := OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if _, err := store.Append(Record{Streamer: Streamer{ID: "kept", Alias: "Kept"}}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	store.Close()
+
+	reopened, err := OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+	if _, err := reopened.Get("kept"); err != nil {
+		t.Fatalf("expected record after reopen: %v", err)
+	}
+}
+
+func TestMigrateJSONToSQLite(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "streamers.json")
+	for _, alias := range []string{"One", "Two"} {
+		if _, err := Append(jsonPath, Record{Streamer: Streamer{Alias: alias}}); err != nil {
+			t.Fatalf("append %s: %v", alias, err)
+		}
+	}
+	store := openTestSQLite(t)
+
+	migrated, err := MigrateJSONToSQLite(jsonPath, store)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if migrated != 2 {
+		t.Fatalf("expected 2 migrated records, got %d", migrated)
+	}
+	records, err := store.List()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(records) != 2 || records[0].Streamer.Alias != "One" {
+		t.Fatalf("unexpected records after migration: %+v", records)
+	}
+	if _, err := os.Stat(jsonPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected json file to be renamed, stat err=%v", err)
+	}
+	if _, err := os.Stat(jsonPath + ".migrated"); err != nil {
+		t.Fatalf("expected migrated marker file: %v", err)
+	}
+
+	again, err := MigrateJSONToSQLite(jsonPath, store)
+	if err != nil || again != 0 {
+		t.Fatalf("expected second migration to be a no-op, got %d, %v", again, err)
+	}
+}
+
+func TestSQLiteStoreReportsExternalChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "streamers.db")
+	store, err := OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+	other, err := OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("open second handle: %v", err)
+	}
+	defer other.Close()
+
+	if changed, err := store.ExternalChange(); err != nil || changed {
+		t.Fatalf("expected the first check to record a baseline, got %v, %v", changed, err)
+	}
+	if _, err := store.Append(Record{Streamer: Streamer{ID: "own", Alias: "Own"}}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if changed, err := store.ExternalChange(); err != nil || changed {
+		t.Fatalf("expected the store's own write not to count, got %v, %v", changed, err)
+	}
+	if _, err := other.Append(Record{Streamer: Streamer{ID: "other", Alias: "Other"}}); err != nil {
+		t.Fatalf("external append: %v", err)
+	}
+	if changed, err := store.ExternalChange(); err != nil || !changed {
+		t.Fatalf("expected the other connection's commit to count, got %v, %v", changed, err)
+	}
+}
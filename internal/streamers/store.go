@@ -4,12 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
+
+	"live-stream-alerts/internal/filestore"
 )
 
 const (
@@ -30,8 +31,28 @@ type Record struct {
 	Streamer  Streamer  `json:"streamer"`
 	Platforms Platforms `json:"platforms"`
 	Status    *Status   `json:"status,omitempty"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	// Schedule lists announced broadcasts that have not started yet.
+	Schedule  []ScheduledBroadcast `json:"schedule,omitempty"`
+	CreatedAt time.Time            `json:"createdAt"`
+	UpdatedAt time.Time            `json:"updatedAt"`
+}
+
+// Public returns a copy of the record that is safe to serve to anonymous callers: the
+// YouTube hub secret, the Facebook access token and the contact email are cleared.
+func (r Record) Public() Record {
+	public := r
+	public.Streamer.Email = ""
+	if yt := r.Platforms.YouTube; yt != nil {
+		copy := *yt
+		copy.HubSecret = ""
+		public.Platforms.YouTube = &copy
+	}
+	if fb := r.Platforms.Facebook; fb != nil {
+		copy := *fb
+		copy.AccessToken = ""
+		public.Platforms.Facebook = &copy
+	}
+	return public
 }
 
 // Streamer captures personal information for a streamer.
@@ -67,7 +88,9 @@ type Status struct {
 type YouTubeStatus struct {
 	Live      bool      `json:"live"`
 	VideoID   string    `json:"videoId,omitempty"`
+	Title     string    `json:"title,omitempty"`
 	StartedAt time.Time `json:"startedAt,omitempty"`
+	EndedAt   time.Time `json:"endedAt,omitempty"`
 }
 
 // TwitchStatus stores Twitch live metadata.
@@ -84,6 +107,16 @@ type FacebookStatus struct {
 	StartedAt time.Time `json:"startedAt,omitempty"`
 }
 
+// ScheduledBroadcast describes an upcoming broadcast announced on a platform.
+type ScheduledBroadcast struct {
+	Platform       string    `json:"platform"`
+	VideoID        string    `json:"videoId"`
+	Title          string    `json:"title,omitempty"`
+	URL            string    `json:"url,omitempty"`
+	ScheduledStart time.Time `json:"scheduledStart"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
 // YouTubePlatform stores YouTube-specific metadata.
 type YouTubePlatform struct {
 	Handle       string `json:"handle"`
@@ -120,18 +153,54 @@ var (
 
 // Store persists streamer records to a JSON file with per-path locking.
 type Store struct {
-	path string
-	mu   sync.Mutex
+	path        string
+	mu          sync.Mutex
+	backups     filestore.Backups
+	lockTimeout time.Duration
+	events      *EventBus
+	// stamp is the file version this process last wrote or checked; external is set
+	// when a write found the file changed by someone else since then.
+	stamp    fileStamp
+	stamped  bool
+	external bool
 }
 
 var storeCache sync.Map
 
+// StoreOption customises the store behaviour.
+type StoreOption func(*Store)
+
+// WithBackups overrides where backups are written and how many are retained.
+func WithBackups(backups filestore.Backups) StoreOption {
+	return func(s *Store) {
+		s.backups = backups
+	}
+}
+
+// WithLockTimeout bounds how long writes wait for another process holding the file lock.
+func WithLockTimeout(timeout time.Duration) StoreOption {
+	return func(s *Store) {
+		s.lockTimeout = timeout
+	}
+}
+
+// WithEvents publishes every record change made through the store to bus.
+func WithEvents(bus *EventBus) StoreOption {
+	return func(s *Store) {
+		s.events = bus
+	}
+}
+
 // NewStore returns a file-backed store for the provided path.
-func NewStore(path string) *Store {
+func NewStore(path string, opts ...StoreOption) *Store {
 	if path == "" {
 		path = DefaultFilePath
 	}
-	return &Store{path: filepath.Clean(path)}
+	store := &Store{path: filepath.Clean(path)}
+	for _, opt := range opts {
+		opt(store)
+	}
+	return store
 }
 
 // Path returns the file path backing the store.
@@ -192,13 +261,52 @@ func (s *Store) writeFileLocked(file File) error {
 	if err != nil {
 		return fmt.Errorf("encode streamers file: %w", err)
 	}
-	if err := os.WriteFile(s.path, encoded, 0o644); err != nil {
+	if err := filestore.WriteAtomic(s.path, encoded, 0o644); err != nil {
 		return fmt.Errorf("write streamers file: %w", err)
 	}
+	// The data is already durable; a failed backup must not turn the write into an error.
+	_, _ = s.backups.Save(s.path, encoded)
 	return nil
 }
 
+// Recover checks that the streamers file parses and, when it does not, restores the
+// newest backup that does. Read errors are returned without restoring anything. It
+// returns the restored backup path, or "" when the file was readable.
+func (s *Store) Recover() (string, error) {
+	if s == nil {
+		return "", errors.New("streamers store is nil")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, err := filestore.LockFile(s.path, s.lockTimeout)
+	if err != nil {
+		return "", fmt.Errorf("lock streamers file: %w", err)
+	}
+	defer lock.Unlock()
+	return s.backups.RecoverCorrupt(s.path, func(data []byte) error {
+		if len(data) == 0 {
+			return nil
+		}
+		var file File
+		if err := json.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("parse streamers file: %w", err)
+		}
+		return nil
+	})
+}
+
+// updateFileLocked runs a read-modify-write cycle. The caller holds s.mu, which serialises
+// goroutines; the advisory file lock extends that to other processes sharing the file.
 func (s *Store) updateFileLocked(updateFn func(*File) error) error {
+	lock, err := filestore.LockFile(s.path, s.lockTimeout)
+	if err != nil {
+		return fmt.Errorf("lock streamers file: %w", err)
+	}
+	defer lock.Unlock()
+
+	if s.stamped && statFile(s.path) != s.stamp {
+		s.external = true
+	}
 	fileData, err := s.readFileLocked()
 	if err != nil {
 		return err
@@ -206,7 +314,41 @@ func (s *Store) updateFileLocked(updateFn func(*File) error) error {
 	if err := updateFn(&fileData); err != nil {
 		return err
 	}
-	return s.writeFileLocked(fileData)
+	if err := s.writeFileLocked(fileData); err != nil {
+		return err
+	}
+	s.stamp, s.stamped = statFile(s.path), true
+	return nil
+}
+
+// ExternalChange reports whether another process rewrote the file since the previous
+// call, going by its modification time and size. The first call only records the
+// current version. Writes made through this store do not count.
+func (s *Store) ExternalChange() (bool, error) {
+	if s == nil {
+		return false, errors.New("streamers store is nil")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current := statFile(s.path)
+	changed := s.external || (s.stamped && current != s.stamp)
+	s.stamp, s.stamped, s.external = current, true, false
+	return changed, nil
+}
+
+// fileStamp identifies one version of a file on disk.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// statFile returns the file's stamp, or the zero stamp when it does not exist.
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
 }
 
 // UpdateFields describes the mutable streamer fields.
@@ -217,48 +359,54 @@ type UpdateFields struct {
 	Languages   *[]string
 }
 
-// Append adds a new streamer record to disk and returns a copy with timestamps populated.
-func (s *Store) Append(record Record) (Record, error) {
+// mutate runs updateFn against the on-disk file while holding the store lock. The whole
+// file is read either way, so scope is ignored.
+func (s *Store) mutate(_ recordScope, updateFn func(*File) error) error {
 	if s == nil {
-		return Record{}, errors.New("streamers store is nil")
-	}
-	if err := s.ensureDir(); err != nil {
-		return Record{}, fmt.Errorf("create streamers dir: %w", err)
+		return errors.New("streamers store is nil")
 	}
-
 	s.mu.Lock()
 	defer s.mu.Unlock()
-
-	fileData, err := s.readFileLocked()
-	if err != nil {
-		return Record{}, err
+	if s.events == nil {
+		return s.updateFileLocked(updateFn)
 	}
-	if fileData.SchemaRef == "" {
-		fileData.SchemaRef = DefaultSchemaPath
+	var (
+		before map[string]recordSnapshot
+		after  []Record
+	)
+	err := s.updateFileLocked(func(file *File) error {
+		before = snapshotRecords(file.Records)
+		if err := updateFn(file); err != nil {
+			return err
+		}
+		after = file.Records
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	s.events.publishChanges(before, after)
+	return nil
+}
 
-	if record.Streamer.ID == "" {
-		record.Streamer.ID = GenerateID()
+func (s *Store) view() (File, error) {
+	if s == nil {
+		return File{}, errors.New("streamers store is nil")
 	}
-	now := time.Now().UTC()
-	record.CreatedAt = now
-	record.UpdatedAt = now
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readFileLocked()
+}
 
-	newAliasKey := NormaliseAlias(record.Streamer.Alias)
-	for _, existing := range fileData.Records {
-		if existing.Streamer.ID == record.Streamer.ID {
-			return Record{}, fmt.Errorf("%w: %s", ErrDuplicateStreamerID, record.Streamer.ID)
-		}
-		if newAliasKey != "" && newAliasKey == NormaliseAlias(existing.Streamer.Alias) {
-			return Record{}, fmt.Errorf("%w: %s", ErrDuplicateAlias, record.Streamer.Alias)
-		}
+// Append adds a new streamer record to disk and returns a copy with timestamps populated.
+func (s *Store) Append(record Record) (Record, error) {
+	if s == nil {
+		return Record{}, errors.New("streamers store is nil")
 	}
-
-	fileData.Records = append(fileData.Records, record)
-	if err := s.writeFileLocked(fileData); err != nil {
-		return Record{}, err
+	if err := s.ensureDir(); err != nil {
+		return Record{}, fmt.Errorf("create streamers dir: %w", err)
 	}
-	return record, nil
+	return appendRecord(s, record)
 }
 
 // Append adds a new streamer record using a shared store derived from the path.
@@ -268,13 +416,7 @@ func Append(path string, record Record) (Record, error) {
 
 // List loads all streamer records from disk.
 func (s *Store) List() ([]Record, error) {
-	if s == nil {
-		return nil, errors.New("streamers store is nil")
-	}
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	fileData, err := s.readFileLocked()
+	fileData, err := s.view()
 	if err != nil {
 		return nil, err
 	}
@@ -288,42 +430,9 @@ func List(path string) ([]Record, error) {
 	return storeForPath(path).List()
 }
 
-// YouTubeLiveStatus describes the live state to persist for a YouTube channel.
-type YouTubeLiveStatus struct {
-	Live      bool
-	VideoID   string
-	StartedAt time.Time
-}
-
 // UpdateYouTubeLiveStatus updates the stored status for the streamer owning the channel ID.
 func (s *Store) UpdateYouTubeLiveStatus(channelID string, liveStatus YouTubeLiveStatus) (Record, error) {
-	if s == nil {
-		return Record{}, errors.New("streamers store is nil")
-	}
-	ch := strings.TrimSpace(channelID)
-	if ch == "" {
-		return Record{}, errors.New("channel id is required")
-	}
-	var updated Record
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	err := s.updateFileLocked(func(file *File) error {
-		for i := range file.Records {
-			yt := file.Records[i].Platforms.YouTube
-			if yt == nil || !strings.EqualFold(yt.ChannelID, ch) {
-				continue
-			}
-			applyYouTubeStatus(&file.Records[i], liveStatus)
-			file.Records[i].UpdatedAt = time.Now().UTC()
-			updated = file.Records[i]
-			return nil
-		}
-		return fmt.Errorf("%w: %s", ErrStreamerNotFound, ch)
-	})
-	if err != nil {
-		return Record{}, err
-	}
-	return updated, nil
+	return updateYouTubeLiveStatus(s, channelID, liveStatus)
 }
 
 // UpdateYouTubeLiveStatus updates the stored status using a shared store instance derived from the provided path.
@@ -331,108 +440,9 @@ func UpdateYouTubeLiveStatus(path, channelID string, liveStatus YouTubeLiveStatu
 	return storeForPath(path).UpdateYouTubeLiveStatus(channelID, liveStatus)
 }
 
-func applyYouTubeStatus(record *Record, liveStatus YouTubeLiveStatus) {
-	if record.Status == nil {
-		record.Status = &Status{}
-	}
-	if record.Status.YouTube == nil {
-		record.Status.YouTube = &YouTubeStatus{}
-	}
-	record.Status.YouTube.Live = liveStatus.Live
-	record.Status.YouTube.VideoID = liveStatus.VideoID
-	if liveStatus.StartedAt.IsZero() {
-		record.Status.YouTube.StartedAt = time.Time{}
-	} else {
-		record.Status.YouTube.StartedAt = liveStatus.StartedAt.UTC()
-	}
-
-	if liveStatus.Live {
-		record.Status.Platforms = addPlatform(record.Status.Platforms, platformYouTube)
-	} else {
-		record.Status.Platforms = removePlatform(record.Status.Platforms, platformYouTube)
-	}
-	record.Status.Live = liveStatus.Live
-	if !liveStatus.Live && record.Status.YouTube != nil {
-		record.Status.YouTube.Live = false
-		record.Status.YouTube.VideoID = ""
-		record.Status.YouTube.StartedAt = time.Time{}
-	}
-	if len(record.Status.Platforms) == 0 && !record.Status.Live {
-		record.Status.Platforms = nil
-	}
-}
-
-func addPlatform(platforms []string, platform string) []string {
-	platform = strings.ToLower(strings.TrimSpace(platform))
-	if platform == "" {
-		return platforms
-	}
-	for _, existing := range platforms {
-		if strings.EqualFold(existing, platform) {
-			return platforms
-		}
-	}
-	return append(platforms, platform)
-}
-
-func removePlatform(platforms []string, platform string) []string {
-	if len(platforms) == 0 {
-		return platforms
-	}
-	platform = strings.ToLower(strings.TrimSpace(platform))
-	out := platforms[:0]
-	for _, existing := range platforms {
-		if strings.EqualFold(existing, platform) {
-			continue
-		}
-		out = append(out, existing)
-	}
-	if len(out) == 0 {
-		return nil
-	}
-	return out
-}
-
 // Update applies modifications to an existing streamer.
 func (s *Store) Update(fields UpdateFields) (Record, error) {
-	if s == nil {
-		return Record{}, errors.New("streamers store is nil")
-	}
-	id := strings.TrimSpace(fields.StreamerID)
-	if id == "" {
-		return Record{}, errors.New("streamer id is required")
-	}
-	if fields.Alias == nil && fields.Description == nil && fields.Languages == nil {
-		return Record{}, errors.New("no fields provided to update")
-	}
-
-	var updated Record
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	err := s.updateFileLocked(func(file *File) error {
-		for i := range file.Records {
-			if !strings.EqualFold(file.Records[i].Streamer.ID, id) {
-				continue
-			}
-			if fields.Alias != nil {
-				file.Records[i].Streamer.Alias = *fields.Alias
-			}
-			if fields.Description != nil {
-				file.Records[i].Streamer.Description = *fields.Description
-			}
-			if fields.Languages != nil {
-				file.Records[i].Streamer.Languages = append([]string(nil), (*fields.Languages)...)
-			}
-			file.Records[i].UpdatedAt = time.Now().UTC()
-			updated = file.Records[i]
-			return nil
-		}
-		return fmt.Errorf("%w: %s", ErrStreamerNotFound, id)
-	})
-	if err != nil {
-		return Record{}, err
-	}
-	return updated, nil
+	return updateRecord(s, fields)
 }
 
 // Update applies modifications using a shared store derived from the provided path.
@@ -442,15 +452,10 @@ func Update(path string, fields UpdateFields) (Record, error) {
 
 // UpdateFile reads the streamers file, applies the provided mutation, and writes it back to disk atomically.
 func (s *Store) UpdateFile(updateFn func(*File) error) error {
-	if s == nil {
-		return errors.New("streamers store is nil")
-	}
 	if updateFn == nil {
 		return errors.New("updateFn is required")
 	}
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	return s.updateFileLocked(updateFn)
+	return s.mutate(recordScope{}, updateFn)
 }
 
 // UpdateFile reads and updates the file for the provided path using a shared store instance.
@@ -460,24 +465,7 @@ func UpdateFile(path string, updateFn func(*File) error) error {
 
 // Delete removes a streamer by ID.
 func (s *Store) Delete(streamerID string) error {
-	if s == nil {
-		return errors.New("streamers store is nil")
-	}
-	streamerID = strings.TrimSpace(streamerID)
-	if streamerID == "" {
-		return errors.New("streamer id is required")
-	}
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	return s.updateFileLocked(func(file *File) error {
-		for i := range file.Records {
-			if strings.EqualFold(file.Records[i].Streamer.ID, streamerID) {
-				file.Records = append(file.Records[:i], file.Records[i+1:]...)
-				return nil
-			}
-		}
-		return fmt.Errorf("%w: %s", ErrStreamerNotFound, streamerID)
-	})
+	return deleteRecord(s, streamerID)
 }
 
 // Delete removes a streamer by ID for the provided path using a shared store instance.
@@ -487,25 +475,15 @@ func Delete(path, streamerID string) error {
 
 // Get returns a single streamer record by ID.
 func (s *Store) Get(streamerID string) (Record, error) {
-	if s == nil {
-		return Record{}, errors.New("streamers store is nil")
-	}
 	streamerID = strings.TrimSpace(streamerID)
 	if streamerID == "" {
 		return Record{}, errors.New("streamer id is required")
 	}
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	fileData, err := s.readFileLocked()
+	fileData, err := s.view()
 	if err != nil {
 		return Record{}, err
 	}
-	for _, record := range fileData.Records {
-		if strings.EqualFold(record.Streamer.ID, streamerID) {
-			return record, nil
-		}
-	}
-	return Record{}, fmt.Errorf("%w: %s", ErrStreamerNotFound, streamerID)
+	return findRecord(fileData.Records, streamerID)
 }
 
 // Get returns a record using a shared store derived from the provided path.
@@ -515,133 +493,49 @@ func Get(path, streamerID string) (Record, error) {
 
 // SetYouTubeLive marks the streamer associated with the provided channel ID as live.
 func (s *Store) SetYouTubeLive(channelID, videoID string, startedAt time.Time) (Record, error) {
-	return s.updateYouTubeStatus(channelID, func(status *Status) {
-		if status.YouTube == nil {
-			status.YouTube = &YouTubeStatus{}
-		}
-		status.YouTube.Live = true
-		status.YouTube.VideoID = videoID
-		if !startedAt.IsZero() {
-			status.YouTube.StartedAt = startedAt
-		} else {
-			status.YouTube.StartedAt = time.Time{}
-		}
-		status.Platforms = addPlatform(status.Platforms, platformYouTube)
-		status.Live = true
-	})
+	return setYouTubeLive(s, channelID, videoID, startedAt)
 }
 
 // ClearYouTubeLive marks the YouTube platform as offline for the matching channel ID.
 func (s *Store) ClearYouTubeLive(channelID string) (Record, error) {
-	return s.updateYouTubeStatus(channelID, func(status *Status) {
-		if status.YouTube == nil {
-			status.YouTube = &YouTubeStatus{}
-		}
-		status.YouTube.Live = false
-		status.YouTube.VideoID = ""
-		status.YouTube.StartedAt = time.Time{}
-		status.Platforms = removePlatform(status.Platforms, platformYouTube)
-	})
+	return clearYouTubeLive(s, channelID)
 }
 
-// SetYouTubeLive marks the streamer as live using a shared store derived from path.
-func SetYouTubeLive(path, channelID, videoID string, startedAt time.Time) (Record, error) {
-	return storeForPath(path).SetYouTubeLive(channelID, videoID, startedAt)
+// EndYouTubeLive clears the YouTube live status once the broadcast identified by videoID
+// has ended, recording endedAt. Records that have already moved on to a different video
+// are left untouched.
+func (s *Store) EndYouTubeLive(channelID, videoID string, endedAt time.Time) (Record, error) {
+	return endYouTubeLive(s, channelID, videoID, endedAt)
 }
 
-// ClearYouTubeLive marks the streamer as offline using a shared store.
-func ClearYouTubeLive(path, channelID string) (Record, error) {
-	return storeForPath(path).ClearYouTubeLive(channelID)
+// SetYouTubeUpcoming records (or reschedules) an upcoming broadcast for the channel.
+func (s *Store) SetYouTubeUpcoming(channelID string, broadcast ScheduledBroadcast) (Record, error) {
+	return setYouTubeUpcoming(s, channelID, broadcast)
 }
 
-const platformYouTube = "youtube"
-
-func (s *Store) updateYouTubeStatus(channelID string, updateFn func(*Status)) (Record, error) {
-	if s == nil {
-		return Record{}, errors.New("streamers store is nil")
-	}
-	channelID = strings.TrimSpace(channelID)
-	if channelID == "" {
-		return Record{}, errors.New("youtube channel id is required")
-	}
-	var updated Record
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	err := s.updateFileLocked(func(file *File) error {
-		for i := range file.Records {
-			yt := file.Records[i].Platforms.YouTube
-			if !channelMatches(yt, channelID) {
-				continue
-			}
-			if file.Records[i].Status == nil {
-				file.Records[i].Status = &Status{}
-			}
-			updateFn(file.Records[i].Status)
-			refreshLiveFlag(file.Records[i].Status)
-			file.Records[i].UpdatedAt = time.Now().UTC()
-			updated = file.Records[i]
-			return nil
-		}
-		return fmt.Errorf("%w: %s", ErrStreamerNotFound, channelID)
-	})
-	return updated, err
+// RemoveYouTubeUpcoming drops a scheduled broadcast that was cancelled or has started.
+func (s *Store) RemoveYouTubeUpcoming(channelID, videoID string) (Record, error) {
+	return removeYouTubeUpcoming(s, channelID, videoID)
 }
 
-func channelMatches(yt *YouTubePlatform, target string) bool {
-	if yt == nil {
-		return false
-	}
-	stored := strings.TrimSpace(yt.ChannelID)
-	if stored == "" {
-		stored = extractChannelIDFromTopic(yt.Topic)
-	}
-	if stored == "" || target == "" {
-		return false
-	}
-	if strings.EqualFold(stored, target) {
-		return true
-	}
-	return strings.EqualFold(trimChannelPrefix(stored), trimChannelPrefix(target))
+// UpdateTwitchLiveStatus updates the stored status for the streamer owning the broadcaster ID.
+func (s *Store) UpdateTwitchLiveStatus(broadcasterID string, liveStatus TwitchLiveStatus) (Record, error) {
+	return updateTwitchLiveStatus(s, broadcasterID, liveStatus)
 }
 
-func trimChannelPrefix(value string) string {
-	value = strings.TrimSpace(strings.ToUpper(value))
-	return strings.TrimPrefix(value, "UC")
+// UpdateFacebookLiveStatus updates the stored status for the streamer owning the page ID.
+func (s *Store) UpdateFacebookLiveStatus(pageID string, liveStatus FacebookLiveStatus) (Record, error) {
+	return updateFacebookLiveStatus(s, pageID, liveStatus)
 }
 
-func extractChannelIDFromTopic(topic string) string {
-	if topic == "" {
-		return ""
-	}
-	u, err := url.Parse(topic)
-	if err != nil {
-		return ""
-	}
-	return u.Query().Get("channel_id")
+// SetYouTubeLive marks the streamer as live using a shared store derived from path.
+func SetYouTubeLive(path, channelID, videoID string, startedAt time.Time) (Record, error) {
+	return storeForPath(path).SetYouTubeLive(channelID, videoID, startedAt)
 }
 
-func refreshLiveFlag(status *Status) {
-	if status == nil {
-		return
-	}
-	status.Live = len(status.Platforms) > 0
-	if status.Live {
-		return
-	}
-	if status.YouTube != nil && status.YouTube.Live {
-		status.Platforms = addPlatform(status.Platforms, platformYouTube)
-		status.Live = true
-		return
-	}
-	if status.Twitch != nil && status.Twitch.Live {
-		status.Platforms = addPlatform(status.Platforms, "twitch")
-		status.Live = true
-		return
-	}
-	if status.Facebook != nil && status.Facebook.Live {
-		status.Platforms = addPlatform(status.Platforms, "facebook")
-		status.Live = true
-	}
+// ClearYouTubeLive marks the streamer as offline using a shared store.
+func ClearYouTubeLive(path, channelID string) (Record, error) {
+	return storeForPath(path).ClearYouTubeLive(channelID)
 }
 
 func readFile(path string) (File, error) {
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"live-stream-alerts/internal/filestore"
 )
 
 func TestAppendAndList(t *testing.T) {
@@ -116,6 +118,11 @@ func TestUpdateYouTubeLiveStatus(t *testing.T) {
 		t.Fatalf("expected video id cleared, got %q", updated.Status.YouTube.VideoID)
 	}
 
+	// The UC prefix and case are optional, as for every other YouTube update.
+	if updated, err = UpdateYouTubeLiveStatus(path, "DEMO", YouTubeLiveStatus{Live: true, VideoID: "video456"}); err != nil || updated.Status.YouTube.VideoID != "video456" {
+		t.Fatalf("expected a prefix-less channel ID to match, got %+v, %v", updated.Status, err)
+	}
+
 	if _, err := UpdateYouTubeLiveStatus(path, "missing", YouTubeLiveStatus{Live: true}); !errors.Is(err, ErrStreamerNotFound) {
 		t.Fatalf("expected not found, got %v", err)
 	}
@@ -184,6 +191,7 @@ func TestListValidatesPath(t *testing.T) {
 
 func TestUpdate(t *testing.T) {
 	dir := t.TempDir()
+	t.Chdir(dir)
 	path := filepath.Join(dir, "streamers.json")
 	record, err := Append(path, Record{
 		Streamer: Streamer{
@@ -313,3 +321,207 @@ func TestClearYouTubeLiveResetsStatus(t *testing.T) {
 		t.Fatalf("expected platforms to be empty, got %v", updated.Status.Platforms)
 	}
 }
+
+func TestEndYouTubeLiveRecordsEndTime(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "streamers.json"))
+	if _, err := store.Append(Record{
+		Streamer:  Streamer{Alias: "Test"},
+		Platforms: Platforms{YouTube: &YouTubePlatform{ChannelID: "UC777"}},
+	}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if _, err := store.SetYouTubeLive("UC777", "video-live", time.Now()); err != nil {
+		t.Fatalf("set live: %v", err)
+	}
+
+	stale, err := store.EndYouTubeLive("UC777", "other-video", time.Now())
+	if err != nil {
+		t.Fatalf("end stale video: %v", err)
+	}
+	if !stale.Status.Live {
+		t.Fatalf("expected mismatched video id to leave the stream live")
+	}
+
+	endedAt := time.Date(2025, 2, 3, 4, 5, 6, 0, time.UTC)
+	updated, err := store.EndYouTubeLive("UC777", "video-live", endedAt)
+	if err != nil {
+		t.Fatalf("end live: %v", err)
+	}
+	if updated.Status.Live || updated.Status.YouTube.Live {
+		t.Fatalf("expected status to be offline: %+v", updated.Status)
+	}
+	if !updated.Status.YouTube.EndedAt.Equal(endedAt) {
+		t.Fatalf("expected endedAt %s, got %s", endedAt, updated.Status.YouTube.EndedAt)
+	}
+}
+
+func TestUpdateTwitchLiveStatusCombinesPlatforms(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(filepath.Join(dir, "streamers.json"))
+	if _, err := store.Append(Record{
+		Streamer: Streamer{Alias: "Multi"},
+		Platforms: Platforms{
+			YouTube: &YouTubePlatform{ChannelID: "UCmulti"},
+			Twitch:  &TwitchPlatform{Username: "multi", BroadcasterID: "42"},
+		},
+	}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if _, err := store.SetYouTubeLive("UCmulti", "video1", time.Now()); err != nil {
+		t.Fatalf("set youtube live: %v", err)
+	}
+	updated, err := store.UpdateTwitchLiveStatus("42", TwitchLiveStatus{Live: true, StreamID: "stream1", StartedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("set twitch live: %v", err)
+	}
+	if !updated.Status.Live || len(updated.Status.Platforms) != 2 {
+		t.Fatalf("expected both platforms live, got %+v", updated.Status)
+	}
+
+	if _, err := store.ClearYouTubeLive("UCmulti"); err != nil {
+		t.Fatalf("clear youtube: %v", err)
+	}
+	records, _ := store.List()
+	status := records[0].Status
+	if !status.Live || len(status.Platforms) != 1 || status.Platforms[0] != "twitch" {
+		t.Fatalf("expected twitch to keep the record live, got %+v", status)
+	}
+
+	updated, err = store.UpdateTwitchLiveStatus("42", TwitchLiveStatus{Live: false})
+	if err != nil {
+		t.Fatalf("clear twitch: %v", err)
+	}
+	if updated.Status.Live || updated.Status.Twitch.Live || updated.Status.Twitch.StreamID != "" {
+		t.Fatalf("expected record offline, got %+v", updated.Status)
+	}
+
+	if _, err := store.UpdateTwitchLiveStatus("missing", TwitchLiveStatus{Live: true}); !errors.Is(err, ErrStreamerNotFound) {
+		t.Fatalf("expected ErrStreamerNotFound, got %v", err)
+	}
+}
+
+func TestUpdateFacebookLiveStatusFeedsAggregateFlag(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "streamers.json"))
+	if _, err := store.Append(Record{
+		Streamer:  Streamer{Alias: "Page"},
+		Platforms: Platforms{Facebook: &FacebookPlatform{PageID: "100200", AccessToken: "token"}},
+	}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	updated, err := store.UpdateFacebookLiveStatus("100200", FacebookLiveStatus{Live: true, VideoID: "v1", StartedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("set facebook live: %v", err)
+	}
+	if !updated.Status.Live || !updated.Status.Facebook.Live || len(updated.Status.Platforms) != 1 || updated.Status.Platforms[0] != "facebook" {
+		t.Fatalf("expected facebook live status, got %+v", updated.Status)
+	}
+
+	// A stop for some other broadcast must not end the current one.
+	updated, err = store.UpdateFacebookLiveStatus("100200", FacebookLiveStatus{VideoID: "v0"})
+	if err != nil {
+		t.Fatalf("stale stop: %v", err)
+	}
+	if !updated.Status.Live || updated.Status.Facebook.VideoID != "v1" {
+		t.Fatalf("stale stop should be ignored, got %+v", updated.Status.Facebook)
+	}
+
+	updated, err = store.UpdateFacebookLiveStatus("100200", FacebookLiveStatus{VideoID: "v1"})
+	if err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if updated.Status.Live || updated.Status.Facebook.Live || len(updated.Status.Platforms) != 0 {
+		t.Fatalf("expected record offline, got %+v", updated.Status)
+	}
+
+	if _, err := store.UpdateFacebookLiveStatus("missing", FacebookLiveStatus{Live: true}); !errors.Is(err, ErrStreamerNotFound) {
+		t.Fatalf("expected ErrStreamerNotFound, got %v", err)
+	}
+}
+
+func TestYouTubeScheduleLifecycle(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "streamers.json"))
+	if _, err := store.Append(Record{
+		Streamer:  Streamer{Alias: "Sched"},
+		Platforms: Platforms{YouTube: &YouTubePlatform{ChannelID: "UCsched"}},
+	}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	start := time.Date(2025, 11, 21, 18, 0, 0, 0, time.UTC)
+	if _, err := store.SetYouTubeUpcoming("UCsched", ScheduledBroadcast{VideoID: "late", ScheduledStart: start.Add(time.Hour)}); err != nil {
+		t.Fatalf("set upcoming: %v", err)
+	}
+	if _, err := store.SetYouTubeUpcoming("UCsched", ScheduledBroadcast{VideoID: "early", Title: "Early", ScheduledStart: start.Add(2 * time.Hour)}); err != nil {
+		t.Fatalf("set upcoming: %v", err)
+	}
+	// Rescheduling replaces the entry and keeps the list ordered by start time.
+	updated, err := store.SetYouTubeUpcoming("UCsched", ScheduledBroadcast{VideoID: "early", Title: "Early", ScheduledStart: start})
+	if err != nil {
+		t.Fatalf("reschedule: %v", err)
+	}
+	if len(updated.Schedule) != 2 || updated.Schedule[0].VideoID != "early" || updated.Schedule[0].Platform != "youtube" {
+		t.Fatalf("unexpected schedule %+v", updated.Schedule)
+	}
+
+	// Going live promotes the broadcast out of the schedule.
+	updated, err = store.UpdateYouTubeLiveStatus("UCsched", YouTubeLiveStatus{Live: true, VideoID: "early", StartedAt: start})
+	if err != nil {
+		t.Fatalf("go live: %v", err)
+	}
+	if len(updated.Schedule) != 1 || updated.Schedule[0].VideoID != "late" {
+		t.Fatalf("expected the live broadcast to leave the schedule, got %+v", updated.Schedule)
+	}
+
+	updated, err = store.RemoveYouTubeUpcoming("UCsched", "late")
+	if err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if len(updated.Schedule) != 0 {
+		t.Fatalf("expected an empty schedule, got %+v", updated.Schedule)
+	}
+	if _, err := store.SetYouTubeUpcoming("missing", ScheduledBroadcast{VideoID: "x", ScheduledStart: start}); !errors.Is(err, ErrStreamerNotFound) {
+		t.Fatalf("expected ErrStreamerNotFound, got %v", err)
+	}
+}
+
+func TestStoreRecoverRestoresNewestBackup(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(filepath.Join(dir, "streamers.json"))
+	if _, err := store.Append(Record{Streamer: Streamer{ID: "saved", Alias: "Saved"}}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if restored, err := store.Recover(); err != nil || restored != "" {
+		t.Fatalf("healthy file should not be recovered, got %q, %v", restored, err)
+	}
+	if err := os.WriteFile(store.Path(), []byte(`{"streamers": [`), 0o644); err != nil {
+		t.Fatalf("corrupt file: %v", err)
+	}
+	if _, err := store.List(); err == nil {
+		t.Fatalf("expected parse error before recovery")
+	}
+
+	restored, err := store.Recover()
+	if err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+	if filepath.Dir(restored) != filepath.Join(dir, "backups") {
+		t.Fatalf("expected backup under data/backups, got %q", restored)
+	}
+	if _, err := store.Get("saved"); err != nil {
+		t.Fatalf("expected record after recovery: %v", err)
+	}
+}
+
+func TestStoreWriteFailsWhenFileLockHeld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "streamers.json")
+	held, err := filestore.LockFile(path, time.Second)
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	defer held.Unlock()
+
+	store := NewStore(path, WithLockTimeout(20*time.Millisecond))
+	_, err = store.Append(Record{Streamer: Streamer{Alias: "Blocked"}})
+	if !errors.Is(err, filestore.ErrLockTimeout) {
+		t.Fatalf("expected lock timeout, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package streamers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"live-stream-alerts/internal/logging"
+)
+
+const defaultChangeInterval = 5 * time.Second
+
+// ChangeDetector reports whether a store was changed outside this process. Store and
+// SQLiteStore satisfy it.
+type ChangeDetector interface {
+	ExternalChange() (bool, error)
+}
+
+// ChangeWatcherConfig configures the external change watcher.
+type ChangeWatcherConfig struct {
+	Source ChangeDetector
+	Events *EventBus
+	// Interval is how often Source is checked (default 5s).
+	Interval time.Duration
+	Logger   logging.Logger
+}
+
+// ChangeWatcher publishes EventResync when the store is changed by another process or
+// by hand. The event bus only sees changes made through this process's store, so without
+// it watchers would miss those edits until they reconnect.
+type ChangeWatcher struct {
+	cfg    ChangeWatcherConfig
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// StartChangeWatcher records the store's current version and then polls it until ctx
+// is done or Stop is called.
+func StartChangeWatcher(ctx context.Context, cfg ChangeWatcherConfig) *ChangeWatcher {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultChangeInterval
+	}
+	watcher := &ChangeWatcher{cfg: cfg}
+	watcher.check()
+	runCtx, cancel := context.WithCancel(ctx)
+	watcher.cancel = cancel
+	watcher.wg.Add(1)
+	go func() {
+		defer watcher.wg.Done()
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-runCtx.Done():
+				return
+			case <-ticker.C:
+				watcher.check()
+			}
+		}
+	}()
+	return watcher
+}
+
+// check publishes EventResync when the source changed, reporting whether it did.
+func (w *ChangeWatcher) check() bool {
+	changed, err := w.cfg.Source.ExternalChange()
+	if err != nil {
+		if w.cfg.Logger != nil {
+			w.cfg.Logger.Printf("streamers: failed to check for external changes: %v", err)
+		}
+		return false
+	}
+	if !changed {
+		return false
+	}
+	if w.cfg.Logger != nil {
+		w.cfg.Logger.Printf("streamers: store changed outside this process, publishing resync")
+	}
+	w.cfg.Events.Publish(EventResync, Record{})
+	return true
+}
+
+// Stop cancels the watcher and waits for its goroutine to exit.
+func (w *ChangeWatcher) Stop() {
+	if w == nil {
+		return
+	}
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+}
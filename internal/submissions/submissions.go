@@ -8,24 +8,54 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"live-stream-alerts/internal/filestore"
 )
 
 const (
-	// DefaultFilePath is where pending submissions are stored.
+	// DefaultFilePath is where submissions and their review decisions are stored.
 	DefaultFilePath = "data/submissions.json"
 )
 
 var (
 	// ErrNotFound is returned when a submission ID cannot be located.
 	ErrNotFound = errors.New("submission not found")
+	// ErrNotPending is returned when a submission has already been reviewed or withdrawn.
+	ErrNotPending = errors.New("submission is no longer pending")
+)
+
+// Status is where a submission is in the review lifecycle.
+type Status string
+
+const (
+	// StatusPending submissions await review. Only they can be edited or decided.
+	StatusPending Status = "pending"
+	// StatusApproved submissions were turned into a streamer record (see StreamerID).
+	StatusApproved Status = "approved"
+	// StatusRejected submissions were turned down, usually with a Reason.
+	StatusRejected Status = "rejected"
+	// StatusWithdrawn submissions were taken back at the submitter's request.
+	StatusWithdrawn Status = "withdrawn"
 )
 
+// Valid reports whether s is one of the known statuses.
+func (s Status) Valid() bool {
+	switch s {
+	case StatusPending, StatusApproved, StatusRejected, StatusWithdrawn:
+		return true
+	default:
+		return false
+	}
+}
+
 // Store persists submissions to disk behind a per-path mutex.
 type Store struct {
 	path        string
 	mu          sync.Mutex
 	now         func() time.Time
 	idGenerator func() string
+	backups     filestore.Backups
+	lockTimeout time.Duration
 }
 
 var storeCache sync.Map
@@ -36,7 +66,8 @@ type File struct {
 	Submissions []Submission `json:"submissions"`
 }
 
-// Submission captures the data submitted by a user awaiting admin review.
+// Submission captures the data submitted by a user and the outcome of its review.
+// Reviewed submissions are kept, rather than deleted, so past decisions can be listed.
 type Submission struct {
 	ID          string    `json:"id"`
 	Alias       string    `json:"alias"`
@@ -45,6 +76,21 @@ type Submission struct {
 	PlatformURL string    `json:"platformUrl,omitempty"`
 	SubmittedAt time.Time `json:"submittedAt"`
 	SubmittedBy string    `json:"submittedBy,omitempty"`
+	Status      Status    `json:"status"`
+	// UpdatedAt and UpdatedBy record the last reviewer edit before the decision.
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+	// ReviewedAt, ReviewedBy and Reason record the decision once Status leaves pending.
+	ReviewedAt time.Time `json:"reviewedAt,omitempty"`
+	ReviewedBy string    `json:"reviewedBy,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	// StreamerID is the streamer record created by an approval.
+	StreamerID string `json:"streamerId,omitempty"`
+}
+
+// Pending reports whether the submission still awaits review.
+func (s Submission) Pending() bool {
+	return s.Status == StatusPending
 }
 
 // StoreOption customises the store behaviour.
@@ -68,6 +114,20 @@ func WithIDGenerator(fn func() string) StoreOption {
 	}
 }
 
+// WithBackups overrides where backups are written and how many are retained.
+func WithBackups(backups filestore.Backups) StoreOption {
+	return func(s *Store) {
+		s.backups = backups
+	}
+}
+
+// WithLockTimeout bounds how long writes wait for another process holding the file lock.
+func WithLockTimeout(timeout time.Duration) StoreOption {
+	return func(s *Store) {
+		s.lockTimeout = timeout
+	}
+}
+
 // NewStore returns a file-backed submissions store for the provided path.
 func NewStore(path string, opts ...StoreOption) *Store {
 	if path == "" {
@@ -110,10 +170,58 @@ func (s *Store) readFileLocked() (File, error) {
 }
 
 func (s *Store) writeFileLocked(file File) error {
-	return writeFile(s.path, file)
+	data, err := writeFile(s.path, file)
+	if err != nil {
+		return err
+	}
+	// The data is already durable; a failed backup must not turn the write into an error.
+	_, _ = s.backups.Save(s.path, data)
+	return nil
+}
+
+// updateFileLocked runs a read-modify-write cycle. The caller holds s.mu, which serialises
+// goroutines; the advisory file lock extends that to other processes sharing the file.
+func (s *Store) updateFileLocked(updateFn func(*File) error) error {
+	lock, err := filestore.LockFile(s.path, s.lockTimeout)
+	if err != nil {
+		return fmt.Errorf("lock submissions file: %w", err)
+	}
+	defer lock.Unlock()
+
+	file, err := s.readFileLocked()
+	if err != nil {
+		return err
+	}
+	if err := updateFn(&file); err != nil {
+		return err
+	}
+	return s.writeFileLocked(file)
+}
+
+// Recover checks that the submissions file decodes and, when it does not, restores the
+// newest backup that does. Read errors are returned without restoring anything. It
+// returns the restored backup path, or "" when the file was readable.
+func (s *Store) Recover() (string, error) {
+	if s == nil {
+		return "", errors.New("submissions store is nil")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, err := filestore.LockFile(s.path, s.lockTimeout)
+	if err != nil {
+		return "", fmt.Errorf("lock submissions file: %w", err)
+	}
+	defer lock.Unlock()
+	return s.backups.RecoverCorrupt(s.path, func(data []byte) error {
+		var file File
+		if err := json.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("decode submissions file: %w", err)
+		}
+		return nil
+	})
 }
 
-// List returns every submission recorded at the provided path.
+// List returns every submission recorded at the provided path, pending or decided.
 func (s *Store) List() ([]Submission, error) {
 	if s == nil {
 		return nil, errors.New("submissions store is nil")
@@ -134,8 +242,8 @@ func List(path string) ([]Submission, error) {
 	return storeForPath(path).List()
 }
 
-// Remove deletes the submission with the specified ID and returns it.
-func (s *Store) Remove(id string) (Submission, error) {
+// Get returns the submission with the specified ID.
+func (s *Store) Get(id string) (Submission, error) {
 	if s == nil {
 		return Submission{}, errors.New("submissions store is nil")
 	}
@@ -145,19 +253,89 @@ func (s *Store) Remove(id string) (Submission, error) {
 	if err != nil {
 		return Submission{}, err
 	}
-	idx := -1
-	for i, sub := range file.Submissions {
+	for _, sub := range file.Submissions {
 		if sub.ID == id {
-			idx = i
-			break
+			return sub, nil
 		}
 	}
-	if idx == -1 {
-		return Submission{}, ErrNotFound
+	return Submission{}, ErrNotFound
+}
+
+// Update applies fn to the submission with the specified ID and saves the result. fn runs
+// under the store and file locks, so checks it makes (such as Pending) cannot race with
+// another update. The ID is preserved whatever fn does.
+func (s *Store) Update(id string, fn func(*Submission) error) (Submission, error) {
+	return s.UpdateChecked(id, fn, nil)
+}
+
+// UpdateChecked is Update with a check that, once fn has run, receives the updated
+// submission and every other one. Like AppendChecked's check it runs under the locks,
+// and nothing is saved when it fails. A nil check accepts everything.
+func (s *Store) UpdateChecked(id string, fn func(*Submission) error, check func(updated Submission, others []Submission) error) (Submission, error) {
+	if s == nil {
+		return Submission{}, errors.New("submissions store is nil")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var updated Submission
+	err := s.updateFileLocked(func(file *File) error {
+		for i := range file.Submissions {
+			if file.Submissions[i].ID != id {
+				continue
+			}
+			next := file.Submissions[i]
+			next.Languages = append([]string(nil), next.Languages...)
+			if err := fn(&next); err != nil {
+				return err
+			}
+			next.ID = id
+			if !next.Status.Valid() {
+				return fmt.Errorf("invalid submission status %q", next.Status)
+			}
+			if check != nil {
+				others := make([]Submission, 0, len(file.Submissions)-1)
+				others = append(others, file.Submissions[:i]...)
+				others = append(others, file.Submissions[i+1:]...)
+				if err := check(next, others); err != nil {
+					return err
+				}
+			}
+			file.Submissions[i] = next
+			updated = next
+			return nil
+		}
+		return ErrNotFound
+	})
+	if err != nil {
+		return Submission{}, err
+	}
+	return updated, nil
+}
+
+// Remove deletes the submission with the specified ID and returns it.
+func (s *Store) Remove(id string) (Submission, error) {
+	if s == nil {
+		return Submission{}, errors.New("submissions store is nil")
 	}
-	removed := file.Submissions[idx]
-	file.Submissions = append(file.Submissions[:idx], file.Submissions[idx+1:]...)
-	if err := s.writeFileLocked(file); err != nil {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var removed Submission
+	err := s.updateFileLocked(func(file *File) error {
+		idx := -1
+		for i, sub := range file.Submissions {
+			if sub.ID == id {
+				idx = i
+				break
+			}
+		}
+		if idx == -1 {
+			return ErrNotFound
+		}
+		removed = file.Submissions[idx]
+		file.Submissions = append(file.Submissions[:idx], file.Submissions[idx+1:]...)
+		return nil
+	})
+	if err != nil {
 		return Submission{}, err
 	}
 	return removed, nil
@@ -170,15 +348,19 @@ func Remove(path, id string) (Submission, error) {
 
 // Append stores a new submission entry.
 func (s *Store) Append(submission Submission) (Submission, error) {
+	return s.AppendChecked(submission, nil)
+}
+
+// AppendChecked stores a new submission entry once check accepts the existing
+// submissions. check runs under the store and file locks, so limits it enforces (such as
+// a cap on pending entries) cannot be raced past by concurrent appends. A nil check
+// accepts everything.
+func (s *Store) AppendChecked(submission Submission, check func(existing []Submission) error) (Submission, error) {
 	if s == nil {
 		return Submission{}, errors.New("submissions store is nil")
 	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	file, err := s.readFileLocked()
-	if err != nil {
-		return Submission{}, err
-	}
 	copy := submission
 	if copy.ID == "" {
 		copy.ID = s.idGenerator()
@@ -186,8 +368,19 @@ func (s *Store) Append(submission Submission) (Submission, error) {
 	if copy.SubmittedAt.IsZero() {
 		copy.SubmittedAt = s.now().UTC()
 	}
-	file.Submissions = append(file.Submissions, copy)
-	if err := s.writeFileLocked(file); err != nil {
+	if copy.Status == "" {
+		copy.Status = StatusPending
+	}
+	err := s.updateFileLocked(func(file *File) error {
+		if check != nil {
+			if err := check(file.Submissions); err != nil {
+				return err
+			}
+		}
+		file.Submissions = append(file.Submissions, copy)
+		return nil
+	})
+	if err != nil {
 		return Submission{}, err
 	}
 	return copy, nil
@@ -220,22 +413,28 @@ func readFile(path string) (File, error) {
 	if file.Submissions == nil {
 		file.Submissions = []Submission{}
 	}
+	for i := range file.Submissions {
+		// Files written before statuses existed only held pending submissions.
+		if file.Submissions[i].Status == "" {
+			file.Submissions[i].Status = StatusPending
+		}
+	}
 	return file, nil
 }
 
-func writeFile(path string, file File) error {
+func writeFile(path string, file File) ([]byte, error) {
 	if path == "" {
 		path = DefaultFilePath
 	}
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return fmt.Errorf("create submissions dir: %w", err)
+		return nil, fmt.Errorf("create submissions dir: %w", err)
 	}
 	data, err := json.MarshalIndent(file, "", "  ")
 	if err != nil {
-		return fmt.Errorf("encode submissions file: %w", err)
+		return nil, fmt.Errorf("encode submissions file: %w", err)
 	}
-	if err := os.WriteFile(path, data, 0o644); err != nil {
-		return fmt.Errorf("write submissions file: %w", err)
+	if err := filestore.WriteAtomic(path, data, 0o644); err != nil {
+		return nil, fmt.Errorf("write submissions file: %w", err)
 	}
-	return nil
+	return data, nil
 }
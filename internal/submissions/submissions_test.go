@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"live-stream-alerts/internal/filestore"
 	"live-stream-alerts/internal/submissions"
 )
 
@@ -90,7 +91,8 @@ func TestStoreRemove(t *testing.T) {
 
 func TestNewStoreCreatesDefaultPathWhenEmpty(t *testing.T) {
 	t.Setenv("HOME", t.TempDir())
-	store := submissions.NewStore("")
+	t.Chdir(t.TempDir())
+	store := submissions.NewStore("", submissions.WithBackups(filestore.Backups{Retention: -1}))
 	if store.Path() != submissions.DefaultFilePath {
 		t.Fatalf("expected default path, got %s", store.Path())
 	}
@@ -116,3 +118,73 @@ func TestStoreUsesInjectedIDGenerator(t *testing.T) {
 		t.Fatalf("expected injected ID generator to run, got %s", saved.ID)
 	}
 }
+
+func TestStoreRecoverRestoresNewestBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "subs.json")
+	store := submissions.NewStore(path, submissions.WithBackups(filestore.Backups{Dir: filepath.Join(dir, "bak"), Retention: 1}))
+	if _, err := store.Append(submissions.Submission{ID: "first", Alias: "First"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if _, err := store.Append(submissions.Submission{ID: "second", Alias: "Second"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dir, "bak")); len(entries) != 1 {
+		t.Fatalf("expected retention to keep one backup, got %d", len(entries))
+	}
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatalf("corrupt file: %v", err)
+	}
+
+	if _, err := store.Recover(); err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+	subs, err := store.List()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(subs) != 2 {
+		t.Fatalf("expected both submissions after recovery, got %+v", subs)
+	}
+}
+
+func TestStoreUpdateKeepsIDAndTreatsLegacyEntriesAsPending(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "subs.json")
+	if err := os.WriteFile(path, []byte(`{"submissions":[{"id":"legacy","alias":"Old","submittedAt":"2024-01-01T00:00:00Z"}]}`), 0o644); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	store := submissions.NewStore(path)
+	legacy, err := store.Get("legacy")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !legacy.Pending() {
+		t.Fatalf("expected a submission without status to be pending, got %q", legacy.Status)
+	}
+
+	updated, err := store.Update("legacy", func(sub *submissions.Submission) error {
+		sub.ID = "changed"
+		sub.Status = submissions.StatusRejected
+		sub.Reason = "spam"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if updated.ID != "legacy" || updated.Status != submissions.StatusRejected {
+		t.Fatalf("unexpected update result %+v", updated)
+	}
+	if _, err := store.Update("legacy", func(sub *submissions.Submission) error {
+		sub.Status = "archived"
+		return nil
+	}); err == nil {
+		t.Fatalf("expected an unknown status to be refused")
+	}
+	if _, err := store.Update("missing", func(*submissions.Submission) error { return nil }); err != submissions.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	list, _ := store.List()
+	if len(list) != 1 || list[0].Reason != "spam" {
+		t.Fatalf("expected the decision to be saved, got %+v", list)
+	}
+}